@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/api"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+)
+
+// startAPI starts the HTTP API on addr. It returns nil when addr is empty.
+func startAPI(addr string, mgr *services.Manager) (*api.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+
+	server := api.New(mgr, addr)
+	if err := server.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start api server: %w", err)
+	}
+	return server, nil
+}
+
+// tuiAPI serves the HTTP API from the TUI while it polls quotas itself,
+// either from startup or after taking over from a stopped daemon.
+type tuiAPI struct {
+	server *api.Server
+	addr   string
+	mu     sync.Mutex
+	closed bool
+}
+
+// start starts the API unless it already runs or close was called.
+func (a *tuiAPI) start(mgr *services.Manager) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed || a.server != nil {
+		return nil
+	}
+	server, err := startAPI(a.addr, mgr)
+	if err != nil {
+		return err
+	}
+	a.server = server
+	return nil
+}
+
+// takeOver starts the API once the manager polls in place of the daemon.
+func (a *tuiAPI) takeOver(mgr *services.Manager) {
+	if err := a.start(mgr); err != nil {
+		logger.Error("failed to start api server after the daemon stopped", "error", err)
+	}
+}
+
+// close stops the API and keeps a later takeover from starting it.
+func (a *tuiAPI) close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	if a.server != nil {
+		_ = a.server.Close()
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/daemon"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+)
+
+// runDaemon runs the service manager headless until SIGINT or SIGTERM.
+// Dashboards started while it runs read its data from the shared database.
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	pidPath := fs.String("pid-file", "", "PID/lock file (default: DAEMON_PID_FILE or next to the database)")
+	logPath := fs.String("log-file", "", "log file (default: DAEMON_LOG_FILE or next to the database)")
+	apiAddr := fs.String("api", "", "serve the HTTP API on a loopback host:port or unix:<path> (default: API_ADDR)")
+	readOnly := fs.Bool("read-only-accounts", false, "never write the accounts file (default: READ_ONLY_ACCOUNTS)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *pidPath == "" {
+		*pidPath = cfg.DaemonPIDPath
+	}
+	if *logPath == "" {
+		*logPath = cfg.DaemonLogPath
+	}
+	if *apiAddr == "" {
+		*apiAddr = cfg.APIAddr
+	}
+	if *readOnly {
+		cfg.ReadOnlyAccounts = true
+	}
+
+	pidFile, err := daemon.Acquire(*pidPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := pidFile.Release(); err != nil {
+			logger.Error("failed to release pid file", "error", err)
+		}
+	}()
+
+	logFile, err := os.OpenFile(*logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+	logger.SetOutput(logFile)
+
+	fmt.Printf("adt daemon running (pid %d), logging to %s\n", os.Getpid(), *logPath)
+	logger.Info("daemon starting", "pid", os.Getpid(), "database", cfg.DatabasePath, "pid_file", *pidPath)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	mgr, err := services.NewManager(context.Background(), cfg)
+	if err != nil {
+		logger.Error("failed to initialize services", "error", err)
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	apiServer, err := startAPI(*apiAddr, mgr)
+	if err != nil {
+		logger.Error("failed to start api server", "error", err)
+		_ = mgr.Close()
+		return err
+	}
+	if apiServer != nil {
+		logger.Info("api server listening", "addr", apiServer.Addr())
+	}
+
+	events, _ := mgr.Subscribe()
+
+	for running := true; running; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				running = false
+				break
+			}
+			if e, isErr := event.(services.ErrorEvent); isErr {
+				logger.Error("service error", "service", e.Service, "error", e.Error)
+			}
+
+		case sig := <-sigChan:
+			logger.Info("daemon shutting down", "signal", sig.String())
+			running = false
+		}
+	}
+
+	if apiServer != nil {
+		if err := apiServer.Close(); err != nil {
+			logger.Error("failed to close api server", "error", err)
+		}
+	}
+	if err := mgr.Close(); err != nil {
+		logger.Error("failed to close services", "error", err)
+		return fmt.Errorf("failed to close services: %w", err)
+	}
+	logger.Info("daemon stopped")
+	return nil
+}
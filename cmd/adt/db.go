@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
+)
+
+// runDB dispatches the "db" subcommands.
+func runDB(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: adt db migrate [--status] [--dry-run]")
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runDBMigrate(args[1:])
+	default:
+		return fmt.Errorf("unknown db command %q", args[0])
+	}
+}
+
+// runDBMigrate applies pending migrations or, with --status/--dry-run,
+// reports on them without changing the database.
+func runDBMigrate(args []string) error {
+	fs := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	status := fs.Bool("status", false, "list applied and pending migrations")
+	dryRun := fs.Bool("dry-run", false, "show pending migrations without applying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	database, err := db.Open(cfg.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	fmt.Printf("Database: %s\n", database.Path())
+
+	if *status {
+		return printMigrationStatus(database)
+	}
+
+	pending, err := database.PendingMigrations()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Printf("Schema is up to date (version %d)\n", db.LatestSchemaVersion())
+		return nil
+	}
+
+	if *dryRun {
+		fmt.Println("Pending migrations:")
+		for _, m := range pending {
+			fmt.Printf("  %3d  %s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	applied, err := database.Migrate()
+	for _, m := range applied {
+		fmt.Printf("Applied %3d  %s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+func printMigrationStatus(database *db.DB) error {
+	statuses, err := database.MigrationStatuses()
+	if err != nil {
+		return err
+	}
+
+	current, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema version: %d (latest %d)\n\n", current, db.LatestSchemaVersion())
+
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("  %3d  %-32s %s\n", s.Version, s.Name, state)
+	}
+	return nil
+}
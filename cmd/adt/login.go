@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/login"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+)
+
+// runLogin signs in with Google in the browser and adds the account to the
+// accounts file, or re-authenticates it if it is already there.
+func runLogin(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	noBrowser := fs.Bool("no-browser", false, "only print the sign-in URL instead of opening a browser")
+	timeout := fs.Duration("timeout", login.DefaultTimeout, "how long to wait for the sign-in to complete")
+	redirect := fs.String("redirect-url", "", "loopback redirect URL (default: LOGIN_REDIRECT_URL or a free port)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.ReadOnlyAccounts {
+		return fmt.Errorf("cannot log in: %w", accounts.ErrReadOnly)
+	}
+
+	logger.SetOutput(io.Discard)
+
+	svc, err := accounts.New(cfg.AccountsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load accounts: %w", err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	loginConfig := login.FromConfig(cfg)
+	if *redirect != "" {
+		loginConfig.RedirectURL = *redirect
+	}
+	loginConfig.Open = func(authURL string) error {
+		fmt.Printf("Open this URL to sign in:\n\n  %s\n\n", authURL)
+		if !*noBrowser {
+			if err := login.OpenBrowser(authURL); err != nil {
+				fmt.Printf("Could not open a browser (%v); open the URL manually.\n", err)
+			}
+		}
+		fmt.Println("Waiting for the sign-in to complete...")
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	result, err := login.Run(ctx, loginConfig)
+	if err != nil {
+		return err
+	}
+	added, err := login.Save(svc, result)
+	if err != nil {
+		return err
+	}
+
+	if added {
+		fmt.Printf("Added %s to %s\n", result.Email, cfg.AccountsPath)
+	} else {
+		fmt.Printf("Re-authenticated %s\n", result.Email)
+	}
+	return nil
+}
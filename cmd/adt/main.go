@@ -3,6 +3,9 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -12,7 +15,9 @@ import (
 
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/app"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/login"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/tabs/accounts"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/tabs/dashboard"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/tabs/history"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/tabs/info"
@@ -32,24 +37,78 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle subcommands
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			os.Exit(0)
+		}
+	}
+
 	// Run the application
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// subcommands maps command names to their handlers. Each handler receives the
+// arguments following the command name.
+var subcommands = map[string]func(args []string) error{
+	"db":          runDB,
+	"daemon":      runDaemon,
+	"login":       runLogin,
+	"mock-server": runMockServer,
+	"notify":      runNotify,
+	"status":      runStatus,
+	"statusline":  runStatusline,
+}
+
+// exitError is returned by a subcommand that needs an exit code other than 1.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit code for a subcommand error.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
+}
+
 // run contains the main application logic, separated for cleaner error handling.
-func run() error {
+func run(args []string) error {
+	fs := flag.NewFlagSet("adt", flag.ContinueOnError)
+	readOnly := fs.Bool("read-only-accounts", false, "never write the accounts file (default: READ_ONLY_ACCOUNTS)")
+	fs.Usage = printUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
 	// 1. Load configuration from .env files and environment variables
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if *readOnly {
+		cfg.ReadOnlyAccounts = true
+	}
 
 	// 2. Initialize the service manager
-	// This starts all background services: accounts and quota fetching
-	svcManager, err := services.NewManager(cfg)
+	// This starts all background services: accounts and quota fetching.
+	// While `adt daemon` runs, quotas are read from its database instead.
+	apiServer := &tuiAPI{addr: cfg.APIAddr}
+	svcManager, err := services.NewManager(context.Background(), cfg,
+		services.FollowDaemon(cfg.DaemonPIDPath), services.OnTakeover(apiServer.takeOver))
 	if err != nil {
 		return fmt.Errorf("failed to initialize services: %w", err)
 	}
@@ -61,6 +120,15 @@ func run() error {
 		}
 	}()
 
+	// Serve the HTTP API if configured. A running daemon serves it instead,
+	// until it stops and the manager takes over.
+	defer apiServer.close()
+	if !svcManager.FollowingDaemon() {
+		if err := apiServer.start(svcManager); err != nil {
+			return err
+		}
+	}
+
 	// 3. Create the root Bubble Tea model
 	model := app.NewModel(svcManager)
 
@@ -68,11 +136,15 @@ func run() error {
 	// Each tab receives the shared application state for consistent data access
 	state := model.GetState()
 	tabs := []app.Tab{
-		dashboard.New(state),           // Tab 0: Dashboard - quota overview
-		history.New(state, svcManager), // Tab 1: History - usage history
-		info.New(state, cfg),           // Tab 2: Info - configuration and app info
+		dashboard.New(state),                      // Tab 0: Dashboard - quota overview
+		accounts.New(state, cfg.ReadOnlyAccounts), // Tab 1: Accounts - manage accounts
+		history.New(state, svcManager),            // Tab 2: History - usage history
+		info.New(state, cfg),                      // Tab 3: Info - configuration and app info
 	}
 	model.SetTabs(tabs)
+	if !cfg.ReadOnlyAccounts {
+		model.SetLogin(login.FromConfig(cfg))
+	}
 
 	// 5. Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -106,25 +178,73 @@ func printUsage() {
 	fmt.Println(`Antigravity Dashboard TUI - Multi-account Google Cloud quota monitor
 
 Usage:
-  antigravity [flags]
+  adt [flags]
+  adt <command> [args]
+
+Commands:
+  login           Sign in with Google in the browser and add the account,
+                  or re-authenticate it (--no-browser, --timeout, --redirect-url)
+  daemon          Collect quotas in the background without the TUI
+                  (--pid-file, --log-file and --api override the defaults;
+                  --read-only-accounts as below)
+  status          Refresh all accounts once and print their quota, or read
+                  them from the running daemon (--cached, --json, --format, --account, --model;
+                  --check/--min-remaining exit 2 when quota is low)
+  statusline      Print a one-line quota summary for tmux, prompts and bars
+                  (--output plain|ansi|tmux|i3blocks|waybar, --format, --account)
+  notify test     Send a test notification to every configured notifier
+                  (--notifier limits it to one, --title and --body set the text)
+  mock-server     Serve fake OAuth and quota endpoints with scripted quota
+                  curves and failures, for demos and tests without Google
+                  (--addr, --scenario, --speed, --accounts-file, --print-scenario)
+  db migrate      Apply pending database migrations
+                  (--status lists applied/pending, --dry-run shows what would run)
 
 Flags:
   -h, --help      Show this help message
   -v, --version   Show version information
+  --read-only-accounts
+                  Never write the accounts file (no switching or rotation)
 
 Keyboard Shortcuts:
-  1-3             Switch between tabs (Dashboard, History, Info)
+  1-4             Switch between tabs (Dashboard, Accounts, History, Info)
   Tab/Shift+Tab   Navigate between tabs
   j/k, Up/Down    Navigate lists
   Enter           Select/confirm
   r               Refresh data
+  s, d, e, f      Accounts tab: set active, delete, rename, refresh quota
+  p               Accounts tab: pause or resume polling of an account
+  a               Accounts tab: log in to add or re-authenticate an account
+  u               Undo the pending or last automatic account rotation
   ?               Toggle help
   q, Ctrl+C       Quit
 
 Environment Variables:
   DATABASE_PATH           SQLite database path
   ACCOUNTS_PATH           Accounts JSON file path
-  QUOTA_REFRESH_INTERVAL  Quota polling interval (default: 30s)
+  QUOTA_REFRESH_INTERVAL  Base quota polling interval per account (default: 30s)
+  QUOTA_REFRESH_MIN_INTERVAL
+                          Fastest polling while an account is in use (default: 10s)
+  QUOTA_REFRESH_MAX_INTERVAL
+                          Slowest polling while an account is idle (default: 5m)
+  QUOTA_REFRESH_JITTER    Random spread of each poll interval (default: 0.1)
+  DAEMON_PID_FILE         Daemon PID/lock file (default: next to the database)
+  DAEMON_LOG_FILE         Daemon log file (default: next to the database)
+  STATUSLINE_CACHE_FILE   adt statusline cache (default: next to the database)
+  ALERT_RULES_FILE        Alert rules JSON (default: alerts.json next to the database)
+  HOOKS_FILE              Event hook commands JSON (default: hooks.json next to the database)
+  API_ADDR                Serve the HTTP API on a loopback host:port or unix:<path>
+  AUTO_ROTATE             Switch the active account when it is rate limited or CRITICAL
+  AUTO_ROTATE_STRATEGY    most_remaining (default), soonest_reset or round_robin
+  AUTO_ROTATE_DELAY       Countdown before an automatic switch (default: 30s)
+  AUTO_ROTATE_MODEL       Glob of the models that trigger a rotation (default: all)
+  READ_ONLY_ACCOUNTS      Never write the accounts file, like --read-only-accounts
+  GOOGLE_AUTH_URL         OAuth authorization endpoint for adt login
+  GOOGLE_TOKEN_URL        OAuth token endpoint for adt login and token refreshes
+  GOOGLE_USERINFO_URL     Endpoint that identifies a signed-in account
+  ANTIGRAVITY_ENDPOINTS   Comma-separated Cloud Code API base URLs, tried in order
+  ANTIGRAVITY_HEADERS     JSON object of headers merged over the quota request headers
+  LOGIN_REDIRECT_URL      Loopback redirect for adt login (default: a free port)
 
 Configuration:
   The application looks for .env files in the following locations:
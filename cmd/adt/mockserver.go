@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/mockserver"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+)
+
+// runMockServer serves fake OAuth, userinfo and quota endpoints until
+// SIGINT or SIGTERM, and prints the environment that points adt at them.
+func runMockServer(args []string) error {
+	fs := flag.NewFlagSet("mock-server", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8089", "address to listen on")
+	scenarioPath := fs.String("scenario", "", "scenario JSON file (default: the built-in scenario)")
+	speed := fs.Float64("speed", 1, "run the scenario clock this many times faster than real time")
+	accountsPath := fs.String("accounts-file", "", "add the scenario accounts to this accounts file")
+	printScenario := fs.Bool("print-scenario", false, "print the built-in scenario as JSON and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *printScenario {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(mockserver.DefaultScenario())
+	}
+
+	scenario := mockserver.DefaultScenario()
+	if *scenarioPath != "" {
+		var err error
+		if scenario, err = mockserver.LoadScenario(*scenarioPath); err != nil {
+			return err
+		}
+	}
+
+	if *accountsPath != "" {
+		if err := writeMockAccounts(*accountsPath, scenario); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", *addr, err)
+	}
+	srv := &http.Server{
+		Handler:           logRequests(mockserver.New(scenario, mockserver.Speed(*speed)).Handler()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	base := "http://" + listener.Addr().String()
+	fmt.Printf("adt mock-server listening on %s (%d accounts, speed %gx)\n\n", base, len(scenario.Accounts), *speed)
+	fmt.Println("Point adt at it with:")
+	fmt.Println()
+	fmt.Println("  export GOOGLE_CLIENT_ID=mock GOOGLE_CLIENT_SECRET=mock")
+	fmt.Printf("  export GOOGLE_AUTH_URL=%s%s\n", base, mockserver.AuthPath)
+	fmt.Printf("  export GOOGLE_TOKEN_URL=%s%s\n", base, mockserver.TokenPath)
+	fmt.Printf("  export GOOGLE_USERINFO_URL=%s%s\n", base, mockserver.UserInfoPath)
+	fmt.Printf("  export ANTIGRAVITY_ENDPOINTS=%s/primary,%s/fallback\n", base, base)
+	if *accountsPath != "" {
+		dir := filepath.Dir(*accountsPath)
+		fmt.Printf("  export ACCOUNTS_PATH=%s DATABASE_PATH=%s\n", *accountsPath, filepath.Join(dir, "mock-usage.db"))
+	}
+	fmt.Println()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- srv.Serve(listener) }()
+
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("mock server stopped: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down mock server: %w", err)
+	}
+	return nil
+}
+
+// writeMockAccounts adds the scenario accounts that are not there yet to the
+// accounts file at path.
+func writeMockAccounts(path string, scenario *mockserver.Scenario) error {
+	svc, err := accounts.New(path)
+	if err != nil {
+		return fmt.Errorf("failed to load accounts: %w", err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	for i := range scenario.Accounts {
+		acc := scenario.Accounts[i].ToAccount()
+		if svc.GetAccountByEmail(acc.Email) != nil {
+			continue
+		}
+		if err := svc.AddAccount(&acc); err != nil {
+			return fmt.Errorf("failed to add account %s: %w", acc.Email, err)
+		}
+	}
+	return nil
+}
+
+// statusRecorder remembers the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests prints one line per request: time, method, path and status.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Printf("%s %-4s %-45s %d\n", time.Now().Format(time.TimeOnly), r.Method, r.URL.Path, rec.status)
+	})
+}
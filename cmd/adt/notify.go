@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/alerts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/notify"
+)
+
+// runNotify dispatches the "notify" subcommands.
+func runNotify(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: adt notify test [--notifier NAME] [--title TEXT] [--body TEXT]")
+	}
+
+	switch args[0] {
+	case "test":
+		return runNotifyTest(args[1:])
+	default:
+		return fmt.Errorf("unknown notify command %q", args[0])
+	}
+}
+
+// runNotifyTest sends a test message to the configured notifiers once,
+// without retries or queueing, and reports each result.
+func runNotifyTest(args []string) error {
+	fs := flag.NewFlagSet("notify test", flag.ContinueOnError)
+	var names stringList
+	fs.Var(&names, "notifier", "only send to this notifier (repeatable; default: all)")
+	title := fs.String("title", "Antigravity Dashboard test", "notification title")
+	body := fs.String("body", "Notifications from adt reach this destination.", "notification body")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	alertConfig, err := alerts.LoadConfig(cfg.AlertRulesPath)
+	if err != nil {
+		return err
+	}
+	notifiers, err := notify.Build(alertConfig.Notifiers)
+	if err != nil {
+		return fmt.Errorf("invalid notifiers in %s: %w", cfg.AlertRulesPath, err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	d := notify.NewDispatcher(ctx, notifiers, nil)
+
+	for _, name := range names {
+		if !slices.Contains(d.Names(), name) {
+			return fmt.Errorf("unknown notifier %q (configured: %v)", name, d.Names())
+		}
+	}
+
+	logger.SetOutput(io.Discard)
+
+	results := d.Test(notify.Message{
+		At:       time.Now(),
+		RuleID:   "test",
+		Kind:     "test",
+		Severity: alerts.SeverityInfo,
+		Title:    *title,
+		Body:     *body,
+	}, names)
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("%-16s FAILED  %v\n", r.Name, r.Err)
+			continue
+		}
+		fmt.Printf("%-16s ok\n", r.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notifiers failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/status"
+)
+
+// defaultMinRemaining is the --check threshold when --min-remaining is unset.
+const defaultMinRemaining = 10
+
+// runStatus refreshes every account once, or reads the database with
+// --cached or while the daemon runs, and prints the result. With --check it exits with code 2 when a
+// selected account or model is below the threshold.
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	cached := fs.Bool("cached", false, "read last-known quotas from the database instead of refreshing")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	format := fs.String("format", "", "print the report with a Go template, e.g. '{{range .Accounts}}{{.Email}} {{percent .RemainingPercent}}\\n{{end}}'")
+	check := fs.Bool("check", false, fmt.Sprintf("exit with code 2 if a selected model is below --min-remaining (default %d%%)", defaultMinRemaining))
+	minRemaining := fs.Float64("min-remaining", defaultMinRemaining, "minimum remaining percent for --check; implies --check")
+	quiet := fs.Bool("quiet", false, "print nothing, only set the exit code")
+	var accountTerms, modelTerms stringList
+	fs.Var(&accountTerms, "account", "only include accounts whose email contains this (repeatable)")
+	fs.Var(&modelTerms, "model", "only include models whose ID, name or family contains this (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *asJSON && *format != "" {
+		return fmt.Errorf("--json and --format cannot be combined")
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "min-remaining" {
+			*check = true
+		}
+	})
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Failures are reported per account in the output; log lines would only
+	// interleave with it.
+	logger.SetOutput(io.Discard)
+
+	var report *status.Report
+	if *cached {
+		report, err = status.Cached(cfg)
+	} else {
+		report, err = status.Live(context.Background(), cfg)
+	}
+	if err != nil {
+		return err
+	}
+	report.Filter(accountTerms, modelTerms)
+
+	if !*quiet {
+		switch {
+		case *asJSON:
+			err = report.WriteJSON(os.Stdout)
+		case *format != "":
+			err = report.WriteTemplate(os.Stdout, unescapeFormat(*format))
+		default:
+			err = report.WriteTable(os.Stdout)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !*check {
+		return nil
+	}
+	if len(report.Accounts) == 0 {
+		return &exitError{code: 2, err: fmt.Errorf("no accounts match the selection")}
+	}
+	if violations := report.Check(*minRemaining); len(violations) > 0 {
+		lines := make([]string, 0, len(violations))
+		for _, v := range violations {
+			lines = append(lines, "  "+v.String())
+		}
+		return &exitError{
+			code: 2,
+			err:  fmt.Errorf("quota check failed (minimum %g%% remaining):\n%s", *minRemaining, strings.Join(lines, "\n")),
+		}
+	}
+	return nil
+}
+
+// unescapeFormat turns the \n and \t escapes typed on a command line into
+// real characters.
+func unescapeFormat(format string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(format)
+}
+
+// stringList is a repeatable string flag that also accepts comma-separated
+// values.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/statusline"
+)
+
+// runStatusline prints a one-line quota summary from the database. Results
+// are cached so bars can call it every few seconds.
+func runStatusline(args []string) error {
+	fs := flag.NewFlagSet("statusline", flag.ContinueOnError)
+	output := fs.String("output", statusline.OutputPlain, "output style: "+strings.Join(statusline.Outputs, ", "))
+	format := fs.String("format", "", "Go template for the text (default: "+statusline.DefaultTemplate+")")
+	account := fs.String("account", "", "show the first account whose email contains this (default: the active account)")
+	maxAge := fs.Duration("max-age", 30*time.Second, "reuse cached data for this long unless the database changed; 0 disables the cache")
+	cacheFile := fs.String("cache-file", "", "cache file (default: STATUSLINE_CACHE_FILE or next to the database)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *cacheFile == "" {
+		*cacheFile = cfg.StatuslineCachePath
+	}
+
+	// Bars display whatever is printed; keep log lines out of it.
+	logger.SetOutput(io.Discard)
+
+	var data *statusline.Data
+	if *maxAge > 0 {
+		data, err = statusline.LoadCached(cfg, *account, *cacheFile, *maxAge)
+	} else {
+		data, err = statusline.Load(cfg, *account)
+	}
+	if err != nil {
+		return err
+	}
+
+	return statusline.Render(os.Stdout, data, *output, unescapeFormat(*format), time.Now())
+}
@@ -11,6 +11,7 @@ require (
 	github.com/gen2brain/beeep v0.11.2
 	github.com/guptarohit/asciigraph v0.7.3
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/sync v0.16.0
 	modernc.org/sqlite v1.43.0
 )
 
@@ -45,7 +46,6 @@ require (
 	github.com/tadvi/systray v0.0.0-20190226123456-11a2b8fa57af // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
-	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.3.8 // indirect
 	modernc.org/libc v1.66.10 // indirect
@@ -0,0 +1,421 @@
+package alerts
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// Alert is a notification produced by a rule.
+type Alert struct {
+	At       time.Time
+	RuleID   string
+	Kind     Kind
+	Severity string
+	Email    string
+	Model    string
+	Title    string
+	Body     string
+	// Notifiers are the notifiers the alert goes to; empty means all.
+	Notifiers []string
+}
+
+// Store persists alert state between runs. *db.DB implements it.
+type Store interface {
+	GetAlertStates() ([]models.AlertState, error)
+	UpsertAlertState(state *models.AlertState) error
+}
+
+// stateKey identifies the state of a rule for one account and model.
+type stateKey struct {
+	rule  string
+	email string
+	model string
+}
+
+// Engine evaluates rules against observations and delivers the alerts that
+// fire. It is safe for concurrent use.
+type Engine struct {
+	store   Store
+	deliver func(Alert)
+	now     func() time.Time
+	quiet   *QuietHours
+	states  map[stateKey]*models.AlertState
+	rules   []Rule
+	mu      sync.Mutex
+}
+
+// NewEngine creates an engine for cfg and restores the state persisted in
+// store, which may be nil. deliver is called for every alert that is not
+// suppressed by a cooldown or quiet hours.
+func NewEngine(cfg *Config, store Store, deliver func(Alert)) (*Engine, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		store:   store,
+		deliver: deliver,
+		now:     time.Now,
+		quiet:   cfg.QuietHours,
+		states:  make(map[stateKey]*models.AlertState),
+	}
+	for _, r := range cfg.Rules {
+		if !r.Disabled {
+			e.rules = append(e.rules, r)
+		}
+	}
+
+	if store != nil {
+		states, err := store.GetAlertStates()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load alert state: %w", err)
+		}
+		for i := range states {
+			s := states[i]
+			e.states[stateKey{s.RuleID, s.Email, s.Model}] = &s
+		}
+	}
+	return e, nil
+}
+
+// Rules returns the enabled rules.
+func (e *Engine) Rules() []Rule {
+	return append([]Rule(nil), e.rules...)
+}
+
+// evaluation collects the state changes and alerts of one observation, so
+// they are persisted and delivered after the engine lock is released.
+type evaluation struct {
+	changed []models.AlertState
+	alerts  []Alert
+}
+
+// ObserveQuota evaluates the quota, reset and tier rules against a
+// successful refresh, and clears token failures and revoked tokens of the
+// account.
+func (e *Engine) ObserveQuota(qi *models.QuotaInfo) {
+	if qi == nil || qi.IsStale() {
+		return
+	}
+	email := qi.AccountEmail
+
+	var ev evaluation
+	e.mu.Lock()
+	now := e.now()
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !r.matchesAccount(email) {
+			continue
+		}
+		switch r.Kind {
+		case KindQuotaBelow, KindQuotaReset:
+			for j := range qi.ModelQuotas {
+				mq := &qi.ModelQuotas[j]
+				if mq.Limit <= 0 && !mq.IsRateLimited {
+					continue
+				}
+				if r.matchesModel(mq.ModelID, mq.ModelFamily) {
+					e.evaluateQuota(&ev, r, email, mq, now)
+				}
+			}
+		case KindTierChange:
+			e.evaluateTier(&ev, r, email, qi.SubscriptionTier, now)
+		case KindTokenFailure, KindTokenRevoked:
+			e.transition(&ev, r, email, "", false, true, now, nil)
+		}
+	}
+	e.mu.Unlock()
+
+	e.finish(&ev)
+}
+
+// ObserveProjection evaluates the projection rules. Per-model projections
+// are used when available; family rollups only stand in for accounts without
+// them.
+func (e *Engine) ObserveProjection(ap *models.AccountProjection) {
+	if ap == nil {
+		return
+	}
+	projections := ap.Models
+	if len(projections) == 0 {
+		for _, mp := range []*models.ModelProjection{ap.Claude, ap.Gemini} {
+			if mp != nil {
+				projections = append(projections, mp)
+			}
+		}
+	}
+
+	var ev evaluation
+	e.mu.Lock()
+	now := e.now()
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !r.matchesAccount(ap.Email) {
+			continue
+		}
+		for _, mp := range projections {
+			if !r.matchesModel(mp.Model, mp.Family) {
+				continue
+			}
+			// An unknown projection neither fires nor clears an alert.
+			known := mp.Status != models.ProjectionUnknown
+			switch r.Kind {
+			case KindProjectionStatus:
+				firing := mp.Status.Severity() >= r.Status.Severity()
+				e.transition(&ev, r, ap.Email, mp.Model, firing, known && !firing, now, func() Alert {
+					return Alert{
+						Severity: statusSeverity(mp.Status),
+						Title:    fmt.Sprintf("Projection %s: %s", mp.Status, ap.Email),
+						Body:     fmt.Sprintf("%s is at %.1f%% and %s at the current rate", projectionName(mp), mp.CurrentPercent, hoursLeft(mp)),
+					}
+				})
+			case KindDepleteBeforeReset:
+				e.transition(&ev, r, ap.Email, mp.Model, mp.WillDepleteBefore, known && !mp.WillDepleteBefore, now, func() Alert {
+					return Alert{
+						Severity: SeverityWarning,
+						Title:    fmt.Sprintf("Will run out before reset: %s", ap.Email),
+						Body: fmt.Sprintf("%s %s, but resets in %s", projectionName(mp), hoursLeft(mp),
+							mp.TimeUntilReset.Round(time.Minute)),
+					}
+				})
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	e.finish(&ev)
+}
+
+// ObserveTokenFailure evaluates the token failure rules for an account.
+func (e *Engine) ObserveTokenFailure(email string, err error) {
+	var ev evaluation
+	e.mu.Lock()
+	now := e.now()
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.Kind != KindTokenFailure || !r.matchesAccount(email) {
+			continue
+		}
+		e.transition(&ev, r, email, "", true, false, now, func() Alert {
+			body := "The OAuth token could not be refreshed"
+			if err != nil {
+				body += ": " + err.Error()
+			}
+			return Alert{
+				Severity: SeverityCritical,
+				Title:    fmt.Sprintf("Token refresh failed: %s", email),
+				Body:     body,
+			}
+		})
+	}
+	e.mu.Unlock()
+
+	e.finish(&ev)
+}
+
+// ObserveTokenRevoked evaluates the revoked token rules for an account whose
+// refresh token was rejected as revoked or expired.
+func (e *Engine) ObserveTokenRevoked(email string, err error) {
+	var ev evaluation
+	e.mu.Lock()
+	now := e.now()
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.Kind != KindTokenRevoked || !r.matchesAccount(email) {
+			continue
+		}
+		e.transition(&ev, r, email, "", true, false, now, func() Alert {
+			body := "The refresh token was revoked or has expired. Polling is paused until you run adt login"
+			if err != nil {
+				body += " (" + err.Error() + ")"
+			}
+			return Alert{
+				Severity: SeverityCritical,
+				Title:    fmt.Sprintf("Log in again: %s", email),
+				Body:     body,
+			}
+		})
+	}
+	e.mu.Unlock()
+
+	e.finish(&ev)
+}
+
+func (e *Engine) evaluateQuota(ev *evaluation, r *Rule, email string, mq *models.ModelQuota, now time.Time) {
+	percent := mq.RemainingPercent()
+	model := modelKey(mq)
+
+	if r.Kind == KindQuotaBelow {
+		firing := percent < r.Threshold
+		cleared := percent >= r.Threshold+r.Hysteresis
+		e.transition(ev, r, email, model, firing, cleared, now, func() Alert {
+			body := fmt.Sprintf("%s is at %.1f%% (below %g%%)", mq.Name(), percent, r.Threshold)
+			if mq.IsRateLimited {
+				body = mq.Name() + " is rate limited"
+			}
+			return Alert{
+				Severity: SeverityWarning,
+				Title:    fmt.Sprintf("Low quota: %s", email),
+				Body:     body,
+			}
+		})
+		return
+	}
+
+	// quota_reset compares against the previous refresh.
+	st := e.state(r.ID, email, model)
+	previous, err := strconv.ParseFloat(st.Value, 64)
+	if value := strconv.FormatFloat(percent, 'f', 1, 64); value != st.Value {
+		st.Value = value
+		e.changed(ev, st, now)
+	}
+	if err != nil || percent-previous < r.Threshold {
+		return
+	}
+	e.fire(ev, r, st, now, Alert{
+		Severity: SeverityInfo,
+		Title:    fmt.Sprintf("Quota reset: %s", email),
+		Body:     fmt.Sprintf("%s went from %.0f%% to %.0f%%", mq.Name(), previous, percent),
+	})
+}
+
+func (e *Engine) evaluateTier(ev *evaluation, r *Rule, email, tier string, now time.Time) {
+	if tier == "" {
+		return
+	}
+	st := e.state(r.ID, email, "")
+	previous := st.Value
+	if previous == tier {
+		return
+	}
+	st.Value = tier
+	e.changed(ev, st, now)
+	if previous == "" {
+		return
+	}
+	e.fire(ev, r, st, now, Alert{
+		Severity: SeverityInfo,
+		Title:    fmt.Sprintf("Tier changed: %s", email),
+		Body:     fmt.Sprintf("Subscription tier changed from %s to %s", previous, tier),
+	})
+}
+
+// transition applies hysteresis: a rule fires when its condition starts to
+// hold and does not fire again until the condition has cleared. A rule only
+// counts as firing once its alert was delivered, so an alert held back by a
+// cooldown or quiet hours fires on the first observation after they end if
+// the condition still holds.
+func (e *Engine) transition(
+	ev *evaluation, r *Rule, email, model string,
+	firing, cleared bool, now time.Time, build func() Alert,
+) {
+	st := e.state(r.ID, email, model)
+	switch {
+	case firing && !st.Firing:
+		if e.fire(ev, r, st, now, build()) {
+			st.Firing = true
+			e.changed(ev, st, now)
+		}
+	case cleared && st.Firing:
+		st.Firing = false
+		e.changed(ev, st, now)
+	}
+}
+
+// fire records an alert unless the rule's cooldown or quiet hours suppress
+// it, and reports whether it was recorded.
+func (e *Engine) fire(ev *evaluation, r *Rule, st *models.AlertState, now time.Time, alert Alert) bool {
+	if r.Cooldown > 0 && !st.LastFired.IsZero() && now.Sub(st.LastFired) < time.Duration(r.Cooldown) {
+		logger.Debug("alert suppressed by cooldown", "rule", r.ID, "email", st.Email, "model", st.Model)
+		return false
+	}
+	if !r.IgnoreQuietHours && e.quiet.Active(now.Local()) {
+		logger.Debug("alert suppressed by quiet hours", "rule", r.ID, "email", st.Email, "model", st.Model)
+		return false
+	}
+
+	st.LastFired = now
+	e.changed(ev, st, now)
+
+	alert.At = now
+	alert.RuleID = r.ID
+	alert.Kind = r.Kind
+	alert.Email = st.Email
+	alert.Model = st.Model
+	alert.Notifiers = r.Notifiers
+	ev.alerts = append(ev.alerts, alert)
+	return true
+}
+
+func (e *Engine) state(rule, email, model string) *models.AlertState {
+	key := stateKey{rule, email, model}
+	st, ok := e.states[key]
+	if !ok {
+		st = &models.AlertState{RuleID: rule, Email: email, Model: model}
+		e.states[key] = st
+	}
+	return st
+}
+
+func (e *Engine) changed(ev *evaluation, st *models.AlertState, now time.Time) {
+	st.UpdatedAt = now
+	for i := range ev.changed {
+		c := &ev.changed[i]
+		if c.RuleID == st.RuleID && c.Email == st.Email && c.Model == st.Model {
+			*c = *st
+			return
+		}
+	}
+	ev.changed = append(ev.changed, *st)
+}
+
+// finish persists changed state and delivers alerts.
+func (e *Engine) finish(ev *evaluation) {
+	if e.store != nil {
+		for i := range ev.changed {
+			if err := e.store.UpsertAlertState(&ev.changed[i]); err != nil {
+				logger.Error("failed to persist alert state", "rule", ev.changed[i].RuleID, "error", err)
+			}
+		}
+	}
+	if e.deliver == nil {
+		return
+	}
+	for _, alert := range ev.alerts {
+		e.deliver(alert)
+	}
+}
+
+// modelKey identifies a model in alert state.
+func modelKey(mq *models.ModelQuota) string {
+	if mq.ModelID != "" {
+		return mq.ModelID
+	}
+	return mq.ModelFamily
+}
+
+func projectionName(mp *models.ModelProjection) string {
+	if mp.DisplayName != "" {
+		return mp.DisplayName
+	}
+	return mp.Model
+}
+
+func hoursLeft(mp *models.ModelProjection) string {
+	if mp.SessionDepleteAt.IsZero() {
+		return "is not being consumed"
+	}
+	return fmt.Sprintf("runs out in %s", time.Duration(mp.SessionHoursLeft*float64(time.Hour)).Round(time.Minute))
+}
+
+func statusSeverity(s models.ProjectionStatus) string {
+	if s == models.ProjectionCritical {
+		return SeverityCritical
+	}
+	return SeverityWarning
+}
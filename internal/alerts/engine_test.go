@@ -0,0 +1,261 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// memStore keeps alert state in memory.
+type memStore struct {
+	states map[stateKey]models.AlertState
+}
+
+func (s *memStore) GetAlertStates() ([]models.AlertState, error) {
+	var out []models.AlertState
+	for _, st := range s.states {
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (s *memStore) UpsertAlertState(st *models.AlertState) error {
+	if s.states == nil {
+		s.states = make(map[stateKey]models.AlertState)
+	}
+	s.states[stateKey{st.RuleID, st.Email, st.Model}] = *st
+	return nil
+}
+
+type recorder struct {
+	alerts []Alert
+}
+
+func (r *recorder) deliver(a Alert) { r.alerts = append(r.alerts, a) }
+
+func newTestEngine(t *testing.T, cfg *Config, store Store, now *time.Time) (*Engine, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	e, err := NewEngine(cfg, store, rec.deliver)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	e.now = func() time.Time { return *now }
+	return e, rec
+}
+
+func quota(email string, remaining int64) *models.QuotaInfo {
+	return &models.QuotaInfo{
+		AccountEmail:     email,
+		SubscriptionTier: "PRO",
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-sonnet-4-5", ModelFamily: "claude", Limit: 100, Remaining: remaining},
+			{ModelID: "gemini-3-pro", ModelFamily: "gemini", Limit: 100, Remaining: 90},
+		},
+	}
+}
+
+func TestEngine_QuotaBelowHysteresis(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	cfg := &Config{Rules: []Rule{{ID: "low", Kind: KindQuotaBelow, Model: "claude", Threshold: 10, Hysteresis: 5}}}
+	e, rec := newTestEngine(t, cfg, nil, &now)
+
+	for _, remaining := range []int64{50, 9, 8, 12, 9} {
+		e.ObserveQuota(quota("a@example.com", remaining))
+	}
+	if len(rec.alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1 while within the hysteresis band: %+v", len(rec.alerts), rec.alerts)
+	}
+	a := rec.alerts[0]
+	if a.RuleID != "low" || a.Email != "a@example.com" || a.Model != "claude-sonnet-4-5" {
+		t.Errorf("alert = %+v", a)
+	}
+
+	e.ObserveQuota(quota("a@example.com", 15))
+	e.ObserveQuota(quota("a@example.com", 9))
+	if len(rec.alerts) != 2 {
+		t.Errorf("got %d alerts, want a second one after clearing", len(rec.alerts))
+	}
+
+	// Stale data must not change alert state.
+	stale := quota("a@example.com", 90)
+	stale.Cached = true
+	e.ObserveQuota(stale)
+	if st := e.states[stateKey{"low", "a@example.com", "claude-sonnet-4-5"}]; !st.Firing {
+		t.Error("cached quota cleared the alert")
+	}
+}
+
+func TestEngine_Cooldown(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	cfg := &Config{Rules: []Rule{{ID: "low", Kind: KindQuotaBelow, Threshold: 10, Cooldown: Duration(time.Hour)}}}
+	e, rec := newTestEngine(t, cfg, nil, &now)
+
+	e.ObserveQuota(quota("a@example.com", 5))
+	e.ObserveQuota(quota("a@example.com", 50))
+	now = now.Add(10 * time.Minute)
+	e.ObserveQuota(quota("a@example.com", 5))
+	if len(rec.alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1 within the cooldown", len(rec.alerts))
+	}
+
+	// The suppressed alert fires once the cooldown ends if the condition
+	// still holds, without having to clear first.
+	now = now.Add(time.Hour)
+	e.ObserveQuota(quota("a@example.com", 5))
+	if len(rec.alerts) != 2 {
+		t.Fatalf("got %d alerts, want 2 after the cooldown", len(rec.alerts))
+	}
+	e.ObserveQuota(quota("a@example.com", 4))
+	if len(rec.alerts) != 2 {
+		t.Errorf("got %d alerts, want no repeat while still firing", len(rec.alerts))
+	}
+}
+
+func TestEngine_QuietHours(t *testing.T) {
+	now := time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)
+	cfg := &Config{
+		QuietHours: &QuietHours{Start: "22:00", End: "07:00"},
+		Rules: []Rule{
+			{ID: "low", Kind: KindQuotaBelow, Threshold: 10},
+			{ID: "token", Kind: KindTokenFailure, IgnoreQuietHours: true},
+		},
+	}
+	e, rec := newTestEngine(t, cfg, nil, &now)
+
+	e.ObserveQuota(quota("a@example.com", 5))
+	if len(rec.alerts) != 0 {
+		t.Fatalf("alert delivered during quiet hours: %+v", rec.alerts)
+	}
+	if st := e.states[stateKey{"low", "a@example.com", "claude-sonnet-4-5"}]; st.Firing {
+		t.Error("suppressed alert was marked as firing")
+	}
+
+	// The condition still holds in the morning, so the alert is delivered.
+	now = now.Add(9 * time.Hour)
+	e.ObserveQuota(quota("a@example.com", 4))
+	if len(rec.alerts) != 1 || rec.alerts[0].RuleID != "low" {
+		t.Fatalf("suppressed alert not delivered after quiet hours: %+v", rec.alerts)
+	}
+
+	now = time.Date(2026, 1, 2, 23, 0, 0, 0, time.Local)
+	e.ObserveTokenFailure("a@example.com", errors.New("invalid_grant"))
+	if len(rec.alerts) != 2 || rec.alerts[1].RuleID != "token" {
+		t.Errorf("rule ignoring quiet hours was suppressed: %+v", rec.alerts)
+	}
+}
+
+func TestEngine_PersistsState(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	store := &memStore{}
+	cfg := DefaultConfig()
+
+	e, rec := newTestEngine(t, cfg, store, &now)
+	e.ObserveQuota(quota("a@example.com", 3))
+	e.ObserveTokenFailure("a@example.com", nil)
+	if len(rec.alerts) != 2 {
+		t.Fatalf("got %d alerts, want 2: %+v", len(rec.alerts), rec.alerts)
+	}
+
+	// A restarted engine sees the same conditions without re-firing.
+	restarted, rec := newTestEngine(t, DefaultConfig(), store, &now)
+	restarted.ObserveTokenFailure("a@example.com", nil)
+	restarted.ObserveQuota(quota("a@example.com", 3))
+	if len(rec.alerts) != 0 {
+		t.Errorf("restart re-fired alerts: %+v", rec.alerts)
+	}
+	if st := store.states[stateKey{"token-failure", "a@example.com", ""}]; st.Firing {
+		t.Error("successful refresh did not clear the token failure")
+	}
+}
+
+func TestEngine_TokenRevoked(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	store := &memStore{}
+	e, rec := newTestEngine(t, DefaultConfig(), store, &now)
+
+	e.ObserveTokenRevoked("a@example.com", errors.New("invalid_grant"))
+	now = now.Add(24 * time.Hour)
+	e.ObserveTokenRevoked("a@example.com", errors.New("invalid_grant"))
+	if len(rec.alerts) != 1 || rec.alerts[0].RuleID != "token-revoked" || rec.alerts[0].Email != "a@example.com" {
+		t.Fatalf("fired = %+v, want one token-revoked alert", rec.alerts)
+	}
+
+	// Logging in again clears it, so a later revocation is reported.
+	e.ObserveQuota(quota("a@example.com", 50))
+	e.ObserveTokenRevoked("a@example.com", nil)
+	if len(rec.alerts) != 2 {
+		t.Errorf("fired = %+v, want the new revocation reported", rec.alerts)
+	}
+}
+
+func TestEngine_QuotaResetAndTierChange(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	e, rec := newTestEngine(t, DefaultConfig(), nil, &now)
+
+	e.ObserveQuota(quota("a@example.com", 30))
+	e.ObserveQuota(quota("a@example.com", 40))
+	if len(rec.alerts) != 0 {
+		t.Fatalf("unexpected alerts: %+v", rec.alerts)
+	}
+
+	qi := quota("a@example.com", 100)
+	qi.SubscriptionTier = "ULTRA"
+	e.ObserveQuota(qi)
+	got := map[string]string{}
+	for _, a := range rec.alerts {
+		got[a.RuleID] = a.Body
+	}
+	if len(got) != 2 || got["quota-reset"] == "" || got["tier-change"] != "Subscription tier changed from PRO to ULTRA" {
+		t.Errorf("alerts = %+v, want a reset and a tier change", rec.alerts)
+	}
+}
+
+func TestEngine_Projections(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	cfg := &Config{Rules: []Rule{
+		{ID: "warn", Kind: KindProjectionStatus, Status: models.ProjectionWarning},
+		{ID: "deplete", Kind: KindDepleteBeforeReset, Account: "a@*"},
+	}}
+	e, rec := newTestEngine(t, cfg, nil, &now)
+
+	project := func(email string, status models.ProjectionStatus, deplete bool) {
+		e.ObserveProjection(&models.AccountProjection{
+			Email: email,
+			Models: []*models.ModelProjection{{
+				Model:             "claude-sonnet-4-5",
+				Family:            "claude",
+				Status:            status,
+				CurrentPercent:    40,
+				SessionHoursLeft:  2,
+				SessionDepleteAt:  now.Add(2 * time.Hour),
+				TimeUntilReset:    4 * time.Hour,
+				WillDepleteBefore: deplete,
+			}},
+		})
+	}
+
+	project("a@example.com", models.ProjectionSafe, false)
+	project("a@example.com", models.ProjectionWarning, true)
+	project("a@example.com", models.ProjectionCritical, true)
+	project("a@example.com", models.ProjectionUnknown, false)
+	if len(rec.alerts) != 2 {
+		t.Fatalf("got %d alerts, want warn and deplete once: %+v", len(rec.alerts), rec.alerts)
+	}
+	if rec.alerts[0].Severity != SeverityWarning {
+		t.Errorf("severity = %q, want warning", rec.alerts[0].Severity)
+	}
+
+	project("a@example.com", models.ProjectionSafe, false)
+	project("a@example.com", models.ProjectionCritical, false)
+	if len(rec.alerts) != 3 || rec.alerts[2].Severity != SeverityCritical {
+		t.Errorf("alerts = %+v, want a critical alert after recovering", rec.alerts)
+	}
+
+	project("b@example.com", models.ProjectionSafe, true)
+	if len(rec.alerts) != 3 {
+		t.Errorf("deplete rule fired for an account it does not match: %+v", rec.alerts[3:])
+	}
+}
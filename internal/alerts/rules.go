@@ -0,0 +1,267 @@
+// Package alerts evaluates configurable alert rules against quota,
+// projection and token events.
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/notify"
+)
+
+// Kind selects what a rule watches.
+type Kind string
+
+const (
+	// KindQuotaBelow fires when a model's remaining quota drops below
+	// Threshold percent. It clears once the quota is back above Threshold
+	// plus Hysteresis.
+	KindQuotaBelow Kind = "quota_below"
+	// KindQuotaReset fires when a model's remaining quota rises by at least
+	// Threshold percentage points between two refreshes.
+	KindQuotaReset Kind = "quota_reset"
+	// KindProjectionStatus fires when a model's projection reaches Status
+	// (WARNING or CRITICAL). It clears when the projection is less severe.
+	KindProjectionStatus Kind = "projection_status"
+	// KindDepleteBeforeReset fires when a model is projected to run out
+	// before its quota resets.
+	KindDepleteBeforeReset Kind = "deplete_before_reset"
+	// KindTokenFailure fires when an account's OAuth token cannot be
+	// refreshed. It clears after the next successful quota refresh.
+	KindTokenFailure Kind = "token_failure"
+	// KindTokenRevoked fires once when an account's refresh token is revoked
+	// or expired and the account has to log in again. It clears after the
+	// next successful quota refresh.
+	KindTokenRevoked Kind = "token_revoked"
+	// KindTierChange fires when an account's subscription tier changes.
+	KindTierChange Kind = "tier_change"
+)
+
+// Severities of an alert.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Config is the content of the alert rules file.
+type Config struct {
+	QuietHours *QuietHours `json:"quietHours,omitempty"`
+	Rules      []Rule      `json:"rules"`
+	// Notifiers are the destinations alerts are sent to; without any, alerts
+	// are shown as desktop notifications.
+	Notifiers []notify.Config `json:"notifiers,omitempty"`
+}
+
+// Rule is a single alert rule.
+type Rule struct {
+	ID   string `json:"id"`
+	Kind Kind   `json:"kind"`
+	// Account is a glob matched against the account email; empty matches all.
+	Account string `json:"account,omitempty"`
+	// Model is a glob matched against the model ID and its family, e.g.
+	// "claude" or "gemini-*"; empty matches all.
+	Model string `json:"model,omitempty"`
+	// Status is the minimum projection status for projection_status rules.
+	Status     models.ProjectionStatus `json:"status,omitempty"`
+	Threshold  float64                 `json:"threshold,omitempty"`
+	Hysteresis float64                 `json:"hysteresis,omitempty"`
+	// Cooldown is the minimum time between two notifications of the rule
+	// for the same account and model.
+	Cooldown         Duration `json:"cooldown,omitempty"`
+	IgnoreQuietHours bool     `json:"ignoreQuietHours,omitempty"`
+	Disabled         bool     `json:"disabled,omitempty"`
+	// Notifiers names the notifiers the rule's alerts go to; empty means all.
+	Notifiers []string `json:"notifiers,omitempty"`
+}
+
+// QuietHours suppresses notifications during a daily local time window,
+// e.g. 22:00 to 07:00. Alerts that fire while quiet are dropped, not queued.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	start int
+	end   int
+}
+
+// Duration is a time.Duration written as a string such as "30m" in JSON.
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DefaultConfig returns the rules used when no rules file exists. They cover
+// the alerts the dashboard always had, low quota and resets, per model, plus
+// critical projections, token failures, revoked tokens and tier changes.
+func DefaultConfig() *Config {
+	return &Config{
+		Rules: []Rule{
+			{ID: "quota-low", Kind: KindQuotaBelow, Threshold: 5, Hysteresis: 5},
+			{ID: "quota-reset", Kind: KindQuotaReset, Threshold: 20},
+			{ID: "projection-critical", Kind: KindProjectionStatus, Status: models.ProjectionCritical, Cooldown: Duration(time.Hour)},
+			{ID: "token-failure", Kind: KindTokenFailure, Cooldown: Duration(6 * time.Hour)},
+			{ID: "token-revoked", Kind: KindTokenRevoked},
+			{ID: "tier-change", Kind: KindTierChange},
+		},
+	}
+}
+
+// LoadConfig reads the rules file at path. A missing file yields the
+// default rules.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rules %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid alert rules %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the rules and prepares quiet hours for use.
+func (c *Config) Validate() error {
+	if c.QuietHours != nil {
+		var err error
+		if c.QuietHours.start, err = parseClock(c.QuietHours.Start); err != nil {
+			return fmt.Errorf("quietHours.start: %w", err)
+		}
+		if c.QuietHours.end, err = parseClock(c.QuietHours.End); err != nil {
+			return fmt.Errorf("quietHours.end: %w", err)
+		}
+	}
+
+	if err := notify.Validate(c.Notifiers); err != nil {
+		return err
+	}
+	sinks := notify.Names(c.Notifiers)
+
+	seen := make(map[string]bool, len(c.Rules))
+	for i := range c.Rules {
+		r := &c.Rules[i]
+		if r.ID == "" {
+			return fmt.Errorf("rule %d: missing id", i+1)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("rule %q: duplicate id", r.ID)
+		}
+		seen[r.ID] = true
+
+		for _, glob := range []string{r.Account, r.Model} {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("rule %q: invalid pattern %q: %w", r.ID, glob, err)
+			}
+		}
+
+		for _, name := range r.Notifiers {
+			if !slices.Contains(sinks, name) {
+				return fmt.Errorf("rule %q: unknown notifier %q", r.ID, name)
+			}
+		}
+
+		switch r.Kind {
+		case KindQuotaBelow:
+			if r.Threshold <= 0 || r.Threshold > 100 {
+				return fmt.Errorf("rule %q: threshold must be between 0 and 100", r.ID)
+			}
+			if r.Hysteresis < 0 {
+				return fmt.Errorf("rule %q: hysteresis must not be negative", r.ID)
+			}
+		case KindQuotaReset:
+			if r.Threshold <= 0 {
+				r.Threshold = 20
+			}
+		case KindProjectionStatus:
+			r.Status = models.ProjectionStatus(strings.ToUpper(string(r.Status)))
+			if r.Status == "" {
+				r.Status = models.ProjectionCritical
+			}
+			if r.Status != models.ProjectionWarning && r.Status != models.ProjectionCritical {
+				return fmt.Errorf("rule %q: status must be WARNING or CRITICAL", r.ID)
+			}
+		case KindDepleteBeforeReset, KindTokenFailure, KindTokenRevoked, KindTierChange:
+		default:
+			return fmt.Errorf("rule %q: unknown kind %q", r.ID, r.Kind)
+		}
+	}
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("want HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Active reports whether t falls inside the quiet hours. The window may wrap
+// around midnight.
+func (q *QuietHours) Active(t time.Time) bool {
+	if q == nil || q.start == q.end {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+// matchesAccount reports whether the rule applies to email.
+func (r *Rule) matchesAccount(email string) bool {
+	return glob(r.Account, email)
+}
+
+// matchesModel reports whether the rule applies to a model ID or family.
+func (r *Rule) matchesModel(names ...string) bool {
+	if r.Model == "" {
+		return true
+	}
+	for _, name := range names {
+		if name != "" && glob(r.Model, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func glob(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(s))
+	return ok
+}
@@ -0,0 +1,124 @@
+package alerts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/notify"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadConfig(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig(missing) error = %v", err)
+	}
+	if len(cfg.Rules) != len(DefaultConfig().Rules) {
+		t.Errorf("missing file should yield the default rules, got %+v", cfg.Rules)
+	}
+
+	path := filepath.Join(dir, "alerts.json")
+	data := `{
+		"quietHours": {"start": "22:00", "end": "07:30"},
+		"rules": [
+			{"id": "work-claude", "kind": "quota_below", "account": "*@work.com", "model": "claude", "threshold": 20, "cooldown": "30m"},
+			{"id": "warning", "kind": "projection_status", "status": "warning", "notifiers": ["team"]}
+		],
+		"notifiers": [
+			{"name": "team", "type": "webhook", "url": "https://hooks.slack.com/services/x", "preset": "slack"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(cfg.Rules))
+	}
+	if got := time.Duration(cfg.Rules[0].Cooldown); got != 30*time.Minute {
+		t.Errorf("cooldown = %v, want 30m", got)
+	}
+	if cfg.Rules[1].Status != models.ProjectionWarning {
+		t.Errorf("status = %q, want WARNING", cfg.Rules[1].Status)
+	}
+	if !cfg.QuietHours.Active(time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)) {
+		t.Error("quiet hours should be active at 23:00")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing id", Config{Rules: []Rule{{Kind: KindTierChange}}}},
+		{"duplicate id", Config{Rules: []Rule{{ID: "a", Kind: KindTierChange}, {ID: "a", Kind: KindTierChange}}}},
+		{"unknown kind", Config{Rules: []Rule{{ID: "a", Kind: "sometimes"}}}},
+		{"threshold out of range", Config{Rules: []Rule{{ID: "a", Kind: KindQuotaBelow, Threshold: 120}}}},
+		{"bad status", Config{Rules: []Rule{{ID: "a", Kind: KindProjectionStatus, Status: "SAFE"}}}},
+		{"bad pattern", Config{Rules: []Rule{{ID: "a", Kind: KindTierChange, Account: "["}}}},
+		{"bad quiet hours", Config{QuietHours: &QuietHours{Start: "25:00", End: "07:00"}}},
+		{"unknown notifier", Config{Rules: []Rule{{ID: "a", Kind: KindTierChange, Notifiers: []string{"team"}}}}},
+		{"invalid notifier", Config{Notifiers: []notify.Config{{Name: "team", Type: "webhook"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); err == nil {
+				t.Error("Validate() = nil, want error")
+			}
+		})
+	}
+}
+
+func TestQuietHours_Active(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 1, 1, h, m, 0, 0, time.Local) }
+
+	overnight := &Config{QuietHours: &QuietHours{Start: "22:00", End: "07:00"}}
+	daytime := &Config{QuietHours: &QuietHours{Start: "12:00", End: "13:00"}}
+	for _, c := range []*Config{overnight, daytime} {
+		if err := c.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		q    *QuietHours
+		t    time.Time
+		want bool
+	}{
+		{overnight.QuietHours, at(23, 0), true},
+		{overnight.QuietHours, at(6, 59), true},
+		{overnight.QuietHours, at(7, 0), false},
+		{overnight.QuietHours, at(12, 0), false},
+		{daytime.QuietHours, at(12, 30), true},
+		{daytime.QuietHours, at(13, 0), false},
+		{nil, at(23, 0), false},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Active(tt.t); got != tt.want {
+			t.Errorf("Active(%s) = %v, want %v", tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	r := Rule{Account: "*@Work.com", Model: "gemini-*"}
+	if !r.matchesAccount("me@work.com") || r.matchesAccount("me@home.com") {
+		t.Error("account glob should match case-insensitively")
+	}
+	if !r.matchesModel("gemini-3-pro", "gemini") || r.matchesModel("claude-sonnet", "claude") {
+		t.Error("model glob should match the model ID")
+	}
+
+	family := Rule{Model: "claude"}
+	if !family.matchesModel("claude-opus-4-5", "claude") {
+		t.Error("model pattern should match the family")
+	}
+}
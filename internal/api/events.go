@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment so
+// proxies and clients do not time it out.
+const keepAliveInterval = 30 * time.Second
+
+// AccountsChanged is the payload of an accounts_changed event.
+type AccountsChanged struct {
+	ActiveAccount string    `json:"activeAccount,omitempty"`
+	Accounts      []Account `json:"accounts"`
+}
+
+// ServiceError is the payload of an error event.
+type ServiceError struct {
+	Service string `json:"service"`
+	Error   string `json:"error"`
+}
+
+// Rotation is the payload of a rotation event.
+type Rotation struct {
+	Deadline   time.Time  `json:"deadline"`
+	SwitchedAt *time.Time `json:"switchedAt,omitempty"`
+	Type       string     `json:"type"`
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	Reason     string     `json:"reason"`
+	Strategy   string     `json:"strategy"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// encodeEvent returns the SSE event name and payload for a service event.
+func encodeEvent(event services.ServiceEvent) (name string, payload any, ok bool) {
+	switch e := event.(type) {
+	case services.AccountsChangedEvent:
+		out := AccountsChanged{Accounts: make([]Account, 0, len(e.Accounts))}
+		if e.ActiveAccount != nil {
+			out.ActiveAccount = e.ActiveAccount.Email
+		}
+		for i := range e.Accounts {
+			acc := &e.Accounts[i]
+			out.Accounts = append(out.Accounts, Account{
+				ID:          acc.ID,
+				Email:       acc.Email,
+				DisplayName: acc.DisplayName,
+				ProjectID:   acc.ProjectID,
+				Active:      e.ActiveAccount != nil && e.ActiveAccount.Email == acc.Email,
+			})
+		}
+		return "accounts_changed", out, true
+
+	case services.QuotaUpdatedEvent:
+		return "quota_updated", e.QuotaInfo, e.QuotaInfo != nil
+
+	case services.ProjectionUpdatedEvent:
+		return "projection_updated", newProjection(e.Projection), e.Projection != nil
+
+	case services.ErrorEvent:
+		msg := ""
+		if e.Error != nil {
+			msg = e.Error.Error()
+		}
+		return "error", ServiceError{Service: e.Service, Error: msg}, true
+
+	case services.RotationEvent:
+		out := Rotation{
+			Type:     string(e.Type),
+			From:     e.Rotation.From,
+			To:       e.Rotation.To,
+			Reason:   e.Rotation.Reason,
+			Strategy: e.Rotation.Strategy,
+			Deadline: e.Rotation.Deadline,
+		}
+		if !e.Rotation.SwitchedAt.IsZero() {
+			out.SwitchedAt = &e.Rotation.SwitchedAt
+		}
+		if e.Error != nil {
+			out.Error = e.Error.Error()
+		}
+		return "rotation", out, true
+
+	case services.StatsEvent:
+		return "stats", newStats(&e), true
+	}
+	return "", nil, false
+}
+
+// handleEvents streams service events as Server-Sent Events until the
+// client disconnects or the server closes.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	events, _ := s.backend.Subscribe()
+	defer s.backend.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			name, payload, ok := encodeEvent(event)
+			if !ok {
+				continue
+			}
+			data, err := json.Marshal(payload)
+			if err != nil {
+				logger.Debug("failed to encode api event", "event", name, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+
+		case <-s.stopChan:
+			return
+		}
+	}
+}
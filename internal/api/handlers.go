@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+)
+
+// timeRanges maps the range query parameter to history time ranges.
+var timeRanges = map[string]models.TimeRange{
+	"24h": models.TimeRange24Hours,
+	"7d":  models.TimeRange7Days,
+	"30d": models.TimeRange30Days,
+	"all": models.TimeRangeAllTime,
+}
+
+// rangeName returns the query parameter value for a time range.
+func rangeName(tr models.TimeRange) string {
+	for name, r := range timeRanges {
+		if r == tr {
+			return name
+		}
+	}
+	return ""
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, _ *http.Request) {
+	accs := s.backend.GetAccountsWithQuota()
+	out := make([]Account, 0, len(accs))
+	for i := range accs {
+		out = append(out, newAccount(&accs[i]))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleActivate(w http.ResponseWriter, r *http.Request) {
+	account := r.PathValue("account")
+	if err := s.backend.SetActiveAccount(account); err != nil {
+		switch {
+		case errors.Is(err, accounts.ErrAccountNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, accounts.ErrReadOnly):
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	for _, acc := range s.backend.GetAccountsWithQuota() {
+		if acc.ID == account || acc.Email == account {
+			writeJSON(w, http.StatusOK, newAccount(&acc))
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleQuotas(w http.ResponseWriter, _ *http.Request) {
+	accs := s.backend.GetAccountsWithQuota()
+	out := make([]*models.QuotaInfo, 0, len(accs))
+	for i := range accs {
+		if accs[i].QuotaInfo != nil {
+			out = append(out, accs[i].QuotaInfo)
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleQuota(w http.ResponseWriter, r *http.Request) {
+	email := r.PathValue("email")
+	for _, acc := range s.backend.GetAccountsWithQuota() {
+		if acc.Email != email {
+			continue
+		}
+		if acc.QuotaInfo == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no quota for %s yet", email))
+			return
+		}
+		writeJSON(w, http.StatusOK, acc.QuotaInfo)
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("%s: %s", accounts.ErrAccountNotFound, email))
+}
+
+func (s *Server) handleRefreshAccount(w http.ResponseWriter, r *http.Request) {
+	email := r.PathValue("email")
+	if !s.hasAccount(email) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s: %s", accounts.ErrAccountNotFound, email))
+		return
+	}
+
+	qi, err := s.backend.RefreshQuotaForAccount(email)
+	if err != nil && qi == nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, qi)
+}
+
+func (s *Server) handleProjections(w http.ResponseWriter, _ *http.Request) {
+	projections := s.backend.GetAllProjections()
+	emails := make([]string, 0, len(projections))
+	for email := range projections {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	out := make([]*Projection, 0, len(emails))
+	for _, email := range emails {
+		if p := newProjection(projections[email]); p != nil {
+			out = append(out, p)
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleProjection(w http.ResponseWriter, r *http.Request) {
+	email := r.PathValue("email")
+	p := newProjection(s.backend.GetAllProjections()[email])
+	if p == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no projection for %s", email))
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	email := r.PathValue("email")
+	if !s.hasAccount(email) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s: %s", accounts.ErrAccountNotFound, email))
+		return
+	}
+
+	name := r.URL.Query().Get("range")
+	if name == "" {
+		name = "7d"
+	}
+	timeRange, ok := timeRanges[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid range %q (want 24h, 7d, 30d or all)", name))
+		return
+	}
+
+	stats, err := s.backend.GetAccountHistory(email, timeRange)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, newHistory(stats))
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, _ *http.Request) {
+	stats := s.backend.GetStats()
+	writeJSON(w, http.StatusOK, newStats(&stats))
+}
+
+// handleRefresh starts a refresh of all accounts. Results arrive on the
+// event stream, so the request does not wait for them.
+func (s *Server) handleRefresh(w http.ResponseWriter, _ *http.Request) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.backend.RefreshQuota()
+	}()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "refreshing"})
+}
+
+func (s *Server) hasAccount(email string) bool {
+	for _, acc := range s.backend.GetAccountsWithQuota() {
+		if acc.Email == email {
+			return true
+		}
+	}
+	return false
+}
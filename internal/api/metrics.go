@@ -0,0 +1,232 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+// metricsContentType is the Prometheus text exposition format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// projectionStatuses are the values of the projection status enum metric.
+var projectionStatuses = []models.ProjectionStatus{
+	models.ProjectionSafe,
+	models.ProjectionWarning,
+	models.ProjectionCritical,
+	models.ProjectionUnknown,
+}
+
+// label is a metric label pair.
+type label struct {
+	name  string
+	value string
+}
+
+// metricWriter writes the Prometheus text exposition format.
+type metricWriter struct {
+	w *bufio.Writer
+}
+
+// header writes the HELP and TYPE lines of a metric family.
+func (m *metricWriter) header(name, typ, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes a single sample line.
+func (m *metricWriter) sample(name string, labels []label, value float64) {
+	m.w.WriteString(name)
+	if len(labels) > 0 {
+		m.w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				m.w.WriteByte(',')
+			}
+			fmt.Fprintf(m.w, "%s=\"%s\"", l.name, escapeLabel(l.value))
+		}
+		m.w.WriteByte('}')
+	}
+	m.w.WriteByte(' ')
+	m.w.WriteString(formatValue(value))
+	m.w.WriteByte('\n')
+}
+
+// escapeLabel escapes a label value as the exposition format requires.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// modelLabels returns the email, model and tier labels of a model quota.
+func modelLabels(qi *models.QuotaInfo, mq *models.ModelQuota) []label {
+	model := mq.ModelID
+	if model == "" {
+		model = mq.ModelFamily
+	}
+	tier := mq.SubscriptionTier
+	if tier == "" {
+		tier = qi.SubscriptionTier
+	}
+	return []label{{"email", qi.AccountEmail}, {"model", model}, {"tier", tier}}
+}
+
+// handleMetrics serves quota, projection and fetch metrics for Prometheus.
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	bw := bufio.NewWriter(w)
+	m := &metricWriter{w: bw}
+
+	now := time.Now()
+	accs := s.backend.GetAccountsWithQuota()
+	quotas := make([]*models.QuotaInfo, 0, len(accs))
+	tiers := make(map[string]string, len(accs))
+	for i := range accs {
+		if qi := accs[i].QuotaInfo; qi != nil {
+			quotas = append(quotas, qi)
+			tiers[qi.AccountEmail] = qi.SubscriptionTier
+		}
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].AccountEmail < quotas[j].AccountEmail })
+
+	m.header("adt_quota_remaining_percent", "gauge", "Remaining quota of a model in percent.")
+	for _, qi := range quotas {
+		for i := range qi.ModelQuotas {
+			mq := &qi.ModelQuotas[i]
+			m.sample("adt_quota_remaining_percent", modelLabels(qi, mq), mq.RemainingPercent())
+		}
+	}
+
+	m.header("adt_quota_reset_seconds", "gauge", "Seconds until the quota of a model resets.")
+	for _, qi := range quotas {
+		for i := range qi.ModelQuotas {
+			mq := &qi.ModelQuotas[i]
+			if mq.ResetTime.IsZero() {
+				continue
+			}
+			m.sample("adt_quota_reset_seconds", modelLabels(qi, mq), max(0, mq.ResetTime.Sub(now).Seconds()))
+		}
+	}
+
+	m.header("adt_quota_rate_limited", "gauge", "Whether a model is currently rate limited (1) or not (0).")
+	for _, qi := range quotas {
+		for i := range qi.ModelQuotas {
+			mq := &qi.ModelQuotas[i]
+			m.sample("adt_quota_rate_limited", modelLabels(qi, mq), boolValue(mq.IsRateLimited))
+		}
+	}
+
+	s.writeProjectionMetrics(m, tiers)
+	s.writeFetchMetrics(m)
+
+	if err := bw.Flush(); err != nil {
+		logger.Debug("failed to write metrics", "error", err)
+	}
+}
+
+// writeProjectionMetrics writes the projected hours left and status of every
+// model and family rollup. Family rollups use the family name as model.
+func (s *Server) writeProjectionMetrics(m *metricWriter, tiers map[string]string) {
+	projections := s.backend.GetAllProjections()
+	emails := make([]string, 0, len(projections))
+	for email := range projections {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	type entry struct {
+		mp     *models.ModelProjection
+		labels []label
+	}
+	var entries []entry
+	for _, email := range emails {
+		p := projections[email]
+		if p == nil {
+			continue
+		}
+		all := append([]*models.ModelProjection{p.Claude, p.Gemini}, p.Models...)
+		for _, mp := range all {
+			if mp == nil {
+				continue
+			}
+			entries = append(entries, entry{
+				mp:     mp,
+				labels: []label{{"email", email}, {"model", mp.Model}, {"tier", tiers[email]}},
+			})
+		}
+	}
+
+	m.header("adt_projection_hours_left", "gauge", "Projected hours until a model runs out at the current session rate.")
+	for _, e := range entries {
+		if e.mp.Status == models.ProjectionUnknown || e.mp.SessionRate <= 0 {
+			continue
+		}
+		m.sample("adt_projection_hours_left", e.labels, e.mp.SessionHoursLeft)
+	}
+
+	m.header("adt_projection_status", "gauge", "Projection status of a model; 1 for the current status.")
+	for _, e := range entries {
+		for _, status := range projectionStatuses {
+			labels := append(append([]label(nil), e.labels...), label{"status", string(status)})
+			m.sample("adt_projection_status", labels, boolValue(e.mp.Status == status))
+		}
+	}
+}
+
+// writeFetchMetrics writes the token refresh and quota fetch counters.
+func (s *Server) writeFetchMetrics(m *metricWriter) {
+	failures := s.backend.TokenRefreshFailures()
+	emails := make([]string, 0, len(failures))
+	for email := range failures {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	m.header("adt_token_refresh_failures_total", "counter", "Failed OAuth token refreshes after retries.")
+	for _, email := range emails {
+		m.sample("adt_token_refresh_failures_total", []label{{"email", email}}, float64(failures[email]))
+	}
+
+	endpoints := s.backend.FetchMetrics()
+
+	m.header("adt_quota_fetch_duration_seconds", "histogram", "Latency of quota requests per endpoint.")
+	for _, e := range endpoints {
+		for i, bound := range quota.LatencyBuckets {
+			m.sample("adt_quota_fetch_duration_seconds_bucket",
+				[]label{{"endpoint", e.Endpoint}, {"le", formatValue(bound)}}, float64(e.Buckets[i]))
+		}
+		m.sample("adt_quota_fetch_duration_seconds_bucket",
+			[]label{{"endpoint", e.Endpoint}, {"le", "+Inf"}}, float64(e.Requests))
+		m.sample("adt_quota_fetch_duration_seconds_sum", []label{{"endpoint", e.Endpoint}}, e.Sum)
+		m.sample("adt_quota_fetch_duration_seconds_count", []label{{"endpoint", e.Endpoint}}, float64(e.Requests))
+	}
+
+	m.header("adt_quota_fetch_errors_total", "counter", "Failed quota requests per endpoint.")
+	for _, e := range endpoints {
+		m.sample("adt_quota_fetch_errors_total", []label{{"endpoint", e.Endpoint}}, float64(e.Errors))
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestHandler_Metrics(t *testing.T) {
+	backend := newFakeBackend()
+	qi := backend.accounts[0].QuotaInfo
+	qi.SubscriptionTier = "PRO"
+	qi.ModelQuotas[0].ResetTime = time.Now().Add(time.Hour)
+	qi.ModelQuotas = append(qi.ModelQuotas, models.ModelQuota{
+		ModelID: `gemini-"pro"`, ModelFamily: "gemini", Limit: 100, IsRateLimited: true,
+	})
+	backend.projections["a@example.com"].Claude.SessionRate = 10
+	backend.projections["a@example.com"].Claude.SessionHoursLeft = 4
+
+	rec := doRequest(t, New(backend, "127.0.0.1:0").Handler(), http.MethodGet, "/metrics")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE adt_quota_remaining_percent gauge\n",
+		`adt_quota_remaining_percent{email="a@example.com",model="claude-sonnet",tier="PRO"} 40` + "\n",
+		`adt_quota_rate_limited{email="a@example.com",model="gemini-\"pro\"",tier="PRO"} 1` + "\n",
+		`adt_quota_reset_seconds{email="a@example.com",model="claude-sonnet",tier="PRO"} `,
+		`adt_projection_hours_left{email="a@example.com",model="claude",tier="PRO"} 4` + "\n",
+		`adt_projection_status{email="a@example.com",model="claude",tier="PRO",status="WARNING"} 1` + "\n",
+		`adt_projection_status{email="a@example.com",model="claude",tier="PRO",status="SAFE"} 0` + "\n",
+		`adt_token_refresh_failures_total{email="b@example.com"} 3` + "\n",
+		`adt_quota_fetch_duration_seconds_bucket{endpoint="https://cloudcode-pa.googleapis.com",le="0.25"} 1` + "\n",
+		`adt_quota_fetch_duration_seconds_bucket{endpoint="https://cloudcode-pa.googleapis.com",le="+Inf"} 2` + "\n",
+		`adt_quota_fetch_duration_seconds_count{endpoint="https://cloudcode-pa.googleapis.com"} 2` + "\n",
+		`adt_quota_fetch_errors_total{endpoint="https://cloudcode-pa.googleapis.com"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q\n%s", want, body)
+		}
+	}
+
+	// A model that was never reset-timed has no reset series.
+	if strings.Contains(body, `adt_quota_reset_seconds{email="a@example.com",model="gemini`) {
+		t.Error("reset series emitted for a model without a reset time")
+	}
+}
@@ -0,0 +1,249 @@
+// Package api serves the data held by the service manager as a versioned
+// JSON API over a loopback TCP address or a Unix socket.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+// unixPrefix marks a listen address as a Unix socket path.
+const unixPrefix = "unix:"
+
+// shutdownTimeout bounds how long Close waits for in-flight requests.
+const shutdownTimeout = 5 * time.Second
+
+// Backend is the part of services.Manager the API serves.
+type Backend interface {
+	GetAccountsWithQuota() []models.AccountWithQuota
+	GetAllProjections() map[string]*models.AccountProjection
+	GetAccountHistory(email string, timeRange models.TimeRange) (*models.AccountHistoryStats, error)
+	GetStats() services.StatsEvent
+	RefreshQuota()
+	RefreshQuotaForAccount(email string) (*models.QuotaInfo, error)
+	SetActiveAccount(idOrEmail string) error
+	Subscribe() (chan services.ServiceEvent, tea.Cmd)
+	Unsubscribe(ch chan services.ServiceEvent)
+	TokenRefreshFailures() map[string]uint64
+	FetchMetrics() []quota.EndpointMetrics
+}
+
+// Server is the HTTP API server.
+type Server struct {
+	backend  Backend
+	server   *http.Server
+	listener net.Listener
+	stopChan chan struct{}
+	addr     string
+	wg       sync.WaitGroup
+}
+
+// New creates a server for addr, which is either a loopback host:port such
+// as "127.0.0.1:8787" or "unix:/path/to/socket".
+func New(backend Backend, addr string) *Server {
+	s := &Server{
+		backend:  backend,
+		addr:     addr,
+		stopChan: make(chan struct{}),
+	}
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	listener, err := listen(s.addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("api server stopped", "error", err)
+		}
+	}()
+
+	logger.Debug("api server listening", "addr", s.Addr())
+	return nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.addr
+	}
+	if s.listener.Addr().Network() == "unix" {
+		return unixPrefix + s.listener.Addr().String()
+	}
+	return s.listener.Addr().String()
+}
+
+// Close ends event streams, waits for in-flight requests and stops the server.
+func (s *Server) Close() error {
+	select {
+	case <-s.stopChan:
+		return nil
+	default:
+		close(s.stopChan)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	s.wg.Wait()
+
+	if s.listener != nil && s.listener.Addr().Network() == "unix" {
+		_ = os.Remove(s.listener.Addr().String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to shut down api server: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts", s.handleAccounts)
+	mux.HandleFunc("POST /api/v1/accounts/{account}/activate", s.handleActivate)
+	mux.HandleFunc("GET /api/v1/quotas", s.handleQuotas)
+	mux.HandleFunc("GET /api/v1/quotas/{email}", s.handleQuota)
+	mux.HandleFunc("POST /api/v1/quotas/{email}/refresh", s.handleRefreshAccount)
+	mux.HandleFunc("GET /api/v1/projections", s.handleProjections)
+	mux.HandleFunc("GET /api/v1/projections/{email}", s.handleProjection)
+	mux.HandleFunc("GET /api/v1/history/{email}", s.handleHistory)
+	mux.HandleFunc("GET /api/v1/stats", s.handleStats)
+	mux.HandleFunc("POST /api/v1/refresh", s.handleRefresh)
+	mux.HandleFunc("GET /api/v1/events", s.handleEvents)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return s.guard(mux)
+}
+
+// guard refuses requests a browser could have been tricked into sending.
+// The API has no authentication, so it relies on these checks:
+//   - the Host must be loopback, which defeats DNS rebinding (skipped on a
+//     Unix socket, which browsers cannot reach);
+//   - an Origin, when sent, must be the API's own;
+//   - a POST must be application/json, which a cross-site form cannot send
+//     and a cross-site fetch cannot send without a CORS preflight.
+func (s *Server) guard(next http.Handler) http.Handler {
+	unix := strings.HasPrefix(s.addr, unixPrefix)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !unix && !isLoopback(requestHost(r.Host)) {
+			writeError(w, http.StatusForbidden, "host not allowed")
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+			writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		if r.Method == http.MethodPost {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, "POST requires Content-Type: application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestHost strips the port from a Host header.
+func requestHost(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
+
+// sameOrigin reports whether origin names the host the request was sent to.
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
+
+// listen opens a loopback TCP or Unix socket listener. Other TCP hosts are
+// refused: the API has no authentication, see guard.
+func listen(addr string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+		if path == "" {
+			return nil, errors.New("api address: empty unix socket path")
+		}
+		// A socket left behind by a crashed process blocks the bind.
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		if err := os.Chmod(path, 0o600); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+		}
+		return listener, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api address %q: %w", addr, err)
+	}
+	if !isLoopback(host) {
+		return nil, fmt.Errorf("api address %q is not a loopback address", addr)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// isLoopback reports whether host only accepts local connections.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debug("failed to write api response", "error", err)
+	}
+}
+
+// writeError writes an ErrorResponse.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrorResponse{Error: msg})
+}
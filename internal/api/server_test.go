@@ -0,0 +1,432 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+type fakeBackend struct {
+	accounts    []models.AccountWithQuota
+	projections map[string]*models.AccountProjection
+	history     *models.AccountHistoryStats
+	historyArg  models.TimeRange
+	active      string
+	refreshed   chan struct{}
+	readOnly    bool
+	subscribers []chan services.ServiceEvent
+	mu          sync.Mutex
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		accounts: []models.AccountWithQuota{
+			{
+				Account:  models.Account{ID: "1", Email: "a@example.com", RefreshToken: "secret-token"},
+				IsActive: true,
+				QuotaInfo: &models.QuotaInfo{
+					AccountEmail: "a@example.com",
+					ModelQuotas:  []models.ModelQuota{{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 40}},
+				},
+			},
+			{Account: models.Account{ID: "2", Email: "b@example.com", RefreshToken: "secret-token"}},
+		},
+		projections: map[string]*models.AccountProjection{
+			"a@example.com": {
+				Email:  "a@example.com",
+				Claude: &models.ModelProjection{Model: "claude", Status: models.ProjectionWarning, TimeUntilReset: 90 * time.Second},
+			},
+		},
+		history:   &models.AccountHistoryStats{Email: "a@example.com", HourlyPatterns: []models.HourlyPattern{{Hour: 9}}},
+		refreshed: make(chan struct{}, 1),
+	}
+}
+
+func (f *fakeBackend) GetAccountsWithQuota() []models.AccountWithQuota { return f.accounts }
+func (f *fakeBackend) GetAllProjections() map[string]*models.AccountProjection {
+	return f.projections
+}
+
+func (f *fakeBackend) GetAccountHistory(email string, tr models.TimeRange) (*models.AccountHistoryStats, error) {
+	f.historyArg = tr
+	stats := *f.history
+	stats.TimeRange = tr
+	return &stats, nil
+}
+
+func (f *fakeBackend) GetStats() services.StatsEvent {
+	return services.StatsEvent{AccountCount: len(f.accounts), DaemonPID: 42}
+}
+
+func (f *fakeBackend) RefreshQuota() { f.refreshed <- struct{}{} }
+func (f *fakeBackend) RefreshQuotaForAccount(email string) (*models.QuotaInfo, error) {
+	return &models.QuotaInfo{AccountEmail: email}, nil
+}
+
+func (f *fakeBackend) SetActiveAccount(idOrEmail string) error {
+	if f.readOnly {
+		return accounts.ErrReadOnly
+	}
+	for _, acc := range f.accounts {
+		if acc.ID == idOrEmail || acc.Email == idOrEmail {
+			f.active = acc.Email
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", accounts.ErrAccountNotFound, idOrEmail)
+}
+
+func (f *fakeBackend) Subscribe() (chan services.ServiceEvent, tea.Cmd) {
+	ch := make(chan services.ServiceEvent, 10)
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, ch)
+	f.mu.Unlock()
+	return ch, nil
+}
+
+func (f *fakeBackend) Unsubscribe(ch chan services.ServiceEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, sub := range f.subscribers {
+		if sub == ch {
+			f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+func (f *fakeBackend) TokenRefreshFailures() map[string]uint64 {
+	return map[string]uint64{"b@example.com": 3}
+}
+
+func (f *fakeBackend) FetchMetrics() []quota.EndpointMetrics {
+	return []quota.EndpointMetrics{{
+		Endpoint: "https://cloudcode-pa.googleapis.com",
+		Buckets:  []uint64{0, 1, 2, 2, 2, 2, 2, 2},
+		Sum:      0.6,
+		Requests: 2,
+		Errors:   1,
+	}}
+}
+
+func (f *fakeBackend) publish(event services.ServiceEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, sub := range f.subscribers {
+		sub <- event
+	}
+}
+
+func doRequest(t *testing.T, h http.Handler, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = "127.0.0.1:8787"
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Guard(t *testing.T) {
+	tests := []struct {
+		header     http.Header
+		name       string
+		method     string
+		path       string
+		host       string
+		wantStatus int
+	}{
+		{
+			name: "loopback get", method: http.MethodGet, path: "/api/v1/stats",
+			host: "localhost:8787", wantStatus: http.StatusOK,
+		},
+		{
+			name: "rebound host", method: http.MethodGet, path: "/api/v1/accounts",
+			host: "attacker.example:8787", wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "foreign origin", method: http.MethodGet, path: "/api/v1/accounts",
+			host: "127.0.0.1:8787", header: http.Header{"Origin": {"https://attacker.example"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "other local origin", method: http.MethodPost, path: "/api/v1/refresh",
+			host: "127.0.0.1:8787",
+			header: http.Header{
+				"Origin":       {"http://127.0.0.1:3000"},
+				"Content-Type": {"application/json"},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "own origin", method: http.MethodPost, path: "/api/v1/refresh",
+			host: "127.0.0.1:8787",
+			header: http.Header{
+				"Origin":       {"http://127.0.0.1:8787"},
+				"Content-Type": {"application/json"},
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "form post", method: http.MethodPost, path: "/api/v1/refresh",
+			host: "127.0.0.1:8787", header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "post without content type", method: http.MethodPost, path: "/api/v1/accounts/b@example.com/activate",
+			host: "127.0.0.1:8787", wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "json post with charset", method: http.MethodPost, path: "/api/v1/refresh",
+			host: "[::1]:8787", header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newFakeBackend()
+			h := New(backend, "127.0.0.1:0").Handler()
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Host = tt.host
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if backend.active != "" {
+				t.Errorf("refused request switched the account to %s", backend.active)
+			}
+		})
+	}
+}
+
+func TestHandler_Accounts(t *testing.T) {
+	h := New(newFakeBackend(), "127.0.0.1:0").Handler()
+
+	rec := doRequest(t, h, http.MethodGet, "/api/v1/accounts")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "secret-token") {
+		t.Fatal("response leaks the refresh token")
+	}
+
+	var accs []Account
+	if err := json.Unmarshal(rec.Body.Bytes(), &accs); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(accs) != 2 || !accs[0].Active || accs[0].Quota == nil || accs[1].Quota != nil {
+		t.Errorf("unexpected accounts: %+v", accs)
+	}
+}
+
+func TestHandler_Quotas(t *testing.T) {
+	h := New(newFakeBackend(), "127.0.0.1:0").Handler()
+
+	rec := doRequest(t, h, http.MethodGet, "/api/v1/quotas/a@example.com")
+	var qi models.QuotaInfo
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &qi) != nil || len(qi.ModelQuotas) != 1 {
+		t.Errorf("GET quota = %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := doRequest(t, h, http.MethodGet, "/api/v1/quotas/b@example.com"); rec.Code != http.StatusNotFound {
+		t.Errorf("quota without data: status = %d, want 404", rec.Code)
+	}
+	if rec := doRequest(t, h, http.MethodGet, "/api/v1/quotas/missing@example.com"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown account: status = %d, want 404", rec.Code)
+	}
+	if rec := doRequest(t, h, http.MethodPost, "/api/v1/quotas/a@example.com/refresh"); rec.Code != http.StatusOK {
+		t.Errorf("refresh account: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestHandler_Projections(t *testing.T) {
+	backend := newFakeBackend()
+	// No consumption yet: the projection service reports infinite hours.
+	backend.projections["a@example.com"].Gemini = &models.ModelProjection{Model: "gemini", SessionHoursLeft: math.Inf(1)}
+	h := New(backend, "127.0.0.1:0").Handler()
+
+	rec := doRequest(t, h, http.MethodGet, "/api/v1/projections/a@example.com")
+	var p Projection
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET projection = %d %s", rec.Code, rec.Body)
+	}
+	if p.Claude == nil || p.Claude.Status != "WARNING" || p.Claude.SecondsUntilReset != 90 {
+		t.Errorf("unexpected projection: %+v", p.Claude)
+	}
+	if p.Gemini == nil || p.Gemini.SessionHoursLeft != nil {
+		t.Errorf("infinite hours left should be omitted: %+v", p.Gemini)
+	}
+
+	if rec := doRequest(t, h, http.MethodGet, "/api/v1/projections/b@example.com"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing projection: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestHandler_History(t *testing.T) {
+	backend := newFakeBackend()
+	h := New(backend, "127.0.0.1:0").Handler()
+
+	rec := doRequest(t, h, http.MethodGet, "/api/v1/history/a@example.com?range=30d")
+	var hist History
+	if err := json.Unmarshal(rec.Body.Bytes(), &hist); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET history = %d %s", rec.Code, rec.Body)
+	}
+	if backend.historyArg != models.TimeRange30Days || hist.Range != "30d" {
+		t.Errorf("range = %v / %q, want 30 days", backend.historyArg, hist.Range)
+	}
+	if len(hist.HourlyPatterns) != 1 || hist.HourlyPatterns[0].Label != "09:00" {
+		t.Errorf("unexpected hourly patterns: %+v", hist.HourlyPatterns)
+	}
+
+	if rec := doRequest(t, h, http.MethodGet, "/api/v1/history/a@example.com?range=week"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid range: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandler_Actions(t *testing.T) {
+	backend := newFakeBackend()
+	h := New(backend, "127.0.0.1:0").Handler()
+
+	rec := doRequest(t, h, http.MethodPost, "/api/v1/accounts/b@example.com/activate")
+	if rec.Code != http.StatusOK || backend.active != "b@example.com" {
+		t.Errorf("activate: status = %d, active = %q", rec.Code, backend.active)
+	}
+	if rec := doRequest(t, h, http.MethodPost, "/api/v1/accounts/nobody/activate"); rec.Code != http.StatusNotFound {
+		t.Errorf("activate unknown: status = %d, want 404", rec.Code)
+	}
+	if rec := doRequest(t, h, http.MethodGet, "/api/v1/accounts/b@example.com/activate"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET activate: status = %d, want 405", rec.Code)
+	}
+	backend.readOnly = true
+	if rec := doRequest(t, h, http.MethodPost, "/api/v1/accounts/a@example.com/activate"); rec.Code != http.StatusForbidden {
+		t.Errorf("activate read-only: status = %d, want 403", rec.Code)
+	}
+	backend.readOnly = false
+
+	if rec := doRequest(t, h, http.MethodPost, "/api/v1/refresh"); rec.Code != http.StatusAccepted {
+		t.Errorf("refresh: status = %d, want 202", rec.Code)
+	}
+	select {
+	case <-backend.refreshed:
+	case <-time.After(time.Second):
+		t.Error("refresh was not triggered")
+	}
+
+	rec = doRequest(t, h, http.MethodGet, "/api/v1/stats")
+	var stats Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || stats.AccountCount != 2 || stats.DaemonPID != 42 {
+		t.Errorf("GET stats = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	backend := newFakeBackend()
+	srv := New(backend, "127.0.0.1:0")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr() + "/api/v1/events")
+	if err != nil {
+		t.Fatalf("GET events failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("unexpected first line %q", line)
+	}
+
+	backend.publish(services.QuotaUpdatedEvent{
+		AccountEmail: "a@example.com",
+		QuotaInfo:    &models.QuotaInfo{AccountEmail: "a@example.com", TotalRemaining: 7},
+	})
+
+	var event, data string
+	for event == "" || data == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			event = strings.TrimSpace(v)
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			data = strings.TrimSpace(v)
+		}
+	}
+	if event != "quota_updated" || !strings.Contains(data, `"totalRemaining":7`) {
+		t.Errorf("got event %q data %s", event, data)
+	}
+}
+
+func TestListen(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", ":0", "192.0.2.1:8787", "example.com:80"} {
+		if l, err := listen(addr); err == nil {
+			_ = l.Close()
+			t.Errorf("listen(%q) should refuse a non-loopback address", addr)
+		}
+	}
+
+	for _, addr := range []string{"127.0.0.1:0", "localhost:0"} {
+		l, err := listen(addr)
+		if err != nil {
+			t.Errorf("listen(%q) failed: %v", addr, err)
+			continue
+		}
+		_ = l.Close()
+	}
+
+	socket := filepath.Join(t.TempDir(), "adt.sock")
+	srv := New(newFakeBackend(), unixPrefix+socket)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() on unix socket failed: %v", err)
+	}
+	client := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://adt/api/v1/stats")
+	if err != nil {
+		t.Fatalf("GET over unix socket failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+	if err := srv.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"math"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+)
+
+// The types below are the v1 wire format. They are kept separate from the
+// models so internal changes do not break API clients, and so credentials
+// never leave the process.
+
+// Account is an account without its OAuth tokens, with its current quota.
+type Account struct {
+	Quota       *models.QuotaInfo `json:"quota,omitempty"`
+	ID          string            `json:"id"`
+	Email       string            `json:"email"`
+	DisplayName string            `json:"displayName,omitempty"`
+	ProjectID   string            `json:"projectId,omitempty"`
+	Active      bool              `json:"active"`
+}
+
+// Projection is the quota projection of an account.
+type Projection struct {
+	LastUpdated time.Time          `json:"lastUpdated"`
+	Claude      *ModelProjection   `json:"claude,omitempty"`
+	Gemini      *ModelProjection   `json:"gemini,omitempty"`
+	Email       string             `json:"email"`
+	Models      []*ModelProjection `json:"models,omitempty"`
+}
+
+// ModelProjection is the projection of a model family or a single model.
+// Rates are in percent per hour. SessionHoursLeft is omitted while no
+// consumption has been observed.
+type ModelProjection struct {
+	SessionDepleteAt       *time.Time `json:"sessionDepleteAt,omitempty"`
+	ResetTime              *time.Time `json:"resetTime,omitempty"`
+	Model                  string     `json:"model"`
+	DisplayName            string     `json:"displayName,omitempty"`
+	Family                 string     `json:"family,omitempty"`
+	Status                 string     `json:"status"`
+	Confidence             string     `json:"confidence,omitempty"`
+	CurrentPercent         float64    `json:"currentPercent"`
+	SessionRate            float64    `json:"sessionRate"`
+	HistoricalRate         float64    `json:"historicalRate"`
+	SessionHoursLeft       *float64   `json:"sessionHoursLeft,omitempty"`
+	SecondsUntilReset      float64    `json:"secondsUntilReset"`
+	DataPoints             int        `json:"dataPoints"`
+	WillDepleteBeforeReset bool       `json:"willDepleteBeforeReset"`
+}
+
+// History is the usage history of an account over a time range.
+type History struct {
+	FirstDataPoint  *time.Time     `json:"firstDataPoint,omitempty"`
+	LastDataPoint   *time.Time     `json:"lastDataPoint,omitempty"`
+	RateLimits      *RateLimits    `json:"rateLimits,omitempty"`
+	Exhaustion      *Exhaustion    `json:"exhaustion,omitempty"`
+	Email           string         `json:"email"`
+	Range           string         `json:"range"`
+	Resolution      string         `json:"resolution,omitempty"`
+	DailyUsage      []DailyUsage   `json:"dailyUsage"`
+	HourlyPatterns  []UsagePattern `json:"hourlyPatterns"`
+	WeekdayPatterns []UsagePattern `json:"weekdayPatterns"`
+	TotalDataDays   int            `json:"totalDataDays"`
+	TotalDataPoints int            `json:"totalDataPoints"`
+}
+
+// RateLimits summarises rate limit hits.
+type RateLimits struct {
+	LastHit         *time.Time `json:"lastHit,omitempty"`
+	Total           int        `json:"total"`
+	InRange         int        `json:"inRange"`
+	Last7Days       int        `json:"last7Days"`
+	Last30Days      int        `json:"last30Days"`
+	Claude          int        `json:"claude"`
+	Gemini          int        `json:"gemini"`
+	AvgHoursBetween float64    `json:"avgHoursBetween"`
+}
+
+// Exhaustion summarises how sessions ran out of quota.
+type Exhaustion struct {
+	TotalSessions      int     `json:"totalSessions"`
+	ExhaustedSessions  int     `json:"exhaustedSessions"`
+	ExhaustionRate     float64 `json:"exhaustionRate"`
+	AvgHoursToExhaust  float64 `json:"avgHoursToExhaust"`
+	MedianHoursExhaust float64 `json:"medianHoursToExhaust"`
+	AvgStartPercent    float64 `json:"avgStartPercent"`
+	AvgConsumptionRate float64 `json:"avgConsumptionRate"`
+}
+
+// DailyUsage is the consumption of one day.
+type DailyUsage struct {
+	Date           time.Time `json:"date"`
+	ClaudeConsumed float64   `json:"claudeConsumed"`
+	GeminiConsumed float64   `json:"geminiConsumed"`
+	TotalConsumed  float64   `json:"totalConsumed"`
+	RateLimitHits  int       `json:"rateLimitHits"`
+	Sessions       int       `json:"sessions"`
+}
+
+// UsagePattern is the average consumption of an hour of day or a weekday.
+type UsagePattern struct {
+	Label       string  `json:"label"`
+	Slot        int     `json:"slot"`
+	AvgConsumed float64 `json:"avgConsumed"`
+	RateLimits  int     `json:"rateLimitHits"`
+	Occurrences int     `json:"occurrences"`
+}
+
+// Stats are the global statistics.
+type Stats struct {
+	AccountCount   int   `json:"accountCount"`
+	QuotaCached    int   `json:"quotaCached"`
+	TotalRemaining int64 `json:"totalRemaining"`
+	TotalLimit     int64 `json:"totalLimit"`
+	DaemonPID      int   `json:"daemonPid,omitempty"`
+}
+
+// ErrorResponse is the body of every non-2xx response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func newAccount(awq *models.AccountWithQuota) Account {
+	return Account{
+		ID:          awq.ID,
+		Email:       awq.Email,
+		DisplayName: awq.DisplayName,
+		ProjectID:   awq.ProjectID,
+		Active:      awq.IsActive,
+		Quota:       awq.QuotaInfo,
+	}
+}
+
+func newProjection(p *models.AccountProjection) *Projection {
+	if p == nil {
+		return nil
+	}
+	out := &Projection{
+		Email:       p.Email,
+		LastUpdated: p.LastUpdated,
+		Claude:      newModelProjection(p.Claude),
+		Gemini:      newModelProjection(p.Gemini),
+	}
+	for _, mp := range p.Models {
+		out.Models = append(out.Models, newModelProjection(mp))
+	}
+	return out
+}
+
+func newModelProjection(mp *models.ModelProjection) *ModelProjection {
+	if mp == nil {
+		return nil
+	}
+	return &ModelProjection{
+		Model:                  mp.Model,
+		DisplayName:            mp.DisplayName,
+		Family:                 mp.Family,
+		Status:                 string(mp.Status),
+		Confidence:             mp.Confidence,
+		CurrentPercent:         mp.CurrentPercent,
+		SessionRate:            mp.SessionRate,
+		HistoricalRate:         mp.HistoricalRate,
+		SessionHoursLeft:       finite(mp.SessionHoursLeft),
+		SecondsUntilReset:      mp.TimeUntilReset.Seconds(),
+		SessionDepleteAt:       models.OptionalTime(mp.SessionDepleteAt),
+		ResetTime:              models.OptionalTime(mp.ResetTime),
+		DataPoints:             mp.DataPoints,
+		WillDepleteBeforeReset: mp.WillDepleteBefore,
+	}
+}
+
+func newHistory(h *models.AccountHistoryStats) *History {
+	out := &History{
+		Email:           h.Email,
+		Range:           rangeName(h.TimeRange),
+		Resolution:      h.Resolution,
+		FirstDataPoint:  models.OptionalTime(h.FirstDataPoint),
+		LastDataPoint:   models.OptionalTime(h.LastDataPoint),
+		TotalDataDays:   h.TotalDataDays,
+		TotalDataPoints: h.TotalDataPoints,
+		DailyUsage:      make([]DailyUsage, 0, len(h.DailyUsage)),
+		HourlyPatterns:  make([]UsagePattern, 0, len(h.HourlyPatterns)),
+		WeekdayPatterns: make([]UsagePattern, 0, len(h.WeekdayPatterns)),
+	}
+
+	if rl := h.RateLimits; rl != nil {
+		out.RateLimits = &RateLimits{
+			LastHit:         models.OptionalTime(rl.LastHitTime),
+			Total:           rl.TotalHits,
+			InRange:         rl.HitsInRange,
+			Last7Days:       rl.HitsLast7Days,
+			Last30Days:      rl.HitsLast30Days,
+			Claude:          rl.ClaudeHits,
+			Gemini:          rl.GeminiHits,
+			AvgHoursBetween: rl.AvgTimeBetween.Hours(),
+		}
+	}
+	if ex := h.Exhaustion; ex != nil {
+		out.Exhaustion = &Exhaustion{
+			TotalSessions:      ex.TotalSessions,
+			ExhaustedSessions:  ex.ExhaustedSessions,
+			ExhaustionRate:     ex.ExhaustionRate,
+			AvgHoursToExhaust:  ex.AvgTimeToExhaust.Hours(),
+			MedianHoursExhaust: ex.MedianTimeToExhaust.Hours(),
+			AvgStartPercent:    ex.AvgStartPercent,
+			AvgConsumptionRate: ex.AvgConsumptionRate,
+		}
+	}
+
+	for _, d := range h.DailyUsage {
+		out.DailyUsage = append(out.DailyUsage, DailyUsage{
+			Date:           d.Date,
+			ClaudeConsumed: d.ClaudeConsumed,
+			GeminiConsumed: d.GeminiConsumed,
+			TotalConsumed:  d.TotalConsumed,
+			RateLimitHits:  d.RateLimitHits,
+			Sessions:       d.SessionCount,
+		})
+	}
+	for _, p := range h.HourlyPatterns {
+		out.HourlyPatterns = append(out.HourlyPatterns, UsagePattern{
+			Label:       fmtHour(p.Hour),
+			Slot:        p.Hour,
+			AvgConsumed: p.AvgConsumed,
+			RateLimits:  p.TotalHits,
+			Occurrences: p.Occurrences,
+		})
+	}
+	for _, p := range h.WeekdayPatterns {
+		out.WeekdayPatterns = append(out.WeekdayPatterns, UsagePattern{
+			Label:       p.DayName,
+			Slot:        p.DayOfWeek,
+			AvgConsumed: p.AvgConsumed,
+			RateLimits:  p.TotalHits,
+			Occurrences: p.Occurrences,
+		})
+	}
+
+	return out
+}
+
+func newStats(s *services.StatsEvent) Stats {
+	return Stats{
+		AccountCount:   s.AccountCount,
+		QuotaCached:    s.QuotaCached,
+		TotalRemaining: s.TotalRemaining,
+		TotalLimit:     s.TotalLimit,
+		DaemonPID:      s.DaemonPID,
+	}
+}
+
+// finite returns nil for infinite or NaN values, which JSON cannot encode.
+func finite(v float64) *float64 {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+func fmtHour(hour int) string {
+	return time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC).Format("15:04")
+}
@@ -1,11 +1,16 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/login"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
 )
 
 const (
@@ -20,6 +25,9 @@ const (
 
 	// LongNotificationDuration is for important notifications.
 	LongNotificationDuration = 10 * time.Second
+
+	// RotationTickInterval is how often a rotation countdown is refreshed.
+	RotationTickInterval = time.Second
 )
 
 // tickCmd returns a command that sends a TickMsg after the specified interval.
@@ -49,8 +57,9 @@ func loadAccountsCmd(mgr *services.Manager) tea.Cmd {
 		stats := mgr.GetStats()
 
 		return AccountsLoadedMsg{
-			Accounts: accounts,
-			Stats:    stats,
+			Accounts:    accounts,
+			Stats:       stats,
+			Projections: mgr.GetAllProjections(),
 		}
 	}
 }
@@ -131,6 +140,20 @@ func switchAccountCmd(mgr *services.Manager, email string) tea.Cmd {
 	}
 }
 
+// rotationTickCmd returns a command that sends a RotationTickMsg after a second.
+func rotationTickCmd() tea.Cmd {
+	return tea.Tick(RotationTickInterval, func(_ time.Time) tea.Msg {
+		return RotationTickMsg{}
+	})
+}
+
+// undoRotationCmd returns a command that undoes the last automatic rotation.
+func undoRotationCmd(mgr *services.Manager) tea.Cmd {
+	return func() tea.Msg {
+		return UndoRotationResultMsg{Error: mgr.UndoRotation()}
+	}
+}
+
 // deleteAccountCmd returns a command that deletes an account.
 func deleteAccountCmd(mgr *services.Manager, email string) tea.Cmd {
 	return func() tea.Msg {
@@ -143,6 +166,59 @@ func deleteAccountCmd(mgr *services.Manager, email string) tea.Cmd {
 	}
 }
 
+// renameAccountCmd returns a command that sets the display name of an account.
+func renameAccountCmd(mgr *services.Manager, email, displayName string) tea.Cmd {
+	return func() tea.Msg {
+		result := RenameAccountResultMsg{Email: email, DisplayName: displayName}
+		acc := mgr.Accounts().GetAccountByEmail(email)
+		if acc == nil {
+			result.Error = fmt.Errorf("%w: %s", accounts.ErrAccountNotFound, email)
+			return result
+		}
+		acc.DisplayName = displayName
+		result.Error = mgr.Accounts().UpdateAccount(acc)
+		result.Success = result.Error == nil
+		return result
+	}
+}
+
+// pausePollingCmd returns a command that pauses or resumes background quota
+// polling of an account.
+func pausePollingCmd(mgr *services.Manager, email string, paused bool) tea.Cmd {
+	return func() tea.Msg {
+		result := PausePollingResultMsg{Email: email, Paused: paused}
+		acc := mgr.Accounts().GetAccountByEmail(email)
+		if acc == nil {
+			result.Error = fmt.Errorf("%w: %s", accounts.ErrAccountNotFound, email)
+			return result
+		}
+		acc.PollPaused = paused
+		result.Error = mgr.Accounts().UpdateAccount(acc)
+		result.Success = result.Error == nil
+		return result
+	}
+}
+
+// loginCmd returns a command that runs the browser login flow and saves the
+// account it signs in to.
+func loginCmd(mgr *services.Manager, cfg login.Config) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), login.DefaultTimeout)
+		defer cancel()
+
+		cfg.Open = func(authURL string) error {
+			logger.Info("Waiting for the browser sign-in", "url", authURL)
+			return login.OpenBrowser(authURL)
+		}
+		result, err := login.Run(ctx, cfg)
+		if err != nil {
+			return LoginResultMsg{Error: err}
+		}
+		added, err := login.Save(mgr.Accounts(), result)
+		return LoginResultMsg{Error: err, Email: result.Email, Added: added}
+	}
+}
+
 // notifySuccessCmd returns a command that adds a success notification.
 func notifySuccessCmd(message string) tea.Cmd {
 	return func() tea.Msg {
@@ -259,11 +335,27 @@ func (c *Commands) SwitchAccount(email string) tea.Cmd {
 	return switchAccountCmd(c.manager, email)
 }
 
+// UndoRotation returns a command that undoes the last automatic rotation.
+func (c *Commands) UndoRotation() tea.Cmd {
+	return undoRotationCmd(c.manager)
+}
+
 // DeleteAccount returns a command that deletes an account.
 func (c *Commands) DeleteAccount(email string) tea.Cmd {
 	return deleteAccountCmd(c.manager, email)
 }
 
+// RenameAccount returns a command that sets the display name of an account.
+func (c *Commands) RenameAccount(email, displayName string) tea.Cmd {
+	return renameAccountCmd(c.manager, email, displayName)
+}
+
+// PausePolling returns a command that pauses or resumes background quota
+// polling of an account.
+func (c *Commands) PausePolling(email string, paused bool) tea.Cmd {
+	return pausePollingCmd(c.manager, email, paused)
+}
+
 // NotifySuccess returns a command that adds a success notification.
 func (c *Commands) NotifySuccess(message string) tea.Cmd {
 	return notifySuccessCmd(message)
@@ -35,8 +35,9 @@ type InitialLoadCompleteMsg struct{}
 
 // AccountsLoadedMsg contains loaded account data.
 type AccountsLoadedMsg struct {
-	Accounts []models.AccountWithQuota
-	Stats    services.StatsEvent
+	Projections map[string]*models.AccountProjection
+	Accounts    []models.AccountWithQuota
+	Stats       services.StatsEvent
 }
 
 // QuotaRefreshedMsg contains refreshed quota data for an account.
@@ -63,6 +64,14 @@ type SwitchAccountResultMsg struct {
 	Success bool
 }
 
+// RotationTickMsg refreshes the countdown of a pending automatic rotation.
+type RotationTickMsg struct{}
+
+// UndoRotationResultMsg contains the result of undoing an automatic rotation.
+type UndoRotationResultMsg struct {
+	Error error
+}
+
 // DeleteAccountMsg requests deletion of an account.
 type DeleteAccountMsg struct {
 	Email string
@@ -75,6 +84,52 @@ type DeleteAccountResultMsg struct {
 	Success bool
 }
 
+// RenameAccountMsg requests changing the display name of an account.
+type RenameAccountMsg struct {
+	Email       string
+	DisplayName string
+}
+
+// RenameAccountResultMsg contains the result of renaming an account.
+type RenameAccountResultMsg struct {
+	Error       error
+	Email       string
+	DisplayName string
+	Success     bool
+}
+
+// PausePollingMsg requests pausing or resuming background quota polling of
+// an account.
+type PausePollingMsg struct {
+	Email  string
+	Paused bool
+}
+
+// PausePollingResultMsg contains the result of pausing or resuming polling.
+type PausePollingResultMsg struct {
+	Error   error
+	Email   string
+	Paused  bool
+	Success bool
+}
+
+// FocusAccountMsg reports the account selected in the dashboard, whose quota
+// is refreshed first.
+type FocusAccountMsg struct {
+	Email string
+}
+
+// LoginMsg requests signing in with Google to add or re-authenticate an
+// account.
+type LoginMsg struct{}
+
+// LoginResultMsg contains the result of a login.
+type LoginResultMsg struct {
+	Error error
+	Email string
+	Added bool
+}
+
 // RefreshMsg requests a refresh of data.
 type RefreshMsg struct {
 	Resource string // "all", "accounts", "quota", "stats"
@@ -2,8 +2,10 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -11,6 +13,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/login"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/rotation"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/styles"
 )
@@ -21,6 +25,8 @@ type TabID int
 const (
 	// TabDashboard is the ID for the dashboard tab.
 	TabDashboard TabID = iota
+	// TabAccounts is the ID for the accounts tab.
+	TabAccounts
 	// TabHistory is the ID for the history tab.
 	TabHistory
 	// TabInfo is the ID for the info tab.
@@ -32,6 +38,8 @@ func (t TabID) String() string {
 	switch t {
 	case TabDashboard:
 		return "Dashboard"
+	case TabAccounts:
+		return "Accounts"
 	case TabHistory:
 		return "History"
 	case TabInfo:
@@ -62,11 +70,23 @@ type Tab interface {
 	FullHelp() [][]key.Binding
 }
 
+// InputCapturer is implemented by tabs that can take over the keyboard, for
+// example while editing text. Global key bindings other than Ctrl+C are not
+// applied while CapturingInput returns true.
+type InputCapturer interface {
+	CapturingInput() bool
+}
+
+// navbarHeight is the number of lines above the tab content. Mouse events
+// are passed to tabs relative to the top of their content.
+const navbarHeight = 2
+
 // KeyMap defines the keybindings for the application.
 type KeyMap struct {
 	Tab1        key.Binding
 	Tab2        key.Binding
 	Tab3        key.Binding
+	Tab4        key.Binding
 	NextTab     key.Binding
 	PrevTab     key.Binding
 	Refresh     key.Binding
@@ -85,6 +105,7 @@ type KeyMap struct {
 	Filter      key.Binding
 	Copy        key.Binding
 	Delete      key.Binding
+	Undo        key.Binding
 	SwitchFocus key.Binding
 }
 
@@ -100,8 +121,9 @@ func DefaultKeyMap() KeyMap {
 
 func setTabKeys(k *KeyMap) {
 	k.Tab1 = key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "dashboard"))
-	k.Tab2 = key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "history"))
-	k.Tab3 = key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "info"))
+	k.Tab2 = key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "accounts"))
+	k.Tab3 = key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "history"))
+	k.Tab4 = key.NewBinding(key.WithKeys("4"), key.WithHelp("4", "info"))
 	k.NextTab = key.NewBinding(key.WithKeys("tab", "l", "right"), key.WithHelp("tab/→", "next tab"))
 	k.PrevTab = key.NewBinding(key.WithKeys("shift+tab", "h", "left"), key.WithHelp("shift+tab/←", "prev tab"))
 }
@@ -112,6 +134,7 @@ func setActionKeys(k *KeyMap) {
 	k.Quit = key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit"))
 	k.Copy = key.NewBinding(key.WithKeys("c", "ctrl+c"), key.WithHelp("c", "copy"))
 	k.Delete = key.NewBinding(key.WithKeys("d", "delete"), key.WithHelp("d", "delete"))
+	k.Undo = key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo rotation"))
 }
 
 func setNavigationKeys(k *KeyMap) {
@@ -140,10 +163,10 @@ func (k *KeyMap) ShortHelp() []key.Binding {
 // FullHelp returns key bindings for the full help view.
 func (k *KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Tab1, k.Tab2, k.Tab3},
+		{k.Tab1, k.Tab2, k.Tab3, k.Tab4},
 		{k.NextTab, k.PrevTab},
 		{k.Up, k.Down, k.PageUp, k.PageDown},
-		{k.Refresh, k.Help, k.Quit},
+		{k.Refresh, k.Undo, k.Help, k.Quit},
 	}
 }
 
@@ -219,6 +242,7 @@ type Model struct {
 	eventChannel chan services.ServiceEvent
 	state        *State
 	services     *services.Manager
+	login        *login.Config
 	keymap       KeyMap
 	tabNames     []string
 	tabs         []Tab
@@ -228,6 +252,7 @@ type Model struct {
 	height       int
 	showHelp     bool
 	ready        bool
+	loggingIn    bool
 }
 
 // NewModel initializes a new application model.
@@ -243,8 +268,8 @@ func NewModel(mgr *services.Manager) *Model {
 	// Create model
 	m := &Model{
 		activeTab: TabDashboard,
-		tabNames:  []string{"Dashboard", "History", "Info"},
-		tabs:      make([]Tab, 3), // Placeholder - tabs will be set externally
+		tabNames:  []string{"Dashboard", "Accounts", "History", "Info"},
+		tabs:      make([]Tab, 4), // Placeholder - tabs will be set externally
 		state:     state,
 		services:  mgr,
 		commands:  NewCommands(mgr),
@@ -266,6 +291,11 @@ func (m *Model) SetTabs(tabs []Tab) {
 	}
 }
 
+// SetLogin enables signing in from the TUI with the given configuration.
+func (m *Model) SetLogin(cfg login.Config) {
+	m.login = &cfg
+}
+
 // GetState returns the application state.
 func (m *Model) GetState() *State {
 	return m.state
@@ -386,8 +416,28 @@ func (m *Model) handleAppMsg(msg tea.Msg) []tea.Cmd {
 		m.handleStatsLoaded(msg)
 	case QuotaRefreshedMsg:
 		cmds = append(cmds, m.handleQuotaRefreshed(msg)...)
+	case SwitchAccountMsg, DeleteAccountMsg, RenameAccountMsg, PausePollingMsg, RefreshQuotaForAccountMsg:
+		cmds = append(cmds, m.handleAccountAction(msg))
+	case FocusAccountMsg:
+		if m.services != nil {
+			m.services.SetFocusAccount(msg.Email)
+		}
 	case SwitchAccountResultMsg:
 		cmds = append(cmds, m.handleSwitchAccountResult(msg)...)
+	case RenameAccountResultMsg:
+		cmds = append(cmds, m.handleRenameAccountResult(msg)...)
+	case PausePollingResultMsg:
+		cmds = append(cmds, m.handlePausePollingResult(msg)...)
+	case LoginMsg:
+		cmds = append(cmds, m.handleLogin())
+	case LoginResultMsg:
+		cmds = append(cmds, m.handleLoginResult(msg)...)
+	case RotationTickMsg:
+		if m.state.GetPendingRotation() != nil {
+			cmds = append(cmds, rotationTickCmd())
+		}
+	case UndoRotationResultMsg:
+		cmds = append(cmds, m.handleUndoRotationResult(msg))
 	case DeleteAccountResultMsg:
 		cmds = append(cmds, m.handleDeleteAccountResult(msg)...)
 	case ErrorMsg:
@@ -480,6 +530,11 @@ func (m *Model) handleAccountsLoaded(msg AccountsLoadedMsg) {
 	m.state.SetLoading("accounts", false)
 	m.state.SetAccounts(msg.Accounts)
 	m.state.SetStats(msg.Stats)
+	for email, proj := range msg.Projections {
+		if m.state.GetProjection(email) == nil {
+			m.state.SetProjection(email, proj)
+		}
+	}
 	if !m.state.AnyLoading() {
 		m.state.ClearLoadingNotification()
 	}
@@ -504,6 +559,28 @@ func (m *Model) handleQuotaRefreshed(msg QuotaRefreshedMsg) []tea.Cmd {
 	return cmds
 }
 
+// handleAccountAction runs an account action requested by a tab.
+func (m *Model) handleAccountAction(msg tea.Msg) tea.Cmd {
+	if m.services == nil {
+		return nil
+	}
+	switch msg := msg.(type) {
+	case SwitchAccountMsg:
+		return switchAccountCmd(m.services, msg.Email)
+	case DeleteAccountMsg:
+		return deleteAccountCmd(m.services, msg.Email)
+	case RenameAccountMsg:
+		return renameAccountCmd(m.services, msg.Email, msg.DisplayName)
+	case PausePollingMsg:
+		return pausePollingCmd(m.services, msg.Email, msg.Paused)
+	case RefreshQuotaForAccountMsg:
+		m.state.SetLoading("quota", true)
+		m.state.SetLoadingNotification(fmt.Sprintf("Refreshing %s...", msg.Email))
+		return refreshQuotaCmd(m.services, msg.Email)
+	}
+	return nil
+}
+
 func (m *Model) handleSwitchAccountResult(msg SwitchAccountResultMsg) []tea.Cmd {
 	var cmds []tea.Cmd
 	if msg.Success {
@@ -517,6 +594,49 @@ func (m *Model) handleSwitchAccountResult(msg SwitchAccountResultMsg) []tea.Cmd
 	return cmds
 }
 
+func (m *Model) handleUndoRotationResult(msg UndoRotationResultMsg) tea.Cmd {
+	switch {
+	case msg.Error == nil:
+		// The outcome arrives as a RotationEvent.
+		return nil
+	case errors.Is(msg.Error, rotation.ErrNothingToUndo):
+		return notifyInfoCmd("No account rotation to undo")
+	default:
+		return notifyErrorCmd(fmt.Sprintf("Failed to undo rotation: %v", msg.Error))
+	}
+}
+
+// handleRotationEvent tracks the pending rotation and reports its outcome.
+func (m *Model) handleRotationEvent(e services.RotationEvent) tea.Cmd {
+	rot := e.Rotation
+	switch e.Type {
+	case rotation.EventScheduled:
+		if !rot.Deadline.After(time.Now()) {
+			return nil
+		}
+		m.state.SetPendingRotation(&rot)
+		return rotationTickCmd()
+	case rotation.EventCancelled:
+		m.state.SetPendingRotation(nil)
+		return notifyInfoCmd(fmt.Sprintf("Rotation to %s cancelled", rot.To))
+	case rotation.EventSwitched:
+		m.state.SetPendingRotation(nil)
+		return func() tea.Msg {
+			return AddNotificationMsg{
+				Type:     NotificationWarning,
+				Message:  fmt.Sprintf("Rotated to %s: %s (u to undo)", rot.To, rot.Reason),
+				Duration: LongNotificationDuration,
+			}
+		}
+	case rotation.EventReverted:
+		return notifySuccessCmd(fmt.Sprintf("Switched back to %s", rot.From))
+	case rotation.EventFailed:
+		m.state.SetPendingRotation(nil)
+		return notifyErrorCmd(fmt.Sprintf("Failed to rotate to %s: %v", rot.To, e.Error))
+	}
+	return nil
+}
+
 func (m *Model) handleDeleteAccountResult(msg DeleteAccountResultMsg) []tea.Cmd {
 	var cmds []tea.Cmd
 	if msg.Success {
@@ -530,6 +650,77 @@ func (m *Model) handleDeleteAccountResult(msg DeleteAccountResultMsg) []tea.Cmd
 	return cmds
 }
 
+// handleLogin starts a browser login unless one is already waiting.
+func (m *Model) handleLogin() tea.Cmd {
+	switch {
+	case m.services == nil || m.login == nil:
+		return notifyErrorCmd("Login is not available")
+	case m.loggingIn:
+		return notifyWarningCmd("A login is already waiting for the browser")
+	}
+	m.loggingIn = true
+	return tea.Batch(
+		notifyInfoCmd("Complete the sign-in in your browser"),
+		loginCmd(m.services, *m.login),
+	)
+}
+
+func (m *Model) handleLoginResult(msg LoginResultMsg) []tea.Cmd {
+	m.loggingIn = false
+	var cmds []tea.Cmd
+	switch {
+	case msg.Error != nil:
+		cmds = append(cmds, notifyErrorCmd(fmt.Sprintf("Login failed: %v", msg.Error)))
+	case msg.Added:
+		cmds = append(cmds, notifySuccessCmd(fmt.Sprintf("Added %s", msg.Email)))
+	default:
+		cmds = append(cmds, notifySuccessCmd(fmt.Sprintf("Re-authenticated %s", msg.Email)))
+	}
+	if m.services != nil {
+		cmds = append(cmds, loadAccountsCmd(m.services))
+		// A new refresh token resumes polling of a revoked account; fetch
+		// its quota now rather than on the next poll.
+		if msg.Error == nil {
+			cmds = append(cmds, refreshQuotaCmd(m.services, msg.Email))
+		}
+	}
+	return cmds
+}
+
+func (m *Model) handleRenameAccountResult(msg RenameAccountResultMsg) []tea.Cmd {
+	var cmds []tea.Cmd
+	if msg.Success {
+		if msg.DisplayName == "" {
+			cmds = append(cmds, notifySuccessCmd(fmt.Sprintf("Cleared the name of %s", msg.Email)))
+		} else {
+			cmds = append(cmds, notifySuccessCmd(fmt.Sprintf("Renamed %s to %s", msg.Email, msg.DisplayName)))
+		}
+		if m.services != nil {
+			cmds = append(cmds, loadAccountsCmd(m.services))
+		}
+	} else {
+		cmds = append(cmds, notifyErrorCmd(fmt.Sprintf("Failed to rename account: %v", msg.Error)))
+	}
+	return cmds
+}
+
+func (m *Model) handlePausePollingResult(msg PausePollingResultMsg) []tea.Cmd {
+	var cmds []tea.Cmd
+	if msg.Success {
+		if msg.Paused {
+			cmds = append(cmds, notifySuccessCmd(fmt.Sprintf("Paused polling of %s", msg.Email)))
+		} else {
+			cmds = append(cmds, notifySuccessCmd(fmt.Sprintf("Resumed polling of %s", msg.Email)))
+		}
+		if m.services != nil {
+			cmds = append(cmds, loadAccountsCmd(m.services))
+		}
+	} else {
+		cmds = append(cmds, notifyErrorCmd(fmt.Sprintf("Failed to change polling: %v", msg.Error)))
+	}
+	return cmds
+}
+
 func (m *Model) handleAddNotification(msg AddNotificationMsg) []tea.Cmd {
 	var cmds []tea.Cmd
 	id := m.state.AddNotification(msg.Type, msg.Message, msg.Duration)
@@ -571,6 +762,10 @@ func (m *Model) handleRefresh(msg RefreshMsg) []tea.Cmd {
 }
 
 func (m *Model) updateActiveTab(msg tea.Msg) tea.Cmd {
+	if mouse, ok := msg.(tea.MouseMsg); ok {
+		mouse.Y -= navbarHeight
+		msg = mouse
+	}
 	if int(m.activeTab) < len(m.tabs) && m.tabs[m.activeTab] != nil {
 		var cmd tea.Cmd
 		m.tabs[m.activeTab], cmd = m.tabs[m.activeTab].Update(msg)
@@ -590,8 +785,24 @@ func (m *Model) updateTabSizes() {
 	}
 }
 
+// capturingInput reports whether the active tab has taken over the keyboard.
+func (m *Model) capturingInput() bool {
+	if int(m.activeTab) >= len(m.tabs) || m.tabs[m.activeTab] == nil {
+		return false
+	}
+	capturer, ok := m.tabs[m.activeTab].(InputCapturer)
+	return ok && capturer.CapturingInput()
+}
+
 // handleKeyMsg handles keyboard input.
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	if m.capturingInput() {
+		if msg.String() == "ctrl+c" {
+			return tea.Quit
+		}
+		return nil
+	}
+
 	// Global keybindings (work regardless of tab)
 	switch {
 	case key.Matches(msg, m.keymap.Quit):
@@ -605,9 +816,12 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
 		return func() tea.Msg { return TabSwitchMsg{Tab: TabDashboard} }
 
 	case key.Matches(msg, m.keymap.Tab2):
-		return func() tea.Msg { return TabSwitchMsg{Tab: TabHistory} }
+		return func() tea.Msg { return TabSwitchMsg{Tab: TabAccounts} }
 
 	case key.Matches(msg, m.keymap.Tab3):
+		return func() tea.Msg { return TabSwitchMsg{Tab: TabHistory} }
+
+	case key.Matches(msg, m.keymap.Tab4):
 		return func() tea.Msg { return TabSwitchMsg{Tab: TabInfo} }
 
 	case key.Matches(msg, m.keymap.NextTab):
@@ -633,6 +847,12 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
 		}
 		return nil
 
+	case key.Matches(msg, m.keymap.Undo):
+		if m.services != nil {
+			return undoRotationCmd(m.services)
+		}
+		return nil
+
 	case key.Matches(msg, m.keymap.Escape):
 		if m.showHelp {
 			m.showHelp = false
@@ -668,6 +888,9 @@ func (m *Model) handleServiceEvent(event services.ServiceEvent) tea.Cmd {
 	case services.ErrorEvent:
 		return notifyErrorCmd(fmt.Sprintf("[%s] %v", e.Service, e.Error))
 
+	case services.RotationEvent:
+		return m.handleRotationEvent(e)
+
 	case services.StatsEvent:
 		m.state.SetStats(e)
 	}
@@ -774,11 +997,15 @@ func (m *Model) renderNavbar() string {
 
 func (m *Model) renderNotifications() []string {
 	notifications := m.state.GetNotifications()
-	if len(notifications) == 0 {
+	pending := m.state.GetPendingRotation()
+	if len(notifications) == 0 && pending == nil {
 		return nil
 	}
 
 	var toasts []string
+	if pending != nil {
+		toasts = append(toasts, m.renderRotationCountdown(pending, time.Now()))
+	}
 	for _, n := range notifications {
 		var style lipgloss.Style
 		var prefix string
@@ -809,6 +1036,14 @@ func (m *Model) renderNotifications() []string {
 	return toasts
 }
 
+// renderRotationCountdown renders the toast of a pending automatic rotation.
+func (m *Model) renderRotationCountdown(rot *rotation.Rotation, now time.Time) string {
+	left := max(rot.Deadline.Sub(now).Round(time.Second), 0)
+	content := m.styles.NotificationWarning.Render(fmt.Sprintf(
+		"[ROTATE] %s → %s in %s (%s) · u to undo", rot.From, rot.To, left, rot.Reason))
+	return m.styles.Toast.Render(content)
+}
+
 func (m *Model) overlayToasts(mainView string, toasts []string) string {
 	if len(toasts) == 0 {
 		return mainView
@@ -851,12 +1086,13 @@ func (m *Model) renderHelp() string {
 		m.styles.Title.Render("Keyboard Shortcuts"),
 		"",
 		m.styles.Highlight.Render("Navigation"),
-		"  1-2        Switch tabs",
+		"  1-4        Switch tabs",
 		"  Tab/l      Next tab",
 		"  S-Tab/h    Previous tab",
 		"",
 		m.styles.Highlight.Render("Actions"),
 		"  r          Refresh data",
+		"  u          Undo auto-rotation",
 		"  ?          Toggle help",
 		"  q/Ctrl+C   Quit",
 		"",
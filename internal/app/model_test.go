@@ -5,9 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/rotation"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
 )
 
@@ -22,8 +24,8 @@ func TestNewModel(t *testing.T) {
 	if model.activeTab != TabDashboard {
 		t.Error("Default tab should be Dashboard")
 	}
-	if len(model.tabs) != 3 {
-		t.Errorf("Should have 3 tabs placeholder, got %d", len(model.tabs))
+	if len(model.tabs) != 4 {
+		t.Errorf("Should have 4 tabs placeholder, got %d", len(model.tabs))
 	}
 }
 
@@ -81,6 +83,75 @@ func TestModel_Update_TabSwitch(t *testing.T) {
 	}
 }
 
+// capturingTab is a tab that takes over the keyboard and records the
+// messages it receives.
+type capturingTab struct {
+	msgs      []tea.Msg
+	capturing bool
+}
+
+func (c *capturingTab) Init() tea.Cmd { return nil }
+func (c *capturingTab) Update(msg tea.Msg) (Tab, tea.Cmd) {
+	c.msgs = append(c.msgs, msg)
+	return c, nil
+}
+func (c *capturingTab) View() string              { return "" }
+func (c *capturingTab) SetSize(_, _ int)          {}
+func (c *capturingTab) ShortHelp() []key.Binding  { return nil }
+func (c *capturingTab) FullHelp() [][]key.Binding { return nil }
+func (c *capturingTab) CapturingInput() bool      { return c.capturing }
+
+func TestModel_InputCapture(t *testing.T) {
+	model := NewModel(nil)
+	tab := &capturingTab{capturing: true}
+	model.SetTabs([]Tab{tab})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if cmd := model.handleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}); cmd != nil {
+		t.Error("q should be passed to a tab capturing input, not quit")
+	}
+	if len(tab.msgs) != 1 {
+		t.Errorf("tab received %d messages, want 1", len(tab.msgs))
+	}
+	if cmd := model.handleKeyMsg(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("ctrl+c should still quit")
+	}
+
+	tab.capturing = false
+	if cmd := model.handleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}); cmd == nil {
+		t.Error("q should quit when the tab is not capturing input")
+	}
+}
+
+func TestModel_MouseRelativeToTab(t *testing.T) {
+	model := NewModel(nil)
+	tab := &capturingTab{}
+	model.SetTabs([]Tab{tab})
+
+	model.Update(tea.MouseMsg{X: 4, Y: 7})
+	if len(tab.msgs) != 1 {
+		t.Fatalf("tab received %d messages, want 1", len(tab.msgs))
+	}
+	if got := tab.msgs[0].(tea.MouseMsg); got.X != 4 || got.Y != 7-navbarHeight {
+		t.Errorf("tab received mouse at %d,%d, want 4,%d", got.X, got.Y, 7-navbarHeight)
+	}
+}
+
+func TestModel_Login(t *testing.T) {
+	model := NewModel(nil)
+
+	msg := model.handleLogin()()
+	if n, ok := msg.(AddNotificationMsg); !ok || n.Type != NotificationError {
+		t.Errorf("login without configuration = %#v, want an error", msg)
+	}
+
+	model.loggingIn = true
+	model.Update(LoginResultMsg{Email: "a@example.com", Added: true})
+	if model.loggingIn {
+		t.Error("a login result should end the pending login")
+	}
+}
+
 func TestModel_Update_Tick(t *testing.T) {
 	model := NewModel(nil)
 	msg := TickMsg{Time: time.Now()}
@@ -185,6 +256,42 @@ func TestModel_HandleServiceEvent(t *testing.T) {
 	}
 }
 
+func TestModel_RotationCountdown(t *testing.T) {
+	model := NewModel(nil)
+	model.ready = true
+	model.width = 140
+	model.height = 24
+
+	rot := rotation.Rotation{
+		From:     "a@example.com",
+		To:       "b@example.com",
+		Reason:   "Claude Sonnet is rate limited",
+		Deadline: time.Now().Add(30 * time.Second),
+	}
+	if cmd := model.handleServiceEvent(services.RotationEvent{Type: rotation.EventScheduled, Rotation: rot}); cmd == nil {
+		t.Error("scheduled rotation should start the countdown tick")
+	}
+	if model.state.GetPendingRotation() == nil {
+		t.Fatal("pending rotation should be tracked")
+	}
+	if view := model.View(); !strings.Contains(view, "u to undo") {
+		t.Error("View should show the rotation countdown")
+	}
+	if _, cmd := model.Update(RotationTickMsg{}); cmd == nil {
+		t.Error("countdown should keep ticking while pending")
+	}
+
+	model.handleServiceEvent(services.RotationEvent{Type: rotation.EventSwitched, Rotation: rot})
+	if model.state.GetPendingRotation() != nil {
+		t.Error("pending rotation should be cleared after the switch")
+	}
+
+	cmd := model.handleUndoRotationResult(UndoRotationResultMsg{Error: rotation.ErrNothingToUndo})
+	if msg, ok := cmd().(AddNotificationMsg); !ok || msg.Type != NotificationInfo {
+		t.Errorf("nothing to undo should be an info notification, got %+v", msg)
+	}
+}
+
 func TestModel_Update_Messages(t *testing.T) {
 	model := NewModel(nil)
 
@@ -314,6 +421,9 @@ func TestTabID_String(t *testing.T) {
 	if TabDashboard.String() != "Dashboard" {
 		t.Error("TabDashboard.String() mismatch")
 	}
+	if TabAccounts.String() != "Accounts" {
+		t.Error("TabAccounts.String() mismatch")
+	}
 	if TabHistory.String() != "History" {
 		t.Error("TabHistory.String() mismatch")
 	}
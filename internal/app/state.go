@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/rotation"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
 )
 
@@ -78,6 +79,7 @@ type State struct {
 	ActiveAccount        *models.AccountWithQuota
 	Stats                *services.StatsEvent
 	Projections          map[string]*models.AccountProjection
+	PendingRotation      *rotation.Rotation
 	Accounts             []models.AccountWithQuota
 	notifications        []Notification
 	SelectedAccountIndex int
@@ -386,6 +388,22 @@ func (s *State) GetProjections() map[string]*models.AccountProjection {
 	return result
 }
 
+// SetPendingRotation sets the automatic rotation that is counting down; nil
+// clears it.
+func (s *State) SetPendingRotation(rot *rotation.Rotation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PendingRotation = rot
+}
+
+// GetPendingRotation returns the automatic rotation that is counting down,
+// or nil.
+func (s *State) GetPendingRotation() *rotation.Rotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.PendingRotation
+}
+
 // GetSelectedAccountIndex returns the currently selected account index.
 func (s *State) GetSelectedAccountIndex() int {
 	s.mu.RLock()
@@ -2,10 +2,11 @@
 package config
 
 import (
-	"fmt"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,48 +14,96 @@ import (
 
 // Config holds the application configuration.
 type Config struct {
+	// QuotaHeaders are merged over the headers sent with quota requests.
+	QuotaHeaders map[string]string
+	// QuotaEndpoints are the Cloud Code API base URLs; empty uses Google's.
+	QuotaEndpoints       []string
 	DatabasePath         string
 	AccountsPath         string
 	GoogleClientID       string
 	GoogleClientSecret   string
+	GoogleAuthURL        string // OAuth authorization endpoint used by adt login
+	GoogleTokenURL       string // OAuth token endpoint used by adt login and token refreshes
+	GoogleUserInfoURL    string // endpoint identifying the signed-in account
+	LoginRedirectURL     string // loopback redirect for adt login; empty picks a free port
 	QuotaRefreshInterval time.Duration
+	QuotaRefreshMin      time.Duration // fastest per-account poll while quota is consumed
+	QuotaRefreshMax      time.Duration // slowest per-account poll while idle or exhausted
+	QuotaRefreshJitter   float64       // fraction of each interval to randomise
+	MaintenanceInterval  time.Duration
+	RetentionRawDays     int
+	RetentionBucketDays  int
+	RetentionHourlyDays  int
+	DaemonPIDPath        string
+	DaemonLogPath        string
+	StatuslineCachePath  string
+	AlertRulesPath       string
+	HooksPath            string
+	APIAddr              string // empty disables the HTTP API
+	AutoRotateStrategy   string
+	AutoRotateModel      string // glob of the models that trigger a rotation; empty matches all
+	AutoRotateDelay      time.Duration
+	AutoRotate           bool
+	ReadOnlyAccounts     bool // never write the accounts file
 }
 
 // Default values
 const (
 	defaultQuotaRefreshInterval = 30 * time.Second
+	defaultQuotaRefreshMin      = 10 * time.Second
+	defaultQuotaRefreshMax      = 5 * time.Minute
+	defaultQuotaRefreshJitter   = 0.1
+	defaultMaintenanceInterval  = time.Hour
+	defaultRetentionRawDays     = 7
+	defaultRetentionBucketDays  = 30
+	defaultRetentionHourlyDays  = 365
+	defaultAutoRotateDelay      = 30 * time.Second
+	defaultGoogleAuthURL        = "https://accounts.google.com/o/oauth2/v2/auth"
+	defaultGoogleTokenURL       = "https://oauth2.googleapis.com/token"
+	defaultGoogleUserInfoURL    = "https://www.googleapis.com/oauth2/v2/userinfo"
 )
 
 // Load reads configuration from .env files and environment variables.
+// Missing OAuth credentials are not an error: the dashboard starts in a
+// degraded, read-only mode and picks them up later via LoadCredentials.
 func Load() (*Config, error) {
-	// Try loading .env from multiple locations
-	envPaths := getEnvPaths()
-	for _, path := range envPaths {
-		if _, err := os.Stat(path); err == nil {
-			_ = godotenv.Load(path) //nolint:errcheck // Logger not initialized yet
-			break
-		}
-	}
+	loadEnvFile()
 
-	antigravityConstants := LoadAntigravityConstants()
-	var defaultClientID, defaultClientSecret string
-	if antigravityConstants != nil {
-		defaultClientID = antigravityConstants.ClientID
-		defaultClientSecret = antigravityConstants.ClientSecret
-	}
+	clientID, clientSecret := resolveCredentials()
 
 	cfg := &Config{
 		DatabasePath:         getEnvString("DATABASE_PATH", getDefaultDatabasePath()),
 		AccountsPath:         getEnvString("ACCOUNTS_PATH", getDefaultAccountsPath()),
-		GoogleClientID:       getEnvString("GOOGLE_CLIENT_ID", defaultClientID),
-		GoogleClientSecret:   getEnvString("GOOGLE_CLIENT_SECRET", defaultClientSecret),
+		GoogleClientID:       clientID,
+		GoogleClientSecret:   clientSecret,
+		GoogleAuthURL:        getEnvString("GOOGLE_AUTH_URL", defaultGoogleAuthURL),
+		GoogleTokenURL:       getEnvString("GOOGLE_TOKEN_URL", defaultGoogleTokenURL),
+		GoogleUserInfoURL:    getEnvString("GOOGLE_USERINFO_URL", defaultGoogleUserInfoURL),
+		QuotaEndpoints:       getEnvList("ANTIGRAVITY_ENDPOINTS"),
+		QuotaHeaders:         getEnvJSONMap("ANTIGRAVITY_HEADERS"),
+		LoginRedirectURL:     getEnvString("LOGIN_REDIRECT_URL", defaultRedirectURL()),
 		QuotaRefreshInterval: getEnvDuration("QUOTA_REFRESH_INTERVAL", defaultQuotaRefreshInterval),
+		QuotaRefreshMin:      getEnvDuration("QUOTA_REFRESH_MIN_INTERVAL", defaultQuotaRefreshMin),
+		QuotaRefreshMax:      getEnvDuration("QUOTA_REFRESH_MAX_INTERVAL", defaultQuotaRefreshMax),
+		QuotaRefreshJitter:   getEnvFloat("QUOTA_REFRESH_JITTER", defaultQuotaRefreshJitter),
+		MaintenanceInterval:  getEnvDuration("MAINTENANCE_INTERVAL", defaultMaintenanceInterval),
+		RetentionRawDays:     getEnvInt("RETENTION_RAW_DAYS", defaultRetentionRawDays),
+		RetentionBucketDays:  getEnvInt("RETENTION_BUCKET_DAYS", defaultRetentionBucketDays),
+		RetentionHourlyDays:  getEnvInt("RETENTION_HOURLY_DAYS", defaultRetentionHourlyDays),
+		APIAddr:              getEnvString("API_ADDR", ""),
+		AutoRotate:           getEnvBool("AUTO_ROTATE", false),
+		AutoRotateStrategy:   getEnvString("AUTO_ROTATE_STRATEGY", "most_remaining"),
+		AutoRotateModel:      getEnvString("AUTO_ROTATE_MODEL", ""),
+		AutoRotateDelay:      getEnvDuration("AUTO_ROTATE_DELAY", defaultAutoRotateDelay),
+		ReadOnlyAccounts:     getEnvBool("READ_ONLY_ACCOUNTS", false),
 	}
 
-	if cfg.GoogleClientID == "" || cfg.GoogleClientSecret == "" {
-		return nil, fmt.Errorf(
-			"GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET are required (set via env or opencode-antigravity-auth)")
-	}
+	dataDir := filepath.Dir(cfg.DatabasePath)
+	cfg.DaemonPIDPath = getEnvString("DAEMON_PID_FILE", filepath.Join(dataDir, "adt-daemon.pid"))
+	cfg.DaemonLogPath = getEnvString("DAEMON_LOG_FILE", filepath.Join(dataDir, "adt-daemon.log"))
+	cfg.StatuslineCachePath = getEnvString("STATUSLINE_CACHE_FILE", filepath.Join(dataDir, "adt-statusline.json"))
+	cfg.AlertRulesPath = getEnvString("ALERT_RULES_FILE", filepath.Join(dataDir, "alerts.json"))
+	cfg.HooksPath = getEnvString("HOOKS_FILE", filepath.Join(dataDir, "hooks.json"))
 
 	// Ensure database directory exists
 	if err := ensureDir(filepath.Dir(cfg.DatabasePath)); err != nil {
@@ -69,6 +118,51 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// HasCredentials reports whether OAuth client credentials are configured.
+func (c *Config) HasCredentials() bool {
+	return c.GoogleClientID != "" && c.GoogleClientSecret != ""
+}
+
+// LoadCredentials re-reads the .env files and the opencode-antigravity-auth
+// constants and returns the OAuth client credentials, which may be empty.
+// It is used to recover once credentials become available after startup.
+func LoadCredentials() (clientID, clientSecret string) {
+	loadEnvFile()
+	return resolveCredentials()
+}
+
+// loadEnvFile loads the first .env file found. Existing environment
+// variables are never overridden.
+func loadEnvFile() {
+	for _, path := range getEnvPaths() {
+		if _, err := os.Stat(path); err == nil {
+			_ = godotenv.Load(path) //nolint:errcheck // Logger not initialized yet
+			break
+		}
+	}
+}
+
+// resolveCredentials returns credentials from the environment, falling back
+// to the opencode-antigravity-auth constants.
+func resolveCredentials() (clientID, clientSecret string) {
+	var defaultClientID, defaultClientSecret string
+	if antigravityConstants := LoadAntigravityConstants(); antigravityConstants != nil {
+		defaultClientID = antigravityConstants.ClientID
+		defaultClientSecret = antigravityConstants.ClientSecret
+	}
+	return getEnvString("GOOGLE_CLIENT_ID", defaultClientID),
+		getEnvString("GOOGLE_CLIENT_SECRET", defaultClientSecret)
+}
+
+// defaultRedirectURL returns the redirect URL registered for the
+// opencode-antigravity-auth client, if known.
+func defaultRedirectURL() string {
+	if antigravityConstants := LoadAntigravityConstants(); antigravityConstants != nil {
+		return antigravityConstants.RedirectURI
+	}
+	return ""
+}
+
 // getEnvPaths returns a list of paths to check for .env files.
 func getEnvPaths() []string {
 	var paths []string
@@ -139,6 +233,63 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvInt retrieves an integer environment variable or returns the default.
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat retrieves a floating-point environment variable or returns the default.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList retrieves a comma-separated list environment variable, or nil.
+// Empty items are dropped.
+func getEnvList(key string) []string {
+	var list []string
+	for item := range strings.SplitSeq(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvJSONMap retrieves an environment variable holding a JSON object of
+// strings, e.g. {"User-Agent": "adt"}, or nil if it is unset or invalid.
+func getEnvJSONMap(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(value), &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// getEnvBool retrieves a boolean environment variable or returns the default.
+// Accepts the values understood by strconv.ParseBool, e.g. "1", "true", "false".
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
 // ensureDir creates a directory and all parent directories if they don't exist.
 func ensureDir(path string) error {
 	if path == "" || path == "." {
@@ -53,6 +53,58 @@ func TestGetEnvDuration(t *testing.T) {
 	}
 }
 
+func TestGetEnvBool(t *testing.T) {
+	key := "TEST_ENV_BOOL"
+
+	tests := []struct {
+		name       string
+		envVal     string
+		defaultVal bool
+		want       bool
+	}{
+		{"True", "true", false, true},
+		{"One", "1", false, true},
+		{"False", "false", true, false},
+		{"Invalid", "maybe", true, true},
+		{"Empty", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(key, tt.envVal)
+			if got := getEnvBool(key, tt.defaultVal); got != tt.want {
+				t.Errorf("getEnvBool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	key := "TEST_ENV_LIST"
+
+	t.Setenv(key, " http://a, ,http://b ")
+	if got := getEnvList(key); len(got) != 2 || got[0] != "http://a" || got[1] != "http://b" {
+		t.Errorf("getEnvList() = %q, want [http://a http://b]", got)
+	}
+	t.Setenv(key, "")
+	if got := getEnvList(key); got != nil {
+		t.Errorf("getEnvList() = %q, want nil", got)
+	}
+}
+
+func TestGetEnvJSONMap(t *testing.T) {
+	key := "TEST_ENV_JSON_MAP"
+
+	t.Setenv(key, `{"User-Agent": "adt", "X-Goog-Api-Client": ""}`)
+	if got := getEnvJSONMap(key); len(got) != 2 || got["User-Agent"] != "adt" {
+		t.Errorf("getEnvJSONMap() = %v", got)
+	}
+	t.Setenv(key, "User-Agent: adt")
+	if got := getEnvJSONMap(key); got != nil {
+		t.Errorf("getEnvJSONMap() = %v for invalid JSON, want nil", got)
+	}
+}
+
 func TestEnsureDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "nested", "dir")
@@ -113,6 +165,7 @@ func TestParseConstants(t *testing.T) {
 	content := `
 export declare const ANTIGRAVITY_CLIENT_ID = "client-id-123";
 export declare const ANTIGRAVITY_CLIENT_SECRET = "client-secret-456";
+export declare const ANTIGRAVITY_REDIRECT_URI = "http://localhost:51121/oauth-callback";
 `
 	constants := parseConstants(content)
 	if constants == nil {
@@ -124,6 +177,9 @@ export declare const ANTIGRAVITY_CLIENT_SECRET = "client-secret-456";
 	if constants.ClientSecret != "client-secret-456" {
 		t.Errorf("ClientSecret = %q, want %q", constants.ClientSecret, "client-secret-456")
 	}
+	if constants.RedirectURI != "http://localhost:51121/oauth-callback" {
+		t.Errorf("RedirectURI = %q", constants.RedirectURI)
+	}
 }
 
 func TestParseConstants_Invalid(t *testing.T) {
@@ -189,9 +245,28 @@ func TestLoad_MissingCredentials(t *testing.T) {
 	os.Setenv("HOME", tmpDir) // Set HOME to empty temp dir
 	defer os.Setenv("HOME", origHome)
 
-	_, err := Load()
-	if err == nil {
-		t.Error("Load() should fail when credentials are missing")
+	os.Setenv("DATABASE_PATH", filepath.Join(tmpDir, "db.sqlite"))
+	os.Setenv("ACCOUNTS_PATH", filepath.Join(tmpDir, "accounts.json"))
+	defer os.Unsetenv("DATABASE_PATH")
+	defer os.Unsetenv("ACCOUNTS_PATH")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() should succeed in degraded mode, got %v", err)
+	}
+	if cfg.HasCredentials() {
+		t.Error("HasCredentials() should be false when credentials are missing")
+	}
+
+	// Credentials that appear after startup are picked up by LoadCredentials.
+	os.Setenv("GOOGLE_CLIENT_ID", "late-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "late-secret")
+	defer os.Unsetenv("GOOGLE_CLIENT_ID")
+	defer os.Unsetenv("GOOGLE_CLIENT_SECRET")
+
+	id, secret := LoadCredentials()
+	if id != "late-id" || secret != "late-secret" {
+		t.Errorf("LoadCredentials() = %q, %q", id, secret)
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 type AntigravityConstants struct {
 	ClientID     string
 	ClientSecret string
+	RedirectURI  string // optional; the loopback URL registered for the client
 }
 
 func getConstantsFilePath() string {
@@ -64,6 +65,12 @@ func parseConstants(content string) *AntigravityConstants {
 		constants.ClientSecret = match[1]
 	}
 
+	// Match: export declare const ANTIGRAVITY_REDIRECT_URI = "...";
+	redirectRe := regexp.MustCompile(`ANTIGRAVITY_REDIRECT_URI\s*=\s*"([^"]+)"`)
+	if match := redirectRe.FindStringSubmatch(content); len(match) > 1 {
+		constants.RedirectURI = match[1]
+	}
+
 	if constants.ClientID == "" || constants.ClientSecret == "" {
 		return nil
 	}
@@ -0,0 +1,36 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package daemon
+
+import (
+	"errors"
+	"os"
+)
+
+// errLocked is returned by lockFile when another process holds the lock.
+var errLocked = errors.New("file is locked")
+
+// lockFile treats a PID file naming a live process as locked. Without flock
+// a daemon that crashed may leave a stale PID behind until it is reused.
+func lockFile(file *os.File) error {
+	if pid, ok := readPID(file.Name()); ok && pid != os.Getpid() && processAlive(pid) {
+		return errLocked
+	}
+	return nil
+}
+
+// isLocked reports whether the PID file names a live process.
+func isLocked(file *os.File) bool {
+	pid, ok := readPID(file.Name())
+	return ok && processAlive(pid)
+}
+
+// processAlive reports whether a process with the PID exists.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = proc.Release()
+	return true
+}
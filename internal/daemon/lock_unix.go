@@ -0,0 +1,32 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package daemon
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLocked is returned by lockFile when another process holds the lock.
+var errLocked = errors.New("file is locked")
+
+// lockFile takes an exclusive, non-blocking flock on the file.
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// isLocked reports whether another open file description holds a lock on
+// the file. A shared probe lock is taken and released immediately.
+func isLocked(file *os.File) bool {
+	fd := int(file.Fd())
+	if err := syscall.Flock(fd, syscall.LOCK_SH|syscall.LOCK_NB); err != nil {
+		return errors.Is(err, syscall.EWOULDBLOCK)
+	}
+	_ = syscall.Flock(fd, syscall.LOCK_UN)
+	return false
+}
@@ -0,0 +1,112 @@
+// Package daemon implements the PID/lock file shared by the headless
+// collector and the dashboards that read from it.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrAlreadyRunning is returned by Acquire when another daemon holds the lock.
+var ErrAlreadyRunning = errors.New("daemon already running")
+
+// PIDFile is a held PID/lock file. The lock lives as long as the process
+// keeps the file open, so a crashed daemon never leaves a stale lock behind.
+type PIDFile struct {
+	file *os.File
+	path string
+}
+
+// Acquire takes the lock at path and writes the current PID into it.
+// It fails with ErrAlreadyRunning while another process holds the lock.
+func Acquire(path string) (*PIDFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create pid file directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pid file: %w", err)
+	}
+
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		if errors.Is(err, errLocked) {
+			if pid, ok := readPID(path); ok {
+				return nil, fmt.Errorf("%w (pid %d)", ErrAlreadyRunning, pid)
+			}
+			return nil, ErrAlreadyRunning
+		}
+		return nil, fmt.Errorf("failed to lock pid file: %w", err)
+	}
+
+	if err := file.Truncate(0); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to truncate pid file: %w", err)
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write pid file: %w", err)
+	}
+
+	return &PIDFile{file: file, path: path}, nil
+}
+
+// Path returns the location of the PID file.
+func (p *PIDFile) Path() string {
+	return p.path
+}
+
+// Release removes the PID file and drops the lock.
+func (p *PIDFile) Release() error {
+	if p == nil || p.file == nil {
+		return nil
+	}
+
+	removeErr := os.Remove(p.path)
+	closeErr := p.file.Close()
+	p.file = nil
+
+	if removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove pid file: %w", removeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close pid file: %w", closeErr)
+	}
+	return nil
+}
+
+// Running reports whether a daemon currently holds the lock at path and
+// returns its PID. The PID is 0 while a daemon that just took the lock has
+// not written it yet.
+func Running(path string) (int, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer func() { _ = file.Close() }()
+
+	if !isLocked(file) {
+		return 0, false
+	}
+
+	pid, _ := readPID(path)
+	return pid, true
+}
+
+// readPID parses the PID stored in the file at path.
+func readPID(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
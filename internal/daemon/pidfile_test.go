@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "adt-daemon.pid")
+
+	if _, running := Running(path); running {
+		t.Fatal("expected no daemon before Acquire")
+	}
+
+	pidFile, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+
+	pid, running := Running(path)
+	if !running || pid != os.Getpid() {
+		t.Errorf("Running() = %d, %v; want %d, true", pid, running, os.Getpid())
+	}
+
+	if _, err := Acquire(path); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second Acquire() error = %v, want ErrAlreadyRunning", err)
+	}
+
+	if err := pidFile.Release(); err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected pid file to be removed, stat error = %v", err)
+	}
+	if _, running := Running(path); running {
+		t.Error("expected no daemon after Release")
+	}
+	if err := pidFile.Release(); err != nil {
+		t.Errorf("second Release() failed: %v", err)
+	}
+}
+
+func TestRunning_StalePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adt-daemon.pid")
+	// A crashed daemon leaves its PID behind without holding the lock.
+	if err := os.WriteFile(path, []byte("999999\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, running := Running(path); running {
+		t.Error("expected stale pid file not to count as running")
+	}
+
+	pidFile, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() over stale pid file failed: %v", err)
+	}
+	defer func() { _ = pidFile.Release() }()
+
+	if pid, _ := readPID(path); pid != os.Getpid() {
+		t.Errorf("pid file holds %d, want %d", pid, os.Getpid())
+	}
+}
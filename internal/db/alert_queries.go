@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// GetAlertStates returns the persisted state of every alert rule.
+func (db *DB) GetAlertStates() ([]models.AlertState, error) {
+	query := `
+		SELECT rule_id, email, model, firing, value, last_fired, updated_at
+		FROM alert_state
+	`
+	rows, err := db.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert states: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var states []models.AlertState
+	for rows.Next() {
+		var state models.AlertState
+		var lastFired, updatedAt sql.NullString
+		if err := rows.Scan(&state.RuleID, &state.Email, &state.Model, &state.Firing,
+			&state.Value, &lastFired, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert state: %w", err)
+		}
+		if t, ok := parseTimeString(lastFired.String); ok {
+			state.LastFired = t
+		}
+		if t, ok := parseTimeString(updatedAt.String); ok {
+			state.UpdatedAt = t
+		}
+		states = append(states, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert states: %w", err)
+	}
+	return states, nil
+}
+
+// UpsertAlertState stores the state of one alert rule for an account and model.
+func (db *DB) UpsertAlertState(state *models.AlertState) error {
+	updatedAt := state.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	var lastFired sql.NullString
+	if !state.LastFired.IsZero() {
+		lastFired = sql.NullString{String: state.LastFired.UTC().Format("2006-01-02 15:04:05"), Valid: true}
+	}
+
+	query := `
+		INSERT INTO alert_state (rule_id, email, model, firing, value, last_fired, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(rule_id, email, model) DO UPDATE SET
+			firing = excluded.firing,
+			value = excluded.value,
+			last_fired = excluded.last_fired,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.ExecContext(context.Background(), query,
+		state.RuleID, state.Email, state.Model, state.Firing, state.Value, lastFired,
+		updatedAt.UTC().Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert alert state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestAlertState_RoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	fired := time.Date(2025, 6, 10, 15, 4, 5, 0, time.UTC)
+	state := &models.AlertState{
+		RuleID: "quota-low", Email: "test@example.com", Model: "claude-opus",
+		Firing: true, Value: "4.5", LastFired: fired,
+	}
+	if err := db.UpsertAlertState(state); err != nil {
+		t.Fatalf("UpsertAlertState failed: %v", err)
+	}
+	account := &models.AlertState{RuleID: "tier-change", Email: "test@example.com", Value: "PRO"}
+	if err := db.UpsertAlertState(account); err != nil {
+		t.Fatalf("UpsertAlertState failed: %v", err)
+	}
+
+	// Clearing the alert updates the row in place.
+	state.Firing = false
+	if err := db.UpsertAlertState(state); err != nil {
+		t.Fatalf("UpsertAlertState failed: %v", err)
+	}
+
+	states, err := db.GetAlertStates()
+	if err != nil {
+		t.Fatalf("GetAlertStates failed: %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("Expected 2 alert states, got %+v", states)
+	}
+	for _, s := range states {
+		switch s.RuleID {
+		case "quota-low":
+			if s.Firing || s.Value != "4.5" || !s.LastFired.Equal(fired) || s.Model != "claude-opus" {
+				t.Errorf("Unexpected state: %+v", s)
+			}
+		case "tier-change":
+			if !s.LastFired.IsZero() || s.Model != "" || s.Value != "PRO" || s.UpdatedAt.IsZero() {
+				t.Errorf("Unexpected state: %+v", s)
+			}
+		}
+	}
+}
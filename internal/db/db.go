@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
@@ -18,11 +19,28 @@ import (
 // DB wraps the SQL database connection with application-specific methods.
 type DB struct {
 	*sql.DB
-	path string
+	path      string
+	retention RetentionPolicy
 }
 
-// New creates a new database connection and initializes the schema.
+// New opens the database and applies any pending schema migrations.
 func New(path string) (*DB, error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Open opens the database without applying migrations. It refuses to open a
+// database written by a newer version of the schema.
+func Open(path string) (*DB, error) {
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if dir != "" && dir != "." {
@@ -32,7 +50,11 @@ func New(path string) (*DB, error) {
 	}
 
 	// Open database connection
-	sqlDB, err := sql.Open("sqlite", path)
+	source, err := dsn(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	sqlDB, err := sql.Open("sqlite", source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -44,8 +66,9 @@ func New(path string) (*DB, error) {
 	}
 
 	db := &DB{
-		DB:   sqlDB,
-		path: path,
+		DB:        sqlDB,
+		path:      path,
+		retention: DefaultRetentionPolicy(),
 	}
 
 	// Configure database
@@ -54,21 +77,39 @@ func New(path string) (*DB, error) {
 		return nil, fmt.Errorf("failed to configure database: %w", err)
 	}
 
-	// Create schema
-	if err := db.createSchema(); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("failed to create schema: %w", err)
-	}
-
-	// Fix legacy time formats
-	if err := db.FixLegacyTimeFormats(); err != nil {
+	// Refuse databases from newer releases before touching anything
+	if err := db.checkSchemaVersion(); err != nil {
 		_ = db.Close()
-		return nil, fmt.Errorf("failed to fix legacy time formats: %w", err)
+		return nil, err
 	}
 
 	return db, nil
 }
 
+// dsnParams make write transactions begin IMMEDIATE and every pooled
+// connection wait on a busy database, so concurrent writers (including
+// another process migrating) queue instead of failing with SQLITE_BUSY.
+const dsnParams = "_txlock=immediate&_pragma=busy_timeout(5000)"
+
+// dsn builds the driver connection string for path. The absolute path goes
+// into a file: URI so that characters such as '?' and '#' are escaped
+// instead of starting the parameters.
+func dsn(path string) (string, error) {
+	if path == ":memory:" {
+		return path + "?" + dsnParams, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.ToSlash(abs)
+	if filepath.VolumeName(abs) != "" {
+		p = "/" + p
+	}
+	u := url.URL{Scheme: "file", Path: p, RawQuery: dsnParams}
+	return u.String(), nil
+}
+
 // Path returns the database file path.
 func (db *DB) Path() string {
 	return db.path
@@ -94,23 +135,27 @@ func (db *DB) configure() error {
 	return nil
 }
 
-func (db *DB) createSchema() error {
-	if err := db.createAPICallsTable(); err != nil {
-		return err
-	}
-	if err := db.createAccountStatusTable(); err != nil {
-		return err
+// createInitialSchema creates the tables that existed before schema
+// versioning. Every statement is idempotent so databases created by older
+// releases are adopted as-is.
+func createInitialSchema(ctx context.Context, tx *sql.Tx) error {
+	creators := []func(context.Context, *sql.Tx) error{
+		createAPICallsTable,
+		createAccountStatusTable,
+		createSessionEventsTable,
+		createQuotaSnapshotsTable,
+		createAggregatedSnapshotsTable,
+		createModelSnapshotsTable,
 	}
-	if err := db.createSessionEventsTable(); err != nil {
-		return err
-	}
-	if err := db.createQuotaSnapshotsTable(); err != nil {
-		return err
+	for _, create := range creators {
+		if err := create(ctx, tx); err != nil {
+			return err
+		}
 	}
-	return db.createAggregatedSnapshotsTable()
+	return nil
 }
 
-func (db *DB) createAPICallsTable() error {
+func createAPICallsTable(ctx context.Context, tx *sql.Tx) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS api_calls (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -132,11 +177,11 @@ func (db *DB) createAPICallsTable() error {
 	CREATE INDEX IF NOT EXISTS idx_api_calls_email ON api_calls(email);
 	CREATE INDEX IF NOT EXISTS idx_api_calls_session ON api_calls(session_id);
 	`
-	_, err := db.ExecContext(context.Background(), query)
+	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func (db *DB) createAccountStatusTable() error {
+func createAccountStatusTable(ctx context.Context, tx *sql.Tx) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS account_status (
 		email TEXT PRIMARY KEY,
@@ -151,11 +196,11 @@ func (db *DB) createAccountStatusTable() error {
 		gemini_reset_sec INTEGER
 	);
 	`
-	_, err := db.ExecContext(context.Background(), query)
+	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func (db *DB) createSessionEventsTable() error {
+func createSessionEventsTable(ctx context.Context, tx *sql.Tx) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS session_events (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -168,11 +213,11 @@ func (db *DB) createSessionEventsTable() error {
 	CREATE INDEX IF NOT EXISTS idx_session_events_session ON session_events(session_id);
 	CREATE INDEX IF NOT EXISTS idx_session_events_timestamp ON session_events(timestamp);
 	`
-	_, err := db.ExecContext(context.Background(), query)
+	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func (db *DB) createQuotaSnapshotsTable() error {
+func createQuotaSnapshotsTable(ctx context.Context, tx *sql.Tx) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS quota_snapshots (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -187,11 +232,11 @@ func (db *DB) createQuotaSnapshotsTable() error {
 	CREATE INDEX IF NOT EXISTS idx_quota_snapshots_email ON quota_snapshots(email);
 	CREATE INDEX IF NOT EXISTS idx_quota_snapshots_timestamp ON quota_snapshots(timestamp);
 	`
-	_, err := db.ExecContext(context.Background(), query)
+	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func (db *DB) createAggregatedSnapshotsTable() error {
+func createAggregatedSnapshotsTable(ctx context.Context, tx *sql.Tx) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS quota_snapshots_agg (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -216,7 +261,119 @@ func (db *DB) createAggregatedSnapshotsTable() error {
 	CREATE INDEX IF NOT EXISTS idx_agg_session ON quota_snapshots_agg(session_id);
 	CREATE INDEX IF NOT EXISTS idx_agg_dow_hour ON quota_snapshots_agg(email, day_of_week, hour);
 	`
-	_, err := db.ExecContext(context.Background(), query)
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func createModelSnapshotsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS quota_snapshots_model_agg (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL,
+		bucket_time DATETIME NOT NULL,
+		model_id TEXT NOT NULL,
+		display_name TEXT,
+		model_family TEXT NOT NULL,
+		quota_avg REAL DEFAULT 0,
+		consumed REAL DEFAULT 0,
+		sample_count INTEGER DEFAULT 1,
+		session_id TEXT,
+		tier TEXT DEFAULT 'UNKNOWN',
+		UNIQUE(email, bucket_time, model_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_model_agg_email_model ON quota_snapshots_model_agg(email, model_id, bucket_time);
+	CREATE INDEX IF NOT EXISTS idx_model_agg_session ON quota_snapshots_model_agg(session_id);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createRollupTables creates the hourly and daily rollups of
+// quota_snapshots_agg. Rows are keyed by session so session statistics
+// survive once the 5-minute buckets have been pruned.
+func createRollupTables(ctx context.Context, tx *sql.Tx) error {
+	for _, table := range []string{"quota_snapshots_hourly", "quota_snapshots_daily"} {
+		query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email TEXT NOT NULL,
+			bucket_time DATETIME NOT NULL,
+			session_id TEXT NOT NULL DEFAULT '',
+			claude_quota_avg REAL DEFAULT 0,
+			gemini_quota_avg REAL DEFAULT 0,
+			claude_consumed REAL DEFAULT 0,
+			gemini_consumed REAL DEFAULT 0,
+			claude_peak REAL DEFAULT 0,
+			gemini_peak REAL DEFAULT 0,
+			total_peak REAL DEFAULT 0,
+			bucket_count INTEGER DEFAULT 0,
+			sample_count INTEGER DEFAULT 0,
+			tier TEXT DEFAULT 'UNKNOWN',
+			day_of_week INTEGER GENERATED ALWAYS AS (CAST(strftime('%%w', bucket_time) AS INTEGER)) STORED,
+			hour INTEGER GENERATED ALWAYS AS (CAST(strftime('%%H', bucket_time) AS INTEGER)) STORED,
+			UNIQUE(email, bucket_time, session_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_%[1]s_email_time ON %[1]s(email, bucket_time);
+		`, table)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAccountStatusModelQuotas stores the full per-model quotas of each
+// account as JSON, so a reader of the shared database (e.g. a dashboard
+// following the daemon) sees the same detail as the process that polled.
+func addAccountStatusModelQuotas(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "ALTER TABLE account_status ADD COLUMN model_quotas TEXT")
+	return err
+}
+
+// createAlertStateTable stores the state of each alert rule per account and
+// model so restarts do not re-fire alerts that already went out.
+func createAlertStateTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS alert_state (
+		rule_id TEXT NOT NULL,
+		email TEXT NOT NULL,
+		model TEXT NOT NULL DEFAULT '',
+		firing INTEGER NOT NULL DEFAULT 0,
+		value TEXT NOT NULL DEFAULT '',
+		last_fired DATETIME,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (rule_id, email, model)
+	);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createNotificationQueueTable stores notifications that could not be
+// delivered so they are retried after an outage or restart.
+func createNotificationQueueTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS notification_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sink TEXT NOT NULL,
+		payload BLOB NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		next_attempt DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notification_queue_next ON notification_queue(next_attempt);
+	`
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// addAccountStatusRevokedToken remembers the fingerprint of a refresh token
+// Google rejected as revoked or expired, so restarts and followers keep the
+// account paused until it logs in again.
+func addAccountStatusRevokedToken(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "ALTER TABLE account_status ADD COLUMN revoked_token TEXT NOT NULL DEFAULT ''")
 	return err
 }
 
@@ -42,6 +42,38 @@ func TestNew_CreatesDirectory(t *testing.T) {
 	}
 }
 
+func TestNew_PathWithURICharacters(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "odd?dir#1", "usage 100%?.db")
+
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", dbPath, err)
+	}
+	defer db.Close()
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("database was not created at %q: %v", dbPath, err)
+	}
+	var timeout int
+	if err := db.QueryRowContext(context.Background(), "PRAGMA busy_timeout").Scan(&timeout); err != nil || timeout != 5000 {
+		t.Errorf("busy_timeout = %d, %v; want the DSN parameters applied", timeout, err)
+	}
+}
+
+func TestNew_RelativePath(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	db, err := New(filepath.Join("data", "test.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := os.Stat(filepath.Join("data", "test.db")); err != nil {
+		t.Errorf("database was not created relative to the working directory: %v", err)
+	}
+}
+
 func TestSchema_TablesExist(t *testing.T) {
 	db := newTestDB(t)
 	defer db.Close()
@@ -28,24 +28,76 @@ func parseTimeString(s string) (time.Time, bool) {
 	return time.Time{}, false
 }
 
+// History sources expose the same columns at every resolution. For 5-minute
+// buckets the peaks equal the consumption and bucket_count is 1, so
+// SUM(consumed) / SUM(bucket_count) * 12 is the hourly rate at any resolution
+// (see sqlRatePerHour).
+const bucketHistorySelect = `
+	SELECT email, bucket_time, session_id, claude_consumed, gemini_consumed,
+		claude_consumed AS claude_peak, gemini_consumed AS gemini_peak,
+		claude_consumed + gemini_consumed AS total_peak, 1 AS bucket_count, hour, day_of_week
+	FROM quota_snapshots_agg a`
+
+const rollupHistorySelect = `
+	SELECT email, bucket_time, NULLIF(session_id, '') AS session_id, claude_consumed, gemini_consumed,
+		claude_peak, gemini_peak, total_peak, bucket_count, hour, day_of_week
+	FROM %s`
+
+// historySource returns a subquery yielding historyColumns at the given
+// resolution. Rollups only cover completed periods, so each source is
+// topped up with the finer rows newer than its last rolled-up period.
+func historySource(res Resolution) string {
+	hourlyWatermark := `(SELECT datetime(MAX(h.bucket_time), '+1 hour')
+		FROM quota_snapshots_hourly h WHERE h.email = a.email)`
+	dailyWatermark := `(SELECT datetime(MAX(d.bucket_time), '+1 day')
+		FROM quota_snapshots_daily d WHERE d.email = %s.email)`
+
+	switch res {
+	case ResolutionHourly:
+		return "(" + fmt.Sprintf(rollupHistorySelect, "quota_snapshots_hourly") +
+			" UNION ALL " + bucketHistorySelect +
+			" WHERE a.bucket_time >= COALESCE(" + hourlyWatermark + ", ''))"
+	case ResolutionDaily:
+		return "(" + fmt.Sprintf(rollupHistorySelect, "quota_snapshots_daily") +
+			" UNION ALL " + fmt.Sprintf(rollupHistorySelect, "quota_snapshots_hourly h") +
+			" WHERE h.bucket_time >= COALESCE(" + fmt.Sprintf(dailyWatermark, "h") + ", '')" +
+			" UNION ALL " + bucketHistorySelect +
+			" WHERE a.bucket_time >= COALESCE(" + hourlyWatermark + ", " +
+			fmt.Sprintf(dailyWatermark, "a") + ", ''))"
+	default:
+		return "(" + bucketHistorySelect + ")"
+	}
+}
+
+// sqlRatePerHour is the average combined consumption per hour.
+const sqlRatePerHour = "COALESCE(SUM(claude_consumed + gemini_consumed) * 12.0 / NULLIF(SUM(bucket_count), 0), 0)"
+
+// sqlAvgPerBucket is the average combined consumption per 5-minute bucket.
+const sqlAvgPerBucket = "COALESCE(SUM(claude_consumed + gemini_consumed) * 1.0 / NULLIF(SUM(bucket_count), 0), 0)"
+
+// patternResolution caps res at hourly since daily rows carry no hour of day.
+func patternResolution(res Resolution) Resolution {
+	return min(res, ResolutionHourly)
+}
+
 // GetMonthlyStats returns aggregated stats per month.
 func (db *DB) GetMonthlyStats(email string, months int) ([]models.PeriodStats, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
-			strftime('%Y-%m', bucket_time) as period,
+			strftime('%%Y-%%m', bucket_time) as period,
 			SUM(claude_consumed + gemini_consumed) as total_consumed,
-			AVG(claude_consumed + gemini_consumed) * 12 as avg_rate,
-			MAX(claude_consumed + gemini_consumed) * 12 as peak_rate,
+			%s as avg_rate,
+			MAX(total_peak) * 12 as peak_rate,
 			COUNT(DISTINCT session_id) as session_count,
-			COUNT(*) as data_points,
+			SUM(bucket_count) as data_points,
 			MIN(bucket_time) as start_time,
 			MAX(bucket_time) as end_time
-		FROM quota_snapshots_agg
+		FROM %s
 		WHERE email = ? AND bucket_time >= datetime('now', ?)
 		GROUP BY period
 		ORDER BY period DESC
 		LIMIT ?
-	`
+	`, sqlRatePerHour, historySource(ResolutionDaily))
 
 	windowStr := fmt.Sprintf("-%d months", months)
 	rows, err := db.QueryContext(context.Background(), query, email, windowStr, months)
@@ -87,17 +139,17 @@ func (db *DB) GetMonthlyStats(email string, months int) ([]models.PeriodStats, e
 
 // GetUsagePatterns returns average consumption by hour and day of week.
 func (db *DB) GetUsagePatterns(email string) ([]models.UsagePattern, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			day_of_week,
 			hour,
-			AVG(claude_consumed + gemini_consumed) as avg_consumed,
-			COUNT(*) as occurrences
-		FROM quota_snapshots_agg
+			%s as avg_consumed,
+			SUM(bucket_count) as occurrences
+		FROM %s
 		WHERE email = ?
 		GROUP BY day_of_week, hour
 		ORDER BY day_of_week, hour
-	`
+	`, sqlAvgPerBucket, historySource(ResolutionHourly))
 
 	rows, err := db.QueryContext(context.Background(), query, email)
 	if err != nil {
@@ -145,21 +197,21 @@ func (db *DB) GetHistoricalContext(email string) (*models.HistoricalContext, err
 }
 
 func (db *DB) getMonthlyRates(email string, ctx *models.HistoricalContext) error {
-	currentMonthQuery := `
-		SELECT COALESCE(AVG(claude_consumed + gemini_consumed) * 12, 0)
-		FROM quota_snapshots_agg
-		WHERE email = ? AND strftime('%Y-%m', bucket_time) = strftime('%Y-%m', 'now')
-	`
+	currentMonthQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE email = ? AND strftime('%%Y-%%m', bucket_time) = strftime('%%Y-%%m', 'now')
+	`, sqlRatePerHour, historySource(ResolutionDaily))
 	err := db.QueryRowContext(context.Background(), currentMonthQuery, email).Scan(&ctx.CurrentMonthRate)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to scan current month rate: %w", err)
 	}
 
-	lastMonthQuery := `
-		SELECT COALESCE(AVG(claude_consumed + gemini_consumed) * 12, 0)
-		FROM quota_snapshots_agg
-		WHERE email = ? AND strftime('%Y-%m', bucket_time) = strftime('%Y-%m', 'now', '-1 month')
-	`
+	lastMonthQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE email = ? AND strftime('%%Y-%%m', bucket_time) = strftime('%%Y-%%m', 'now', '-1 month')
+	`, sqlRatePerHour, historySource(ResolutionDaily))
 	err = db.QueryRowContext(context.Background(), lastMonthQuery, email).Scan(&ctx.LastMonthRate)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to scan last month rate: %w", err)
@@ -172,16 +224,16 @@ func (db *DB) getMonthlyRates(email string, ctx *models.HistoricalContext) error
 }
 
 func (db *DB) getAllTimeStats(email string, ctx *models.HistoricalContext) error {
-	allTimeQuery := `
+	allTimeQuery := fmt.Sprintf(`
 		SELECT
-			COALESCE(AVG(claude_consumed + gemini_consumed) * 12, 0) as avg_rate,
-			COALESCE(MAX(claude_consumed + gemini_consumed) * 12, 0) as peak_rate,
+			%s as avg_rate,
+			COALESCE(MAX(total_peak) * 12, 0) as peak_rate,
 			COUNT(DISTINCT session_id) as total_sessions,
 			MIN(bucket_time) as first_data,
 			CAST(julianday('now') - julianday(MIN(bucket_time)) AS INTEGER) as total_days
-		FROM quota_snapshots_agg
+		FROM %s
 		WHERE email = ?
-	`
+	`, sqlRatePerHour, historySource(ResolutionDaily))
 	var firstDataStr sql.NullString
 	var totalDays sql.NullInt64
 	if err := db.QueryRowContext(context.Background(), allTimeQuery, email).Scan(
@@ -209,14 +261,14 @@ func (db *DB) getPeakUsage(email string, ctx *models.HistoricalContext) error {
 }
 
 func (db *DB) getPeakUsageDay(email string, ctx *models.HistoricalContext) error {
-	peakDayQuery := `
+	peakDayQuery := fmt.Sprintf(`
 		SELECT day_of_week
-		FROM quota_snapshots_agg
+		FROM %s
 		WHERE email = ?
 		GROUP BY day_of_week
 		ORDER BY SUM(claude_consumed + gemini_consumed) DESC
 		LIMIT 1
-	`
+	`, historySource(ResolutionDaily))
 	var peakDayNum sql.NullInt64
 	if err := db.QueryRowContext(context.Background(), peakDayQuery, email).Scan(&peakDayNum); err != nil &&
 		err != sql.ErrNoRows {
@@ -232,14 +284,14 @@ func (db *DB) getPeakUsageDay(email string, ctx *models.HistoricalContext) error
 }
 
 func (db *DB) getPeakUsageHour(email string, ctx *models.HistoricalContext) error {
-	peakHourQuery := `
+	peakHourQuery := fmt.Sprintf(`
 		SELECT hour
-		FROM quota_snapshots_agg
+		FROM %s
 		WHERE email = ?
 		GROUP BY hour
 		ORDER BY SUM(claude_consumed + gemini_consumed) DESC
 		LIMIT 1
-	`
+	`, historySource(ResolutionHourly))
 	var peakHour sql.NullInt64
 	if err := db.QueryRowContext(context.Background(), peakHourQuery, email).Scan(&peakHour); err != nil &&
 		err != sql.ErrNoRows {
@@ -253,7 +305,7 @@ func (db *DB) getPeakUsageHour(email string, ctx *models.HistoricalContext) erro
 
 // GetFirstSnapshotTime returns the timestamp of the first recorded snapshot.
 func (db *DB) GetFirstSnapshotTime(email string) (time.Time, error) {
-	query := `SELECT MIN(bucket_time) FROM quota_snapshots_agg WHERE email = ?`
+	query := fmt.Sprintf(`SELECT MIN(bucket_time) FROM %s WHERE email = ?`, historySource(ResolutionDaily))
 	var tStr sql.NullString
 	err := db.QueryRowContext(context.Background(), query, email).Scan(&tStr)
 	if err != nil {
@@ -270,49 +322,60 @@ func (db *DB) GetFirstSnapshotTime(email string) (time.Time, error) {
 // GetRateLimitTransitions counts rate limit transitions (quota going from available to exhausted).
 // A transition is detected when consumption goes from <99% to >=99% (near exhaustion).
 func (db *DB) GetRateLimitTransitions(email string, days int) (*models.RateLimitStats, error) {
+	return db.getRateLimitTransitions(email, days, db.retention.ResolutionFor(days))
+}
+
+func (db *DB) getRateLimitTransitions(email string, days int, res Resolution) (*models.RateLimitStats, error) {
 	stats := &models.RateLimitStats{}
 
-	if err := db.getTransitionsInRange(email, days, stats); err != nil {
+	if err := db.getTransitionsInRange(email, days, res, stats); err != nil {
 		return nil, err
 	}
 
 	db.getAllTimeTransitions(email, stats)
 	db.getRecentTransitions(email, stats)
 
-	if err := db.getTransitionsByDay(email, days, stats); err != nil {
+	if err := db.getTransitionsByDay(email, days, res, stats); err != nil {
 		return nil, err
 	}
 
 	return stats, nil
 }
 
-func (db *DB) getTransitionsInRange(email string, days int, stats *models.RateLimitStats) error {
+// orderedPeaksQuery lists peak consumption next to the previous row's peak.
+// Rollups keep the per-bucket peak, so a transition inside a rolled-up
+// period is still seen, though at most once per period.
+const orderedPeaksQuery = `
+		WITH ordered_snapshots AS (
+			SELECT 
+				bucket_time,
+				session_id,
+				claude_peak,
+				gemini_peak,
+				LAG(claude_peak) OVER (ORDER BY bucket_time) as prev_claude,
+				LAG(gemini_peak) OVER (ORDER BY bucket_time) as prev_gemini
+			FROM %s
+			WHERE email = ? %s
+		)`
+
+// sqlTransitionFilter matches rows where a family crossed into exhaustion.
+const sqlTransitionFilter = `
+		WHERE (claude_peak >= 99 AND COALESCE(prev_claude, 0) < 99)
+		   OR (gemini_peak >= 99 AND COALESCE(prev_gemini, 0) < 99)`
+
+func (db *DB) getTransitionsInRange(email string, days int, res Resolution, stats *models.RateLimitStats) error {
 	timeFilter := ""
 	args := []any{email}
 	if days > 0 {
-		timeFilter = "AND bucket_time >= datetime('now', ?)"
+		timeFilter = sqlTimeFilterClause
 		args = append(args, fmt.Sprintf("-%d days", days))
 	}
 
-	transitionQuery := fmt.Sprintf(`
-		WITH ordered_snapshots AS (
-			SELECT 
-				bucket_time,
-				session_id,
-				claude_consumed,
-				gemini_consumed,
-				LAG(claude_consumed) OVER (ORDER BY bucket_time) as prev_claude,
-				LAG(gemini_consumed) OVER (ORDER BY bucket_time) as prev_gemini
-			FROM quota_snapshots_agg
-			WHERE email = ? %s
-		)
+	transitionQuery := fmt.Sprintf(orderedPeaksQuery, historySource(res), timeFilter) + `
 		SELECT 
 			COUNT(*) as total_hits,
 			MAX(bucket_time) as last_hit
-		FROM ordered_snapshots
-		WHERE (claude_consumed >= 99 AND COALESCE(prev_claude, 0) < 99)
-		   OR (gemini_consumed >= 99 AND COALESCE(prev_gemini, 0) < 99)
-	`, timeFilter)
+		FROM ordered_snapshots` + sqlTransitionFilter
 
 	var lastHitStr sql.NullString
 	err := db.QueryRowContext(context.Background(), transitionQuery, args...).Scan(
@@ -329,97 +392,60 @@ func (db *DB) getTransitionsInRange(email string, days int, stats *models.RateLi
 	return nil
 }
 
-func (db *DB) getAllTimeTransitions(email string, stats *models.RateLimitStats) {
-	allTimeQuery := `
-		WITH ordered_snapshots AS (
-			SELECT 
-				bucket_time,
-				claude_consumed,
-				gemini_consumed,
-				LAG(claude_consumed) OVER (ORDER BY bucket_time) as prev_claude,
-				LAG(gemini_consumed) OVER (ORDER BY bucket_time) as prev_gemini
-			FROM quota_snapshots_agg
-			WHERE email = ?
-		)
+// countTransitions counts transitions in the last days (0 for all time),
+// reading the resolution the retention policy allows for that window.
+func (db *DB) countTransitions(email string, days int) (int, error) {
+	timeFilter := ""
+	args := []any{email}
+	if days > 0 {
+		timeFilter = sqlTimeFilterClause
+		args = append(args, fmt.Sprintf("-%d days", days))
+	}
+
+	query := fmt.Sprintf(orderedPeaksQuery, historySource(db.retention.ResolutionFor(days)), timeFilter) + `
 		SELECT COUNT(*)
-		FROM ordered_snapshots
-		WHERE (claude_consumed >= 99 AND COALESCE(prev_claude, 0) < 99)
-		   OR (gemini_consumed >= 99 AND COALESCE(prev_gemini, 0) < 99)
-	`
-	err := db.QueryRowContext(context.Background(), allTimeQuery, email).Scan(&stats.TotalHits)
-	if err != nil && err != sql.ErrNoRows {
-		stats.TotalHits = 0
+		FROM ordered_snapshots` + sqlTransitionFilter
+
+	var count int
+	err := db.QueryRowContext(context.Background(), query, args...).Scan(&count)
+	return count, err
+}
+
+func (db *DB) getAllTimeTransitions(email string, stats *models.RateLimitStats) {
+	total, err := db.countTransitions(email, 0)
+	if err != nil {
+		total = 0
 	}
+	stats.TotalHits = total
 }
 
 func (db *DB) getRecentTransitions(email string, stats *models.RateLimitStats) {
-	last7Query := `
-		WITH ordered_snapshots AS (
-			SELECT 
-				bucket_time,
-				claude_consumed,
-				gemini_consumed,
-				LAG(claude_consumed) OVER (ORDER BY bucket_time) as prev_claude,
-				LAG(gemini_consumed) OVER (ORDER BY bucket_time) as prev_gemini
-			FROM quota_snapshots_agg
-			WHERE email = ? AND bucket_time >= datetime('now', '-7 days')
-		)
-		SELECT COUNT(*)
-		FROM ordered_snapshots
-		WHERE (claude_consumed >= 99 AND COALESCE(prev_claude, 0) < 99)
-		   OR (gemini_consumed >= 99 AND COALESCE(prev_gemini, 0) < 99)
-	`
-	if err := db.QueryRowContext(context.Background(), last7Query, email).Scan(&stats.HitsLast7Days); err != nil {
-		stats.HitsLast7Days = 0
+	last7, err := db.countTransitions(email, 7)
+	if err != nil {
+		last7 = 0
 	}
+	stats.HitsLast7Days = last7
 
-	last30Query := `
-		WITH ordered_snapshots AS (
-			SELECT 
-				bucket_time,
-				claude_consumed,
-				gemini_consumed,
-				LAG(claude_consumed) OVER (ORDER BY bucket_time) as prev_claude,
-				LAG(gemini_consumed) OVER (ORDER BY bucket_time) as prev_gemini
-			FROM quota_snapshots_agg
-			WHERE email = ? AND bucket_time >= datetime('now', '-30 days')
-		)
-		SELECT COUNT(*)
-		FROM ordered_snapshots
-		WHERE (claude_consumed >= 99 AND COALESCE(prev_claude, 0) < 99)
-		   OR (gemini_consumed >= 99 AND COALESCE(prev_gemini, 0) < 99)
-	`
-	if err := db.QueryRowContext(context.Background(), last30Query, email).Scan(&stats.HitsLast30Days); err != nil {
-		stats.HitsLast30Days = 0
+	last30, err := db.countTransitions(email, 30)
+	if err != nil {
+		last30 = 0
 	}
+	stats.HitsLast30Days = last30
 }
 
-func (db *DB) getTransitionsByDay(email string, days int, stats *models.RateLimitStats) error {
+func (db *DB) getTransitionsByDay(email string, days int, res Resolution, stats *models.RateLimitStats) error {
 	timeFilter := ""
 	args := []any{email}
 	if days > 0 {
-		timeFilter = "AND bucket_time >= datetime('now', ?)"
+		timeFilter = sqlTimeFilterClause
 		args = append(args, fmt.Sprintf("-%d days", days))
 	}
 
-	hitsByDayQuery := fmt.Sprintf(`
-		WITH ordered_snapshots AS (
-			SELECT 
-				date(bucket_time) as hit_date,
-				claude_consumed,
-				gemini_consumed,
-				LAG(claude_consumed) OVER (ORDER BY bucket_time) as prev_claude,
-				LAG(gemini_consumed) OVER (ORDER BY bucket_time) as prev_gemini
-			FROM quota_snapshots_agg
-			WHERE email = ? %s
-		)
-		SELECT hit_date, COUNT(*) as count
-		FROM ordered_snapshots
-		WHERE (claude_consumed >= 99 AND COALESCE(prev_claude, 0) < 99)
-		   OR (gemini_consumed >= 99 AND COALESCE(prev_gemini, 0) < 99)
+	hitsByDayQuery := fmt.Sprintf(orderedPeaksQuery, historySource(res), timeFilter) + `
+		SELECT date(bucket_time) as hit_date, COUNT(*) as count
+		FROM ordered_snapshots` + sqlTransitionFilter + `
 		GROUP BY hit_date
-		ORDER BY hit_date ASC
-	`, timeFilter)
+		ORDER BY hit_date ASC`
 
 	rows, err := db.QueryContext(context.Background(), hitsByDayQuery, args...)
 	if err != nil {
@@ -445,9 +471,13 @@ func (db *DB) getTransitionsByDay(email string, days int, stats *models.RateLimi
 
 // GetSessionExhaustionStats calculates time-to-exhaustion statistics per session.
 func (db *DB) GetSessionExhaustionStats(email string, days int) (*models.ExhaustionStats, error) {
+	return db.getSessionExhaustionStats(email, days, db.retention.ResolutionFor(days))
+}
+
+func (db *DB) getSessionExhaustionStats(email string, days int, res Resolution) (*models.ExhaustionStats, error) {
 	stats := &models.ExhaustionStats{}
 
-	rows, err := db.querySessionStats(email, days)
+	rows, err := db.querySessionStats(email, days, res)
 	if err != nil {
 		return nil, err
 	}
@@ -460,7 +490,7 @@ func (db *DB) GetSessionExhaustionStats(email string, days int) (*models.Exhaust
 	return stats, nil
 }
 
-func (db *DB) querySessionStats(email string, days int) (*sql.Rows, error) {
+func (db *DB) querySessionStats(email string, days int, res Resolution) (*sql.Rows, error) {
 	timeFilter := ""
 	args := []any{email}
 	if days > 0 {
@@ -469,27 +499,32 @@ func (db *DB) querySessionStats(email string, days int) (*sql.Rows, error) {
 	}
 
 	sessionQuery := fmt.Sprintf(`
+		WITH ranked AS (
+			SELECT
+				session_id,
+				bucket_time,
+				claude_consumed + gemini_consumed as consumed,
+				total_peak,
+				bucket_count,
+				ROW_NUMBER() OVER (PARTITION BY session_id ORDER BY bucket_time ASC) as rn_first,
+				ROW_NUMBER() OVER (PARTITION BY session_id ORDER BY bucket_time DESC) as rn_last
+			FROM %s
+			WHERE email = ? AND session_id IS NOT NULL AND session_id != '' %s
+		)
 		SELECT 
 			session_id,
 			MIN(bucket_time) as start_time,
 			MAX(bucket_time) as end_time,
 			-- First consumption value
-			(SELECT claude_consumed + gemini_consumed 
-			 FROM quota_snapshots_agg q2 
-			 WHERE q2.session_id = q1.session_id AND q2.email = q1.email
-			 ORDER BY bucket_time ASC LIMIT 1) as start_consumed,
-			-- Last consumption value  
-			(SELECT claude_consumed + gemini_consumed 
-			 FROM quota_snapshots_agg q2 
-			 WHERE q2.session_id = q1.session_id AND q2.email = q1.email
-			 ORDER BY bucket_time DESC LIMIT 1) as end_consumed,
-			MAX(claude_consumed + gemini_consumed) as peak_consumed,
-			COUNT(*) as data_points
-		FROM quota_snapshots_agg q1
-		WHERE email = ? AND session_id IS NOT NULL AND session_id != '' %s
+			MAX(CASE WHEN rn_first = 1 THEN consumed END) as start_consumed,
+			-- Last consumption value
+			MAX(CASE WHEN rn_last = 1 THEN consumed END) as end_consumed,
+			MAX(total_peak) as peak_consumed,
+			SUM(bucket_count) as data_points
+		FROM ranked
 		GROUP BY session_id
-		HAVING COUNT(*) > 1
-	`, timeFilter)
+		HAVING SUM(bucket_count) > 1
+	`, historySource(res), timeFilter)
 
 	rows, err := db.QueryContext(context.Background(), sessionQuery, args...)
 	if err != nil {
@@ -587,6 +622,10 @@ func (db *DB) calculateExhaustionStats(
 
 // GetDailyUsageTrend returns daily consumption data for charts.
 func (db *DB) GetDailyUsageTrend(email string, days int) ([]models.DailyUsagePoint, error) {
+	return db.getDailyUsageTrend(email, days, db.retention.ResolutionFor(days))
+}
+
+func (db *DB) getDailyUsageTrend(email string, days int, res Resolution) ([]models.DailyUsagePoint, error) {
 	// Build time filter
 	timeFilter := ""
 	args := []any{email}
@@ -602,12 +641,12 @@ func (db *DB) GetDailyUsageTrend(email string, days int) ([]models.DailyUsagePoi
 			SUM(gemini_consumed) as gemini_total,
 			SUM(claude_consumed + gemini_consumed) as total_consumed,
 			COUNT(DISTINCT session_id) as session_count,
-			COUNT(*) as data_points
-		FROM quota_snapshots_agg
+			SUM(bucket_count) as data_points
+		FROM %s
 		WHERE email = ? %s
 		GROUP BY day
 		ORDER BY day ASC
-	`, timeFilter)
+	`, historySource(res), timeFilter)
 
 	rows, err := db.QueryContext(context.Background(), query, args...)
 	if err != nil {
@@ -644,6 +683,10 @@ func (db *DB) GetDailyUsageTrend(email string, days int) ([]models.DailyUsagePoi
 
 // GetHourlyPatterns returns usage patterns by hour of day.
 func (db *DB) GetHourlyPatterns(email string, days int) ([]models.HourlyPattern, error) {
+	return db.getHourlyPatterns(email, days, patternResolution(db.retention.ResolutionFor(days)))
+}
+
+func (db *DB) getHourlyPatterns(email string, days int, res Resolution) ([]models.HourlyPattern, error) {
 	// Build time filter
 	timeFilter := ""
 	args := []any{email}
@@ -655,13 +698,13 @@ func (db *DB) GetHourlyPatterns(email string, days int) ([]models.HourlyPattern,
 	query := fmt.Sprintf(`
 		SELECT 
 			hour,
-			AVG(claude_consumed + gemini_consumed) as avg_consumed,
-			COUNT(*) as occurrences
-		FROM quota_snapshots_agg
+			%s as avg_consumed,
+			SUM(bucket_count) as occurrences
+		FROM %s
 		WHERE email = ? %s
 		GROUP BY hour
 		ORDER BY hour ASC
-	`, timeFilter)
+	`, sqlAvgPerBucket, historySource(res), timeFilter)
 
 	rows, err := db.QueryContext(context.Background(), query, args...)
 	if err != nil {
@@ -695,6 +738,10 @@ func (db *DB) GetHourlyPatterns(email string, days int) ([]models.HourlyPattern,
 
 // GetWeekdayPatterns returns usage patterns by day of week.
 func (db *DB) GetWeekdayPatterns(email string, days int) ([]models.WeekdayPattern, error) {
+	return db.getWeekdayPatterns(email, days, db.retention.ResolutionFor(days))
+}
+
+func (db *DB) getWeekdayPatterns(email string, days int, res Resolution) ([]models.WeekdayPattern, error) {
 	dayNames := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
 
 	// Build time filter
@@ -708,13 +755,13 @@ func (db *DB) GetWeekdayPatterns(email string, days int) ([]models.WeekdayPatter
 	query := fmt.Sprintf(`
 		SELECT 
 			day_of_week,
-			AVG(claude_consumed + gemini_consumed) as avg_consumed,
-			COUNT(*) as occurrences
-		FROM quota_snapshots_agg
+			%s as avg_consumed,
+			SUM(bucket_count) as occurrences
+		FROM %s
 		WHERE email = ? %s
 		GROUP BY day_of_week
 		ORDER BY day_of_week ASC
-	`, timeFilter)
+	`, sqlAvgPerBucket, historySource(res), timeFilter)
 
 	rows, err := db.QueryContext(context.Background(), query, args...)
 	if err != nil {
@@ -750,45 +797,49 @@ func (db *DB) GetWeekdayPatterns(email string, days int) ([]models.WeekdayPatter
 }
 
 // GetAccountHistoryStats retrieves all history statistics for an account.
+// Short ranges read 5-minute buckets, longer ones the hourly or daily
+// rollups, as chosen by the retention policy.
 func (db *DB) GetAccountHistoryStats(email string, timeRange models.TimeRange) (*models.AccountHistoryStats, error) {
 	days := timeRange.Days()
+	res := db.retention.ResolutionFor(days)
 
 	stats := &models.AccountHistoryStats{
 		Email:       email,
 		TimeRange:   timeRange,
+		Resolution:  res.String(),
 		LastUpdated: time.Now(),
 	}
 
 	// Get rate limit stats
-	rateLimits, err := db.GetRateLimitTransitions(email, days)
+	rateLimits, err := db.getRateLimitTransitions(email, days, res)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rate limit stats: %w", err)
 	}
 	stats.RateLimits = rateLimits
 
 	// Get exhaustion stats
-	exhaustion, err := db.GetSessionExhaustionStats(email, days)
+	exhaustion, err := db.getSessionExhaustionStats(email, days, res)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get exhaustion stats: %w", err)
 	}
 	stats.Exhaustion = exhaustion
 
 	// Get daily usage trend
-	dailyUsage, err := db.GetDailyUsageTrend(email, days)
+	dailyUsage, err := db.getDailyUsageTrend(email, days, res)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily usage: %w", err)
 	}
 	stats.DailyUsage = dailyUsage
 
 	// Get hourly patterns
-	hourlyPatterns, err := db.GetHourlyPatterns(email, days)
+	hourlyPatterns, err := db.getHourlyPatterns(email, days, patternResolution(res))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get hourly patterns: %w", err)
 	}
 	stats.HourlyPatterns = hourlyPatterns
 
 	// Get weekday patterns
-	weekdayPatterns, err := db.GetWeekdayPatterns(email, days)
+	weekdayPatterns, err := db.getWeekdayPatterns(email, days, res)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get weekday patterns: %w", err)
 	}
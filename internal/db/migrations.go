@@ -2,31 +2,331 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"os"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
 )
 
+// ErrSchemaTooNew is returned when the database was written by a newer
+// release than this binary understands.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version supports")
+
+// Migration is a single, ordered schema change. Up runs inside a transaction
+// together with the schema_version bookkeeping, so a failed migration leaves
+// the database untouched.
+type Migration struct {
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Name    string
+	Version int
+}
+
+// MigrationStatus describes whether a migration has been applied.
+type MigrationStatus struct {
+	AppliedAt time.Time
+	Name      string
+	Version   int
+	Applied   bool
+}
+
+// migrations is the ordered registry of schema changes. Versions must be
+// strictly increasing; never edit or reorder a released migration, append a
+// new one instead.
+var migrations = []Migration{
+	{Version: 1, Name: "initial schema", Up: createInitialSchema},
+	{Version: 2, Name: "fix legacy time formats", Up: fixLegacyTimeFormats},
+	{Version: 3, Name: "hourly and daily rollups", Up: createRollupTables},
+	{Version: 4, Name: "per-model account status", Up: addAccountStatusModelQuotas},
+	{Version: 5, Name: "alert state", Up: createAlertStateTable},
+	{Version: 6, Name: "notification queue", Up: createNotificationQueueTable},
+	{Version: 7, Name: "revoked refresh tokens", Up: addAccountStatusRevokedToken},
+}
+
+// legacyTimeFormatQueries normalise timestamps written as Go time strings.
+var legacyTimeFormatQueries = []string{
+	// Fix quota_snapshots_agg bucket_time (truncate " +0000 UTC")
+	`UPDATE quota_snapshots_agg
+	 SET bucket_time = SUBSTR(bucket_time, 1, 19)
+	 WHERE length(bucket_time) > 19 AND bucket_time LIKE '% UTC'`,
+
+	// Fix quota_snapshots timestamp
+	`UPDATE quota_snapshots
+	 SET timestamp = SUBSTR(timestamp, 1, 19)
+	 WHERE length(timestamp) > 19 AND timestamp LIKE '% UTC'`,
+
+	// Fix api_calls timestamp
+	`UPDATE api_calls
+	 SET timestamp = SUBSTR(timestamp, 1, 19)
+	 WHERE length(timestamp) > 19 AND timestamp LIKE '% UTC'`,
+}
+
+// LatestSchemaVersion returns the highest schema version this binary knows.
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// createSchemaVersionTable creates the bookkeeping table if needed.
+func (db *DB) createSchemaVersionTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	_, err := db.ExecContext(context.Background(), query)
+	return err
+}
+
+// hasSchemaVersionTable reports whether the bookkeeping table exists.
+func (db *DB) hasSchemaVersionTable() (bool, error) {
+	var n int
+	err := db.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'",
+	).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect database: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SchemaVersion returns the version of the most recently applied migration,
+// or 0 for a database that predates versioning. It never writes, so it is
+// safe to use for status reporting.
+func (db *DB) SchemaVersion() (int, error) {
+	exists, err := db.hasSchemaVersionTable()
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version int
+	err = db.QueryRowContext(context.Background(),
+		"SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// checkSchemaVersion fails with ErrSchemaTooNew for databases written by a
+// newer release.
+func (db *DB) checkSchemaVersion() error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if latest := LatestSchemaVersion(); version > latest {
+		return fmt.Errorf("%w: database is at version %d, this build supports up to %d",
+			ErrSchemaTooNew, version, latest)
+	}
+	return nil
+}
+
+// MigrationStatuses returns every known migration with its applied state.
+func (db *DB) MigrationStatuses() ([]MigrationStatus, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// appliedMigrations maps applied versions to when they were applied. A
+// database without the bookkeeping table has none.
+func (db *DB) appliedMigrations() (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+
+	exists, err := db.hasSchemaVersionTable()
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	rows, err := db.QueryContext(context.Background(), "SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema versions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var version int
+		var appliedAt sql.NullString
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema version: %w", err)
+		}
+		t, _ := parseTimeString(appliedAt.String)
+		applied[version] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// PendingMigrations returns the migrations that have not been applied yet.
+func (db *DB) PendingMigrations() ([]Migration, error) {
+	if err := db.checkSchemaVersion(); err != nil {
+		return nil, err
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies all pending migrations in order, each in its own
+// transaction. Existing databases are backed up once before the first
+// migration is applied. Returns the migrations that were applied.
+//
+// Each migration begins IMMEDIATE and re-reads the schema version inside
+// the transaction, so when two processes migrate at once the second waits
+// for the first and skips whatever it already applied.
+func (db *DB) Migrate() ([]Migration, error) {
+	if err := db.createSchemaVersionTable(); err != nil {
+		return nil, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	if err := db.backupBeforeMigrate(); err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, m := range pending {
+		ok, err := db.applyMigration(m)
+		if err != nil {
+			return applied, err
+		}
+		if !ok {
+			continue
+		}
+		logger.Debug("applied schema migration", "version", m.Version, "name", m.Name)
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// applyMigration runs m unless another connection applied it first, and
+// reports whether it did.
+func (db *DB) applyMigration(m Migration) (bool, error) {
+	ctx := context.Background()
+
+	// The DSN sets _txlock=immediate, so this takes the write lock up front.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var version int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version); err != nil {
+		return false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version >= m.Version {
+		return false, nil
+	}
+
+	if err := m.Up(ctx, tx); err != nil {
+		return false, fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now().UTC().Format("2006-01-02 15:04:05"),
+	); err != nil {
+		return false, fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+	return true, nil
+}
+
+// backupBeforeMigrate copies a database that already holds data to
+// "<path>.bak-v<version>" so an upgrade can always be rolled back by hand.
+// Fresh databases are not backed up.
+func (db *DB) backupBeforeMigrate() error {
+	if db.path == "" || db.path == ":memory:" {
+		return nil
+	}
+
+	var tables int
+	err := db.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_version', 'sqlite_sequence')",
+	).Scan(&tables)
+	if err != nil {
+		return fmt.Errorf("failed to inspect database: %w", err)
+	}
+	if tables == 0 {
+		return nil
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	backupPath := fmt.Sprintf("%s.bak-v%d", db.path, version)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+
+	if _, err := db.ExecContext(context.Background(), "VACUUM INTO ?", backupPath); err != nil {
+		return fmt.Errorf("failed to back up database before migrating: %w", err)
+	}
+	logger.Info("backed up database before migrating", "path", backupPath)
+	return nil
+}
+
+// fixLegacyTimeFormats is the migration form of FixLegacyTimeFormats.
+func fixLegacyTimeFormats(ctx context.Context, tx *sql.Tx) error {
+	for _, query := range legacyTimeFormatQueries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to fix legacy time formats: %w", err)
+		}
+	}
+	return nil
+}
+
 // FixLegacyTimeFormats fixes timestamp formats in the database.
 // This is required because modernc.org/sqlite does not store time.Time in a format
 // compatible with SQLite's date/time functions by default.
 func (db *DB) FixLegacyTimeFormats() error {
-	queries := []string{
-		// Fix quota_snapshots_agg bucket_time (truncate " +0000 UTC")
-		`UPDATE quota_snapshots_agg 
-		 SET bucket_time = SUBSTR(bucket_time, 1, 19) 
-		 WHERE length(bucket_time) > 19 AND bucket_time LIKE '% UTC'`,
-		
-		// Fix quota_snapshots timestamp
-		`UPDATE quota_snapshots 
-		 SET timestamp = SUBSTR(timestamp, 1, 19) 
-		 WHERE length(timestamp) > 19 AND timestamp LIKE '% UTC'`,
-		
-		// Fix api_calls timestamp
-		`UPDATE api_calls 
-		 SET timestamp = SUBSTR(timestamp, 1, 19) 
-		 WHERE length(timestamp) > 19 AND timestamp LIKE '% UTC'`,
-	}
-
-	for _, query := range queries {
+	for _, query := range legacyTimeFormatQueries {
 		if _, err := db.ExecContext(context.Background(), query); err != nil {
 			return fmt.Errorf("failed to fix legacy time formats: %w", err)
 		}
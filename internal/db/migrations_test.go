@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestNew_RecordsSchemaVersion(t *testing.T) {
+	db := newTestDB(t)
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("Expected version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		t.Fatalf("PendingMigrations failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending migrations, got %d", len(pending))
+	}
+}
+
+func TestMigrate_Idempotent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	_ = db.Close()
+
+	db, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations on re-run, got %d", len(applied))
+	}
+
+	var rows int
+	if err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM schema_version").Scan(&rows); err != nil {
+		t.Fatalf("Failed to count schema_version rows: %v", err)
+	}
+	if rows != len(migrations) {
+		t.Errorf("Expected %d schema_version rows, got %d", len(migrations), rows)
+	}
+}
+
+func TestOpen_RefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	_, err = db.ExecContext(context.Background(),
+		"INSERT INTO schema_version (version, name) VALUES (?, ?)", LatestSchemaVersion()+1, "future")
+	if err != nil {
+		t.Fatalf("Failed to insert future version: %v", err)
+	}
+	_ = db.Close()
+
+	if _, err := Open(dbPath); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Open: expected ErrSchemaTooNew, got %v", err)
+	}
+	if _, err := New(dbPath); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("New: expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestOpen_DoesNotMigrate(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		t.Fatalf("PendingMigrations failed: %v", err)
+	}
+	if len(pending) != len(migrations) {
+		t.Errorf("Expected %d pending migrations, got %d", len(migrations), len(pending))
+	}
+
+	statuses, err := db.MigrationStatuses()
+	if err != nil {
+		t.Fatalf("MigrationStatuses failed: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Applied {
+			t.Errorf("Migration %d should not be applied", s.Version)
+		}
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != 0 {
+		t.Errorf("Expected version 0, got %d", version)
+	}
+
+	exists, err := db.hasSchemaVersionTable()
+	if err != nil {
+		t.Fatalf("hasSchemaVersionTable failed: %v", err)
+	}
+	if exists {
+		t.Error("Status queries should not create the schema_version table")
+	}
+}
+
+func TestMigrate_Concurrent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	handles := make([]*DB, 2)
+	for i := range handles {
+		db, err := Open(dbPath)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		defer db.Close()
+		handles[i] = db
+	}
+
+	var wg sync.WaitGroup
+	applied := make([][]Migration, len(handles))
+	errs := make([]error, len(handles))
+	for i, db := range handles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied[i], errs[i] = db.Migrate()
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range handles {
+		if errs[i] != nil {
+			t.Fatalf("Migrate %d failed: %v", i, errs[i])
+		}
+		total += len(applied[i])
+	}
+	if total != len(migrations) {
+		t.Errorf("Expected %d migrations applied in total, got %d", len(migrations), total)
+	}
+
+	var rows int
+	if err := handles[0].QueryRowContext(context.Background(), "SELECT COUNT(*) FROM schema_version").Scan(&rows); err != nil {
+		t.Fatalf("Failed to count schema_version rows: %v", err)
+	}
+	if rows != len(migrations) {
+		t.Errorf("Expected %d schema_version rows, got %d", len(migrations), rows)
+	}
+}
+
+func TestMigrate_AdoptsLegacyDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Simulate a database created before schema versioning
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := createInitialSchema(ctx, tx); err != nil {
+		t.Fatalf("createInitialSchema failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO api_calls (timestamp, email, model, provider) VALUES (?, ?, ?, ?)",
+		"2024-01-01 12:00:00 +0000 UTC", "legacy@example.com", "claude", "anthropic")
+	if err != nil {
+		t.Fatalf("Failed to insert legacy row: %v", err)
+	}
+	_ = db.Close()
+
+	db, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+	defer db.Close()
+
+	var ts string
+	if err := db.QueryRowContext(ctx, "SELECT CAST(timestamp AS TEXT) FROM api_calls").Scan(&ts); err != nil {
+		t.Fatalf("Failed to read legacy row: %v", err)
+	}
+	if ts != "2024-01-01 12:00:00" {
+		t.Errorf("Expected legacy timestamp to be fixed, got %q", ts)
+	}
+
+	if _, err := os.Stat(dbPath + ".bak-v0"); err != nil {
+		t.Errorf("Expected backup before migrating: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// EnqueueNotification stores a notification for a later delivery attempt.
+func (db *DB) EnqueueNotification(n *models.QueuedNotification) error {
+	createdAt := n.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	nextAttempt := n.NextAttempt
+	if nextAttempt.IsZero() {
+		nextAttempt = createdAt
+	}
+
+	query := `
+		INSERT INTO notification_queue (sink, payload, attempts, last_error, next_attempt, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.ExecContext(context.Background(), query,
+		n.Sink, n.Payload, n.Attempts, n.LastError,
+		nextAttempt.UTC().Format("2006-01-02 15:04:05"),
+		createdAt.UTC().Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		n.ID = id
+	}
+	return nil
+}
+
+// GetDueNotifications returns up to limit queued notifications whose next
+// attempt is at or before now, oldest first.
+func (db *DB) GetDueNotifications(now time.Time, limit int) ([]models.QueuedNotification, error) {
+	query := `
+		SELECT id, sink, payload, attempts, last_error, next_attempt, created_at
+		FROM notification_queue
+		WHERE next_attempt <= ?
+		ORDER BY created_at, id
+		LIMIT ?
+	`
+	rows, err := db.QueryContext(context.Background(), query, now.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification queue: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var queued []models.QueuedNotification
+	for rows.Next() {
+		var n models.QueuedNotification
+		var nextAttempt, createdAt string
+		if err := rows.Scan(&n.ID, &n.Sink, &n.Payload, &n.Attempts, &n.LastError,
+			&nextAttempt, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan queued notification: %w", err)
+		}
+		if t, ok := parseTimeString(nextAttempt); ok {
+			n.NextAttempt = t
+		}
+		if t, ok := parseTimeString(createdAt); ok {
+			n.CreatedAt = t
+		}
+		queued = append(queued, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read notification queue: %w", err)
+	}
+	return queued, nil
+}
+
+// CountQueuedNotifications returns the number of notifications waiting for
+// delivery.
+func (db *DB) CountQueuedNotifications() (int, error) {
+	var count int
+	err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM notification_queue").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count queued notifications: %w", err)
+	}
+	return count, nil
+}
+
+// RescheduleNotification records a failed attempt of a queued notification.
+func (db *DB) RescheduleNotification(id int64, attempts int, nextAttempt time.Time, lastError string) error {
+	query := `
+		UPDATE notification_queue
+		SET attempts = ?, next_attempt = ?, last_error = ?
+		WHERE id = ?
+	`
+	_, err := db.ExecContext(context.Background(), query,
+		attempts, nextAttempt.UTC().Format("2006-01-02 15:04:05"), lastError, id)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule notification: %w", err)
+	}
+	return nil
+}
+
+// DeleteQueuedNotification removes a notification from the queue after it
+// was delivered or given up on.
+func (db *DB) DeleteQueuedNotification(id int64) error {
+	_, err := db.ExecContext(context.Background(), "DELETE FROM notification_queue WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete queued notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestNotificationQueue(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2025, 6, 10, 15, 0, 0, 0, time.UTC)
+	due := &models.QueuedNotification{Sink: "team", Payload: []byte(`{"text":"low"}`), CreatedAt: now}
+	later := &models.QueuedNotification{Sink: "team", Payload: []byte(`{}`), CreatedAt: now, NextAttempt: now.Add(time.Hour)}
+	for _, n := range []*models.QueuedNotification{due, later} {
+		if err := db.EnqueueNotification(n); err != nil {
+			t.Fatalf("EnqueueNotification failed: %v", err)
+		}
+	}
+	if due.ID == 0 {
+		t.Error("EnqueueNotification did not set the ID")
+	}
+
+	queued, err := db.GetDueNotifications(now, 10)
+	if err != nil {
+		t.Fatalf("GetDueNotifications failed: %v", err)
+	}
+	if len(queued) != 1 || queued[0].ID != due.ID || string(queued[0].Payload) != `{"text":"low"}` {
+		t.Fatalf("Expected only the due notification, got %+v", queued)
+	}
+
+	if err := db.RescheduleNotification(due.ID, 1, now.Add(2*time.Hour), "timeout"); err != nil {
+		t.Fatalf("RescheduleNotification failed: %v", err)
+	}
+	queued, err = db.GetDueNotifications(now.Add(90*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("GetDueNotifications failed: %v", err)
+	}
+	if len(queued) != 1 || queued[0].ID != later.ID {
+		t.Fatalf("Expected the rescheduled notification to wait, got %+v", queued)
+	}
+
+	if err := db.DeleteQueuedNotification(later.ID); err != nil {
+		t.Fatalf("DeleteQueuedNotification failed: %v", err)
+	}
+	count, err := db.CountQueuedNotifications()
+	if err != nil {
+		t.Fatalf("CountQueuedNotifications failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 queued notification, got %d", count)
+	}
+}
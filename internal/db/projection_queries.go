@@ -178,3 +178,93 @@ func (db *DB) CleanupOldRawSnapshots(olderThanDays int) (int64, error) {
 
 	return result.RowsAffected()
 }
+
+// UpsertModelSnapshot inserts or updates a per-model aggregated snapshot.
+func (db *DB) UpsertModelSnapshot(snapshot *models.ModelSnapshot) error {
+	query := `
+		INSERT INTO quota_snapshots_model_agg (
+			email, bucket_time, model_id, display_name, model_family,
+			quota_avg, consumed, sample_count, session_id, tier
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(email, bucket_time, model_id) DO UPDATE SET
+			quota_avg = (quota_snapshots_model_agg.quota_avg * quota_snapshots_model_agg.sample_count +
+				excluded.quota_avg) / (quota_snapshots_model_agg.sample_count + 1),
+			consumed = quota_snapshots_model_agg.consumed + excluded.consumed,
+			sample_count = quota_snapshots_model_agg.sample_count + 1,
+			display_name = COALESCE(excluded.display_name, quota_snapshots_model_agg.display_name),
+			session_id = COALESCE(excluded.session_id, quota_snapshots_model_agg.session_id),
+			tier = COALESCE(excluded.tier, quota_snapshots_model_agg.tier)
+	`
+
+	result, err := db.ExecContext(context.Background(), query,
+		snapshot.Email,
+		snapshot.BucketTime.Format("2006-01-02 15:04:05"),
+		snapshot.ModelID,
+		nullString(snapshot.DisplayName),
+		snapshot.ModelFamily,
+		snapshot.QuotaAvg,
+		snapshot.Consumed,
+		snapshot.SampleCount,
+		snapshot.SessionID,
+		snapshot.Tier,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert model snapshot: %w", err)
+	}
+
+	if snapshot.ID == 0 {
+		id, err := result.LastInsertId()
+		if err == nil {
+			snapshot.ID = id
+		}
+	}
+
+	return nil
+}
+
+// GetModelConsumptionRates calculates consumption rates for a single model.
+func (db *DB) GetModelConsumptionRates(email, modelID, sessionID string) (*models.ModelConsumptionRates, error) {
+	rates := &models.ModelConsumptionRates{Email: email, ModelID: modelID}
+
+	sessionQuery := `
+		SELECT
+			COALESCE(AVG(consumed) * 12, 0) as rate,
+			COUNT(*) as data_points,
+			MIN(bucket_time) as session_start
+		FROM quota_snapshots_model_agg
+		WHERE email = ? AND model_id = ? AND session_id = ?
+	`
+
+	var sessionStartStr sql.NullString
+	err := db.QueryRowContext(context.Background(), sessionQuery, email, modelID, sessionID).Scan(
+		&rates.SessionRate,
+		&rates.SessionDataPoints,
+		&sessionStartStr,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query model session rates: %w", err)
+	}
+	if sessionStartStr.Valid && sessionStartStr.String != "" {
+		if t, ok := parseTimeString(sessionStartStr.String); ok {
+			rates.SessionStart = t
+		}
+	}
+
+	historicalQuery := `
+		SELECT
+			COALESCE(AVG(consumed) * 12, 0) as rate,
+			COUNT(DISTINCT session_id) as sessions
+		FROM quota_snapshots_model_agg
+		WHERE email = ? AND model_id = ? AND session_id != ?
+	`
+
+	err = db.QueryRowContext(context.Background(), historicalQuery, email, modelID, sessionID).Scan(
+		&rates.HistoricalRate,
+		&rates.HistoricalSessions,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query model historical rates: %w", err)
+	}
+
+	return rates, nil
+}
@@ -268,3 +268,93 @@ func TestUpsertAggregatedSnapshot_NilSessionID(t *testing.T) {
 		t.Errorf("Expected empty session ID, got %s", retrieved.SessionID)
 	}
 }
+
+func TestUpsertModelSnapshot_Updates(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	bucket := time.Now().UTC().Truncate(5 * time.Minute)
+	for _, pct := range []float64{80, 60} {
+		snapshot := &models.ModelSnapshot{
+			Email:       "test@example.com",
+			BucketTime:  bucket,
+			ModelID:     "claude-sonnet-4-5",
+			DisplayName: "Claude Sonnet 4.5",
+			ModelFamily: "claude",
+			QuotaAvg:    pct,
+			Consumed:    1.0,
+			SampleCount: 1,
+			SessionID:   "ses_test",
+			Tier:        "PRO",
+		}
+		if err := db.UpsertModelSnapshot(snapshot); err != nil {
+			t.Fatalf("Failed to upsert model snapshot: %v", err)
+		}
+	}
+
+	var count, samples int
+	var avg, consumed float64
+	err := db.QueryRowContext(context.Background(),
+		`SELECT COUNT(*), MAX(sample_count), MAX(quota_avg), MAX(consumed)
+		 FROM quota_snapshots_model_agg WHERE model_id = ?`, "claude-sonnet-4-5",
+	).Scan(&count, &samples, &avg, &consumed)
+	if err != nil {
+		t.Fatalf("Failed to query model snapshots: %v", err)
+	}
+	if count != 1 || samples != 2 {
+		t.Errorf("Expected 1 row with 2 samples, got %d rows, %d samples", count, samples)
+	}
+	if avg != 70 {
+		t.Errorf("Expected running average 70, got %f", avg)
+	}
+	if consumed != 2 {
+		t.Errorf("Expected consumed 2, got %f", consumed)
+	}
+}
+
+func TestGetModelConsumptionRates(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC()
+	for i := range 6 {
+		for _, model := range []string{"claude-opus", "claude-sonnet"} {
+			consumed := 1.0
+			if model == "claude-sonnet" {
+				consumed = 0.5
+			}
+			snapshot := &models.ModelSnapshot{
+				Email:       "test@example.com",
+				BucketTime:  now.Add(time.Duration(-i*5) * time.Minute).Truncate(5 * time.Minute),
+				ModelID:     model,
+				ModelFamily: "claude",
+				QuotaAvg:    float64(100 - i),
+				Consumed:    consumed,
+				SampleCount: 1,
+				SessionID:   "ses_test",
+			}
+			if err := db.UpsertModelSnapshot(snapshot); err != nil {
+				t.Fatalf("Failed to insert model snapshot: %v", err)
+			}
+		}
+	}
+
+	rates, err := db.GetModelConsumptionRates("test@example.com", "claude-opus", "ses_test")
+	if err != nil {
+		t.Fatalf("Failed to get model rates: %v", err)
+	}
+	if rates.SessionRate != 12 {
+		t.Errorf("Expected opus rate 12, got %f", rates.SessionRate)
+	}
+	if rates.SessionDataPoints != 6 {
+		t.Errorf("Expected 6 data points, got %d", rates.SessionDataPoints)
+	}
+
+	rates, err = db.GetModelConsumptionRates("test@example.com", "claude-sonnet", "ses_test")
+	if err != nil {
+		t.Fatalf("Failed to get model rates: %v", err)
+	}
+	if rates.SessionRate != 6 {
+		t.Errorf("Expected sonnet rate 6, got %f", rates.SessionRate)
+	}
+}
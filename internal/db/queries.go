@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -200,24 +201,13 @@ func (db *DB) GetTotalStats() (*models.TotalStats, error) {
 func (db *DB) GetAccountStatus(email string) (*models.AccountStatus, error) {
 	query := `
 		SELECT email, claude_quota, gemini_quota, total_quota, tier,
-			   is_rate_limited, last_error, last_updated, claude_reset_sec, gemini_reset_sec
+			   is_rate_limited, last_error, last_updated, claude_reset_sec, gemini_reset_sec,
+			   model_quotas, revoked_token
 		FROM account_status
 		WHERE email = ?
 	`
 
-	var status models.AccountStatus
-	err := db.QueryRowContext(context.Background(), query, email).Scan(
-		&status.Email,
-		&status.ClaudeQuota,
-		&status.GeminiQuota,
-		&status.TotalQuota,
-		&status.Tier,
-		&status.IsRateLimited,
-		&status.LastError,
-		&status.LastUpdated,
-		&status.ClaudeResetSec,
-		&status.GeminiResetSec,
-	)
+	status, err := scanAccountStatus(db.QueryRowContext(context.Background(), query, email))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -225,14 +215,15 @@ func (db *DB) GetAccountStatus(email string) (*models.AccountStatus, error) {
 		return nil, fmt.Errorf("failed to get account status: %w", err)
 	}
 
-	return &status, nil
+	return status, nil
 }
 
 // GetAllAccountStatuses retrieves all account statuses.
 func (db *DB) GetAllAccountStatuses() ([]models.AccountStatus, error) {
 	query := `
 		SELECT email, claude_quota, gemini_quota, total_quota, tier,
-			   is_rate_limited, last_error, last_updated, claude_reset_sec, gemini_reset_sec
+			   is_rate_limited, last_error, last_updated, claude_reset_sec, gemini_reset_sec,
+			   model_quotas, revoked_token
 		FROM account_status
 		ORDER BY total_quota DESC
 	`
@@ -249,28 +240,158 @@ func (db *DB) GetAllAccountStatuses() ([]models.AccountStatus, error) {
 
 	var statuses []models.AccountStatus
 	for rows.Next() {
-		var status models.AccountStatus
-		err := rows.Scan(
-			&status.Email,
-			&status.ClaudeQuota,
-			&status.GeminiQuota,
-			&status.TotalQuota,
-			&status.Tier,
-			&status.IsRateLimited,
-			&status.LastError,
-			&status.LastUpdated,
-			&status.ClaudeResetSec,
-			&status.GeminiResetSec,
-		)
+		status, err := scanAccountStatus(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan account status: %w", err)
 		}
-		statuses = append(statuses, status)
+		statuses = append(statuses, *status)
 	}
 
 	return statuses, rows.Err()
 }
 
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanAccountStatus scans one account_status row selected in column order
+// by GetAccountStatus.
+func scanAccountStatus(row rowScanner) (*models.AccountStatus, error) {
+	var status models.AccountStatus
+	var modelQuotas sql.NullString
+	err := row.Scan(
+		&status.Email,
+		&status.ClaudeQuota,
+		&status.GeminiQuota,
+		&status.TotalQuota,
+		&status.Tier,
+		&status.IsRateLimited,
+		&status.LastError,
+		&status.LastUpdated,
+		&status.ClaudeResetSec,
+		&status.GeminiResetSec,
+		&modelQuotas,
+		&status.RevokedToken,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if modelQuotas.Valid && modelQuotas.String != "" {
+		if err := json.Unmarshal([]byte(modelQuotas.String), &status.ModelQuotas); err != nil {
+			// Fall back to the family rollups.
+			logger.Debug("ignoring malformed model quotas", "email", status.Email, "error", err)
+			status.ModelQuotas = nil
+		}
+	}
+
+	return &status, nil
+}
+
+// UpsertAccountStatus inserts or replaces the current status for an account.
+func (db *DB) UpsertAccountStatus(status *models.AccountStatus) error {
+	query := `
+		INSERT INTO account_status (
+			email, claude_quota, gemini_quota, total_quota, tier, is_rate_limited,
+			last_error, last_updated, claude_reset_sec, gemini_reset_sec, model_quotas,
+			revoked_token
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(email) DO UPDATE SET
+			claude_quota = excluded.claude_quota,
+			gemini_quota = excluded.gemini_quota,
+			total_quota = excluded.total_quota,
+			tier = excluded.tier,
+			is_rate_limited = excluded.is_rate_limited,
+			last_error = excluded.last_error,
+			last_updated = excluded.last_updated,
+			claude_reset_sec = excluded.claude_reset_sec,
+			gemini_reset_sec = excluded.gemini_reset_sec,
+			model_quotas = excluded.model_quotas,
+			revoked_token = excluded.revoked_token
+	`
+
+	lastUpdated := status.LastUpdated
+	if lastUpdated.IsZero() {
+		lastUpdated = time.Now()
+	}
+
+	var modelQuotas sql.NullString
+	if len(status.ModelQuotas) > 0 {
+		encoded, err := json.Marshal(status.ModelQuotas)
+		if err != nil {
+			return fmt.Errorf("failed to encode model quotas: %w", err)
+		}
+		modelQuotas = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	_, err := db.ExecContext(context.Background(), query,
+		status.Email,
+		status.ClaudeQuota,
+		status.GeminiQuota,
+		status.TotalQuota,
+		status.Tier,
+		status.IsRateLimited,
+		status.LastError,
+		lastUpdated.UTC().Format("2006-01-02 15:04:05"),
+		status.ClaudeResetSec,
+		status.GeminiResetSec,
+		modelQuotas,
+		status.RevokedToken,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert account status: %w", err)
+	}
+	return nil
+}
+
+// UpdateAccountStatusError records the last refresh error for an account
+// without touching its last-known quota values.
+func (db *DB) UpdateAccountStatusError(email, lastError string) error {
+	query := `
+		INSERT INTO account_status (
+			email, claude_quota, gemini_quota, last_error, last_updated,
+			claude_reset_sec, gemini_reset_sec
+		) VALUES (?, -1, -1, ?, ?, 0, 0)
+		ON CONFLICT(email) DO UPDATE SET
+			last_error = excluded.last_error
+	`
+
+	_, err := db.ExecContext(context.Background(), query,
+		email,
+		lastError,
+		time.Now().UTC().Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update account status error: %w", err)
+	}
+	return nil
+}
+
+// SetAccountTokenRevoked records the fingerprint of the account's revoked
+// refresh token without touching its last-known quota values. An empty
+// fingerprint clears it.
+func (db *DB) SetAccountTokenRevoked(email, fingerprint string) error {
+	query := `
+		INSERT INTO account_status (
+			email, claude_quota, gemini_quota, last_error, last_updated,
+			claude_reset_sec, gemini_reset_sec, revoked_token
+		) VALUES (?, -1, -1, '', ?, 0, 0, ?)
+		ON CONFLICT(email) DO UPDATE SET
+			revoked_token = excluded.revoked_token
+	`
+
+	_, err := db.ExecContext(context.Background(), query,
+		email,
+		time.Now().UTC().Format("2006-01-02 15:04:05"),
+		fingerprint,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update account token status: %w", err)
+	}
+	return nil
+}
+
 // InsertQuotaSnapshot records a point-in-time quota reading.
 func (db *DB) InsertQuotaSnapshot(snapshot *models.QuotaSnapshot) error {
 	query := `
@@ -291,7 +412,7 @@ func (db *DB) InsertQuotaSnapshot(snapshot *models.QuotaSnapshot) error {
 		snapshot.TotalQuota,
 		snapshot.Tier,
 		snapshot.IsRateLimited,
-		timestamp.Format("2006-01-02 15:04:05"),
+		timestamp.UTC().Format("2006-01-02 15:04:05"),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert quota snapshot: %w", err)
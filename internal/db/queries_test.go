@@ -375,6 +375,150 @@ func TestDeleteAccountStatus(t *testing.T) {
 	}
 }
 
+func TestUpsertAccountStatus(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	status := &models.AccountStatus{
+		Email:          "test@example.com",
+		ClaudeQuota:    40,
+		GeminiQuota:    70,
+		TotalQuota:     55,
+		Tier:           "PRO",
+		LastUpdated:    updated,
+		ClaudeResetSec: 3600,
+	}
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() failed: %v", err)
+	}
+
+	status.ClaudeQuota = 30
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() update failed: %v", err)
+	}
+
+	got, err := db.GetAccountStatus("test@example.com")
+	if err != nil {
+		t.Fatalf("GetAccountStatus() failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("GetAccountStatus() returned nil")
+	}
+	if got.ClaudeQuota != 30 || got.GeminiQuota != 70 || got.ClaudeResetSec != 3600 {
+		t.Errorf("unexpected status: %+v", got)
+	}
+	if !got.LastUpdated.Equal(updated) {
+		t.Errorf("LastUpdated = %v, want %v", got.LastUpdated, updated)
+	}
+
+	all, err := db.GetAllAccountStatuses()
+	if err != nil {
+		t.Fatalf("GetAllAccountStatuses() failed: %v", err)
+	}
+	if len(all) != 1 {
+		t.Errorf("GetAllAccountStatuses() returned %d statuses, want 1", len(all))
+	}
+}
+
+func TestUpsertAccountStatus_ModelQuotas(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	reset := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	status := &models.AccountStatus{
+		Email:       "test@example.com",
+		ClaudeQuota: 20,
+		GeminiQuota: -1,
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 20, ResetTime: reset},
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 0, IsRateLimited: true},
+		},
+	}
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() failed: %v", err)
+	}
+
+	got, err := db.GetAccountStatus("test@example.com")
+	if err != nil || got == nil {
+		t.Fatalf("GetAccountStatus() = %v, %v", got, err)
+	}
+	if len(got.ModelQuotas) != 2 {
+		t.Fatalf("expected 2 model quotas, got %+v", got.ModelQuotas)
+	}
+	if got.ModelQuotas[0].ModelID != "claude-opus" || !got.ModelQuotas[0].ResetTime.Equal(reset) {
+		t.Errorf("unexpected first model quota: %+v", got.ModelQuotas[0])
+	}
+	if !got.ModelQuotas[1].IsRateLimited || got.ModelQuotas[1].Remaining != 0 {
+		t.Errorf("unexpected second model quota: %+v", got.ModelQuotas[1])
+	}
+
+	// An update without per-model detail clears it.
+	status.ModelQuotas = nil
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() update failed: %v", err)
+	}
+	got, _ = db.GetAccountStatus("test@example.com")
+	if len(got.ModelQuotas) != 0 {
+		t.Errorf("expected model quotas to be cleared, got %+v", got.ModelQuotas)
+	}
+}
+
+func TestUpdateAccountStatusError(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	// Error for an unknown account creates a placeholder row.
+	if err := db.UpdateAccountStatusError("new@example.com", "boom"); err != nil {
+		t.Fatalf("UpdateAccountStatusError() failed: %v", err)
+	}
+	got, err := db.GetAccountStatus("new@example.com")
+	if err != nil || got == nil {
+		t.Fatalf("GetAccountStatus() = %v, %v", got, err)
+	}
+	if got.LastError != "boom" || got.ClaudeQuota != -1 {
+		t.Errorf("unexpected placeholder status: %+v", got)
+	}
+
+	// Error for a known account keeps its quota values.
+	status := &models.AccountStatus{Email: "known@example.com", ClaudeQuota: 50, GeminiQuota: 60, Tier: "FREE"}
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() failed: %v", err)
+	}
+	if err := db.UpdateAccountStatusError("known@example.com", "timeout"); err != nil {
+		t.Fatalf("UpdateAccountStatusError() failed: %v", err)
+	}
+	got, _ = db.GetAccountStatus("known@example.com")
+	if got.LastError != "timeout" || got.ClaudeQuota != 50 {
+		t.Errorf("unexpected status after error: %+v", got)
+	}
+}
+
+func TestSetAccountTokenRevoked(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	status := &models.AccountStatus{Email: "a@example.com", ClaudeQuota: 50, GeminiQuota: 60, Tier: "PRO"}
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() failed: %v", err)
+	}
+	if err := db.SetAccountTokenRevoked("a@example.com", "abc123"); err != nil {
+		t.Fatalf("SetAccountTokenRevoked() failed: %v", err)
+	}
+	got, _ := db.GetAccountStatus("a@example.com")
+	if got.RevokedToken != "abc123" || got.ClaudeQuota != 50 {
+		t.Errorf("unexpected status after revocation: %+v", got)
+	}
+
+	// A successful refresh writes the whole status and clears it.
+	if err := db.UpsertAccountStatus(status); err != nil {
+		t.Fatalf("UpsertAccountStatus() failed: %v", err)
+	}
+	if got, _ := db.GetAccountStatus("a@example.com"); got.RevokedToken != "" {
+		t.Errorf("RevokedToken = %q after a successful refresh", got.RevokedToken)
+	}
+}
+
 func TestNullString(t *testing.T) {
 	tests := []struct {
 		name  string
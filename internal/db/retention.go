@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Resolution is the granularity history queries read from.
+type Resolution int
+
+const (
+	// ResolutionBucket reads the 5-minute buckets in quota_snapshots_agg.
+	ResolutionBucket Resolution = iota
+	// ResolutionHourly reads quota_snapshots_hourly.
+	ResolutionHourly
+	// ResolutionDaily reads quota_snapshots_daily.
+	ResolutionDaily
+)
+
+// Longest ranges served from a finer resolution before falling back to a
+// coarser one, regardless of how long the finer data is kept.
+const (
+	maxBucketRangeDays = 7
+	maxHourlyRangeDays = 90
+)
+
+// vacuumFreelistRatio is the share of free pages that triggers a VACUUM.
+const vacuumFreelistRatio = 0.2
+
+// String returns the display name for a resolution.
+func (r Resolution) String() string {
+	switch r {
+	case ResolutionBucket:
+		return "5m"
+	case ResolutionHourly:
+		return "1h"
+	case ResolutionDaily:
+		return "1d"
+	default:
+		return "unknown"
+	}
+}
+
+// RetentionPolicy controls how long each tier of quota history is kept.
+// A value of 0 keeps that tier forever. Daily rollups are never pruned.
+type RetentionPolicy struct {
+	RawDays    int // quota_snapshots
+	BucketDays int // quota_snapshots_agg
+	HourlyDays int // quota_snapshots_hourly and quota_snapshots_model_agg
+}
+
+// DefaultRetentionPolicy returns the default retention tiers.
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		RawDays:    7,
+		BucketDays: 30,
+		HourlyDays: 365,
+	}
+}
+
+// ResolutionFor returns the finest resolution that still holds a full
+// window of the given number of days. days <= 0 means all time.
+func (p RetentionPolicy) ResolutionFor(days int) Resolution {
+	switch {
+	case days > 0 && days <= maxBucketRangeDays && keeps(p.BucketDays, days):
+		return ResolutionBucket
+	case days > 0 && days <= maxHourlyRangeDays && keeps(p.HourlyDays, days):
+		return ResolutionHourly
+	default:
+		return ResolutionDaily
+	}
+}
+
+func keeps(retentionDays, days int) bool {
+	return retentionDays <= 0 || days <= retentionDays
+}
+
+// SetRetentionPolicy sets the policy used by ApplyRetention and by history
+// queries to choose their resolution.
+func (db *DB) SetRetentionPolicy(policy RetentionPolicy) {
+	db.retention = policy
+}
+
+// RetentionPolicy returns the active retention policy.
+func (db *DB) RetentionPolicy() RetentionPolicy {
+	return db.retention
+}
+
+// MaintenanceResult summarises one maintenance run.
+type MaintenanceResult struct {
+	RawDeleted          int64
+	BucketsDeleted      int64
+	ModelBucketsDeleted int64
+	HourlyDeleted       int64
+	Vacuumed            bool
+}
+
+// Maintain rolls up completed hours and days, prunes every tier past its
+// retention, checkpoints the WAL and vacuums when enough pages are free.
+func (db *DB) Maintain(now time.Time) (*MaintenanceResult, error) {
+	if err := db.RollupSnapshots(now); err != nil {
+		return nil, err
+	}
+
+	result, err := db.ApplyRetention(now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Checkpoint(); err != nil {
+		return result, err
+	}
+
+	vacuumed, err := db.VacuumIfFragmented(vacuumFreelistRatio)
+	if err != nil {
+		return result, err
+	}
+	result.Vacuumed = vacuumed
+
+	return result, nil
+}
+
+// RollupSnapshots folds completed hours of 5-minute buckets into
+// quota_snapshots_hourly and completed days of hourly rows into
+// quota_snapshots_daily. The most recent rolled-up period is recomputed on
+// every run, so calling it repeatedly is safe.
+func (db *DB) RollupSnapshots(now time.Time) error {
+	now = now.UTC()
+	hourCutoff := now.Truncate(time.Hour).Format("2006-01-02 15:04:05")
+	dayCutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Format("2006-01-02 15:04:05")
+
+	hourlyQuery := `
+		INSERT INTO quota_snapshots_hourly (
+			email, bucket_time, session_id, claude_quota_avg, gemini_quota_avg,
+			claude_consumed, gemini_consumed, claude_peak, gemini_peak, total_peak,
+			bucket_count, sample_count, tier
+		)
+		SELECT
+			email,
+			strftime('%Y-%m-%d %H:00:00', bucket_time) AS period,
+			COALESCE(session_id, '') AS sid,
+			AVG(claude_quota_avg),
+			AVG(gemini_quota_avg),
+			SUM(claude_consumed),
+			SUM(gemini_consumed),
+			MAX(claude_consumed),
+			MAX(gemini_consumed),
+			MAX(claude_consumed + gemini_consumed),
+			COUNT(*),
+			SUM(sample_count),
+			MAX(tier)
+		FROM quota_snapshots_agg a
+		WHERE bucket_time < ?
+		  AND bucket_time >= COALESCE(
+			(SELECT MAX(h.bucket_time) FROM quota_snapshots_hourly h WHERE h.email = a.email), '')
+		GROUP BY email, period, sid
+		ON CONFLICT(email, bucket_time, session_id) DO UPDATE SET ` + rollupUpdateSet
+
+	dailyQuery := `
+		INSERT INTO quota_snapshots_daily (
+			email, bucket_time, session_id, claude_quota_avg, gemini_quota_avg,
+			claude_consumed, gemini_consumed, claude_peak, gemini_peak, total_peak,
+			bucket_count, sample_count, tier
+		)
+		SELECT
+			email,
+			strftime('%Y-%m-%d 00:00:00', bucket_time) AS period,
+			session_id,
+			SUM(claude_quota_avg * bucket_count) / MAX(SUM(bucket_count), 1),
+			SUM(gemini_quota_avg * bucket_count) / MAX(SUM(bucket_count), 1),
+			SUM(claude_consumed),
+			SUM(gemini_consumed),
+			MAX(claude_peak),
+			MAX(gemini_peak),
+			MAX(total_peak),
+			SUM(bucket_count),
+			SUM(sample_count),
+			MAX(tier)
+		FROM quota_snapshots_hourly h
+		WHERE bucket_time < ?
+		  AND bucket_time >= COALESCE(
+			(SELECT MAX(d.bucket_time) FROM quota_snapshots_daily d WHERE d.email = h.email), '')
+		GROUP BY email, period, session_id
+		ON CONFLICT(email, bucket_time, session_id) DO UPDATE SET ` + rollupUpdateSet
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin rollup: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, hourlyQuery, hourCutoff); err != nil {
+		return fmt.Errorf("failed to roll up hourly snapshots: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, dailyQuery, dayCutoff); err != nil {
+		return fmt.Errorf("failed to roll up daily snapshots: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollup: %w", err)
+	}
+	return nil
+}
+
+// rollupUpdateSet replaces a recomputed rollup row with the new aggregate.
+const rollupUpdateSet = `
+			claude_quota_avg = excluded.claude_quota_avg,
+			gemini_quota_avg = excluded.gemini_quota_avg,
+			claude_consumed = excluded.claude_consumed,
+			gemini_consumed = excluded.gemini_consumed,
+			claude_peak = excluded.claude_peak,
+			gemini_peak = excluded.gemini_peak,
+			total_peak = excluded.total_peak,
+			bucket_count = excluded.bucket_count,
+			sample_count = excluded.sample_count,
+			tier = excluded.tier
+`
+
+// ApplyRetention deletes rows older than the retention policy allows. Rollups
+// are expected to be current, see RollupSnapshots.
+func (db *DB) ApplyRetention(now time.Time) (*MaintenanceResult, error) {
+	result := &MaintenanceResult{}
+	policy := db.retention
+
+	var err error
+	if result.RawDeleted, err = db.pruneOlderThan("quota_snapshots", "timestamp", policy.RawDays, now); err != nil {
+		return nil, err
+	}
+	if result.BucketsDeleted, err = db.pruneOlderThan("quota_snapshots_agg", "bucket_time", policy.BucketDays, now); err != nil {
+		return nil, err
+	}
+	// Per-model buckets are not rolled up, so they are kept as long as the
+	// hourly tier rather than dropped with the account buckets.
+	if result.ModelBucketsDeleted, err = db.pruneOlderThan(
+		"quota_snapshots_model_agg", "bucket_time", policy.HourlyDays, now); err != nil {
+		return nil, err
+	}
+	if result.HourlyDeleted, err = db.pruneOlderThan(
+		"quota_snapshots_hourly", "bucket_time", policy.HourlyDays, now); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// pruneOlderThan deletes rows whose column is older than days before now.
+// days <= 0 keeps everything.
+func (db *DB) pruneOlderThan(table, column string, days int, now time.Time) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+
+	cutoff := now.UTC().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s < ?", table, column)
+
+	result, err := db.ExecContext(context.Background(), query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune %s: %w", table, err)
+	}
+	return result.RowsAffected()
+}
+
+// Checkpoint writes the WAL back into the main database file and truncates it.
+func (db *DB) Checkpoint() error {
+	if _, err := db.ExecContext(context.Background(), "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+	return nil
+}
+
+// VacuumIfFragmented runs VACUUM when at least ratio of the database pages
+// are on the freelist. Returns whether a VACUUM ran.
+func (db *DB) VacuumIfFragmented(ratio float64) (bool, error) {
+	var pageCount, freelistCount int64
+	if err := db.QueryRowContext(context.Background(), "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return false, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := db.QueryRowContext(context.Background(), "PRAGMA freelist_count").Scan(&freelistCount); err != nil {
+		return false, fmt.Errorf("failed to read freelist count: %w", err)
+	}
+
+	if pageCount == 0 || float64(freelistCount)/float64(pageCount) < ratio {
+		return false, nil
+	}
+
+	if err := db.Vacuum(); err != nil {
+		return false, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestRetentionPolicy_ResolutionFor(t *testing.T) {
+	policy := DefaultRetentionPolicy()
+
+	tests := []struct {
+		days int
+		want Resolution
+	}{
+		{1, ResolutionBucket},
+		{7, ResolutionBucket},
+		{30, ResolutionHourly},
+		{0, ResolutionDaily},
+		{400, ResolutionDaily},
+	}
+	for _, tt := range tests {
+		if got := policy.ResolutionFor(tt.days); got != tt.want {
+			t.Errorf("ResolutionFor(%d) = %s, want %s", tt.days, got, tt.want)
+		}
+	}
+
+	short := RetentionPolicy{BucketDays: 3, HourlyDays: 14}
+	if got := short.ResolutionFor(7); got != ResolutionHourly {
+		t.Errorf("ResolutionFor(7) with 3-day buckets = %s, want 1h", got)
+	}
+	if got := short.ResolutionFor(30); got != ResolutionDaily {
+		t.Errorf("ResolutionFor(30) with 14-day hourly = %s, want 1d", got)
+	}
+}
+
+func insertBuckets(t *testing.T, db *DB, start time.Time, count int, sessionID string) {
+	t.Helper()
+	for i := range count {
+		s := &models.AggregatedSnapshot{
+			Email:          "test@example.com",
+			BucketTime:     start.Add(time.Duration(i) * 5 * time.Minute),
+			ClaudeQuotaAvg: 80,
+			GeminiQuotaAvg: 90,
+			ClaudeConsumed: 1,
+			GeminiConsumed: 0.5,
+			SampleCount:    1,
+			SessionID:      sessionID,
+			Tier:           "PRO",
+		}
+		if err := db.UpsertAggregatedSnapshot(s); err != nil {
+			t.Fatalf("UpsertAggregatedSnapshot failed: %v", err)
+		}
+	}
+}
+
+func countRows(t *testing.T, db *DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestRollupSnapshots(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2025, 6, 10, 12, 30, 0, 0, time.UTC)
+	// Two full hours on the previous day plus the current, incomplete hour.
+	insertBuckets(t, db, time.Date(2025, 6, 9, 10, 0, 0, 0, time.UTC), 24, "s1")
+	insertBuckets(t, db, time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC), 6, "s2")
+
+	for range 2 {
+		if err := db.RollupSnapshots(now); err != nil {
+			t.Fatalf("RollupSnapshots failed: %v", err)
+		}
+	}
+
+	if n := countRows(t, db, "quota_snapshots_hourly"); n != 2 {
+		t.Errorf("hourly rows = %d, want 2", n)
+	}
+	if n := countRows(t, db, "quota_snapshots_daily"); n != 1 {
+		t.Errorf("daily rows = %d, want 1", n)
+	}
+
+	var consumed float64
+	var buckets int
+	err := db.QueryRowContext(context.Background(),
+		"SELECT claude_consumed, bucket_count FROM quota_snapshots_daily WHERE session_id = 's1'",
+	).Scan(&consumed, &buckets)
+	if err != nil {
+		t.Fatalf("read daily rollup: %v", err)
+	}
+	if consumed != 24 || buckets != 24 {
+		t.Errorf("daily rollup = %.1f consumed over %d buckets, want 24 over 24", consumed, buckets)
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	db.SetRetentionPolicy(RetentionPolicy{RawDays: 1, BucketDays: 2, HourlyDays: 3})
+
+	now := time.Now().UTC()
+	insertBuckets(t, db, now.AddDate(0, 0, -10).Truncate(time.Hour), 12, "old")
+	insertBuckets(t, db, now.Add(-30*time.Minute), 3, "new")
+
+	if err := db.RollupSnapshots(now); err != nil {
+		t.Fatalf("RollupSnapshots failed: %v", err)
+	}
+	result, err := db.ApplyRetention(now)
+	if err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+
+	if result.BucketsDeleted != 12 {
+		t.Errorf("BucketsDeleted = %d, want 12", result.BucketsDeleted)
+	}
+	if result.HourlyDeleted != 1 {
+		t.Errorf("HourlyDeleted = %d, want 1", result.HourlyDeleted)
+	}
+	if n := countRows(t, db, "quota_snapshots_daily"); n != 1 {
+		t.Errorf("daily rows = %d, want 1 (daily rollups are kept)", n)
+	}
+}
+
+func TestApplyRetention_KeepsModelBucketsForHourlyHorizon(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	db.SetRetentionPolicy(RetentionPolicy{RawDays: 1, BucketDays: 2, HourlyDays: 3})
+
+	now := time.Now().UTC()
+	for _, age := range []int{1, 2, 10} {
+		err := db.UpsertModelSnapshot(&models.ModelSnapshot{
+			Email:       "test@example.com",
+			BucketTime:  now.AddDate(0, 0, -age).Add(-time.Hour).Truncate(5 * time.Minute),
+			ModelID:     "claude-sonnet",
+			ModelFamily: "claude",
+			QuotaAvg:    50,
+			SampleCount: 1,
+		})
+		if err != nil {
+			t.Fatalf("UpsertModelSnapshot failed: %v", err)
+		}
+	}
+
+	result, err := db.ApplyRetention(now)
+	if err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+
+	// Only the bucket past HourlyDays goes; the one past BucketDays stays
+	// because nothing rolls model buckets up.
+	if result.ModelBucketsDeleted != 1 {
+		t.Errorf("ModelBucketsDeleted = %d, want 1", result.ModelBucketsDeleted)
+	}
+	if n := countRows(t, db, "quota_snapshots_model_agg"); n != 2 {
+		t.Errorf("model bucket rows = %d, want 2", n)
+	}
+}
+
+func TestGetAccountHistoryStats_UsesRollups(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	db.SetRetentionPolicy(RetentionPolicy{BucketDays: 2, HourlyDays: 3})
+
+	now := time.Now().UTC()
+	insertBuckets(t, db, now.AddDate(0, 0, -10).Truncate(time.Hour), 12, "old")
+	insertBuckets(t, db, now.Add(-30*time.Minute), 3, "new")
+
+	if _, err := db.Maintain(now); err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+
+	stats, err := db.GetAccountHistoryStats("test@example.com", models.TimeRangeAllTime)
+	if err != nil {
+		t.Fatalf("GetAccountHistoryStats failed: %v", err)
+	}
+	if stats.Resolution != ResolutionDaily.String() {
+		t.Errorf("Resolution = %q, want %q", stats.Resolution, ResolutionDaily.String())
+	}
+	// The pruned buckets survive in the daily rollup and the recent ones
+	// are read from the 5-minute tail.
+	if stats.TotalDataPoints != 15 {
+		t.Errorf("TotalDataPoints = %d, want 15", stats.TotalDataPoints)
+	}
+	if stats.Exhaustion.TotalSessions != 2 {
+		t.Errorf("TotalSessions = %d, want 2", stats.Exhaustion.TotalSessions)
+	}
+
+	recent, err := db.GetAccountHistoryStats("test@example.com", models.TimeRange24Hours)
+	if err != nil {
+		t.Fatalf("GetAccountHistoryStats failed: %v", err)
+	}
+	if recent.Resolution != ResolutionBucket.String() || recent.TotalDataPoints != 3 {
+		t.Errorf("24h stats = %s with %d points, want 5m with 3", recent.Resolution, recent.TotalDataPoints)
+	}
+}
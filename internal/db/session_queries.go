@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// sessionEventMetadata is the JSON stored in session_events.metadata.
+type sessionEventMetadata struct {
+	ResetTime     string              `json:"reset_time,omitempty"`
+	From          models.SessionState `json:"from,omitempty"`
+	Reason        string              `json:"reason,omitempty"`
+	ClaudePercent float64             `json:"claude_percent"`
+	GeminiPercent float64             `json:"gemini_percent"`
+}
+
+// InsertSessionEvent records a session state transition.
+func (db *DB) InsertSessionEvent(event *models.SessionEvent) error {
+	meta := sessionEventMetadata{
+		From:          event.From,
+		Reason:        event.Reason,
+		ClaudePercent: event.ClaudePercent,
+		GeminiPercent: event.GeminiPercent,
+	}
+	if !event.ResetTime.IsZero() {
+		meta.ResetTime = event.ResetTime.UTC().Format(time.RFC3339)
+	}
+	metadata, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode session event metadata: %w", err)
+	}
+
+	timestamp := event.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	query := `
+		INSERT INTO session_events (session_id, event_type, email, metadata, timestamp)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	result, err := db.ExecContext(context.Background(), query,
+		event.SessionID,
+		string(event.State),
+		event.Email,
+		string(metadata),
+		timestamp.UTC().Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert session event: %w", err)
+	}
+
+	if id, err := result.LastInsertId(); err == nil {
+		event.ID = id
+	}
+	return nil
+}
+
+// GetSessionEvents returns the most recent session events for an account,
+// newest first.
+func (db *DB) GetSessionEvents(email string, limit int) ([]models.SessionEvent, error) {
+	query := `
+		SELECT id, session_id, event_type, email, metadata, timestamp
+		FROM session_events
+		WHERE email = ?
+		ORDER BY timestamp DESC, id DESC
+		LIMIT ?
+	`
+
+	rows, err := db.QueryContext(context.Background(), query, email, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []models.SessionEvent
+	for rows.Next() {
+		event, err := scanSessionEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetLastSessionEvent returns the most recent session event for an account,
+// or nil if none was recorded.
+func (db *DB) GetLastSessionEvent(email string) (*models.SessionEvent, error) {
+	events, err := db.GetSessionEvents(email, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0], nil
+}
+
+func scanSessionEvent(rows *sql.Rows) (*models.SessionEvent, error) {
+	var event models.SessionEvent
+	var eventType string
+	var email, metadata, timestamp sql.NullString
+
+	if err := rows.Scan(&event.ID, &event.SessionID, &eventType, &email, &metadata, &timestamp); err != nil {
+		return nil, fmt.Errorf("failed to scan session event: %w", err)
+	}
+
+	event.State = models.SessionState(eventType)
+	event.Email = email.String
+	if t, ok := parseTimeString(timestamp.String); ok {
+		event.Timestamp = t
+	}
+
+	if metadata.Valid && metadata.String != "" {
+		var meta sessionEventMetadata
+		if err := json.Unmarshal([]byte(metadata.String), &meta); err == nil {
+			event.From = meta.From
+			event.Reason = meta.Reason
+			event.ClaudePercent = meta.ClaudePercent
+			event.GeminiPercent = meta.GeminiPercent
+			if t, err := time.Parse(time.RFC3339, meta.ResetTime); err == nil {
+				event.ResetTime = t
+			}
+		}
+	}
+
+	return &event, nil
+}
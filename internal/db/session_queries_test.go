@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestSessionEvents_RoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+
+	email := "test@example.com"
+	reset := time.Date(2025, 6, 10, 15, 0, 0, 0, time.UTC)
+	events := []models.SessionEvent{
+		{Email: email, SessionID: "s1", State: models.SessionStarted, Timestamp: reset.Add(-4 * time.Hour)},
+		{
+			Email: email, SessionID: "s1", From: models.SessionStarted, State: models.SessionExhausted,
+			Reason: "quota_exhausted", ClaudePercent: 0, GeminiPercent: 12.5, ResetTime: reset,
+			Timestamp: reset.Add(-time.Hour),
+		},
+	}
+	for i := range events {
+		if err := db.InsertSessionEvent(&events[i]); err != nil {
+			t.Fatalf("InsertSessionEvent failed: %v", err)
+		}
+		if events[i].ID == 0 {
+			t.Error("Expected ID to be set")
+		}
+	}
+
+	last, err := db.GetLastSessionEvent(email)
+	if err != nil {
+		t.Fatalf("GetLastSessionEvent failed: %v", err)
+	}
+	if last == nil || last.State != models.SessionExhausted || last.From != models.SessionStarted {
+		t.Fatalf("Unexpected last event: %+v", last)
+	}
+	if !last.ResetTime.Equal(reset) || last.GeminiPercent != 12.5 || last.Reason != "quota_exhausted" {
+		t.Errorf("Metadata not round-tripped: %+v", last)
+	}
+
+	none, err := db.GetLastSessionEvent("other@example.com")
+	if err != nil || none != nil {
+		t.Errorf("Expected no event for unknown account, got %+v, %v", none, err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package hooks runs user-defined commands when quota, projection and
+// account events happen, e.g. to pause a batch job when an account runs out
+// or to start work when its quota resets.
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventType names an event hooks can subscribe to.
+type EventType string
+
+const (
+	// EventQuotaUpdated follows every successful quota refresh of an account.
+	EventQuotaUpdated EventType = "quota_updated"
+	// EventProjectionStatusChanged fires when a model's projection moves
+	// between SAFE, WARNING and CRITICAL.
+	EventProjectionStatusChanged EventType = "projection_status_changed"
+	// EventRateLimited fires when a model becomes rate limited.
+	EventRateLimited EventType = "rate_limited"
+	// EventQuotaExhausted fires when a model's remaining quota reaches zero.
+	EventQuotaExhausted EventType = "quota_exhausted"
+	// EventQuotaReset fires when a model's remaining quota jumps back up.
+	EventQuotaReset EventType = "quota_reset"
+	// EventAccountsChanged fires when accounts are added, removed or updated,
+	// or the active account changes.
+	EventAccountsChanged EventType = "accounts_changed"
+)
+
+// EventTypes lists every event type.
+var EventTypes = []EventType{
+	EventQuotaUpdated, EventProjectionStatusChanged, EventRateLimited,
+	EventQuotaExhausted, EventQuotaReset, EventAccountsChanged,
+}
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultConcurrency = 4
+)
+
+// Config is the content of the hooks file.
+type Config struct {
+	// Timeout is the default time limit of a hook command, e.g. "30s".
+	Timeout string `json:"timeout,omitempty"`
+	Hooks   []Hook `json:"hooks"`
+	// Concurrency is the number of hook commands that may run at once.
+	Concurrency int `json:"concurrency,omitempty"`
+	timeout     time.Duration
+}
+
+// Hook maps an event type to a shell command.
+type Hook struct {
+	Name    string    `json:"name,omitempty"`
+	Event   EventType `json:"event"`
+	Command string    `json:"command"`
+	// Account is a glob matched against the account email; empty matches all.
+	Account string `json:"account,omitempty"`
+	// Model is a glob matched against the model ID and family of model
+	// events; empty matches all.
+	Model string `json:"model,omitempty"`
+	// Timeout overrides the default timeout for this hook.
+	Timeout  string `json:"timeout,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+	timeout  time.Duration
+}
+
+// LoadConfig reads the hooks file at path. A missing file yields no hooks.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hooks %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the hooks and fills in defaults.
+func (c *Config) Validate() error {
+	var err error
+	if c.timeout, err = parseTimeout(c.Timeout, defaultTimeout); err != nil {
+		return fmt.Errorf("timeout: %w", err)
+	}
+	if c.Concurrency < 0 {
+		return errors.New("concurrency must not be negative")
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = defaultConcurrency
+	}
+
+	for i := range c.Hooks {
+		h := &c.Hooks[i]
+		if h.Name == "" {
+			h.Name = fmt.Sprintf("%s#%d", h.Event, i+1)
+		}
+		if !slices.Contains(EventTypes, h.Event) {
+			return fmt.Errorf("hook %q: unknown event %q", h.Name, h.Event)
+		}
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("hook %q: missing command", h.Name)
+		}
+		for _, glob := range []string{h.Account, h.Model} {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("hook %q: invalid pattern %q: %w", h.Name, glob, err)
+			}
+		}
+		if h.timeout, err = parseTimeout(h.Timeout, c.timeout); err != nil {
+			return fmt.Errorf("hook %q: timeout: %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// Enabled returns the hooks that are not disabled.
+func (c *Config) Enabled() []Hook {
+	var hooks []Hook
+	for _, h := range c.Hooks {
+		if !h.Disabled {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// matches reports whether the hook runs for ev.
+func (h *Hook) matches(ev *Event) bool {
+	if h.Event != ev.Type || !glob(h.Account, ev.Email) {
+		return false
+	}
+	if h.Model == "" {
+		return true
+	}
+	return (ev.Model != "" && glob(h.Model, ev.Model)) || (ev.Family != "" && glob(h.Model, ev.Family))
+}
+
+func parseTimeout(s string, fallback time.Duration) (time.Duration, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive, got %s", s)
+	}
+	return d, nil
+}
+
+func glob(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(s))
+	return ok
+}
@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadConfig(filepath.Join(dir, "missing.json"))
+	if err != nil || len(cfg.Hooks) != 0 {
+		t.Fatalf("LoadConfig(missing) = %+v, %v; want no hooks", cfg, err)
+	}
+
+	path := filepath.Join(dir, "hooks.json")
+	data := `{
+		"timeout": "10s",
+		"hooks": [
+			{"name": "pause", "event": "quota_exhausted", "command": "pause-batch", "model": "claude"},
+			{"event": "quota_reset", "command": "resume-batch", "timeout": "2m"},
+			{"event": "accounts_changed", "command": "true", "disabled": true}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Concurrency != defaultConcurrency {
+		t.Errorf("concurrency = %d, want the default", cfg.Concurrency)
+	}
+	if cfg.Hooks[0].timeout != 10*time.Second || cfg.Hooks[1].timeout != 2*time.Minute {
+		t.Errorf("timeouts = %v, %v", cfg.Hooks[0].timeout, cfg.Hooks[1].timeout)
+	}
+	if cfg.Hooks[1].Name != "quota_reset#2" {
+		t.Errorf("default name = %q", cfg.Hooks[1].Name)
+	}
+	if enabled := cfg.Enabled(); len(enabled) != 2 {
+		t.Errorf("got %d enabled hooks, want 2", len(enabled))
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]Config{
+		"unknown event":        {Hooks: []Hook{{Event: "quota_low", Command: "true"}}},
+		"missing command":      {Hooks: []Hook{{Event: EventQuotaReset, Command: " "}}},
+		"bad pattern":          {Hooks: []Hook{{Event: EventQuotaReset, Command: "true", Account: "["}}},
+		"bad timeout":          {Timeout: "soon"},
+		"negative timeout":     {Hooks: []Hook{{Event: EventQuotaReset, Command: "true", Timeout: "-1s"}}},
+		"negative concurrency": {Concurrency: -1},
+	}
+	for name, cfg := range tests {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want error", name)
+		}
+	}
+}
+
+func TestHook_Matches(t *testing.T) {
+	h := Hook{Event: EventQuotaExhausted, Account: "*@work.com", Model: "claude"}
+	tests := []struct {
+		ev   Event
+		want bool
+	}{
+		{Event{Type: EventQuotaExhausted, Email: "me@work.com", Model: "claude-opus-4-5", Family: "claude"}, true},
+		{Event{Type: EventQuotaExhausted, Email: "me@home.com", Model: "claude-opus-4-5", Family: "claude"}, false},
+		{Event{Type: EventQuotaExhausted, Email: "me@work.com", Model: "gemini-3-pro", Family: "gemini"}, false},
+		{Event{Type: EventQuotaReset, Email: "me@work.com", Model: "claude-opus-4-5", Family: "claude"}, false},
+	}
+	for _, tt := range tests {
+		if got := h.matches(&tt.ev); got != tt.want {
+			t.Errorf("matches(%+v) = %v, want %v", tt.ev, got, tt.want)
+		}
+	}
+}
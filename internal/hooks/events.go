@@ -0,0 +1,225 @@
+package hooks
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// resetJump is the rise in remaining percentage points between two refreshes
+// that counts as a quota reset.
+const resetJump = 20
+
+// Event is passed to hook commands as JSON on stdin and as ADT_* environment
+// variables.
+type Event struct {
+	At            time.Time      `json:"at"`
+	ResetTime     *time.Time     `json:"resetTime,omitempty"`
+	Type          EventType      `json:"event"`
+	Email         string         `json:"email,omitempty"`
+	Tier          string         `json:"tier,omitempty"`
+	Model         string         `json:"model,omitempty"`
+	Family        string         `json:"family,omitempty"`
+	Previous      string         `json:"previous,omitempty"`
+	Current       string         `json:"current,omitempty"`
+	ActiveAccount string         `json:"activeAccount,omitempty"`
+	Models        []ModelQuota   `json:"models,omitempty"`
+	Accounts      []AccountEntry `json:"accounts,omitempty"`
+	// RemainingPercent is the model's remaining quota for model events and
+	// the account's overall remaining quota for quota_updated.
+	RemainingPercent *float64 `json:"remainingPercent,omitempty"`
+}
+
+// ModelQuota is a model's quota in a quota_updated event.
+type ModelQuota struct {
+	ResetTime        *time.Time `json:"resetTime,omitempty"`
+	ID               string     `json:"id"`
+	Family           string     `json:"family"`
+	RemainingPercent float64    `json:"remainingPercent"`
+	RateLimited      bool       `json:"rateLimited"`
+}
+
+// AccountEntry is an account in an accounts_changed event.
+type AccountEntry struct {
+	Email  string `json:"email"`
+	Active bool   `json:"active"`
+}
+
+// Env returns the event as environment variables.
+func (e *Event) Env() []string {
+	env := []string{
+		"ADT_EVENT=" + string(e.Type),
+		"ADT_AT=" + e.At.UTC().Format(time.RFC3339),
+	}
+	add := func(key, value string) {
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	add("ADT_EMAIL", e.Email)
+	add("ADT_TIER", e.Tier)
+	add("ADT_MODEL", e.Model)
+	add("ADT_FAMILY", e.Family)
+	add("ADT_PREVIOUS", e.Previous)
+	add("ADT_CURRENT", e.Current)
+	add("ADT_ACTIVE_ACCOUNT", e.ActiveAccount)
+	if e.RemainingPercent != nil {
+		add("ADT_REMAINING_PERCENT", strconv.FormatFloat(*e.RemainingPercent, 'f', 1, 64))
+	}
+	if e.ResetTime != nil {
+		add("ADT_RESET_TIME", e.ResetTime.UTC().Format(time.RFC3339))
+	}
+	return env
+}
+
+// modelState is what the detector remembers about a model between refreshes.
+type modelState struct {
+	status      models.ProjectionStatus
+	percent     float64
+	rateLimited bool
+	seen        bool
+}
+
+// detector turns quota refreshes and projections into hook events. Changes
+// are only reported once a model has been seen, so a restart does not fire
+// every hook.
+type detector struct {
+	models map[string]*modelState
+	mu     sync.Mutex
+}
+
+func newDetector() *detector {
+	return &detector{models: make(map[string]*modelState)}
+}
+
+func (d *detector) state(email, model string) *modelState {
+	key := email + "|" + model
+	st, ok := d.models[key]
+	if !ok {
+		st = &modelState{}
+		d.models[key] = st
+	}
+	return st
+}
+
+// quota returns the events caused by a successful refresh.
+func (d *detector) quota(qi *models.QuotaInfo, now time.Time) []Event {
+	overall := qi.RemainingPercent()
+	updated := Event{
+		At:               now,
+		Type:             EventQuotaUpdated,
+		Email:            qi.AccountEmail,
+		Tier:             qi.SubscriptionTier,
+		RemainingPercent: &overall,
+		Models:           make([]ModelQuota, 0, len(qi.ModelQuotas)),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var events []Event
+	for i := range qi.ModelQuotas {
+		mq := &qi.ModelQuotas[i]
+		if mq.Limit <= 0 && !mq.IsRateLimited {
+			continue
+		}
+		percent := mq.RemainingPercent()
+		resetTime := timePtr(mq.ResetTime)
+		updated.Models = append(updated.Models, ModelQuota{
+			ID:               mq.ModelID,
+			Family:           mq.ModelFamily,
+			RemainingPercent: percent,
+			RateLimited:      mq.IsRateLimited,
+			ResetTime:        resetTime,
+		})
+
+		st := d.state(qi.AccountEmail, mq.ModelID)
+		if st.seen {
+			event := func(t EventType) Event {
+				p := percent
+				return Event{
+					At:               now,
+					Type:             t,
+					Email:            qi.AccountEmail,
+					Tier:             qi.SubscriptionTier,
+					Model:            mq.ModelID,
+					Family:           mq.ModelFamily,
+					Previous:         strconv.FormatFloat(st.percent, 'f', 1, 64),
+					Current:          strconv.FormatFloat(percent, 'f', 1, 64),
+					RemainingPercent: &p,
+					ResetTime:        resetTime,
+				}
+			}
+			if mq.IsRateLimited && !st.rateLimited {
+				events = append(events, event(EventRateLimited))
+			}
+			if percent <= 0 && st.percent > 0 {
+				events = append(events, event(EventQuotaExhausted))
+			}
+			if percent-st.percent >= resetJump {
+				events = append(events, event(EventQuotaReset))
+			}
+		}
+		st.seen = true
+		st.percent = percent
+		st.rateLimited = mq.IsRateLimited
+	}
+	return append([]Event{updated}, events...)
+}
+
+// projection returns an event for every model whose projection status
+// changed. UNKNOWN projections are ignored.
+func (d *detector) projection(ap *models.AccountProjection, now time.Time) []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var events []Event
+	for _, mp := range ap.Models {
+		if mp.Status == models.ProjectionUnknown || mp.Status == "" {
+			continue
+		}
+		st := d.state(ap.Email, mp.Model)
+		previous := st.status
+		st.status = mp.Status
+		if previous == "" || previous == mp.Status {
+			continue
+		}
+		p := mp.CurrentPercent
+		events = append(events, Event{
+			At:               now,
+			Type:             EventProjectionStatusChanged,
+			Email:            ap.Email,
+			Model:            mp.Model,
+			Family:           mp.Family,
+			Previous:         string(previous),
+			Current:          string(mp.Status),
+			RemainingPercent: &p,
+			ResetTime:        timePtr(mp.ResetTime),
+		})
+	}
+	return events
+}
+
+// accountsEvent returns the accounts_changed event.
+func accountsEvent(accs []models.Account, active *models.Account, now time.Time) Event {
+	ev := Event{At: now, Type: EventAccountsChanged, Accounts: make([]AccountEntry, 0, len(accs))}
+	if active != nil {
+		ev.ActiveAccount = active.Email
+	}
+	for i := range accs {
+		ev.Accounts = append(ev.Accounts, AccountEntry{
+			Email:  accs[i].Email,
+			Active: accs[i].Email == ev.ActiveAccount,
+		})
+	}
+	return ev
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,110 @@
+package hooks
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func quotaInfo(remaining int64, rateLimited bool) *models.QuotaInfo {
+	return &models.QuotaInfo{
+		AccountEmail:     "a@example.com",
+		SubscriptionTier: "PRO",
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-sonnet-4-5", ModelFamily: "claude", Limit: 100, Remaining: remaining, IsRateLimited: rateLimited},
+		},
+	}
+}
+
+func types(events []Event) []EventType {
+	var out []EventType
+	for _, ev := range events {
+		out = append(out, ev.Type)
+	}
+	return out
+}
+
+func TestDetector_Quota(t *testing.T) {
+	d := newDetector()
+	now := time.Now()
+
+	tests := []struct {
+		qi   *models.QuotaInfo
+		want []EventType
+	}{
+		// The first observation only reports the update.
+		{quotaInfo(0, true), []EventType{EventQuotaUpdated}},
+		{quotaInfo(80, false), []EventType{EventQuotaUpdated, EventQuotaReset}},
+		{quotaInfo(70, false), []EventType{EventQuotaUpdated}},
+		{quotaInfo(0, false), []EventType{EventQuotaUpdated, EventQuotaExhausted}},
+		{quotaInfo(0, true), []EventType{EventQuotaUpdated, EventRateLimited}},
+		{quotaInfo(0, true), []EventType{EventQuotaUpdated}},
+	}
+	for i, tt := range tests {
+		if got := types(d.quota(tt.qi, now)); !slices.Equal(got, tt.want) {
+			t.Errorf("refresh %d: events = %v, want %v", i+1, got, tt.want)
+		}
+	}
+
+	events := d.quota(quotaInfo(100, false), now)
+	reset := events[1]
+	if reset.Model != "claude-sonnet-4-5" || reset.Previous != "0.0" || reset.Current != "100.0" || *reset.RemainingPercent != 100 {
+		t.Errorf("reset event = %+v", reset)
+	}
+	if len(events[0].Models) != 1 || events[0].Tier != "PRO" {
+		t.Errorf("update event = %+v", events[0])
+	}
+}
+
+func TestDetector_Projection(t *testing.T) {
+	d := newDetector()
+	project := func(status models.ProjectionStatus) []Event {
+		return d.projection(&models.AccountProjection{
+			Email:  "a@example.com",
+			Models: []*models.ModelProjection{{Model: "claude-sonnet-4-5", Family: "claude", Status: status}},
+		}, time.Now())
+	}
+
+	if events := project(models.ProjectionSafe); len(events) != 0 {
+		t.Errorf("first projection fired %+v", events)
+	}
+	if events := project(models.ProjectionUnknown); len(events) != 0 {
+		t.Errorf("unknown projection fired %+v", events)
+	}
+	events := project(models.ProjectionCritical)
+	if len(events) != 1 || events[0].Previous != "SAFE" || events[0].Current != "CRITICAL" {
+		t.Errorf("events = %+v, want SAFE -> CRITICAL", events)
+	}
+	if events := project(models.ProjectionCritical); len(events) != 0 {
+		t.Errorf("unchanged status fired %+v", events)
+	}
+}
+
+func TestEvent_Env(t *testing.T) {
+	percent := 4.25
+	ev := Event{
+		At:               time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Type:             EventQuotaExhausted,
+		Email:            "a@example.com",
+		Model:            "claude-sonnet-4-5",
+		RemainingPercent: &percent,
+	}
+	env := ev.Env()
+	for _, want := range []string{
+		"ADT_EVENT=quota_exhausted",
+		"ADT_AT=2026-01-02T03:04:05Z",
+		"ADT_EMAIL=a@example.com",
+		"ADT_MODEL=claude-sonnet-4-5",
+		"ADT_REMAINING_PERCENT=4.2",
+	} {
+		if !slices.Contains(env, want) {
+			t.Errorf("env %v is missing %q", env, want)
+		}
+	}
+	if slices.ContainsFunc(env, func(s string) bool { return strings.HasPrefix(s, "ADT_TIER=") }) {
+		t.Error("empty values should be omitted")
+	}
+}
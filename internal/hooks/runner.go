@@ -0,0 +1,230 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+const (
+	// queueSize is the number of hook runs that may wait for a free slot;
+	// further runs are dropped.
+	queueSize = 100
+	// maxOutput caps the command output kept for the log.
+	maxOutput = 4096
+)
+
+// job is one hook run.
+type job struct {
+	hook  Hook
+	event Event
+}
+
+// Runner runs the configured hooks for observed events. Commands run
+// through the shell with the event as JSON on stdin and as environment
+// variables; their output goes to the log.
+type Runner struct {
+	detector *detector
+	ctx      context.Context
+	cancel   context.CancelFunc
+	jobs     chan job
+	now      func() time.Time
+	hooks    []Hook
+	workers  int
+	wg       sync.WaitGroup
+	once     sync.Once
+	started  atomic.Bool
+}
+
+// NewRunner creates a runner for cfg. Cancelling ctx, or calling Close,
+// kills running commands.
+func NewRunner(ctx context.Context, cfg *Config) (*Runner, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Runner{
+		detector: newDetector(),
+		ctx:      ctx,
+		cancel:   cancel,
+		jobs:     make(chan job, queueSize),
+		now:      time.Now,
+		hooks:    cfg.Enabled(),
+		workers:  cfg.Concurrency,
+	}, nil
+}
+
+// Hooks returns the enabled hooks.
+func (r *Runner) Hooks() []Hook {
+	return append([]Hook(nil), r.hooks...)
+}
+
+// Start starts the workers that run hook commands. Events observed before
+// Start are ignored.
+func (r *Runner) Start() {
+	r.once.Do(func() {
+		r.started.Store(true)
+		for range r.workers {
+			r.wg.Add(1)
+			go r.work()
+		}
+	})
+}
+
+// Close kills running commands and waits for the workers to exit. Queued
+// runs are dropped.
+func (r *Runner) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// ObserveQuota runs the hooks for a successful quota refresh.
+func (r *Runner) ObserveQuota(qi *models.QuotaInfo) {
+	if len(r.hooks) == 0 || qi == nil || qi.IsStale() {
+		return
+	}
+	r.dispatch(r.detector.quota(qi, r.now()))
+}
+
+// ObserveProjection runs the hooks for projection status changes.
+func (r *Runner) ObserveProjection(ap *models.AccountProjection) {
+	if len(r.hooks) == 0 || ap == nil {
+		return
+	}
+	r.dispatch(r.detector.projection(ap, r.now()))
+}
+
+// ObserveAccounts runs the hooks for a change of the account list.
+func (r *Runner) ObserveAccounts(accs []models.Account, active *models.Account) {
+	if len(r.hooks) == 0 {
+		return
+	}
+	r.dispatch([]Event{accountsEvent(accs, active, r.now())})
+}
+
+func (r *Runner) dispatch(events []Event) {
+	if !r.started.Load() {
+		return
+	}
+	for i := range events {
+		for _, h := range r.hooks {
+			if !h.matches(&events[i]) {
+				continue
+			}
+			select {
+			case r.jobs <- job{hook: h, event: events[i]}:
+			case <-r.ctx.Done():
+				return
+			default:
+				logger.Warn("hook dropped, too many pending runs", "hook", h.Name, "event", events[i].Type)
+			}
+		}
+	}
+}
+
+func (r *Runner) work() {
+	defer r.wg.Done()
+	for {
+		select {
+		case j := <-r.jobs:
+			r.run(j.hook, &j.event)
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// run executes one hook and logs its outcome and output.
+func (r *Runner) run(h Hook, ev *Event) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		logger.Error("failed to encode hook event", "hook", h.Name, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, h.timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, h.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), ev.Env()...)
+	output := &limitedBuffer{limit: maxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Do not wait for background children holding the output pipe open.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	kv := []any{
+		"hook", h.Name,
+		"event", ev.Type,
+		"email", ev.Email,
+		"duration", time.Since(start).Round(time.Millisecond),
+	}
+	if out := output.String(); out != "" {
+		kv = append(kv, "output", out)
+	}
+
+	switch {
+	case err == nil:
+		logger.Info("hook finished", kv...)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		logger.Warn("hook timed out", append(kv, "timeout", h.timeout)...)
+	case r.ctx.Err() != nil:
+		logger.Warn("hook killed on shutdown", kv...)
+	default:
+		logger.Warn("hook failed", append(kv, "error", err)...)
+	}
+}
+
+// shellCommand runs command through the platform shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// limitedBuffer keeps the first limit bytes written to it.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+	mu        sync.Mutex
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := strings.TrimSpace(b.buf.String())
+	if b.truncated {
+		s += " …(truncated)"
+	}
+	return s
+}
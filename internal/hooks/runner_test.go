@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// syncBuffer is a log destination safe for concurrent writers.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func captureLog(t *testing.T) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	logger.SetOutput(buf)
+	t.Cleanup(func() { logger.SetOutput(os.Stderr) })
+	return buf
+}
+
+func newTestRunner(t *testing.T, cfg *Config) *Runner {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use /bin/sh")
+	}
+	r, err := NewRunner(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	return r
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for hook")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunner_PassesEvent(t *testing.T) {
+	log := captureLog(t)
+	dir := t.TempDir()
+	r := newTestRunner(t, &Config{Hooks: []Hook{
+		{Name: "exhausted", Event: EventQuotaExhausted, Model: "claude",
+			Command: `cat > "` + dir + `/event.json"; echo "$ADT_EVENT $ADT_EMAIL $ADT_MODEL"`},
+		{Name: "gemini", Event: EventQuotaExhausted, Model: "gemini", Command: `touch "` + dir + `/gemini"`},
+	}})
+	r.Start()
+	defer r.Close()
+
+	qi := &models.QuotaInfo{
+		AccountEmail: "a@example.com",
+		ModelQuotas:  []models.ModelQuota{{ModelID: "claude-sonnet-4-5", ModelFamily: "claude", Limit: 100, Remaining: 10}},
+	}
+	r.ObserveQuota(qi)
+	qi.ModelQuotas[0].Remaining = 0
+	r.ObserveQuota(qi)
+
+	waitFor(t, func() bool { return strings.Contains(log.String(), "hook finished") })
+
+	data, err := os.ReadFile(filepath.Join(dir, "event.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ev Event
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatalf("stdin is not an event: %v\n%s", err, data)
+	}
+	if ev.Type != EventQuotaExhausted || ev.Model != "claude-sonnet-4-5" || ev.Previous != "10.0" {
+		t.Errorf("event = %+v", ev)
+	}
+	if !strings.Contains(log.String(), `output="quota_exhausted a@example.com claude-sonnet-4-5"`) {
+		t.Errorf("output not logged:\n%s", log.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gemini")); err == nil {
+		t.Error("hook for another model ran")
+	}
+}
+
+func TestRunner_TimeoutAndFailure(t *testing.T) {
+	log := captureLog(t)
+	r := newTestRunner(t, &Config{Hooks: []Hook{
+		{Name: "slow", Event: EventAccountsChanged, Command: "sleep 10", Timeout: "100ms"},
+		{Name: "broken", Event: EventAccountsChanged, Command: "echo oops >&2; exit 3"},
+	}})
+	r.Start()
+	defer r.Close()
+
+	start := time.Now()
+	r.ObserveAccounts([]models.Account{{Email: "a@example.com"}}, nil)
+	waitFor(t, func() bool {
+		out := log.String()
+		return strings.Contains(out, "hook timed out") && strings.Contains(out, "hook failed")
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timed out hook took %v", elapsed)
+	}
+	if !strings.Contains(log.String(), "output=oops") || !strings.Contains(log.String(), "exit status 3") {
+		t.Errorf("failure not logged:\n%s", log.String())
+	}
+}
+
+func TestRunner_IgnoresEventsBeforeStart(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRunner(t, &Config{Hooks: []Hook{
+		{Event: EventAccountsChanged, Command: `touch "` + dir + `/ran"`},
+	}})
+	r.ObserveAccounts(nil, nil)
+	r.Start()
+	r.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err == nil {
+		t.Error("hook ran for an event observed before Start")
+	}
+}
@@ -2,6 +2,7 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
@@ -9,6 +10,11 @@ import (
 // Logger is the global logger instance.
 var Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
 
+// SetOutput replaces the global logger with one writing text to w.
+func SetOutput(w io.Writer) {
+	Logger = slog.New(slog.NewTextHandler(w, nil))
+}
+
 // Error logs an error message.
 func Error(msg string, args ...any) {
 	Logger.Error(msg, args...)
@@ -0,0 +1,392 @@
+// Package login implements the installed-app Google OAuth flow that adds an
+// account to the accounts file: PKCE, a loopback redirect server and the
+// authorization code exchange.
+package login
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+const (
+	// DefaultTimeout is how long Run waits for the user to finish signing in.
+	DefaultTimeout = 5 * time.Minute
+
+	// defaultRedirectPath is the callback path used when no redirect URL is
+	// configured; the port is picked by the system.
+	defaultRedirectPath = "/oauth-callback"
+)
+
+// DefaultScopes are the scopes requested for a new account: Cloud Code
+// quota access and the email address identifying the account.
+var DefaultScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/userinfo.email",
+	"https://www.googleapis.com/auth/userinfo.profile",
+}
+
+// ErrNoClientID is returned when no OAuth client ID is configured.
+var ErrNoClientID = errors.New("no OAuth client ID configured (set GOOGLE_CLIENT_ID or install opencode-antigravity-auth)")
+
+// Config configures a login.
+type Config struct {
+	// HTTPClient is used for the token exchange and userinfo requests.
+	HTTPClient *http.Client
+	// UserInfo looks up the signed-in user; defaults to quota.FetchUserInfo
+	// at UserInfoURL.
+	UserInfo func(ctx context.Context, client *http.Client, accessToken string) (*quota.UserInfo, error)
+	// Open is called with the consent page URL once the redirect server
+	// listens. It usually prints the URL and opens a browser; an error
+	// aborts the login. Defaults to OpenBrowser.
+	Open         func(authURL string) error
+	ClientID     string
+	ClientSecret string
+	AuthURL      string // required
+	TokenURL     string // required
+	// UserInfoURL identifies the signed-in user; empty uses Google's.
+	UserInfoURL string
+	// RedirectURL is the loopback URL Google redirects to. Empty listens on
+	// a free port of 127.0.0.1.
+	RedirectURL string
+	Scopes      []string
+}
+
+// FromConfig returns the login configuration for cfg.
+func FromConfig(cfg *config.Config) Config {
+	return Config{
+		ClientID:     cfg.GoogleClientID,
+		ClientSecret: cfg.GoogleClientSecret,
+		AuthURL:      cfg.GoogleAuthURL,
+		TokenURL:     cfg.GoogleTokenURL,
+		UserInfoURL:  cfg.GoogleUserInfoURL,
+		RedirectURL:  cfg.LoginRedirectURL,
+	}
+}
+
+// Result is a completed login.
+type Result struct {
+	Email        string
+	Name         string
+	Picture      string
+	RefreshToken string
+	AccessToken  string
+	ExpiresAt    time.Time
+}
+
+// Run signs a user in: it listens on the loopback redirect URL, calls Open
+// with the consent page, waits for the authorization code, exchanges it for
+// tokens and fetches the user's email. It returns when ctx is done.
+func Run(ctx context.Context, cfg Config) (*Result, error) {
+	if cfg.ClientID == "" {
+		return nil, ErrNoClientID
+	}
+	if cfg.AuthURL == "" || cfg.TokenURL == "" {
+		return nil, fmt.Errorf("OAuth authorization and token URLs are required")
+	}
+	cfg.setDefaults()
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, redirectURL, err := listen(cfg.RedirectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(chan callback, 1)
+	srv := &http.Server{
+		Handler:           callbackHandler(redirectURL.Path, state, codes),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("login redirect server failed", "error", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := cfg.Open(authCodeURL(&cfg, redirectURL.String(), state, challenge(verifier))); err != nil {
+		return nil, fmt.Errorf("failed to open the sign-in page: %w", err)
+	}
+
+	var cb callback
+	select {
+	case cb = <-codes:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login not completed: %w", ctx.Err())
+	}
+	if cb.err != nil {
+		return nil, cb.err
+	}
+
+	token, err := exchange(ctx, &cfg, cb.code, verifier, redirectURL.String())
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("token response has no refresh token")
+	}
+
+	user, err := cfg.UserInfo(ctx, cfg.HTTPClient, token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+	if user.Email == "" {
+		return nil, fmt.Errorf("user info has no email address")
+	}
+
+	return &Result{
+		Email:        user.Email,
+		Name:         user.Name,
+		Picture:      user.Picture,
+		RefreshToken: token.RefreshToken,
+		AccessToken:  token.AccessToken,
+		ExpiresAt:    time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// Save stores the login in the accounts file. A new email is added; an
+// existing account is re-authenticated with the new tokens, keeping its
+// other fields. It reports whether the account was added.
+func Save(svc *accounts.Service, r *Result) (bool, error) {
+	if acc := svc.GetAccountByEmail(r.Email); acc != nil {
+		acc.RefreshToken = r.RefreshToken
+		acc.AccessToken = r.AccessToken
+		acc.ExpiresAt = r.ExpiresAt
+		if acc.Picture == "" {
+			acc.Picture = r.Picture
+		}
+		if err := svc.UpdateAccount(acc); err != nil {
+			return false, fmt.Errorf("failed to update account %s: %w", r.Email, err)
+		}
+		return false, nil
+	}
+
+	acc := &models.Account{
+		Email:        r.Email,
+		RefreshToken: r.RefreshToken,
+		AccessToken:  r.AccessToken,
+		ExpiresAt:    r.ExpiresAt,
+		Picture:      r.Picture,
+	}
+	if err := svc.AddAccount(acc); err != nil {
+		return false, fmt.Errorf("failed to add account %s: %w", r.Email, err)
+	}
+	return true, nil
+}
+
+// OpenBrowser opens rawURL in the default browser.
+func OpenBrowser(rawURL string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", rawURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", rawURL)
+	default:
+		cmd = exec.Command("xdg-open", rawURL)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+func (c *Config) setDefaults() {
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if c.UserInfo == nil {
+		endpoints := quota.Endpoints{UserInfoURL: c.UserInfoURL}.WithDefaults()
+		c.UserInfo = func(ctx context.Context, client *http.Client, accessToken string) (*quota.UserInfo, error) {
+			return quota.FetchUserInfo(ctx, client, endpoints, accessToken)
+		}
+	}
+	if c.Open == nil {
+		c.Open = OpenBrowser
+	}
+	if len(c.Scopes) == 0 {
+		c.Scopes = DefaultScopes
+	}
+}
+
+// listen starts listening for the redirect. Only loopback addresses are
+// accepted: the authorization code must never leave the machine.
+func listen(redirect string) (net.Listener, *url.URL, error) {
+	if redirect == "" {
+		redirect = "http://127.0.0.1:0" + defaultRedirectPath
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid redirect URL %q: %w", redirect, err)
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); u.Scheme != "http" || (host != "localhost" && (ip == nil || !ip.IsLoopback())) {
+		return nil, nil, fmt.Errorf("redirect URL %q must be http on a loopback address", redirect)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	listener, err := net.Listen("tcp", u.Host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to listen for the OAuth redirect: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	u.Host = net.JoinHostPort(host, fmt.Sprint(port))
+	return listener, u, nil
+}
+
+// callback is the outcome of the redirect.
+type callback struct {
+	err  error
+	code string
+}
+
+// callbackHandler receives the redirect at path and sends the code, or the
+// error Google reported, on codes. Requests with the wrong state are
+// rejected and do not end the login.
+func callbackHandler(path, state string, codes chan<- callback) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+
+		var cb callback
+		switch {
+		case q.Get("error") != "":
+			cb.err = fmt.Errorf("authorization denied: %s", q.Get("error"))
+		case q.Get("code") == "":
+			cb.err = fmt.Errorf("redirect has no authorization code")
+		default:
+			cb.code = q.Get("code")
+		}
+
+		message := "Signed in. You can close this tab and return to the terminal."
+		if cb.err != nil {
+			message = "Sign-in failed: " + cb.err.Error()
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprintf(w, "<!doctype html><title>adt login</title><p>%s</p>", html.EscapeString(message))
+
+		select {
+		case codes <- cb:
+		default:
+		}
+	})
+	return mux
+}
+
+// authCodeURL returns the consent page URL. access_type=offline and
+// prompt=consent make Google return a refresh token every time.
+func authCodeURL(cfg *Config, redirectURL, state, codeChallenge string) string {
+	v := url.Values{}
+	v.Set("client_id", cfg.ClientID)
+	v.Set("redirect_uri", redirectURL)
+	v.Set("response_type", "code")
+	v.Set("scope", strings.Join(cfg.Scopes, " "))
+	v.Set("state", state)
+	v.Set("code_challenge", codeChallenge)
+	v.Set("code_challenge_method", "S256")
+	v.Set("access_type", "offline")
+	v.Set("prompt", "consent")
+
+	sep := "?"
+	if strings.Contains(cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return cfg.AuthURL + sep + v.Encode()
+}
+
+// exchange trades the authorization code for tokens.
+func exchange(ctx context.Context, cfg *Config, code, verifier, redirectURL string) (*quota.TokenResponse, error) {
+	data := url.Values{}
+	data.Set("client_id", cfg.ClientID)
+	if cfg.ClientSecret != "" {
+		data.Set("client_secret", cfg.ClientSecret)
+	}
+	data.Set("code", code)
+	data.Set("code_verifier", verifier)
+	data.Set("grant_type", "authorization_code")
+	data.Set("redirect_uri", redirectURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.Error("failed to close response body", "error", closeErr)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var token quota.TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	return &token, nil
+}
+
+// randomString returns n random bytes encoded as unpadded base64url, the
+// alphabet PKCE verifiers allow.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// challenge returns the S256 PKCE challenge for verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,160 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+// fakeGoogle is a stand-in for the token endpoint. It checks the PKCE
+// verifier against the challenge sent to the consent page.
+type fakeGoogle struct {
+	challenge string
+	redirect  string
+}
+
+func (f *fakeGoogle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code",
+		r.PostForm.Get("code") != "the-code",
+		r.PostForm.Get("client_id") != "client-id",
+		r.PostForm.Get("redirect_uri") != f.redirect,
+		challenge(r.PostForm.Get("code_verifier")) != f.challenge:
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","expires_in":3600,"token_type":"Bearer"}`)
+}
+
+// browser returns an Open function that follows the consent page straight
+// to the redirect with the given query parameters.
+func browser(t *testing.T, google *fakeGoogle, params func(state string) url.Values) func(string) error {
+	return func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		if q.Get("code_challenge_method") != "S256" || q.Get("access_type") != "offline" {
+			t.Errorf("consent URL is missing PKCE or offline access: %s", authURL)
+		}
+		google.challenge = q.Get("code_challenge")
+		google.redirect = q.Get("redirect_uri")
+
+		resp, err := http.Get(google.redirect + "?" + params(q.Get("state")).Encode())
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+}
+
+func newConfig(t *testing.T, google *fakeGoogle) Config {
+	srv := httptest.NewServer(google)
+	t.Cleanup(srv.Close)
+	return Config{
+		ClientID: "client-id",
+		AuthURL:  "https://auth.invalid/o/oauth2/auth",
+		TokenURL: srv.URL + "/token",
+		UserInfo: func(_ context.Context, _ *http.Client, accessToken string) (*quota.UserInfo, error) {
+			if accessToken != "access" {
+				return nil, errors.New("bad access token")
+			}
+			return &quota.UserInfo{Email: "new@example.com", Name: "New"}, nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	google := &fakeGoogle{}
+	cfg := newConfig(t, google)
+	cfg.Open = browser(t, google, func(state string) url.Values {
+		return url.Values{"state": {state}, "code": {"the-code"}}
+	})
+
+	result, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if result.Email != "new@example.com" || result.RefreshToken != "refresh" || result.AccessToken != "access" {
+		t.Errorf("Run() = %+v", result)
+	}
+	if !strings.HasPrefix(google.redirect, "http://127.0.0.1:") || !strings.HasSuffix(google.redirect, defaultRedirectPath) {
+		t.Errorf("redirect_uri = %q, want a loopback URL", google.redirect)
+	}
+}
+
+func TestRun_Denied(t *testing.T) {
+	google := &fakeGoogle{}
+	cfg := newConfig(t, google)
+	cfg.Open = browser(t, google, func(state string) url.Values {
+		return url.Values{"state": {state}, "error": {"access_denied"}}
+	})
+
+	if _, err := Run(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("Run() error = %v, want access_denied", err)
+	}
+}
+
+func TestRun_IgnoresWrongState(t *testing.T) {
+	google := &fakeGoogle{}
+	cfg := newConfig(t, google)
+	cfg.Open = browser(t, google, func(string) url.Values {
+		return url.Values{"state": {"forged"}, "code": {"the-code"}}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := Run(ctx, cfg); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want the login to keep waiting", err)
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	if _, err := Run(context.Background(), Config{}); !errors.Is(err, ErrNoClientID) {
+		t.Errorf("Run() without client ID error = %v", err)
+	}
+	cfg := Config{ClientID: "id", AuthURL: "a", TokenURL: "t", RedirectURL: "http://example.com:8080/cb"}
+	if _, err := Run(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "loopback") {
+		t.Errorf("Run() with a remote redirect error = %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	svc, err := accounts.New(filepath.Join(t.TempDir(), "accounts.json"))
+	if err != nil {
+		t.Fatalf("accounts.New() failed: %v", err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	result := &Result{Email: "a@example.com", RefreshToken: "first"}
+	if added, err := Save(svc, result); err != nil || !added {
+		t.Fatalf("Save() = %v, %v, want added", added, err)
+	}
+
+	result.RefreshToken = "second"
+	if added, err := Save(svc, result); err != nil || added {
+		t.Fatalf("Save() = %v, %v, want re-authenticated", added, err)
+	}
+	if svc.Count() != 1 {
+		t.Errorf("Count() = %d, want 1", svc.Count())
+	}
+	if acc := svc.GetAccountByEmail("a@example.com"); acc.RefreshToken != "second" {
+		t.Errorf("RefreshToken = %q, want the new token", acc.RefreshToken)
+	}
+}
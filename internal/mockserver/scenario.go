@@ -0,0 +1,286 @@
+// Package mockserver fakes the Google OAuth, userinfo and Cloud Code quota
+// endpoints with scripted quota curves and failures, so the dashboard can be
+// demonstrated and tested without Google access.
+package mockserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// Scenario scripts the accounts the server knows, how their quota evolves
+// and when requests fail.
+type Scenario struct {
+	Accounts []Account `json:"accounts"`
+	Faults   []Fault   `json:"faults,omitempty"`
+}
+
+// Account is an account the server signs in and reports quota for.
+type Account struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	// RefreshToken identifies the account at the token endpoint. It
+	// defaults to "mock-refresh-" followed by the email.
+	RefreshToken string  `json:"refreshToken,omitempty"`
+	Models       []Model `json:"models"`
+}
+
+// Model is the quota curve of one model.
+type Model struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName,omitempty"`
+	// Curve is the remaining fraction of the quota over time, from 0 to 1,
+	// interpolated linearly between points. Before the first point the
+	// quota is full.
+	Curve []Point `json:"curve"`
+	// Period is the quota window: the curve restarts and the quota resets
+	// every Period. Zero plays the curve once and then keeps its last value.
+	Period Duration `json:"period,omitempty"`
+}
+
+// Point is the remaining fraction of a quota at a time into its window.
+type Point struct {
+	At        Duration `json:"at"`
+	Remaining float64  `json:"remaining"`
+}
+
+// Fault makes matching requests fail, or slows them down, during a window
+// of the scenario. Start, For and Every are scenario time; RetryAfter and
+// Delay are real time.
+type Fault struct {
+	// Path selects the requests the fault applies to by a part of their
+	// path: "/token", "/userinfo", "/v1internal" for every quota endpoint
+	// or "/primary/" for one of them. Empty matches every request.
+	Path string `json:"path,omitempty"`
+	// Account is the email the fault applies to; empty matches all.
+	Account string `json:"account,omitempty"`
+	// Error is the OAuth error code returned by the token endpoint, e.g.
+	// "invalid_grant" for a revoked refresh token.
+	Error string `json:"error,omitempty"`
+	Body  string `json:"body,omitempty"`
+	// Status is the response status. Zero only adds the Delay.
+	Status int `json:"status,omitempty"`
+	// RetryAfter is sent as a Retry-After header.
+	RetryAfter Duration `json:"retryAfter,omitempty"`
+	// Delay is added to the response time.
+	Delay Duration `json:"delay,omitempty"`
+	// Start and For are when the fault begins and how long it lasts; For
+	// zero lasts forever. With Every the window repeats every Every.
+	Start Duration `json:"start,omitempty"`
+	For   Duration `json:"for,omitempty"`
+	Every Duration `json:"every,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "30m" in JSON.
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DefaultScenario returns the built-in scenario: an account draining its
+// Claude quota over a 5h window, one that runs out early, one that is
+// rate limited every 15 minutes and one whose refresh token was revoked.
+// The primary quota endpoint also fails for 3 minutes every 20 minutes, so
+// requests fail over to the fallback.
+func DefaultScenario() *Scenario {
+	window := Duration(5 * time.Hour)
+	day := Duration(24 * time.Hour)
+	return &Scenario{
+		Accounts: []Account{
+			{
+				Email: "alice@example.com",
+				Name:  "Alice",
+				Models: []Model{
+					{ID: "claude-sonnet-4-5", DisplayName: "Claude Sonnet 4.5", Period: window, Curve: []Point{
+						{At: 0, Remaining: 1},
+						{At: Duration(4 * time.Hour), Remaining: 0.15},
+					}},
+					{ID: "gemini-2.5-pro", DisplayName: "Gemini 2.5 Pro", Period: day, Curve: []Point{
+						{At: 0, Remaining: 1},
+						{At: Duration(20 * time.Hour), Remaining: 0.6},
+					}},
+				},
+			},
+			{
+				Email: "bob@example.com",
+				Name:  "Bob",
+				Models: []Model{
+					{ID: "claude-sonnet-4-5", DisplayName: "Claude Sonnet 4.5", Period: window, Curve: []Point{
+						{At: 0, Remaining: 0.4},
+						{At: Duration(time.Hour), Remaining: 0},
+					}},
+					{ID: "gemini-2.5-flash", DisplayName: "Gemini 2.5 Flash", Period: day, Curve: []Point{
+						{At: 0, Remaining: 0.9},
+						{At: Duration(12 * time.Hour), Remaining: 0.7},
+					}},
+				},
+			},
+			{
+				Email: "carol@example.com",
+				Name:  "Carol",
+				Models: []Model{
+					{ID: "claude-opus-4-1", DisplayName: "Claude Opus 4.1", Period: window, Curve: []Point{
+						{At: 0, Remaining: 0.8},
+						{At: Duration(5 * time.Hour), Remaining: 0.5},
+					}},
+				},
+			},
+			{
+				Email: "dave@example.com",
+				Name:  "Dave",
+				Models: []Model{
+					{ID: "gemini-2.5-pro", DisplayName: "Gemini 2.5 Pro", Curve: []Point{{At: 0, Remaining: 1}}},
+				},
+			},
+		},
+		Faults: []Fault{
+			{
+				Path: "/v1internal", Account: "carol@example.com", Status: 429, RetryAfter: Duration(time.Minute),
+				Start: Duration(5 * time.Minute), For: Duration(2 * time.Minute), Every: Duration(15 * time.Minute),
+			},
+			{
+				// No Retry-After: a 503 that asks for one throttles the account
+				// instead of failing over.
+				Path: "/primary/", Status: 503,
+				Start: Duration(10 * time.Minute), For: Duration(3 * time.Minute), Every: Duration(20 * time.Minute),
+			},
+			{Path: "/token", Account: "dave@example.com", Error: "invalid_grant"},
+		},
+	}
+}
+
+// LoadScenario reads a scenario from a JSON file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var sc Scenario
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// Validate checks that the scenario has accounts with distinct emails and
+// refresh tokens and that its curves are well formed.
+func (sc *Scenario) Validate() error {
+	if len(sc.Accounts) == 0 {
+		return errors.New("no accounts")
+	}
+	emails := make(map[string]bool, len(sc.Accounts))
+	tokens := make(map[string]bool, len(sc.Accounts))
+	for i := range sc.Accounts {
+		acc := &sc.Accounts[i]
+		if acc.Email == "" {
+			return fmt.Errorf("account %d has no email", i+1)
+		}
+		if emails[acc.Email] || tokens[acc.refreshToken()] {
+			return fmt.Errorf("account %s is listed twice", acc.Email)
+		}
+		emails[acc.Email], tokens[acc.refreshToken()] = true, true
+
+		for j := range acc.Models {
+			if err := acc.Models[j].validate(); err != nil {
+				return fmt.Errorf("account %s: %w", acc.Email, err)
+			}
+		}
+	}
+	for i := range sc.Faults {
+		if f := &sc.Faults[i]; f.Account != "" && !emails[f.Account] {
+			return fmt.Errorf("fault %d: unknown account %s", i+1, f.Account)
+		}
+	}
+	return nil
+}
+
+func (m *Model) validate() error {
+	if m.ID == "" {
+		return errors.New("model without id")
+	}
+	for i, p := range m.Curve {
+		if p.Remaining < 0 || p.Remaining > 1 {
+			return fmt.Errorf("model %s: remaining %g is not between 0 and 1", m.ID, p.Remaining)
+		}
+		if i > 0 && p.At < m.Curve[i-1].At {
+			return fmt.Errorf("model %s: curve points are not in time order", m.ID)
+		}
+	}
+	return nil
+}
+
+// ToAccount returns the account as it is stored in the accounts file.
+func (a *Account) ToAccount() models.Account {
+	return models.Account{Email: a.Email, DisplayName: a.Name, RefreshToken: a.refreshToken()}
+}
+
+func (a *Account) refreshToken() string {
+	if a.RefreshToken != "" {
+		return a.RefreshToken
+	}
+	return "mock-refresh-" + a.Email
+}
+
+// remaining returns the remaining fraction of the model's quota elapsed
+// into the scenario, and how long until it resets, or 0 if it never does.
+func (m *Model) remaining(elapsed time.Duration) (fraction float64, untilReset time.Duration) {
+	at := elapsed
+	if period := time.Duration(m.Period); period > 0 {
+		at = elapsed % period
+		untilReset = period - at
+	}
+	if len(m.Curve) == 0 {
+		return 1, untilReset
+	}
+
+	fraction = 1
+	for i, p := range m.Curve {
+		pAt := time.Duration(p.At)
+		if at < pAt {
+			if i > 0 {
+				prev := m.Curve[i-1]
+				progress := float64(at-time.Duration(prev.At)) / float64(pAt-time.Duration(prev.At))
+				fraction = prev.Remaining + progress*(p.Remaining-prev.Remaining)
+			}
+			return fraction, untilReset
+		}
+		fraction = p.Remaining
+	}
+	return fraction, untilReset
+}
+
+// active reports whether the fault's window contains elapsed.
+func (f *Fault) active(elapsed time.Duration) bool {
+	since := elapsed - time.Duration(f.Start)
+	if since < 0 {
+		return false
+	}
+	if every := time.Duration(f.Every); every > 0 {
+		since %= every
+	}
+	return f.For == 0 || since < time.Duration(f.For)
+}
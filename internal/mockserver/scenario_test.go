@@ -0,0 +1,104 @@
+package mockserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModel_Remaining(t *testing.T) {
+	m := &Model{ID: "claude", Period: Duration(5 * time.Hour), Curve: []Point{
+		{At: Duration(time.Hour), Remaining: 1},
+		{At: Duration(3 * time.Hour), Remaining: 0},
+	}}
+
+	tests := []struct {
+		elapsed   time.Duration
+		want      float64
+		wantReset time.Duration
+	}{
+		{0, 1, 5 * time.Hour},
+		{2 * time.Hour, 0.5, 3 * time.Hour},
+		{4 * time.Hour, 0, time.Hour},
+		{7 * time.Hour, 0.5, 3 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, reset := m.remaining(tt.elapsed)
+		if got != tt.want || reset != tt.wantReset {
+			t.Errorf("remaining(%v) = %v, %v, want %v, %v", tt.elapsed, got, reset, tt.want, tt.wantReset)
+		}
+	}
+
+	once := &Model{ID: "gemini", Curve: []Point{{At: 0, Remaining: 1}, {At: Duration(time.Hour), Remaining: 0.2}}}
+	if got, reset := once.remaining(48 * time.Hour); got != 0.2 || reset != 0 {
+		t.Errorf("remaining() after the curve = %v, %v, want its last value and no reset", got, reset)
+	}
+}
+
+func TestFault_Active(t *testing.T) {
+	f := &Fault{Start: Duration(5 * time.Minute), For: Duration(2 * time.Minute), Every: Duration(15 * time.Minute)}
+	for elapsed, want := range map[time.Duration]bool{
+		4 * time.Minute:  false,
+		6 * time.Minute:  true,
+		8 * time.Minute:  false,
+		21 * time.Minute: true,
+	} {
+		if got := f.active(elapsed); got != want {
+			t.Errorf("active(%v) = %v, want %v", elapsed, got, want)
+		}
+	}
+
+	forever := &Fault{}
+	if !forever.active(time.Hour) {
+		t.Error("a fault without a window should always be active")
+	}
+}
+
+func TestScenario_Validate(t *testing.T) {
+	if err := DefaultScenario().Validate(); err != nil {
+		t.Fatalf("DefaultScenario().Validate() = %v", err)
+	}
+
+	tests := map[string]*Scenario{
+		"no accounts": {},
+		"duplicate":   {Accounts: []Account{{Email: "a@example.com"}, {Email: "a@example.com"}}},
+		"range": {Accounts: []Account{{Email: "a@example.com", Models: []Model{
+			{ID: "claude", Curve: []Point{{Remaining: 1.5}}},
+		}}}},
+		"order": {Accounts: []Account{{Email: "a@example.com", Models: []Model{
+			{ID: "claude", Curve: []Point{{At: Duration(time.Hour)}, {At: 0}}},
+		}}}},
+		"fault account": {Accounts: []Account{{Email: "a@example.com"}}, Faults: []Fault{{Account: "b@example.com"}}},
+	}
+	for name, sc := range tests {
+		if err := sc.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	data := `{"accounts":[{"email":"a@example.com","models":[{"id":"claude","period":"5h","curve":[{"at":"0s","remaining":1}]}]}],
+		"faults":[{"path":"/token","status":503,"retryAfter":"30s","for":"1m"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	if sc.Accounts[0].Models[0].Period != Duration(5*time.Hour) || sc.Faults[0].RetryAfter != Duration(30*time.Second) {
+		t.Errorf("LoadScenario() = %+v", sc)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"accounts":[{"email":"a@example.com","models":[{"id":"claude","period":"5 hours"}]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScenario(path); err == nil || !strings.Contains(err.Error(), "scenario") {
+		t.Errorf("LoadScenario() error = %v, want a parse error", err)
+	}
+}
@@ -0,0 +1,300 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+)
+
+// Paths of the mock endpoints. The quota method is served under any prefix,
+// so several quota endpoints, e.g. /primary and /fallback, can point at one
+// server.
+const (
+	AuthPath     = "/auth"
+	TokenPath    = "/token"
+	UserInfoPath = "/userinfo"
+	QuotaMethod  = "/v1internal:fetchAvailableModels"
+)
+
+// Token prefixes. Access tokens and authorization codes carry the account
+// email, so the server needs no session state.
+const (
+	accessTokenPrefix = "mock-access-"
+	authCodePrefix    = "mock-code-"
+	tokenLifetime     = time.Hour
+)
+
+// Server serves a Scenario. Its clock starts when it is created.
+type Server struct {
+	start    time.Time
+	scenario *Scenario
+	now      func() time.Time
+	speed    float64
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// Speed makes the scenario clock, which drives the quota curves and fault
+// windows, run factor times faster than real time.
+func Speed(factor float64) Option {
+	return func(s *Server) {
+		if factor > 0 {
+			s.speed = factor
+		}
+	}
+}
+
+// New creates a server for sc.
+func New(sc *Scenario, opts ...Option) *Server {
+	s := &Server{
+		scenario: sc,
+		now:      time.Now,
+		speed:    1,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.start = s.now()
+	return s
+}
+
+// Handler returns the mock endpoints.
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(s.serve)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	var route func(http.ResponseWriter, *http.Request, *Account)
+	switch {
+	case r.URL.Path == AuthPath && r.Method == http.MethodGet:
+		s.handleAuth(w, r)
+		return
+	case r.URL.Path == TokenPath && r.Method == http.MethodPost:
+		route = s.handleToken
+	case r.URL.Path == UserInfoPath && r.Method == http.MethodGet:
+		route = s.handleUserInfo
+	case strings.HasSuffix(r.URL.Path, QuotaMethod) && r.Method == http.MethodPost:
+		route = s.handleQuota
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	acc := s.accountFor(r)
+	if f := s.fault(r.URL.Path, acc); f != nil {
+		if f.Delay > 0 {
+			select {
+			case <-time.After(time.Duration(f.Delay)):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.Status != 0 || f.Error != "" {
+			writeFault(w, f)
+			return
+		}
+	}
+	route(w, r, acc)
+}
+
+// elapsed returns the scenario time.
+func (s *Server) elapsed() time.Duration {
+	return time.Duration(float64(s.now().Sub(s.start)) * s.speed)
+}
+
+// accountFor returns the account a request is for, from its refresh token
+// or authorization code at the token endpoint and its access token
+// elsewhere, or nil.
+func (s *Server) accountFor(r *http.Request) *Account {
+	if r.URL.Path == TokenPath {
+		if err := r.ParseForm(); err != nil {
+			return nil
+		}
+		if code, ok := strings.CutPrefix(r.PostForm.Get("code"), authCodePrefix); ok {
+			return s.account(code)
+		}
+		token := r.PostForm.Get("refresh_token")
+		for i := range s.scenario.Accounts {
+			if acc := &s.scenario.Accounts[i]; token != "" && acc.refreshToken() == token {
+				return acc
+			}
+		}
+		return nil
+	}
+
+	bearer, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if email, ok := strings.CutPrefix(bearer, accessTokenPrefix); ok {
+		return s.account(email)
+	}
+	return nil
+}
+
+func (s *Server) account(email string) *Account {
+	for i := range s.scenario.Accounts {
+		if s.scenario.Accounts[i].Email == email {
+			return &s.scenario.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// fault returns the first fault active now for a request to path by acc.
+func (s *Server) fault(path string, acc *Account) *Fault {
+	elapsed := s.elapsed()
+	for i := range s.scenario.Faults {
+		f := &s.scenario.Faults[i]
+		if !strings.Contains(path, f.Path) {
+			continue
+		}
+		if f.Account != "" && (acc == nil || acc.Email != f.Account) {
+			continue
+		}
+		if f.active(elapsed) {
+			return f
+		}
+	}
+	return nil
+}
+
+func writeFault(w http.ResponseWriter, f *Fault) {
+	if f.RetryAfter > 0 {
+		seconds := int(math.Ceil(time.Duration(f.RetryAfter).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	status := f.Status
+	if f.Error != "" {
+		if status == 0 {
+			status = http.StatusBadRequest
+		}
+		description := f.Body
+		if description == "" {
+			description = "scripted by the mock server"
+		}
+		writeJSON(w, status, map[string]string{"error": f.Error, "error_description": description})
+		return
+	}
+	body := f.Body
+	if body == "" {
+		body = http.StatusText(status)
+	}
+	http.Error(w, body, status)
+}
+
+// handleAuth stands in for the consent page: it redirects straight back
+// with an authorization code for the account named by login_hint, or for
+// the only account, and otherwise lets the user pick one.
+func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "missing or invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	acc := s.account(query.Get("login_hint"))
+	if acc == nil && len(s.scenario.Accounts) == 1 {
+		acc = &s.scenario.Accounts[0]
+	}
+	if acc == nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		var b strings.Builder
+		b.WriteString("<!doctype html><title>Mock sign-in</title><h1>Choose an account</h1><ul>")
+		for i := range s.scenario.Accounts {
+			email := s.scenario.Accounts[i].Email
+			query.Set("login_hint", email)
+			fmt.Fprintf(&b, `<li><a href="%s?%s">%s</a></li>`, AuthPath, html.EscapeString(query.Encode()), html.EscapeString(email))
+		}
+		b.WriteString("</ul>")
+		_, _ = w.Write([]byte(b.String()))
+		return
+	}
+
+	params := redirect.Query()
+	params.Set("code", authCodePrefix+acc.Email)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request, acc *Account) {
+	if acc == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "unknown refresh token or authorization code",
+		})
+		return
+	}
+
+	resp := map[string]any{
+		"access_token": accessTokenPrefix + acc.Email,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenLifetime.Seconds()),
+	}
+	if r.PostForm.Get("grant_type") == "authorization_code" {
+		resp["refresh_token"] = acc.refreshToken()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, _ *http.Request, acc *Account) {
+	if acc == nil {
+		http.Error(w, "invalid access token", http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":             acc.Email,
+		"email":          acc.Email,
+		"name":           acc.Name,
+		"verified_email": true,
+	})
+}
+
+// quotaInfo is the quotaInfo object of a model in a fetchAvailableModels
+// response.
+type quotaInfo struct {
+	ResetTime         string  `json:"resetTime,omitempty"`
+	RemainingFraction float64 `json:"remainingFraction"`
+}
+
+type modelQuota struct {
+	DisplayName string    `json:"displayName,omitempty"`
+	QuotaInfo   quotaInfo `json:"quotaInfo"`
+}
+
+func (s *Server) handleQuota(w http.ResponseWriter, _ *http.Request, acc *Account) {
+	if acc == nil {
+		http.Error(w, "invalid access token", http.StatusUnauthorized)
+		return
+	}
+
+	now, elapsed := s.now(), s.elapsed()
+	resp := make(map[string]modelQuota, len(acc.Models))
+	for i := range acc.Models {
+		m := &acc.Models[i]
+		fraction, untilReset := m.remaining(elapsed)
+		mq := modelQuota{DisplayName: m.DisplayName, QuotaInfo: quotaInfo{RemainingFraction: fraction}}
+		if untilReset > 0 {
+			reset := now.Add(time.Duration(float64(untilReset) / s.speed))
+			mq.QuotaInfo.ResetTime = reset.UTC().Format(time.RFC3339)
+		}
+		resp[m.ID] = mq
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"models": resp})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debug("failed to write mock response", "error", err)
+	}
+}
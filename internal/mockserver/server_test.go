@@ -0,0 +1,156 @@
+package mockserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+// startServer serves sc with a clock the test moves, and returns the quota
+// endpoints pointing at it with a primary and a fallback quota endpoint.
+func startServer(t *testing.T, sc *Scenario) (srv *Server, endpoints quota.Endpoints, advance func(time.Duration)) {
+	t.Helper()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	srv = New(sc)
+	srv.now = func() time.Time { return now }
+	srv.start = now
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	endpoints = quota.Endpoints{
+		TokenURL:    ts.URL + TokenPath,
+		UserInfoURL: ts.URL + UserInfoPath,
+		Quota:       []string{ts.URL + "/primary", ts.URL + "/fallback"},
+	}.WithDefaults()
+
+	return srv, endpoints, func(d time.Duration) { now = now.Add(d) }
+}
+
+func testScenario() *Scenario {
+	return &Scenario{
+		Accounts: []Account{
+			{Email: "a@example.com", Name: "A", Models: []Model{
+				{ID: "claude-sonnet", Period: Duration(5 * time.Hour), Curve: []Point{
+					{At: 0, Remaining: 1},
+					{At: Duration(4 * time.Hour), Remaining: 0.2},
+				}},
+			}},
+			{Email: "b@example.com", RefreshToken: "rt-b"},
+		},
+		Faults: []Fault{
+			{Path: "/token", Account: "b@example.com", Error: "invalid_grant"},
+			{Path: "/primary/", Status: 503, Start: Duration(time.Hour), For: Duration(10 * time.Minute)},
+			{Path: "/v1internal", Account: "a@example.com", Status: 429, RetryAfter: Duration(time.Minute), Start: Duration(3 * time.Hour)},
+		},
+	}
+}
+
+func TestServer_QuotaCurve(t *testing.T) {
+	_, endpoints, advance := startServer(t, testScenario())
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	token, err := quota.RefreshAccessToken(t.Context(), client, endpoints, "mock-refresh-a@example.com", "mock", "mock")
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	user, err := quota.FetchUserInfo(t.Context(), client, endpoints, token.AccessToken)
+	if err != nil || user.Email != "a@example.com" || user.Name != "A" {
+		t.Fatalf("FetchUserInfo() = %+v, %v", user, err)
+	}
+
+	advance(2 * time.Hour)
+	resp, err := quota.FetchQuota(t.Context(), client, endpoints, token.AccessToken)
+	if err != nil {
+		t.Fatalf("FetchQuota() error = %v", err)
+	}
+	mq := resp.ModelQuotas[0]
+	if mq.ModelID != "claude-sonnet" || mq.Remaining != 60 {
+		t.Errorf("quota after 2h = %s at %d%%, want claude-sonnet at 60%%", mq.ModelID, mq.Remaining)
+	}
+	if want := time.Date(2026, 1, 1, 17, 0, 0, 0, time.UTC); !mq.ResetTime.Equal(want) {
+		t.Errorf("reset time = %v, want the end of the 5h window at %v", mq.ResetTime, want)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	srv, endpoints, advance := startServer(t, testScenario())
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	_, err := quota.RefreshAccessToken(t.Context(), client, endpoints, "rt-b", "mock", "mock")
+	if kind := quota.TokenErrorKindOf(err); kind != quota.TokenErrorRevoked {
+		t.Errorf("refreshing b: error = %v (%v), want a revoked token", err, kind)
+	}
+	if _, err := quota.RefreshAccessToken(t.Context(), client, endpoints, "unknown", "mock", "mock"); quota.TokenErrorKindOf(err) != quota.TokenErrorRevoked {
+		t.Errorf("refreshing an unknown token: error = %v, want invalid_grant", err)
+	}
+	if _, err := quota.FetchQuota(t.Context(), client, endpoints, "not-a-mock-token"); err == nil {
+		t.Error("FetchQuota() with an unknown access token should fail")
+	}
+
+	access := accessTokenPrefix + "a@example.com"
+	advance(time.Hour)
+	if f := srv.fault("/primary"+QuotaMethod, srv.account("a@example.com")); f == nil || f.Status != 503 {
+		t.Fatalf("fault() = %+v, want the primary endpoint down", f)
+	}
+	if _, err := quota.FetchQuota(t.Context(), client, endpoints, access); err != nil {
+		t.Errorf("FetchQuota() error = %v, want the fallback to answer", err)
+	}
+
+	advance(2 * time.Hour)
+	_, err = quota.FetchQuota(t.Context(), client, endpoints, access)
+	var status *quota.StatusError
+	if !errors.As(err, &status) || status.StatusCode != 429 || status.RetryAfter != time.Minute {
+		t.Errorf("FetchQuota() error = %v, want a 429 with a 1m Retry-After", err)
+	}
+}
+
+func TestServer_Speed(t *testing.T) {
+	srv := New(testScenario(), Speed(60))
+	now := srv.start
+	srv.now = func() time.Time { return now }
+
+	now = now.Add(2 * time.Minute)
+	if got := srv.elapsed(); got != 2*time.Hour {
+		t.Errorf("elapsed() = %v after 2 minutes at 60x, want 2h", got)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := New(testScenario())
+	handler := srv.Handler()
+
+	query := url.Values{"redirect_uri": {"http://127.0.0.1:9999/cb"}, "state": {"xyz"}, "login_hint": {"a@example.com"}}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, AuthPath+"?"+query.Encode(), http.NoBody))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("GET /auth = %d, want a redirect", rec.Code)
+	}
+	location, _ := url.Parse(rec.Header().Get("Location"))
+	if location.Query().Get("code") != authCodePrefix+"a@example.com" || location.Query().Get("state") != "xyz" {
+		t.Errorf("redirected to %s, want a code for a and the state", location)
+	}
+
+	query.Del("login_hint")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, AuthPath+"?"+query.Encode(), http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /auth without a hint = %d, want the account picker", rec.Code)
+	}
+
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {authCodePrefix + "a@example.com"}}
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, TokenPath, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"refresh_token":"mock-refresh-a@example.com"`) {
+		t.Errorf("code exchange = %d %s, want tokens with a refresh token", rec.Code, rec.Body)
+	}
+}
@@ -22,7 +22,9 @@ type Account struct {
 	ID                  string           `json:"id"`
 	DisplayName         string           `json:"displayName,omitempty"`
 	Email               string           `json:"email"`
+	PollInterval        string           `json:"pollInterval,omitempty"` // fixed poll interval such as "2m"; empty adapts to usage
 	IsActive            bool             `json:"isActive,omitempty"`
+	PollPaused          bool             `json:"pollPaused,omitempty"` // no background polling
 }
 
 // GetEmail returns the account email (implements interface for quota service).
@@ -35,6 +37,19 @@ func (a *Account) GetRefreshToken() string {
 	return a.RefreshToken
 }
 
+// PollOverride returns the account's fixed poll interval, or 0 when it is
+// polled adaptively. An interval that does not parse is ignored.
+func (a *Account) PollOverride() time.Duration {
+	if a.PollInterval == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(a.PollInterval)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
 // Clone returns a deep copy of the account.
 func (a *Account) Clone() Account {
 	clone := Account{
@@ -48,6 +63,8 @@ func (a *Account) Clone() Account {
 		ProjectID:        a.ProjectID,
 		ManagedProjectID: a.ManagedProjectID,
 		IsActive:         a.IsActive,
+		PollInterval:     a.PollInterval,
+		PollPaused:       a.PollPaused,
 		AddedAt:          a.AddedAt,
 		LastUsed:         a.LastUsed,
 	}
@@ -75,8 +92,11 @@ type RawAccountData struct {
 	RefreshToken        string             `json:"refreshToken"`
 	ProjectID           string             `json:"projectId"`
 	ManagedProjectID    string             `json:"managedProjectId,omitempty"`
+	DisplayName         string             `json:"displayName,omitempty"`
+	PollInterval        string             `json:"pollInterval,omitempty"`
 	AddedAt             json.RawMessage    `json:"addedAt,omitempty"`
 	LastUsed            json.RawMessage    `json:"lastUsed,omitempty"`
+	PollPaused          bool               `json:"pollPaused,omitempty"`
 }
 
 // RawAccountsFile represents the top-level structure of the accounts JSON file.
@@ -92,6 +112,9 @@ func (r *RawAccountData) ToAccount() Account {
 		RefreshToken:     r.RefreshToken,
 		ProjectID:        r.ProjectID,
 		ManagedProjectID: r.ManagedProjectID,
+		DisplayName:      r.DisplayName,
+		PollInterval:     r.PollInterval,
+		PollPaused:       r.PollPaused,
 	}
 
 	if r.RateLimitResetTimes != nil {
@@ -271,3 +271,21 @@ func TestRawAccountData_ToAccount_EmptyDates(t *testing.T) {
 		t.Error("LastUsed should be zero when not provided")
 	}
 }
+
+func TestAccount_PollOverride(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     time.Duration
+	}{
+		{"", 0},
+		{"2m", 2 * time.Minute},
+		{"soon", 0},
+		{"-1s", 0},
+	}
+	for _, tt := range tests {
+		acc := Account{PollInterval: tt.interval}
+		if got := acc.PollOverride(); got != tt.want {
+			t.Errorf("PollOverride(%q) = %v, want %v", tt.interval, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AlertState is the persisted state of one alert rule for one account and
+// model. It lets the alert engine keep hysteresis and cooldowns across
+// restarts instead of re-firing every active alert.
+type AlertState struct {
+	LastFired time.Time
+	UpdatedAt time.Time
+	RuleID    string
+	Email     string
+	// Model is empty for rules that apply to the whole account.
+	Model string
+	// Value is the last observed value for rules that compare against the
+	// previous observation, e.g. the remaining percent or the tier.
+	Value string
+	// Firing is true while the alert condition holds; the rule fires again
+	// only after it has cleared.
+	Firing bool
+}
@@ -144,6 +144,7 @@ type AccountHistoryStats struct {
 	Historical      *HistoricalContext
 	RateLimits      *RateLimitStats
 	Email           string
+	Resolution      string // granularity the stats were computed from, e.g. "5m" or "1h"
 	DailyUsage      []DailyUsagePoint
 	HourlyPatterns  []HourlyPattern
 	WeekdayPatterns []WeekdayPattern
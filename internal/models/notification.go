@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// QueuedNotification is a rendered notification that could not be delivered
+// and waits in the database for its sink to become reachable again.
+type QueuedNotification struct {
+	CreatedAt   time.Time
+	NextAttempt time.Time
+	// Sink is the name of the notifier the payload was rendered for.
+	Sink      string
+	Payload   []byte
+	LastError string
+	ID        int64
+	Attempts  int
+}
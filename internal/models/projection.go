@@ -22,6 +22,21 @@ type AggregatedSnapshot struct {
 	Hour           int
 }
 
+// ModelSnapshot represents a 5-minute quota bucket for a single model.
+type ModelSnapshot struct {
+	BucketTime  time.Time
+	SessionID   string
+	Email       string
+	ModelID     string
+	DisplayName string
+	ModelFamily string
+	Tier        string
+	QuotaAvg    float64
+	Consumed    float64
+	SampleCount int
+	ID          int64
+}
+
 // ProjectionStatus indicates urgency level for quota depletion.
 type ProjectionStatus string
 
@@ -36,6 +51,20 @@ const (
 	ProjectionUnknown ProjectionStatus = "UNKNOWN"
 )
 
+// Severity orders projection statuses from least to most urgent. Unknown
+// statuses rank lowest.
+func (s ProjectionStatus) Severity() int {
+	switch s {
+	case ProjectionSafe:
+		return 1
+	case ProjectionWarning:
+		return 2
+	case ProjectionCritical:
+		return 3
+	}
+	return 0
+}
+
 // HistoricalContext provides long-term usage context for projections.
 type HistoricalContext struct {
 	FirstDataPoint     time.Time
@@ -50,13 +79,17 @@ type HistoricalContext struct {
 	TotalDataDays      int
 }
 
-// ModelProjection contains projection for a single model (Claude or Gemini).
+// ModelProjection contains projection for a single model or a model family.
+// For family rollups Model is "claude" or "gemini"; for per-model projections
+// Model holds the model ID and Family the family it rolls up into.
 type ModelProjection struct {
 	SessionDepleteAt  time.Time
 	ResetTime         time.Time
 	Historical        *HistoricalContext
 	VsLastMonth       string
 	Model             string
+	DisplayName       string
+	Family            string
 	VsHistorical      string
 	Status            ProjectionStatus
 	Confidence        string
@@ -76,6 +109,17 @@ type AccountProjection struct {
 	Claude      *ModelProjection
 	Gemini      *ModelProjection
 	Email       string
+	Models      []*ModelProjection
+}
+
+// ForModel returns the per-model projection for a model ID, or nil.
+func (ap *AccountProjection) ForModel(modelID string) *ModelProjection {
+	for _, mp := range ap.Models {
+		if mp.Model == modelID {
+			return mp
+		}
+	}
+	return nil
 }
 
 // ConsumptionRates holds calculated consumption velocities for an account.
@@ -90,6 +134,17 @@ type ConsumptionRates struct {
 	HistoricalSessions   int
 }
 
+// ModelConsumptionRates holds consumption velocities for a single model.
+type ModelConsumptionRates struct {
+	SessionStart       time.Time
+	Email              string
+	ModelID            string
+	SessionRate        float64
+	HistoricalRate     float64
+	SessionDataPoints  int
+	HistoricalSessions int
+}
+
 // PeriodStats represents usage statistics for a time period.
 type PeriodStats struct {
 	StartTime       time.Time
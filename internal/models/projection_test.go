@@ -22,6 +22,18 @@ func TestProjectionStatus_Constants(t *testing.T) {
 	}
 }
 
+func TestProjectionStatus_Severity(t *testing.T) {
+	ordered := []ProjectionStatus{ProjectionUnknown, ProjectionSafe, ProjectionWarning, ProjectionCritical}
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i].Severity() <= ordered[i-1].Severity() {
+			t.Errorf("%s should be more severe than %s", ordered[i], ordered[i-1])
+		}
+	}
+	if ProjectionStatus("bogus").Severity() != ProjectionUnknown.Severity() {
+		t.Error("unrecognised status should rank as unknown")
+	}
+}
+
 func TestModelProjection_Defaults(t *testing.T) {
 	proj := &ModelProjection{
 		Model: "claude",
@@ -226,3 +238,21 @@ func TestConsumptionRates_ZeroSessions(t *testing.T) {
 		t.Error("Expected zero Gemini rate")
 	}
 }
+
+func TestAccountProjection_ForModel(t *testing.T) {
+	proj := &AccountProjection{
+		Email: "test@example.com",
+		Models: []*ModelProjection{
+			{Model: "claude-sonnet-4-5", Family: "claude", DisplayName: "Claude Sonnet 4.5"},
+			{Model: "gemini-3-pro", Family: "gemini"},
+		},
+	}
+
+	mp := proj.ForModel("gemini-3-pro")
+	if mp == nil || mp.Family != "gemini" {
+		t.Fatalf("expected gemini-3-pro projection, got %+v", mp)
+	}
+	if proj.ForModel("missing") != nil {
+		t.Error("expected nil for unknown model")
+	}
+}
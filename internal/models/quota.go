@@ -1,12 +1,18 @@
 // Package models defines data structures and domain types.
 package models
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 // ModelQuota represents quota information for a specific model.
 type ModelQuota struct {
 	ResetTime        time.Time `json:"resetTime"`
 	LastUpdated      time.Time `json:"lastUpdated"`
+	ModelID          string    `json:"modelId,omitempty"`
+	DisplayName      string    `json:"displayName,omitempty"`
 	ModelFamily      string    `json:"modelFamily"`
 	Tier             string    `json:"tier,omitempty"`
 	SubscriptionTier string    `json:"subscriptionTier,omitempty"`
@@ -17,6 +23,26 @@ type ModelQuota struct {
 	IsRateLimited    bool      `json:"isRateLimited,omitempty"`
 }
 
+// Name returns the best human-readable name for the model.
+func (mq *ModelQuota) Name() string {
+	if mq.DisplayName != "" {
+		return mq.DisplayName
+	}
+	if mq.ModelID != "" {
+		return mq.ModelID
+	}
+	return mq.ModelFamily
+}
+
+// RemainingPercent returns the remaining quota as a percentage (0-100).
+// Rate-limited models always report 0.
+func (mq *ModelQuota) RemainingPercent() float64 {
+	if mq.Limit <= 0 || mq.IsRateLimited {
+		return 0
+	}
+	return float64(mq.Remaining) / float64(mq.Limit) * 100
+}
+
 // QuotaInfo represents aggregated quota information for an account.
 type QuotaInfo struct {
 	LastUpdated      time.Time    `json:"lastUpdated"`
@@ -27,14 +53,149 @@ type QuotaInfo struct {
 	TotalRemaining   int64        `json:"totalRemaining,omitempty"`
 	TotalLimit       int64        `json:"totalLimit,omitempty"`
 	OverallPercent   float64      `json:"overallPercent,omitempty"`
+	// Degraded explains why live data is unavailable. Empty when the last
+	// refresh succeeded.
+	Degraded DegradedReason `json:"degraded,omitempty"`
+	// Cached is true when the values are last-known data rather than the
+	// result of a successful refresh during this run.
+	Cached bool `json:"cached,omitempty"`
+}
+
+// DegradedReason describes why an account is showing last-known data.
+type DegradedReason string
+
+const (
+	// DegradedNone indicates live data is available.
+	DegradedNone DegradedReason = ""
+	// DegradedNoCredentials indicates OAuth client credentials are missing.
+	DegradedNoCredentials DegradedReason = "no credentials"
+	// DegradedNetwork indicates the Google APIs could not be reached.
+	DegradedNetwork DegradedReason = "network unreachable"
+	// DegradedTokenRevoked indicates the refresh token was revoked or has
+	// expired; the account must log in again.
+	DegradedTokenRevoked DegradedReason = "token revoked"
+	// DegradedInvalidClient indicates the OAuth client credentials were
+	// rejected.
+	DegradedInvalidClient DegradedReason = "invalid client"
+	// DegradedRateLimited indicates the quota API is throttling requests or
+	// a circuit breaker paused them.
+	DegradedRateLimited DegradedReason = "rate limited"
+)
+
+// DataAge returns how old the quota data is relative to now.
+func (q *QuotaInfo) DataAge(now time.Time) time.Duration {
+	if q.LastUpdated.IsZero() {
+		return 0
+	}
+	return max(now.Sub(q.LastUpdated), 0)
+}
+
+// IsStale reports whether the quota is last-known data rather than live.
+func (q *QuotaInfo) IsStale() bool {
+	return q.Cached || q.Degraded != DegradedNone
+}
+
+// FamilyModels returns the model quotas belonging to a family, sorted by name.
+func (q *QuotaInfo) FamilyModels(family string) []ModelQuota {
+	var result []ModelQuota
+	for i := range q.ModelQuotas {
+		if q.ModelQuotas[i].ModelFamily == family {
+			result = append(result, q.ModelQuotas[i])
+		}
+	}
+	slices.SortFunc(result, func(a, b ModelQuota) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return result
+}
+
+// FamilyRollup returns the lowest remaining percent and its reset time across
+// all models of a family. The percent is -1 when the family has no models.
+func (q *QuotaInfo) FamilyRollup(family string) (percent float64, resetTime time.Time) {
+	percent = -1
+	for i := range q.ModelQuotas {
+		mq := &q.ModelQuotas[i]
+		if mq.ModelFamily != family {
+			continue
+		}
+		p := mq.RemainingPercent()
+		if percent < 0 || p < percent {
+			percent = p
+			resetTime = mq.ResetTime
+		}
+	}
+	return percent, resetTime
+}
+
+// RemainingPercent returns the overall remaining quota as a percentage (0-100).
+func (q *QuotaInfo) RemainingPercent() float64 {
+	if q.TotalLimit <= 0 {
+		return 0
+	}
+	return float64(q.TotalRemaining) / float64(q.TotalLimit) * 100
+}
+
+// ToAccountStatus converts the quota into its persisted per-account status.
+// Families without models are stored as -1.
+func (q *QuotaInfo) ToAccountStatus() AccountStatus {
+	claude, claudeReset := q.FamilyRollup("claude")
+	gemini, geminiReset := q.FamilyRollup("gemini")
+
+	status := AccountStatus{
+		Email:          q.AccountEmail,
+		Tier:           q.SubscriptionTier,
+		LastError:      q.Error,
+		LastUpdated:    q.LastUpdated,
+		ClaudeQuota:    claude,
+		GeminiQuota:    gemini,
+		TotalQuota:     q.RemainingPercent(),
+		ClaudeResetSec: secondsUntil(claudeReset, q.LastUpdated),
+		GeminiResetSec: secondsUntil(geminiReset, q.LastUpdated),
+	}
+	for i := range q.ModelQuotas {
+		if q.ModelQuotas[i].IsRateLimited {
+			status.IsRateLimited = true
+			break
+		}
+	}
+	if len(q.ModelQuotas) > 0 {
+		status.ModelQuotas = append([]ModelQuota(nil), q.ModelQuotas...)
+	}
+	if status.Tier == "" {
+		status.Tier = "UNKNOWN"
+	}
+	return status
+}
+
+// ToSnapshot converts the quota into a raw point-in-time snapshot.
+func (q *QuotaInfo) ToSnapshot() QuotaSnapshot {
+	status := q.ToAccountStatus()
+	return QuotaSnapshot{
+		Timestamp:     q.LastUpdated,
+		Email:         status.Email,
+		Tier:          status.Tier,
+		ClaudeQuota:   status.ClaudeQuota,
+		GeminiQuota:   status.GeminiQuota,
+		TotalQuota:    status.TotalQuota,
+		IsRateLimited: status.IsRateLimited,
+	}
+}
+
+func secondsUntil(t, from time.Time) int64 {
+	if t.IsZero() || from.IsZero() {
+		return 0
+	}
+	return max(int64(t.Sub(from).Seconds()), 0)
 }
 
 // AccountStatus represents the status for a specific account (DB model).
 type AccountStatus struct {
 	LastUpdated    time.Time
+	ModelQuotas    []ModelQuota // full per-model detail; empty for rows written before it was stored
 	Email          string
 	Tier           string
 	LastError      string
+	RevokedToken   string // fingerprint of the refresh token if it was revoked or expired
 	ClaudeQuota    float64
 	GeminiQuota    float64
 	TotalQuota     float64
@@ -54,3 +215,67 @@ type QuotaSnapshot struct {
 	TotalQuota    float64
 	IsRateLimited bool
 }
+
+// ToQuotaInfo rebuilds a last-known QuotaInfo from a persisted status.
+// Stored per-model quotas are restored as-is; older rows only hold family
+// rollups, so each family present becomes a single model quota without a
+// model ID. Returns nil when the status holds neither quota data nor an
+// error.
+func (s *AccountStatus) ToQuotaInfo() *QuotaInfo {
+	qi := &QuotaInfo{
+		AccountEmail:     s.Email,
+		LastUpdated:      s.LastUpdated,
+		SubscriptionTier: s.Tier,
+		Error:            s.LastError,
+		Cached:           true,
+	}
+	if s.RevokedToken != "" {
+		qi.Degraded = DegradedTokenRevoked
+	}
+
+	addFamily := func(family string, percent float64, resetSec int64) {
+		if percent < 0 {
+			return
+		}
+		remaining := int64(percent)
+		mq := ModelQuota{
+			ModelFamily:      family,
+			Tier:             s.Tier,
+			SubscriptionTier: s.Tier,
+			Limit:            100,
+			Remaining:        remaining,
+			Used:             100 - remaining,
+			UsagePercentage:  float64(100 - remaining),
+			IsRateLimited:    remaining == 0,
+			LastUpdated:      s.LastUpdated,
+		}
+		if resetSec > 0 {
+			mq.ResetTime = s.LastUpdated.Add(time.Duration(resetSec) * time.Second)
+		}
+		qi.ModelQuotas = append(qi.ModelQuotas, mq)
+		qi.TotalRemaining += mq.Remaining
+		qi.TotalLimit += mq.Limit
+	}
+	if len(s.ModelQuotas) > 0 {
+		qi.ModelQuotas = append([]ModelQuota(nil), s.ModelQuotas...)
+		for i := range qi.ModelQuotas {
+			qi.TotalRemaining += qi.ModelQuotas[i].Remaining
+			qi.TotalLimit += qi.ModelQuotas[i].Limit
+		}
+	} else {
+		addFamily("claude", s.ClaudeQuota, s.ClaudeResetSec)
+		addFamily("gemini", s.GeminiQuota, s.GeminiResetSec)
+	}
+
+	if len(qi.ModelQuotas) == 0 && qi.Error == "" && qi.Degraded == DegradedNone {
+		return nil
+	}
+	if len(qi.ModelQuotas) > 0 {
+		// Quota data is still useful even if the last refresh failed.
+		qi.Error = ""
+	}
+	if qi.TotalLimit > 0 {
+		qi.OverallPercent = float64(qi.TotalLimit-qi.TotalRemaining) / float64(qi.TotalLimit) * 100
+	}
+	return qi
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestModelQuota_Name(t *testing.T) {
+	tests := []struct {
+		name string
+		mq   ModelQuota
+		want string
+	}{
+		{"display name", ModelQuota{ModelID: "claude-sonnet-4-5", DisplayName: "Claude Sonnet 4.5", ModelFamily: "claude"}, "Claude Sonnet 4.5"},
+		{"model id", ModelQuota{ModelID: "claude-sonnet-4-5", ModelFamily: "claude"}, "claude-sonnet-4-5"},
+		{"family only", ModelQuota{ModelFamily: "gemini"}, "gemini"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mq.Name(); got != tt.want {
+				t.Errorf("Name() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelQuota_RemainingPercent(t *testing.T) {
+	mq := ModelQuota{Limit: 100, Remaining: 42}
+	if got := mq.RemainingPercent(); got != 42 {
+		t.Errorf("RemainingPercent() = %v, want 42", got)
+	}
+
+	mq.IsRateLimited = true
+	if got := mq.RemainingPercent(); got != 0 {
+		t.Errorf("RemainingPercent() rate limited = %v, want 0", got)
+	}
+
+	empty := ModelQuota{}
+	if got := empty.RemainingPercent(); got != 0 {
+		t.Errorf("RemainingPercent() zero limit = %v, want 0", got)
+	}
+}
+
+func TestQuotaInfo_FamilyModels(t *testing.T) {
+	q := &QuotaInfo{
+		ModelQuotas: []ModelQuota{
+			{ModelID: "claude-sonnet", ModelFamily: "claude"},
+			{ModelID: "gemini-pro", ModelFamily: "gemini"},
+			{ModelID: "claude-opus", ModelFamily: "claude"},
+		},
+	}
+
+	got := q.FamilyModels("claude")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 claude models, got %d", len(got))
+	}
+	if got[0].ModelID != "claude-opus" || got[1].ModelID != "claude-sonnet" {
+		t.Errorf("unexpected order: %s, %s", got[0].ModelID, got[1].ModelID)
+	}
+
+	if got := q.FamilyModels("other"); len(got) != 0 {
+		t.Errorf("expected no models, got %d", len(got))
+	}
+}
+
+func TestQuotaInfo_FamilyRollup(t *testing.T) {
+	early := time.Now().Add(time.Hour)
+	late := time.Now().Add(2 * time.Hour)
+	q := &QuotaInfo{
+		ModelQuotas: []ModelQuota{
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 80, ResetTime: late},
+			{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 30, ResetTime: early},
+		},
+	}
+
+	pct, reset := q.FamilyRollup("claude")
+	if pct != 30 {
+		t.Errorf("expected lowest percent 30, got %v", pct)
+	}
+	if !reset.Equal(early) {
+		t.Errorf("expected reset time of lowest model")
+	}
+
+	if pct, _ := q.FamilyRollup("gemini"); pct != -1 {
+		t.Errorf("expected -1 for missing family, got %v", pct)
+	}
+}
+
+func TestQuotaInfo_AccountStatusRoundTrip(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	q := &QuotaInfo{
+		AccountEmail:     "test@example.com",
+		LastUpdated:      now,
+		SubscriptionTier: "PRO",
+		TotalRemaining:   120,
+		TotalLimit:       200,
+		ModelQuotas: []ModelQuota{
+			{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 20, ResetTime: now.Add(time.Hour)},
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 100},
+		},
+	}
+
+	status := q.ToAccountStatus()
+	if status.ClaudeQuota != 20 || status.ClaudeResetSec != 3600 {
+		t.Errorf("unexpected claude status: %v / %d", status.ClaudeQuota, status.ClaudeResetSec)
+	}
+	if status.GeminiQuota != -1 {
+		t.Errorf("expected -1 for missing gemini family, got %v", status.GeminiQuota)
+	}
+	if status.TotalQuota != 60 {
+		t.Errorf("expected total 60%%, got %v", status.TotalQuota)
+	}
+
+	snapshot := q.ToSnapshot()
+	if snapshot.ClaudeQuota != 20 || !snapshot.Timestamp.Equal(now) {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+
+	restored := status.ToQuotaInfo()
+	if restored == nil || !restored.Cached {
+		t.Fatalf("expected cached quota info, got %+v", restored)
+	}
+	if len(restored.ModelQuotas) != 2 || restored.ModelQuotas[1].ModelID != "claude-sonnet" {
+		t.Fatalf("expected stored per-model quotas, got %+v", restored.ModelQuotas)
+	}
+	if restored.TotalRemaining != 120 || restored.TotalLimit != 200 {
+		t.Errorf("unexpected totals: %d / %d", restored.TotalRemaining, restored.TotalLimit)
+	}
+
+	// Rows written before per-model quotas were stored only hold rollups.
+	status.ModelQuotas = nil
+	restored = status.ToQuotaInfo()
+	if restored == nil || len(restored.ModelQuotas) != 1 || restored.ModelQuotas[0].ModelFamily != "claude" {
+		t.Fatalf("expected single claude rollup, got %+v", restored)
+	}
+	if !restored.ModelQuotas[0].ResetTime.Equal(now.Add(time.Hour)) {
+		t.Errorf("unexpected reset time: %v", restored.ModelQuotas[0].ResetTime)
+	}
+}
+
+func TestAccountStatus_ToQuotaInfo_Empty(t *testing.T) {
+	status := &AccountStatus{Email: "x@example.com", ClaudeQuota: -1, GeminiQuota: -1}
+	if qi := status.ToQuotaInfo(); qi != nil {
+		t.Errorf("expected nil for empty status, got %+v", qi)
+	}
+
+	status.LastError = "token revoked"
+	qi := status.ToQuotaInfo()
+	if qi == nil || qi.Error != "token revoked" {
+		t.Errorf("expected error-only quota info, got %+v", qi)
+	}
+}
+
+func TestQuotaInfo_DataAge(t *testing.T) {
+	now := time.Now()
+	q := &QuotaInfo{LastUpdated: now.Add(-10 * time.Minute)}
+	if got := q.DataAge(now); got != 10*time.Minute {
+		t.Errorf("DataAge() = %v, want 10m", got)
+	}
+	if q.IsStale() {
+		t.Error("fresh quota should not be stale")
+	}
+
+	q.Degraded = DegradedNetwork
+	if !q.IsStale() {
+		t.Error("degraded quota should be stale")
+	}
+
+	empty := &QuotaInfo{}
+	if got := empty.DataAge(now); got != 0 {
+		t.Errorf("DataAge() with zero time = %v, want 0", got)
+	}
+}
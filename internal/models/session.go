@@ -0,0 +1,41 @@
+// Package models defines data structures and domain types.
+package models
+
+import "time"
+
+// SessionState is the lifecycle state of a quota session. A session spans
+// one quota window, from a reset until the next reset.
+type SessionState string
+
+const (
+	// SessionStarted indicates the session is active and has quota left.
+	SessionStarted SessionState = "started"
+	// SessionRateLimited indicates some models are rate limited or empty.
+	SessionRateLimited SessionState = "rate_limited"
+	// SessionExhausted indicates every model family is out of quota.
+	SessionExhausted SessionState = "exhausted"
+	// SessionReset indicates the quota was reset and the session ended.
+	SessionReset SessionState = "reset"
+	// SessionAbandoned indicates the session ended without being observed,
+	// e.g. because the dashboard was not running.
+	SessionAbandoned SessionState = "abandoned"
+)
+
+// IsTerminal reports whether no further transitions follow the state.
+func (s SessionState) IsTerminal() bool {
+	return s == SessionReset || s == SessionAbandoned
+}
+
+// SessionEvent is one recorded session state transition.
+type SessionEvent struct {
+	Timestamp     time.Time
+	ResetTime     time.Time
+	SessionID     string
+	Email         string
+	From          SessionState
+	State         SessionState
+	Reason        string
+	ClaudePercent float64
+	GeminiPercent float64
+	ID            int64
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OptionalTime returns nil for the zero time, so it is omitted from JSON
+// fields tagged omitempty.
+func OptionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
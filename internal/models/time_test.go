@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptionalTime(t *testing.T) {
+	if got := OptionalTime(time.Time{}); got != nil {
+		t.Errorf("OptionalTime(zero) = %v, want nil", got)
+	}
+	now := time.Now()
+	if got := OptionalTime(now); got == nil || !got.Equal(now) {
+		t.Errorf("OptionalTime(now) = %v, want %v", got, now)
+	}
+}
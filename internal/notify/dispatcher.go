@@ -0,0 +1,294 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// Queue persists payloads that could not be delivered. *db.DB implements it.
+type Queue interface {
+	EnqueueNotification(n *models.QueuedNotification) error
+	GetDueNotifications(now time.Time, limit int) ([]models.QueuedNotification, error)
+	RescheduleNotification(id int64, attempts int, nextAttempt time.Time, lastError string) error
+	DeleteQueuedNotification(id int64) error
+}
+
+const (
+	// defaultRetries is the number of immediate delivery attempts before a
+	// payload is queued.
+	defaultRetries = 3
+	// defaultBackoff is the wait before the first retry; it doubles after
+	// each attempt.
+	defaultBackoff = 2 * time.Second
+	// flushInterval is how often the queue is checked for due payloads.
+	flushInterval = 30 * time.Second
+	// flushBatch caps the payloads tried per flush.
+	flushBatch = 50
+	// maxQueueBackoff caps the wait between attempts of a queued payload.
+	maxQueueBackoff = 30 * time.Minute
+	// maxQueueAge is how long a payload is retried before it is dropped.
+	maxQueueAge = 24 * time.Hour
+)
+
+// Result is the outcome of a test delivery to one notifier.
+type Result struct {
+	Err  error
+	Name string
+}
+
+// Dispatcher sends messages to notifiers. Webhook deliveries are retried
+// with exponential backoff and queued in the database when the endpoint
+// stays unreachable; the queue is flushed in the background after Start.
+type Dispatcher struct {
+	ctx       context.Context
+	queue     Queue
+	sinks     map[string]Notifier
+	stopChan  chan struct{}
+	now       func() time.Time
+	names     []string
+	retries   int
+	backoff   time.Duration
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// NewDispatcher creates a dispatcher for notifiers. queue may be nil, in
+// which case undeliverable payloads are dropped. Cancelling ctx aborts
+// in-flight deliveries; those that were retrying are queued.
+func NewDispatcher(ctx context.Context, notifiers []Notifier, queue Queue) *Dispatcher {
+	d := &Dispatcher{
+		ctx:      ctx,
+		queue:    queue,
+		sinks:    make(map[string]Notifier, len(notifiers)),
+		stopChan: make(chan struct{}),
+		now:      time.Now,
+		retries:  defaultRetries,
+		backoff:  defaultBackoff,
+	}
+	for _, n := range notifiers {
+		d.sinks[n.Name()] = n
+		d.names = append(d.names, n.Name())
+	}
+	return d
+}
+
+// Names returns the notifier names in configuration order.
+func (d *Dispatcher) Names() []string {
+	return append([]string(nil), d.names...)
+}
+
+// Send delivers msg to the named notifiers, or to all of them when names is
+// empty. It does not block; failures are logged.
+func (d *Dispatcher) Send(msg Message, names []string) {
+	for _, n := range d.targets(names) {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.send(n, msg)
+		}()
+	}
+}
+
+// Test delivers msg once to the named notifiers, or all of them, and
+// reports each outcome. Nothing is retried or queued.
+func (d *Dispatcher) Test(msg Message, names []string) []Result {
+	targets := d.targets(names)
+	results := make([]Result, len(targets))
+	for i, n := range targets {
+		results[i] = Result{Name: n.Name(), Err: n.Notify(d.ctx, msg)}
+	}
+	return results
+}
+
+// Start flushes the queue now and then periodically until Close.
+func (d *Dispatcher) Start() {
+	if d.queue == nil {
+		return
+	}
+	d.startOnce.Do(func() {
+		d.wg.Add(1)
+		go d.run()
+	})
+}
+
+// Close stops the background flush and waits for in-flight deliveries.
+// Deliveries waiting to retry are queued instead.
+func (d *Dispatcher) Close() {
+	d.closeOnce.Do(func() {
+		close(d.stopChan)
+	})
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) targets(names []string) []Notifier {
+	if len(names) == 0 {
+		names = d.names
+	}
+	targets := make([]Notifier, 0, len(names))
+	for _, name := range names {
+		if n, ok := d.sinks[name]; ok {
+			targets = append(targets, n)
+		} else {
+			logger.Warn("unknown notifier", "name", name)
+		}
+	}
+	return targets
+}
+
+func (d *Dispatcher) send(n Notifier, msg Message) {
+	pn, ok := n.(PayloadNotifier)
+	if !ok {
+		if err := n.Notify(d.ctx, msg); err != nil {
+			logger.Error("failed to send notification", "notifier", n.Name(), "error", err)
+		}
+		return
+	}
+
+	payload, err := pn.Render(msg)
+	if err != nil {
+		logger.Error("failed to render notification", "notifier", n.Name(), "error", err)
+		return
+	}
+
+	wait := d.backoff
+	for attempt := 1; ; attempt++ {
+		err = pn.Deliver(d.ctx, payload)
+		if err == nil {
+			return
+		}
+		if !Retryable(err) {
+			logger.Error("notification rejected", "notifier", n.Name(), "error", err)
+			return
+		}
+		if attempt >= d.retries || !d.sleep(wait) {
+			break
+		}
+		wait *= 2
+	}
+
+	logger.Warn("notification delivery failed, queueing", "notifier", n.Name(), "error", err)
+	d.enqueue(n.Name(), payload, err)
+}
+
+// sleep waits before a retry and reports false if the dispatcher is closed
+// or its context done first.
+func (d *Dispatcher) sleep(wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.stopChan:
+		return false
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+func (d *Dispatcher) enqueue(name string, payload []byte, lastErr error) {
+	if d.queue == nil {
+		return
+	}
+	now := d.now()
+	n := &models.QueuedNotification{
+		Sink:        name,
+		Payload:     payload,
+		Attempts:    d.retries,
+		LastError:   lastErr.Error(),
+		CreatedAt:   now,
+		NextAttempt: now.Add(queueBackoff(0)),
+	}
+	if err := d.queue.EnqueueNotification(n); err != nil {
+		logger.Error("failed to queue notification", "notifier", name, "error", err)
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	d.flush()
+	for {
+		select {
+		case <-ticker.C:
+			d.flush()
+		case <-d.stopChan:
+			return
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+// flush retries the queued payloads that are due. After a retryable failure
+// the remaining payloads of the same notifier wait for the next flush.
+func (d *Dispatcher) flush() {
+	now := d.now()
+	queued, err := d.queue.GetDueNotifications(now, flushBatch)
+	if err != nil {
+		logger.Error("failed to read notification queue", "error", err)
+		return
+	}
+
+	down := make(map[string]bool)
+	for i := range queued {
+		q := &queued[i]
+		n, ok := d.sinks[q.Sink].(PayloadNotifier)
+		switch {
+		case !ok:
+			logger.Warn("dropping queued notification for removed notifier", "notifier", q.Sink)
+			d.remove(q.ID)
+			continue
+		case now.Sub(q.CreatedAt) > maxQueueAge:
+			logger.Warn("dropping expired notification", "notifier", q.Sink, "attempts", q.Attempts, "error", q.LastError)
+			d.remove(q.ID)
+			continue
+		case down[q.Sink]:
+			continue
+		}
+
+		err := n.Deliver(d.ctx, q.Payload)
+		if d.ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err == nil:
+			d.remove(q.ID)
+		case !Retryable(err):
+			logger.Error("queued notification rejected", "notifier", q.Sink, "error", err)
+			d.remove(q.ID)
+		default:
+			down[q.Sink] = true
+			attempts := q.Attempts + 1
+			next := now.Add(queueBackoff(attempts - d.retries))
+			if err := d.queue.RescheduleNotification(q.ID, attempts, next, err.Error()); err != nil {
+				logger.Error("failed to reschedule notification", "error", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) remove(id int64) {
+	if err := d.queue.DeleteQueuedNotification(id); err != nil {
+		logger.Error("failed to remove queued notification", "error", err)
+	}
+}
+
+// queueBackoff returns the wait before the n-th retry from the queue: one
+// minute, doubling up to maxQueueBackoff.
+func queueBackoff(n int) time.Duration {
+	if n < 0 {
+		n = 0
+	}
+	if n > 10 {
+		return maxQueueBackoff
+	}
+	return min(time.Minute<<n, maxQueueBackoff)
+}
@@ -0,0 +1,217 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// fakeSink is a PayloadNotifier that fails a configured number of times.
+type fakeSink struct {
+	err       error
+	delivered []string
+	name      string
+	attempts  int
+	failures  int
+	mu        sync.Mutex
+}
+
+func (f *fakeSink) Name() string { return f.name }
+
+func (f *fakeSink) Notify(ctx context.Context, msg Message) error {
+	payload, _ := f.Render(msg)
+	return f.Deliver(ctx, payload)
+}
+
+func (f *fakeSink) Render(msg Message) ([]byte, error) { return []byte(msg.Title), nil }
+
+func (f *fakeSink) Deliver(_ context.Context, payload []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.attempts <= f.failures {
+		return f.err
+	}
+	f.delivered = append(f.delivered, string(payload))
+	return nil
+}
+
+// memQueue keeps queued notifications in memory.
+type memQueue struct {
+	items  map[int64]models.QueuedNotification
+	nextID int64
+	mu     sync.Mutex
+}
+
+func (q *memQueue) EnqueueNotification(n *models.QueuedNotification) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.items == nil {
+		q.items = make(map[int64]models.QueuedNotification)
+	}
+	q.nextID++
+	n.ID = q.nextID
+	q.items[n.ID] = *n
+	return nil
+}
+
+func (q *memQueue) GetDueNotifications(now time.Time, limit int) ([]models.QueuedNotification, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []models.QueuedNotification
+	for id := int64(1); id <= q.nextID && len(due) < limit; id++ {
+		if n, ok := q.items[id]; ok && !n.NextAttempt.After(now) {
+			due = append(due, n)
+		}
+	}
+	return due, nil
+}
+
+func (q *memQueue) RescheduleNotification(id int64, attempts int, next time.Time, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := q.items[id]
+	n.Attempts, n.NextAttempt, n.LastError = attempts, next, lastError
+	q.items[id] = n
+	return nil
+}
+
+func (q *memQueue) DeleteQueuedNotification(id int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.items, id)
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, queue Queue, sinks ...Notifier) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(t.Context(), sinks, queue)
+	d.backoff = time.Millisecond
+	return d
+}
+
+func TestDispatcher_RetriesThenDelivers(t *testing.T) {
+	sink := &fakeSink{name: "team", failures: 2, err: errors.New("connection refused")}
+	queue := &memQueue{}
+	d := newTestDispatcher(t, queue, sink)
+
+	d.Send(Message{Title: "low"}, nil)
+	d.wg.Wait()
+
+	if sink.attempts != 3 || len(sink.delivered) != 1 {
+		t.Errorf("attempts = %d, delivered = %v; want delivery on the third attempt", sink.attempts, sink.delivered)
+	}
+	if len(queue.items) != 0 {
+		t.Errorf("delivered notification was queued: %+v", queue.items)
+	}
+}
+
+func TestDispatcher_QueuesAndFlushes(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sink := &fakeSink{name: "team", failures: 7, err: errors.New("network unreachable")}
+	queue := &memQueue{}
+	d := newTestDispatcher(t, queue, sink)
+	d.now = func() time.Time { return now }
+
+	d.Send(Message{Title: "first"}, []string{"team"})
+	d.Send(Message{Title: "second"}, []string{"team"})
+	d.wg.Wait()
+	if len(queue.items) != 2 {
+		t.Fatalf("queued %d notifications, want 2", len(queue.items))
+	}
+
+	// Not due yet.
+	d.flush()
+	if sink.attempts != 6 {
+		t.Fatalf("attempts = %d, flush should wait for the next attempt", sink.attempts)
+	}
+
+	// The sink is still down: one attempt, and the other payload waits.
+	now = now.Add(time.Minute)
+	d.flush()
+	if sink.attempts != 7 || len(queue.items) != 2 {
+		t.Fatalf("attempts = %d, queued = %d", sink.attempts, len(queue.items))
+	}
+	first := queue.items[1]
+	if first.Attempts != defaultRetries+1 || !first.NextAttempt.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("rescheduled = %+v, want the backoff to double", first)
+	}
+
+	now = now.Add(2 * time.Minute)
+	d.flush()
+	if len(queue.items) != 0 || len(sink.delivered) != 2 {
+		t.Errorf("queued = %+v, delivered = %v; want both delivered", queue.items, sink.delivered)
+	}
+}
+
+func TestDispatcher_CloseQueuesPendingRetries(t *testing.T) {
+	sink := &fakeSink{name: "team", failures: 10, err: errors.New("timeout")}
+	queue := &memQueue{}
+	d := newTestDispatcher(t, queue, sink)
+	d.backoff = time.Hour
+
+	d.Send(Message{Title: "low"}, nil)
+	d.Close()
+	if sink.attempts != 1 || len(queue.items) != 1 {
+		t.Errorf("attempts = %d, queued = %d; Close should queue instead of waiting", sink.attempts, len(queue.items))
+	}
+}
+
+func TestDispatcher_CancelQueuesPendingRetries(t *testing.T) {
+	sink := &fakeSink{name: "team", failures: 10, err: errors.New("timeout")}
+	queue := &memQueue{}
+	ctx, cancel := context.WithCancel(t.Context())
+	d := NewDispatcher(ctx, []Notifier{sink}, queue)
+	d.backoff = time.Hour
+
+	d.Send(Message{Title: "low"}, nil)
+	cancel()
+	d.wg.Wait()
+	if sink.attempts != 1 || len(queue.items) != 1 {
+		t.Errorf("attempts = %d, queued = %d; a cancelled context should queue instead of waiting", sink.attempts, len(queue.items))
+	}
+}
+
+func TestDispatcher_DropsRejectedAndExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rejected := &fakeSink{name: "rejected", failures: 10, err: &StatusError{Code: 400}}
+	queue := &memQueue{}
+	d := newTestDispatcher(t, queue, rejected)
+	d.now = func() time.Time { return now }
+
+	d.Send(Message{Title: "bad"}, nil)
+	d.wg.Wait()
+	if rejected.attempts != 1 || len(queue.items) != 0 {
+		t.Errorf("attempts = %d, queued = %d; a rejected payload must not be retried", rejected.attempts, len(queue.items))
+	}
+
+	_ = queue.EnqueueNotification(&models.QueuedNotification{Sink: "removed", CreatedAt: now, NextAttempt: now})
+	_ = queue.EnqueueNotification(&models.QueuedNotification{Sink: "rejected", CreatedAt: now.Add(-25 * time.Hour), NextAttempt: now})
+	d.flush()
+	if len(queue.items) != 0 || rejected.attempts != 1 {
+		t.Errorf("queued = %+v; removed and expired notifications should be dropped unsent", queue.items)
+	}
+}
+
+func TestDispatcher_Test(t *testing.T) {
+	ok := &fakeSink{name: "ok"}
+	down := &fakeSink{name: "down", failures: 5, err: errors.New("timeout")}
+	queue := &memQueue{}
+	d := newTestDispatcher(t, queue, ok, down)
+
+	results := d.Test(Message{Title: "test"}, nil)
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("results = %+v", results)
+	}
+	if down.attempts != 1 || len(queue.items) != 0 {
+		t.Errorf("test deliveries must not be retried or queued")
+	}
+
+	if results := d.Test(Message{}, []string{"ok"}); len(results) != 1 || results[0].Name != "ok" {
+		t.Errorf("results = %+v, want only the named notifier", results)
+	}
+}
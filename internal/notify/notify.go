@@ -0,0 +1,158 @@
+// Package notify delivers alert notifications to the desktop and to webhooks
+// such as Slack or Discord, retrying and queueing webhook deliveries that
+// fail.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gen2brain/beeep"
+)
+
+// Sink types.
+const (
+	TypeDesktop = "desktop"
+	TypeWebhook = "webhook"
+)
+
+// DefaultSink is the name of the desktop notifier used when no notifiers
+// are configured.
+const DefaultSink = "desktop"
+
+// Message is a notification to deliver.
+type Message struct {
+	At       time.Time `json:"at"`
+	RuleID   string    `json:"rule"`
+	Kind     string    `json:"kind"`
+	Severity string    `json:"severity"`
+	Email    string    `json:"email,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+}
+
+// Notifier delivers messages to one destination.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// PayloadNotifier is a Notifier whose messages are rendered to a payload
+// first. Payloads that cannot be delivered are queued and retried later.
+type PayloadNotifier interface {
+	Notifier
+	Render(msg Message) ([]byte, error)
+	Deliver(ctx context.Context, payload []byte) error
+}
+
+// Config configures one notifier.
+type Config struct {
+	// Headers are added to webhook requests; values may reference
+	// environment variables as $VAR or ${VAR}.
+	Headers map[string]string `json:"headers,omitempty"`
+	Name    string            `json:"name"`
+	Type    string            `json:"type"`
+	// URL is the webhook endpoint and may reference environment variables.
+	URL string `json:"url,omitempty"`
+	// Preset selects a built-in payload: generic, slack or discord.
+	Preset string `json:"preset,omitempty"`
+	// Template is a Go template producing the JSON payload; it overrides
+	// Preset.
+	Template string `json:"template,omitempty"`
+}
+
+// Validate checks notifier configurations without building them.
+func Validate(configs []Config) error {
+	_, err := Build(configs)
+	return err
+}
+
+// Build creates the notifiers for configs. Without configs a single desktop
+// notifier named DefaultSink is returned.
+func Build(configs []Config) ([]Notifier, error) {
+	if len(configs) == 0 {
+		return []Notifier{Desktop{name: DefaultSink}}, nil
+	}
+
+	seen := make(map[string]bool, len(configs))
+	notifiers := make([]Notifier, 0, len(configs))
+	for i := range configs {
+		c := &configs[i]
+		if c.Name == "" {
+			return nil, fmt.Errorf("notifier %d: missing name", i+1)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("notifier %q: duplicate name", c.Name)
+		}
+		seen[c.Name] = true
+
+		switch c.Type {
+		case TypeDesktop:
+			notifiers = append(notifiers, Desktop{name: c.Name})
+		case TypeWebhook:
+			w, err := NewWebhook(c)
+			if err != nil {
+				return nil, fmt.Errorf("notifier %q: %w", c.Name, err)
+			}
+			notifiers = append(notifiers, w)
+		default:
+			return nil, fmt.Errorf("notifier %q: unknown type %q", c.Name, c.Type)
+		}
+	}
+	return notifiers, nil
+}
+
+// Names returns the names of the notifiers configs builds.
+func Names(configs []Config) []string {
+	if len(configs) == 0 {
+		return []string{DefaultSink}
+	}
+	names := make([]string, len(configs))
+	for i := range configs {
+		names[i] = configs[i].Name
+	}
+	return names
+}
+
+// Desktop shows messages as desktop notifications.
+type Desktop struct {
+	name string
+}
+
+// Name returns the notifier name.
+func (d Desktop) Name() string { return d.name }
+
+// Notify shows the message.
+func (d Desktop) Notify(_ context.Context, msg Message) error {
+	if err := beeep.Notify(msg.Title, msg.Body, ""); err != nil {
+		return fmt.Errorf("failed to send desktop notification: %w", err)
+	}
+	return nil
+}
+
+// StatusError is returned when a webhook answers with a non-2xx status.
+type StatusError struct {
+	Body string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("webhook returned status %d", e.Code)
+	}
+	return fmt.Sprintf("webhook returned status %d: %s", e.Code, e.Body)
+}
+
+// Retryable reports whether a failed delivery may succeed later. Requests
+// the endpoint rejected, other than timeouts and rate limits, are not
+// retried.
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= 500 || statusErr.Code == 408 || statusErr.Code == 429
+	}
+	return err != nil
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	notifiers, err := Build(nil)
+	if err != nil || len(notifiers) != 1 || notifiers[0].Name() != DefaultSink {
+		t.Fatalf("Build(nil) = %v, %v; want the desktop notifier", notifiers, err)
+	}
+
+	notifiers, err = Build([]Config{
+		{Name: "desk", Type: TypeDesktop},
+		{Name: "team", Type: TypeWebhook, URL: "https://hooks.slack.com/services/x", Preset: PresetSlack},
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, ok := notifiers[1].(PayloadNotifier); !ok {
+		t.Error("webhook should be a PayloadNotifier")
+	}
+
+	invalid := map[string][]Config{
+		"missing name":   {{Type: TypeDesktop}},
+		"duplicate name": {{Name: "a", Type: TypeDesktop}, {Name: "a", Type: TypeDesktop}},
+		"unknown type":   {{Name: "a", Type: "pager"}},
+		"missing url":    {{Name: "a", Type: TypeWebhook}},
+		"bad scheme":     {{Name: "a", Type: TypeWebhook, URL: "ftp://example.com"}},
+		"unknown preset": {{Name: "a", Type: TypeWebhook, URL: "https://example.com", Preset: "teams"}},
+		"bad template":   {{Name: "a", Type: TypeWebhook, URL: "https://example.com", Template: "{{.Title"}},
+	}
+	for name, configs := range invalid {
+		if err := Validate(configs); err == nil {
+			t.Errorf("%s: Validate() = nil, want error", name)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("connection refused"), true},
+		{&StatusError{Code: 500}, true},
+		{&StatusError{Code: 429}, true},
+		{fmt.Errorf("wrapped: %w", &StatusError{Code: 503}), true},
+		{&StatusError{Code: 404}, false},
+		{&StatusError{Code: 400}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
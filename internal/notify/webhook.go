@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Webhook presets.
+const (
+	PresetGeneric = "generic"
+	PresetSlack   = "slack"
+	PresetDiscord = "discord"
+)
+
+// presets are the built-in payload templates. Slack and Discord incoming
+// webhooks both accept a single markdown text field.
+var presets = map[string]string{
+	PresetGeneric: `{"title":{{json .Title}},"body":{{json .Body}},"severity":{{json .Severity}},` +
+		`"rule":{{json .RuleID}},"kind":{{json .Kind}},"email":{{json .Email}},"model":{{json .Model}},"at":{{json .At}}}`,
+	PresetSlack:   `{"text":{{json (printf "*%s*\n%s" .Title .Body)}}}`,
+	PresetDiscord: `{"content":{{json (printf "**%s**\n%s" .Title .Body)}}}`,
+}
+
+// webhookTimeout bounds a single delivery attempt.
+const webhookTimeout = 10 * time.Second
+
+// Webhook posts messages as JSON to an HTTP endpoint.
+type Webhook struct {
+	client  *http.Client
+	tmpl    *template.Template
+	headers map[string]string
+	name    string
+	url     string
+}
+
+// NewWebhook creates a webhook notifier from its configuration.
+func NewWebhook(c *Config) (*Webhook, error) {
+	url := os.ExpandEnv(c.URL)
+	if url == "" {
+		return nil, errors.New("missing url")
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("url must be http or https, got %q", url)
+	}
+
+	text := c.Template
+	if text == "" {
+		preset := c.Preset
+		if preset == "" {
+			preset = PresetGeneric
+		}
+		var ok bool
+		if text, ok = presets[preset]; !ok {
+			return nil, fmt.Errorf("unknown preset %q (want generic, slack or discord)", preset)
+		}
+	}
+	tmpl, err := template.New(c.Name).Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	headers := make(map[string]string, len(c.Headers))
+	for k, v := range c.Headers {
+		headers[k] = os.ExpandEnv(v)
+	}
+
+	return &Webhook{
+		client:  &http.Client{Timeout: webhookTimeout},
+		tmpl:    tmpl,
+		headers: headers,
+		name:    c.Name,
+		url:     url,
+	}, nil
+}
+
+// Name returns the notifier name.
+func (w *Webhook) Name() string { return w.name }
+
+// Notify renders and posts the message once.
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	payload, err := w.Render(msg)
+	if err != nil {
+		return err
+	}
+	return w.Deliver(ctx, payload)
+}
+
+// Render executes the payload template and checks the result is JSON.
+func (w *Webhook) Render(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.tmpl.Execute(&buf, msg); err != nil {
+		return nil, fmt.Errorf("failed to render webhook payload: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template %q did not produce valid JSON", w.name)
+	}
+	return buf.Bytes(), nil
+}
+
+// Deliver posts a rendered payload. Cancelling ctx aborts the request.
+func (w *Webhook) Deliver(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{Code: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// toJSON encodes v for use inside a JSON template.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMessage() Message {
+	return Message{
+		At:       time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		RuleID:   "quota-low",
+		Kind:     "quota_below",
+		Severity: "warning",
+		Email:    "a@example.com",
+		Model:    "claude-sonnet-4-5",
+		Title:    `Low quota: "a@example.com"`,
+		Body:     "Claude Sonnet is at 4.0%\nbelow 5%",
+	}
+}
+
+func TestWebhook_Presets(t *testing.T) {
+	tests := []struct {
+		preset string
+		field  string
+		want   string
+	}{
+		{PresetGeneric, "title", `Low quota: "a@example.com"`},
+		{PresetSlack, "text", "*Low quota: \"a@example.com\"*\nClaude Sonnet is at 4.0%\nbelow 5%"},
+		{PresetDiscord, "content", "**Low quota: \"a@example.com\"**\nClaude Sonnet is at 4.0%\nbelow 5%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			w, err := NewWebhook(&Config{Name: "hook", URL: "https://example.com", Preset: tt.preset})
+			if err != nil {
+				t.Fatal(err)
+			}
+			payload, err := w.Render(testMessage())
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(payload, &got); err != nil {
+				t.Fatalf("payload is not JSON: %v\n%s", err, payload)
+			}
+			if got[tt.field] != tt.want {
+				t.Errorf("%s = %q, want %q", tt.field, got[tt.field], tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhook_CustomTemplate(t *testing.T) {
+	w, err := NewWebhook(&Config{Name: "hook", URL: "https://example.com", Template: `{"msg": {{json .Title}}, "sev": "{{.Severity}}"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Render(testMessage()); err != nil {
+		t.Errorf("Render() error = %v", err)
+	}
+
+	broken, err := NewWebhook(&Config{Name: "hook", URL: "https://example.com", Template: `{"msg": "{{.Title}}"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broken.Render(testMessage()); err == nil {
+		t.Error("Render() should reject a template that produces invalid JSON")
+	}
+}
+
+func TestWebhook_Deliver(t *testing.T) {
+	var gotBody, gotAuth, gotType string
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("channel_not_found"))
+	}))
+	defer srv.Close()
+
+	t.Setenv("ADT_TEST_WEBHOOK_URL", srv.URL)
+	t.Setenv("ADT_TEST_TOKEN", "secret")
+	w, err := NewWebhook(&Config{
+		Name:    "hook",
+		URL:     "${ADT_TEST_WEBHOOK_URL}/hook",
+		Preset:  PresetSlack,
+		Headers: map[string]string{"Authorization": "Bearer $ADT_TEST_TOKEN"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Notify(t.Context(), testMessage()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if !strings.HasPrefix(gotBody, `{"text":`) || gotAuth != "Bearer secret" || gotType != "application/json" {
+		t.Errorf("request body=%q auth=%q type=%q", gotBody, gotAuth, gotType)
+	}
+
+	status = http.StatusNotFound
+	err = w.Deliver(t.Context(), []byte(`{}`))
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound || statusErr.Body != "channel_not_found" {
+		t.Errorf("Deliver() error = %v, want a 404 StatusError", err)
+	}
+}
+
+func TestWebhook_DeliverCancelled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	w, err := NewWebhook(&Config{Name: "hook", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = w.Deliver(ctx, []byte(`{}`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Deliver() error = %v, want the context's error", err)
+	}
+	if elapsed := time.Since(start); elapsed >= webhookTimeout {
+		t.Errorf("Deliver() took %v, want it to stop with the context", elapsed)
+	}
+}
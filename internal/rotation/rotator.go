@@ -0,0 +1,452 @@
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// undoWindow is how long after a switch Undo switches back.
+const undoWindow = time.Minute
+
+// ErrNothingToUndo is returned by Undo when no rotation is pending or recent.
+var ErrNothingToUndo = errors.New("no rotation to undo")
+
+// Config configures a Rotator.
+type Config struct {
+	Strategy Strategy
+	// Model is a glob matched against model IDs and families. Only matching
+	// models trigger a rotation and count when accounts are compared; empty
+	// matches all.
+	Model string
+	// Delay is the countdown between deciding to rotate and switching, during
+	// which the rotation can be undone. Zero switches immediately.
+	Delay time.Duration
+}
+
+// Accounts reads and switches the active account. *accounts.Service
+// implements it.
+type Accounts interface {
+	GetAccounts() []models.Account
+	GetActiveAccount() *models.Account
+	SetActiveAccount(idOrEmail string) error
+}
+
+// EventType describes what happened to a rotation.
+type EventType string
+
+const (
+	// EventScheduled fires when a rotation starts counting down.
+	EventScheduled EventType = "scheduled"
+	// EventCancelled fires when a pending rotation is undone or no longer
+	// needed.
+	EventCancelled EventType = "cancelled"
+	// EventSwitched fires after the active account was switched.
+	EventSwitched EventType = "switched"
+	// EventReverted fires after a switch was undone.
+	EventReverted EventType = "reverted"
+	// EventFailed fires when the accounts file could not be updated.
+	EventFailed EventType = "failed"
+)
+
+// Rotation is a switch of the active account.
+type Rotation struct {
+	// Deadline is when a pending rotation switches.
+	Deadline time.Time
+	// SwitchedAt is when the switch happened; zero while pending.
+	SwitchedAt time.Time
+	From       string
+	To         string
+	Reason     string
+	Strategy   string
+}
+
+// Event reports a change of a rotation.
+type Event struct {
+	Error    error
+	Type     EventType
+	Rotation Rotation
+}
+
+// Rotator watches the active account and switches to another account when
+// it becomes rate limited or its projection turns CRITICAL. It is safe for
+// concurrent use.
+type Rotator struct {
+	accounts Accounts
+	strategy Strategy
+	notify   func(Event)
+	now      func() time.Time
+	quotas   map[string]*models.QuotaInfo
+	// critical holds the reason of accounts with a CRITICAL projection.
+	critical map[string]string
+	// suppressed holds accounts a rotation was undone for; they are not
+	// rotated away from again until they recover.
+	suppressed map[string]bool
+	// stuck holds accounts that needed a rotation but had no alternative, so
+	// the warning is logged once.
+	stuck   map[string]bool
+	pending *Rotation
+	last    *Rotation
+	timer   *time.Timer
+	model   string
+	delay   time.Duration
+	seq     int
+	mu      sync.Mutex
+}
+
+// New creates a rotator that switches accounts and reports every change to
+// notify, which may be nil.
+func New(cfg Config, accounts Accounts, notify func(Event)) (*Rotator, error) {
+	if _, err := path.Match(cfg.Model, ""); err != nil {
+		return nil, fmt.Errorf("invalid model pattern %q: %w", cfg.Model, err)
+	}
+	strategy := cfg.Strategy
+	if strategy == nil {
+		strategy = MostRemaining{}
+	}
+	return &Rotator{
+		accounts:   accounts,
+		strategy:   strategy,
+		notify:     notify,
+		now:        time.Now,
+		quotas:     make(map[string]*models.QuotaInfo),
+		critical:   make(map[string]string),
+		suppressed: make(map[string]bool),
+		stuck:      make(map[string]bool),
+		model:      cfg.Model,
+		delay:      max(cfg.Delay, 0),
+	}, nil
+}
+
+// Strategy returns the name of the strategy in use.
+func (r *Rotator) Strategy() string {
+	return r.strategy.Name()
+}
+
+// Pending returns the rotation that is counting down, or nil.
+func (r *Rotator) Pending() *Rotation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		return nil
+	}
+	rot := *r.pending
+	return &rot
+}
+
+// ObserveQuota records a quota refresh and rotates away from the active
+// account if it is rate limited.
+func (r *Rotator) ObserveQuota(qi *models.QuotaInfo) {
+	if qi == nil {
+		return
+	}
+	r.mu.Lock()
+	r.quotas[qi.AccountEmail] = qi
+	r.mu.Unlock()
+
+	if !qi.IsStale() {
+		r.evaluate(qi.AccountEmail)
+	}
+}
+
+// ObserveProjection records a projection and rotates away from the active
+// account if it is CRITICAL.
+func (r *Rotator) ObserveProjection(ap *models.AccountProjection) {
+	if ap == nil {
+		return
+	}
+	reason := ""
+	for _, mp := range projections(ap) {
+		if mp.Status == models.ProjectionCritical && r.watches(mp.Model, mp.Family) {
+			reason = fmt.Sprintf("%s projection is CRITICAL", projectionName(mp))
+			break
+		}
+	}
+
+	r.mu.Lock()
+	if reason == "" {
+		delete(r.critical, ap.Email)
+	} else {
+		r.critical[ap.Email] = reason
+	}
+	r.mu.Unlock()
+
+	r.evaluate(ap.Email)
+}
+
+// ObserveAccounts cancels a pending rotation once the active account was
+// switched by someone else.
+func (r *Rotator) ObserveAccounts() {
+	active := r.accounts.GetActiveAccount()
+
+	r.mu.Lock()
+	if r.pending == nil || (active != nil && active.Email == r.pending.From) {
+		r.mu.Unlock()
+		return
+	}
+	rot := r.cancelLocked()
+	r.mu.Unlock()
+
+	logger.Info("auto-rotation cancelled, active account changed", "from", rot.From, "to", rot.To)
+	r.emit(Event{Type: EventCancelled, Rotation: rot})
+}
+
+// Undo cancels the pending rotation or, within a minute of a switch,
+// switches back. The account is not rotated away from again until it
+// recovers.
+func (r *Rotator) Undo() error {
+	r.mu.Lock()
+	if r.pending != nil {
+		rot := r.cancelLocked()
+		r.suppressed[rot.From] = true
+		r.mu.Unlock()
+
+		logger.Info("auto-rotation cancelled by user", "from", rot.From, "to", rot.To)
+		r.emit(Event{Type: EventCancelled, Rotation: rot})
+		return nil
+	}
+
+	if r.last == nil || r.now().Sub(r.last.SwitchedAt) > undoWindow {
+		r.mu.Unlock()
+		return ErrNothingToUndo
+	}
+	rot := *r.last
+	r.last = nil
+	r.suppressed[rot.From] = true
+	r.mu.Unlock()
+
+	if err := r.accounts.SetActiveAccount(rot.From); err != nil {
+		logger.Error("failed to undo auto-rotation", "from", rot.From, "to", rot.To, "error", err)
+		r.emit(Event{Type: EventFailed, Rotation: rot, Error: err})
+		return fmt.Errorf("failed to switch back to %s: %w", rot.From, err)
+	}
+	logger.Info("auto-rotation undone", "active", rot.From, "from", rot.To)
+	r.emit(Event{Type: EventReverted, Rotation: rot})
+	return nil
+}
+
+// Close stops a pending rotation without switching.
+func (r *Rotator) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending != nil {
+		r.cancelLocked()
+	}
+}
+
+// evaluate schedules a rotation if email is the active account and needs
+// one, and cancels a pending rotation once it has recovered.
+func (r *Rotator) evaluate(email string) {
+	active := r.accounts.GetActiveAccount()
+	if active == nil || active.Email != email {
+		return
+	}
+	accs := r.accounts.GetAccounts()
+
+	r.mu.Lock()
+	reason := r.reasonLocked(email)
+	if reason == "" {
+		delete(r.suppressed, email)
+		delete(r.stuck, email)
+		if r.pending == nil || r.pending.From != email {
+			r.mu.Unlock()
+			return
+		}
+		rot := r.cancelLocked()
+		r.mu.Unlock()
+
+		logger.Info("auto-rotation cancelled, account recovered", "from", rot.From, "to", rot.To)
+		r.emit(Event{Type: EventCancelled, Rotation: rot})
+		return
+	}
+	if r.pending != nil || r.suppressed[email] {
+		r.mu.Unlock()
+		return
+	}
+
+	candidates, current := r.candidatesLocked(accs, email)
+	if len(candidates) == 0 {
+		first := !r.stuck[email]
+		r.stuck[email] = true
+		r.mu.Unlock()
+		if first {
+			logger.Warn("auto-rotation needed but no other account has quota", "email", email, "reason", reason)
+		}
+		return
+	}
+	delete(r.stuck, email)
+
+	target := r.strategy.Pick(current, candidates)
+	rot := Rotation{
+		From:     email,
+		To:       target.Email,
+		Reason:   reason,
+		Strategy: r.strategy.Name(),
+		Deadline: r.now().Add(r.delay),
+	}
+	r.seq++
+	seq := r.seq
+	r.pending = &rot
+	if r.delay > 0 {
+		r.timer = time.AfterFunc(r.delay, func() { r.apply(seq) })
+	}
+	r.mu.Unlock()
+
+	logger.Info("auto-rotation scheduled", "from", rot.From, "to", rot.To, "reason", rot.Reason,
+		"strategy", rot.Strategy, "remaining", target.Remaining, "delay", r.delay)
+	r.emit(Event{Type: EventScheduled, Rotation: rot})
+	if r.delay == 0 {
+		r.apply(seq)
+	}
+}
+
+// apply switches to the target of the pending rotation seq.
+func (r *Rotator) apply(seq int) {
+	r.mu.Lock()
+	if r.pending == nil || r.seq != seq {
+		r.mu.Unlock()
+		return
+	}
+	rot := *r.pending
+	r.pending = nil
+	r.timer = nil
+	r.mu.Unlock()
+
+	if active := r.accounts.GetActiveAccount(); active == nil || active.Email != rot.From {
+		logger.Info("auto-rotation cancelled, active account changed", "from", rot.From, "to", rot.To)
+		r.emit(Event{Type: EventCancelled, Rotation: rot})
+		return
+	}
+
+	if err := r.accounts.SetActiveAccount(rot.To); err != nil {
+		logger.Error("auto-rotation failed", "from", rot.From, "to", rot.To, "error", err)
+		r.emit(Event{Type: EventFailed, Rotation: rot, Error: err})
+		return
+	}
+
+	rot.SwitchedAt = r.now()
+	r.mu.Lock()
+	r.last = &rot
+	r.mu.Unlock()
+
+	logger.Info("auto-rotated active account", "from", rot.From, "to", rot.To, "reason", rot.Reason,
+		"strategy", rot.Strategy)
+	r.emit(Event{Type: EventSwitched, Rotation: rot})
+}
+
+// cancelLocked drops the pending rotation and returns it.
+func (r *Rotator) cancelLocked() Rotation {
+	rot := *r.pending
+	r.pending = nil
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	return rot
+}
+
+// reasonLocked returns why email should be rotated away from, or "".
+func (r *Rotator) reasonLocked(email string) string {
+	if qi := r.quotas[email]; qi != nil && !qi.IsStale() {
+		for i := range qi.ModelQuotas {
+			mq := &qi.ModelQuotas[i]
+			if mq.IsRateLimited && r.watches(mq.ModelID, mq.ModelFamily) {
+				return mq.Name() + " is rate limited"
+			}
+		}
+	}
+	return r.critical[email]
+}
+
+// candidatesLocked returns the accounts that can take over from email, and
+// the index of email in accs.
+func (r *Rotator) candidatesLocked(accs []models.Account, email string) ([]Candidate, int) {
+	current := -1
+	var candidates []Candidate
+	for i := range accs {
+		if accs[i].Email == email {
+			current = i
+			continue
+		}
+		qi := r.quotas[accs[i].Email]
+		if qi == nil || qi.IsStale() || r.reasonLocked(accs[i].Email) != "" {
+			continue
+		}
+		c, ok := r.candidate(qi)
+		if !ok {
+			continue
+		}
+		c.Index = i
+		candidates = append(candidates, c)
+	}
+	return candidates, current
+}
+
+// candidate summarizes the watched models of an account. It reports false
+// when the account has no watched model with quota left.
+func (r *Rotator) candidate(qi *models.QuotaInfo) (Candidate, bool) {
+	c := Candidate{Email: qi.AccountEmail, Remaining: -1}
+	for i := range qi.ModelQuotas {
+		mq := &qi.ModelQuotas[i]
+		if (mq.Limit <= 0 && !mq.IsRateLimited) || !r.watches(mq.ModelID, mq.ModelFamily) {
+			continue
+		}
+		if p := mq.RemainingPercent(); c.Remaining < 0 || p < c.Remaining {
+			c.Remaining = p
+		}
+		if !mq.ResetTime.IsZero() && (c.ResetTime.IsZero() || mq.ResetTime.Before(c.ResetTime)) {
+			c.ResetTime = mq.ResetTime
+		}
+	}
+	return c, c.Remaining > 0
+}
+
+// watches reports whether the model glob matches a model ID or family.
+func (r *Rotator) watches(names ...string) bool {
+	if r.model == "" {
+		return true
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if ok, _ := path.Match(strings.ToLower(r.model), strings.ToLower(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rotator) emit(ev Event) {
+	if r.notify != nil {
+		r.notify(ev)
+	}
+}
+
+// projections returns the per-model projections of an account, or its family
+// rollups when it has none.
+func projections(ap *models.AccountProjection) []*models.ModelProjection {
+	if len(ap.Models) > 0 {
+		return ap.Models
+	}
+	var result []*models.ModelProjection
+	for _, mp := range []*models.ModelProjection{ap.Claude, ap.Gemini} {
+		if mp != nil {
+			result = append(result, mp)
+		}
+	}
+	return result
+}
+
+func projectionName(mp *models.ModelProjection) string {
+	if mp.DisplayName != "" {
+		return mp.DisplayName
+	}
+	return mp.Model
+}
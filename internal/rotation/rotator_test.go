@@ -0,0 +1,296 @@
+package rotation
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// fakeAccounts is an in-memory Accounts.
+type fakeAccounts struct {
+	err    error
+	active string
+	emails []string
+	mu     sync.Mutex
+}
+
+func (f *fakeAccounts) GetAccounts() []models.Account {
+	accs := make([]models.Account, len(f.emails))
+	for i, e := range f.emails {
+		accs[i] = models.Account{Email: e}
+	}
+	return accs
+}
+
+func (f *fakeAccounts) GetActiveAccount() *models.Account {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &models.Account{Email: f.active}
+}
+
+func (f *fakeAccounts) SetActiveAccount(email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.active = email
+	return nil
+}
+
+func (f *fakeAccounts) Active() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+// recorder collects rotation events.
+type recorder struct {
+	ch chan Event
+}
+
+func newRecorder() *recorder {
+	return &recorder{ch: make(chan Event, 10)}
+}
+
+func (r *recorder) notify(ev Event) { r.ch <- ev }
+
+func (r *recorder) next(t *testing.T) Event {
+	t.Helper()
+	select {
+	case ev := <-r.ch:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("no rotation event")
+		return Event{}
+	}
+}
+
+func quota(email string, remaining int64, rateLimited bool) *models.QuotaInfo {
+	return &models.QuotaInfo{
+		AccountEmail: email,
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-sonnet-4-5", ModelFamily: "claude", Limit: 100, Remaining: remaining, IsRateLimited: rateLimited},
+			{ModelID: "gemini-3-pro", ModelFamily: "gemini", Limit: 100, Remaining: 100},
+		},
+	}
+}
+
+func critical(email string) *models.AccountProjection {
+	return &models.AccountProjection{
+		Email: email,
+		Models: []*models.ModelProjection{
+			{Model: "claude-sonnet-4-5", Family: "claude", Status: models.ProjectionCritical, CurrentPercent: 3},
+		},
+	}
+}
+
+func newTestRotator(t *testing.T, cfg Config, accs *fakeAccounts) (*Rotator, *recorder) {
+	t.Helper()
+	rec := newRecorder()
+	r, err := New(cfg, accs, rec.notify)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(r.Close)
+	return r, rec
+}
+
+func TestRotator_RotatesOnRateLimit(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b", "c"}, active: "a"}
+	r, rec := newTestRotator(t, Config{}, accs)
+
+	r.ObserveQuota(quota("b", 30, false))
+	r.ObserveQuota(quota("c", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+
+	if ev := rec.next(t); ev.Type != EventScheduled || ev.Rotation.To != "c" {
+		t.Fatalf("event = %+v, want scheduled rotation to c", ev)
+	}
+	ev := rec.next(t)
+	if ev.Type != EventSwitched || ev.Rotation.From != "a" || ev.Rotation.Reason != "claude-sonnet-4-5 is rate limited" {
+		t.Fatalf("event = %+v, want switch from a", ev)
+	}
+	if accs.Active() != "c" {
+		t.Errorf("active = %s, want c", accs.Active())
+	}
+}
+
+func TestRotator_RotatesOnCriticalProjection(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b", "c"}, active: "a"}
+	r, rec := newTestRotator(t, Config{Strategy: RoundRobin{}}, accs)
+
+	r.ObserveQuota(quota("b", 30, false))
+	r.ObserveQuota(quota("c", 80, false))
+	r.ObserveQuota(quota("a", 3, false))
+	r.ObserveProjection(critical("a"))
+
+	rec.next(t)
+	if ev := rec.next(t); ev.Type != EventSwitched || ev.Rotation.To != "b" {
+		t.Fatalf("event = %+v, want switch to b", ev)
+	}
+}
+
+func TestRotator_SkipsUnusableCandidates(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b", "c", "d", "e"}, active: "a"}
+	r, rec := newTestRotator(t, Config{}, accs)
+
+	r.ObserveQuota(quota("b", 90, true)) // rate limited
+	r.ObserveQuota(quota("c", 0, false)) // exhausted
+	stale := quota("d", 95, false)
+	stale.Cached = true
+	r.ObserveQuota(stale)
+	r.ObserveQuota(quota("e", 10, false))
+	r.ObserveProjection(critical("e"))
+
+	r.ObserveQuota(quota("a", 50, true))
+	select {
+	case ev := <-rec.ch:
+		t.Fatalf("unexpected event %+v", ev)
+	default:
+	}
+
+	// Once e recovers it becomes the only candidate.
+	r.ObserveProjection(&models.AccountProjection{Email: "e"})
+	r.ObserveQuota(quota("a", 50, true))
+	if ev := rec.next(t); ev.Rotation.To != "e" {
+		t.Fatalf("event = %+v, want rotation to e", ev)
+	}
+}
+
+func TestRotator_ModelFilter(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b"}, active: "a"}
+	r, rec := newTestRotator(t, Config{Model: "gemini*"}, accs)
+
+	r.ObserveQuota(quota("b", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+	select {
+	case ev := <-rec.ch:
+		t.Fatalf("claude rate limit should not rotate with a gemini filter: %+v", ev)
+	default:
+	}
+
+	if _, err := New(Config{Model: "["}, accs, nil); err == nil {
+		t.Error("New() should reject an invalid model pattern")
+	}
+}
+
+func TestRotator_CountdownAndUndo(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b"}, active: "a"}
+	r, rec := newTestRotator(t, Config{Delay: time.Hour}, accs)
+
+	r.ObserveQuota(quota("b", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+
+	ev := rec.next(t)
+	if ev.Type != EventScheduled {
+		t.Fatalf("event = %+v, want scheduled", ev)
+	}
+	if p := r.Pending(); p == nil || p.To != "b" || !p.Deadline.After(time.Now().Add(50*time.Minute)) {
+		t.Fatalf("Pending() = %+v, want rotation to b in an hour", p)
+	}
+
+	if err := r.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if ev := rec.next(t); ev.Type != EventCancelled {
+		t.Fatalf("event = %+v, want cancelled", ev)
+	}
+	if r.Pending() != nil || accs.Active() != "a" {
+		t.Fatalf("rotation should be cancelled, active = %s", accs.Active())
+	}
+
+	// The undone account stays until it recovers.
+	r.ObserveQuota(quota("a", 50, true))
+	if r.Pending() != nil {
+		t.Fatal("rotation rescheduled after undo")
+	}
+	r.ObserveQuota(quota("a", 50, false))
+	r.ObserveQuota(quota("a", 50, true))
+	if r.Pending() == nil {
+		t.Fatal("rotation not scheduled after recovery")
+	}
+
+	if err := r.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if err := r.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestRotator_CountdownSwitches(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b"}, active: "a"}
+	r, rec := newTestRotator(t, Config{Delay: 20 * time.Millisecond}, accs)
+
+	r.ObserveQuota(quota("b", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+
+	rec.next(t)
+	if ev := rec.next(t); ev.Type != EventSwitched || accs.Active() != "b" {
+		t.Fatalf("event = %+v, active = %s, want switch to b", ev, accs.Active())
+	}
+}
+
+func TestRotator_UndoAfterSwitch(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b"}, active: "a"}
+	r, rec := newTestRotator(t, Config{}, accs)
+
+	r.ObserveQuota(quota("b", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+	rec.next(t)
+	rec.next(t)
+
+	if err := r.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if ev := rec.next(t); ev.Type != EventReverted || accs.Active() != "a" {
+		t.Fatalf("event = %+v, active = %s, want revert to a", ev, accs.Active())
+	}
+
+	// Past the undo window nothing is reverted.
+	r.ObserveQuota(quota("a", 50, false))
+	r.ObserveQuota(quota("a", 50, true))
+	rec.next(t)
+	rec.next(t)
+	r.now = func() time.Time { return time.Now().Add(2 * undoWindow) }
+	if err := r.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestRotator_CancelsWhenRecoveredOrSwitched(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b"}, active: "a"}
+	r, rec := newTestRotator(t, Config{Delay: time.Hour}, accs)
+
+	r.ObserveQuota(quota("b", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+	rec.next(t)
+	r.ObserveQuota(quota("a", 50, false))
+	if ev := rec.next(t); ev.Type != EventCancelled {
+		t.Fatalf("event = %+v, want cancelled after recovery", ev)
+	}
+
+	r.ObserveQuota(quota("a", 50, true))
+	rec.next(t)
+	_ = accs.SetActiveAccount("b")
+	r.ObserveAccounts()
+	if ev := rec.next(t); ev.Type != EventCancelled || r.Pending() != nil {
+		t.Fatalf("event = %+v, want cancelled after a manual switch", ev)
+	}
+}
+
+func TestRotator_SwitchFailure(t *testing.T) {
+	accs := &fakeAccounts{emails: []string{"a", "b"}, active: "a", err: errors.New("read-only")}
+	r, rec := newTestRotator(t, Config{}, accs)
+
+	r.ObserveQuota(quota("b", 80, false))
+	r.ObserveQuota(quota("a", 50, true))
+	rec.next(t)
+	if ev := rec.next(t); ev.Type != EventFailed || ev.Error == nil {
+		t.Fatalf("event = %+v, want failure", ev)
+	}
+}
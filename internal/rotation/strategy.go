@@ -0,0 +1,122 @@
+// Package rotation switches the active opencode account when it runs out of
+// quota, so long sessions continue on another account instead of stalling.
+package rotation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Strategy names.
+const (
+	StrategyMostRemaining = "most_remaining"
+	StrategySoonestReset  = "soonest_reset"
+	StrategyRoundRobin    = "round_robin"
+)
+
+// Strategies lists the strategy names.
+var Strategies = []string{StrategyMostRemaining, StrategySoonestReset, StrategyRoundRobin}
+
+// Candidate is an account the active account can be rotated to.
+type Candidate struct {
+	// ResetTime is the earliest reset of the account's watched models; zero
+	// when unknown.
+	ResetTime time.Time
+	Email     string
+	// Remaining is the lowest remaining percentage of the account's watched
+	// models.
+	Remaining float64
+	// Index is the position of the account in the accounts file.
+	Index int
+}
+
+// Strategy picks the account to rotate to.
+type Strategy interface {
+	// Name returns the strategy name.
+	Name() string
+	// Pick returns the best of candidates, which is never empty. current is
+	// the index of the active account in the accounts file.
+	Pick(current int, candidates []Candidate) Candidate
+}
+
+// ParseStrategy returns the strategy with the given name. An empty name
+// selects most_remaining.
+func ParseStrategy(name string) (Strategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", StrategyMostRemaining:
+		return MostRemaining{}, nil
+	case StrategySoonestReset:
+		return SoonestReset{}, nil
+	case StrategyRoundRobin:
+		return RoundRobin{}, nil
+	}
+	return nil, fmt.Errorf("unknown rotation strategy %q (want one of %s)", name, strings.Join(Strategies, ", "))
+}
+
+// MostRemaining picks the account with the most quota left.
+type MostRemaining struct{}
+
+// Name implements Strategy.
+func (MostRemaining) Name() string { return StrategyMostRemaining }
+
+// Pick implements Strategy.
+func (MostRemaining) Pick(_ int, candidates []Candidate) Candidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Remaining > best.Remaining {
+			best = c
+		}
+	}
+	return best
+}
+
+// SoonestReset picks the account whose quota resets first, so quota that
+// would be replenished anyway is used up before quota that has to last.
+// Accounts with an unknown reset time come last; ties go to the account with
+// the most quota left.
+type SoonestReset struct{}
+
+// Name implements Strategy.
+func (SoonestReset) Name() string { return StrategySoonestReset }
+
+// Pick implements Strategy.
+func (SoonestReset) Pick(_ int, candidates []Candidate) Candidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		switch {
+		case c.ResetTime.IsZero():
+			if best.ResetTime.IsZero() && c.Remaining > best.Remaining {
+				best = c
+			}
+		case best.ResetTime.IsZero(), c.ResetTime.Before(best.ResetTime):
+			best = c
+		case c.ResetTime.Equal(best.ResetTime) && c.Remaining > best.Remaining:
+			best = c
+		}
+	}
+	return best
+}
+
+// RoundRobin picks the next account after the active one in file order.
+type RoundRobin struct{}
+
+// Name implements Strategy.
+func (RoundRobin) Name() string { return StrategyRoundRobin }
+
+// Pick implements Strategy.
+func (RoundRobin) Pick(current int, candidates []Candidate) Candidate {
+	best := candidates[0]
+	bestDistance := -1
+	for _, c := range candidates {
+		// Distance going forward from current, wrapping around.
+		distance := c.Index - current
+		if distance <= 0 {
+			distance += 1 << 30
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = c, distance
+		}
+	}
+	return best
+}
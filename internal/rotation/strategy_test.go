@@ -0,0 +1,61 @@
+package rotation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStrategy(t *testing.T) {
+	for _, name := range append([]string{""}, Strategies...) {
+		s, err := ParseStrategy(name)
+		if err != nil {
+			t.Fatalf("ParseStrategy(%q) error = %v", name, err)
+		}
+		if name != "" && s.Name() != name {
+			t.Errorf("ParseStrategy(%q).Name() = %q", name, s.Name())
+		}
+	}
+	if s, _ := ParseStrategy(""); s.Name() != StrategyMostRemaining {
+		t.Errorf("default strategy = %q, want %q", s.Name(), StrategyMostRemaining)
+	}
+	if _, err := ParseStrategy("random"); err == nil {
+		t.Error("ParseStrategy(random) should fail")
+	}
+}
+
+func TestStrategies_Pick(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	candidates := []Candidate{
+		{Email: "a", Index: 0, Remaining: 40, ResetTime: now.Add(3 * time.Hour)},
+		{Email: "c", Index: 2, Remaining: 90},
+		{Email: "d", Index: 3, Remaining: 20, ResetTime: now.Add(time.Hour)},
+		{Email: "e", Index: 4, Remaining: 60, ResetTime: now.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		strategy Strategy
+		want     string
+		current  int
+	}{
+		{MostRemaining{}, "c", 1},
+		{SoonestReset{}, "e", 1},
+		{RoundRobin{}, "c", 1},
+		{RoundRobin{}, "a", 4},
+		{RoundRobin{}, "d", 2},
+	}
+	for _, tt := range tests {
+		if got := tt.strategy.Pick(tt.current, candidates); got.Email != tt.want {
+			t.Errorf("%s.Pick(%d) = %s, want %s", tt.strategy.Name(), tt.current, got.Email, tt.want)
+		}
+	}
+}
+
+func TestSoonestReset_UnknownResetTimes(t *testing.T) {
+	candidates := []Candidate{
+		{Email: "a", Remaining: 40},
+		{Email: "b", Remaining: 70},
+	}
+	if got := (SoonestReset{}).Pick(0, candidates); got.Email != "b" {
+		t.Errorf("Pick() = %s, want b", got.Email)
+	}
+}
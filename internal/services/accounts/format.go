@@ -0,0 +1,419 @@
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// fileFormat identifies the layout of the accounts file.
+type fileFormat int
+
+const (
+	// formatStandard is the File shape with activeAccount. New files use it.
+	formatStandard fileFormat = iota
+	// formatJSDashboard is the opencode-antigravity-auth shape with activeIndex.
+	formatJSDashboard
+	// formatLegacy is a bare array of accounts; the first one is active.
+	formatLegacy
+)
+
+// jsTimeLayout is the layout of JavaScript's Date.prototype.toISOString.
+const jsTimeLayout = "2006-01-02T15:04:05.000Z"
+
+var errNotObject = errors.New("not a JSON object")
+
+// object is a JSON object that keeps its keys in order and its values
+// verbatim, so fields we do not understand survive a rewrite.
+type object struct {
+	values map[string]json.RawMessage
+	keys   []string
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]json.RawMessage)}
+}
+
+// parseObject decodes a JSON object, keeping key order.
+func parseObject(data []byte) (*object, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, errNotObject
+	}
+
+	o := newObject()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, errNotObject
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		o.set(key, value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *object) get(key string) (json.RawMessage, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// set stores value under key. New keys are appended.
+func (o *object) set(key string, value json.RawMessage) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// setValue marshals v and stores it under key.
+func (o *object) setValue(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	o.set(key, data)
+	return nil
+}
+
+func (o *object) del(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (o *object) clone() *object {
+	if o == nil {
+		return newObject()
+	}
+	return &object{values: maps.Clone(o.values), keys: append([]string(nil), o.keys...)}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(o.values[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// entry is the on-disk form of one account together with the account as it
+// was last read or written, so a save only touches the fields that changed.
+type entry struct {
+	raw  *object
+	base models.Account
+}
+
+// document is the accounts file as loaded: its format, the top-level object
+// and one entry per account, parallel to Service.accounts. Accounts past the
+// end of entries have not been written yet.
+type document struct {
+	root   *object // nil for the legacy format and for new files
+	active string  // active account as last read or written
+	// deleted holds the emails of accounts removed since the last read or
+	// write, so a merge can remove them from the file too.
+	deleted []string
+	entries []entry
+	format  fileFormat
+}
+
+// newDocument captures the raw JSON of a file parsed as accounts in format.
+// Entries that cannot be captured are written from scratch on the next save.
+func newDocument(data []byte, format fileFormat, accounts []models.Account, active string) document {
+	d := document{format: format, active: active}
+
+	var rawAccounts []json.RawMessage
+	if format == formatLegacy {
+		_ = json.Unmarshal(data, &rawAccounts) //nolint:errcheck // Already parsed as accounts
+	} else if root, err := parseObject(data); err == nil {
+		d.root = root
+		if v, ok := root.get("accounts"); ok {
+			_ = json.Unmarshal(v, &rawAccounts) //nolint:errcheck // Already parsed as accounts
+		}
+	}
+
+	d.entries = make([]entry, len(accounts))
+	for i := range accounts {
+		d.entries[i].base = cloneAccount(&accounts[i])
+		if i < len(rawAccounts) {
+			if raw, err := parseObject(rawAccounts[i]); err == nil {
+				d.entries[i].raw = raw
+			}
+		}
+	}
+	return d
+}
+
+// remove drops the entry of the account at index i.
+func (d *document) remove(i int) {
+	if i < len(d.entries) {
+		d.deleted = append(d.deleted, d.entries[i].base.Email)
+		d.entries = append(d.entries[:i:i], d.entries[i+1:]...)
+	}
+}
+
+// encode renders accounts in the document's format, rewriting only the
+// fields that changed since the document was read. It returns the file
+// contents and the document describing them; d itself is not modified.
+func (d *document) encode(accounts []models.Account, active string) ([]byte, document, error) {
+	next := document{format: d.format, active: active, entries: make([]entry, len(accounts))}
+	if next.format == formatLegacy && !legacyActive(accounts, active) {
+		logger.Info("Upgrading legacy accounts file to record the active account")
+		next.format = formatStandard
+	}
+
+	rawAccounts := make([]json.RawMessage, len(accounts))
+	for i := range accounts {
+		var prev entry
+		if i < len(d.entries) {
+			prev = d.entries[i]
+		}
+		raw, err := next.format.encodeAccount(&accounts[i], &prev)
+		if err != nil {
+			return nil, document{}, fmt.Errorf("failed to encode account %s: %w", accounts[i].Email, err)
+		}
+		next.entries[i] = entry{raw: raw, base: cloneAccount(&accounts[i])}
+		if rawAccounts[i], err = raw.MarshalJSON(); err != nil {
+			return nil, document{}, err
+		}
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if next.format == formatLegacy {
+		data, err = json.Marshal(rawAccounts)
+	} else {
+		if next.format == d.format {
+			next.root = d.root.clone()
+		} else {
+			next.root = newObject()
+		}
+		if err := next.setTopLevel(accounts, active, rawAccounts); err != nil {
+			return nil, document{}, err
+		}
+		data, err = next.root.MarshalJSON()
+	}
+	if err != nil {
+		return nil, document{}, fmt.Errorf("failed to marshal accounts: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, document{}, fmt.Errorf("failed to indent accounts: %w", err)
+	}
+	return out.Bytes(), next, nil
+}
+
+// setTopLevel updates the active account, the accounts array and the version
+// of the root object, leaving any other key as it is.
+func (d *document) setTopLevel(accounts []models.Account, active string, rawAccounts []json.RawMessage) error {
+	switch d.format {
+	case formatJSDashboard:
+		if idx := accountIndex(accounts, active); idx >= 0 {
+			if err := d.root.setValue("activeIndex", idx); err != nil {
+				return err
+			}
+		} else if _, ok := d.root.get("activeIndex"); !ok {
+			if err := d.root.setValue("activeIndex", 0); err != nil {
+				return err
+			}
+		}
+	default:
+		if active == "" {
+			d.root.del("activeAccount")
+		} else if err := d.root.setValue("activeAccount", active); err != nil {
+			return err
+		}
+	}
+
+	if err := d.root.setValue("accounts", rawAccounts); err != nil {
+		return err
+	}
+	if _, ok := d.root.get("version"); !ok {
+		return d.root.setValue("version", 1)
+	}
+	return nil
+}
+
+// encodeAccount returns the on-disk object for acc: prev's raw object with
+// the fields that differ from prev's base replaced.
+func (f fileFormat) encodeAccount(acc *models.Account, prev *entry) (*object, error) {
+	raw := prev.raw.clone()
+
+	before := newObject()
+	if prev.raw != nil {
+		var err error
+		if before, err = f.fields(&prev.base, raw); err != nil {
+			return nil, err
+		}
+	}
+	after, err := f.fields(acc, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range before.keys {
+		if _, ok := after.get(k); !ok {
+			raw.del(k)
+		}
+	}
+	for _, k := range after.keys {
+		v, _ := after.get(k)
+		if old, ok := before.get(k); ok && bytes.Equal(old, v) {
+			continue
+		}
+		raw.set(k, v)
+	}
+	return raw, nil
+}
+
+// fields returns the fields of acc that format f stores. raw is the
+// account's current object, used to keep the representation of times.
+func (f fileFormat) fields(acc *models.Account, raw *object) (*object, error) {
+	if f != formatJSDashboard {
+		data, err := json.Marshal(acc)
+		if err != nil {
+			return nil, err
+		}
+		return parseObject(data)
+	}
+
+	o := newObject()
+	var err error
+	set := func(key string, v any) {
+		if err == nil {
+			err = o.setValue(key, v)
+		}
+	}
+	set("email", acc.Email)
+	set("refreshToken", acc.RefreshToken)
+	set("projectId", acc.ProjectID)
+	if acc.ManagedProjectID != "" {
+		set("managedProjectId", acc.ManagedProjectID)
+	}
+	if acc.DisplayName != "" {
+		set("displayName", acc.DisplayName)
+	}
+	if acc.PollInterval != "" {
+		set("pollInterval", acc.PollInterval)
+	}
+	if acc.PollPaused {
+		set("pollPaused", true)
+	}
+	for _, tf := range []struct {
+		t   time.Time
+		key string
+	}{{acc.AddedAt, "addedAt"}, {acc.LastUsed, "lastUsed"}} {
+		if !tf.t.IsZero() {
+			old, _ := raw.get(tf.key)
+			set(tf.key, jsTime(tf.t, old))
+		}
+	}
+	if len(acc.RateLimitResetTimes) > 0 {
+		set("rateLimitResetTimes", acc.RateLimitResetTimes)
+	}
+	return o, err
+}
+
+// decodeAccount parses an account object stored in format f.
+func (f fileFormat) decodeAccount(raw *object) (models.Account, error) {
+	data, err := raw.MarshalJSON()
+	if err != nil {
+		return models.Account{}, err
+	}
+	if f != formatJSDashboard {
+		var acc models.Account
+		err := json.Unmarshal(data, &acc)
+		return acc, err
+	}
+
+	var rawAcc models.RawAccountData
+	if err := json.Unmarshal(data, &rawAcc); err != nil {
+		return models.Account{}, err
+	}
+	acc := rawAcc.ToAccount()
+	acc.ID = acc.ProjectID
+	acc.IsActive = true
+	return acc, nil
+}
+
+// jsTime returns t in the representation of old: an ISO string, Unix seconds
+// or, by default, Unix milliseconds as the JS plugin writes them.
+func jsTime(t time.Time, old json.RawMessage) any {
+	old = bytes.TrimSpace(old)
+	if len(old) > 0 && old[0] == '"' {
+		return t.UTC().Format(jsTimeLayout)
+	}
+	var n float64
+	if json.Unmarshal(old, &n) == nil && n > 0 && n <= 1e12 {
+		return t.Unix()
+	}
+	return t.UnixMilli()
+}
+
+// legacyActive reports whether active is what the legacy format implies:
+// the first account.
+func legacyActive(accounts []models.Account, active string) bool {
+	if len(accounts) == 0 {
+		return true
+	}
+	return active == "" || accountIndex(accounts, active) == 0
+}
+
+// accountIndex returns the index of the account with the given ID or email,
+// or -1.
+func accountIndex(accounts []models.Account, idOrEmail string) int {
+	for i := range accounts {
+		if accounts[i].ID == idOrEmail || accounts[i].Email == idOrEmail {
+			return i
+		}
+	}
+	return -1
+}
+
+func cloneAccount(acc *models.Account) models.Account {
+	c := *acc
+	c.RateLimitResetTimes = maps.Clone(acc.RateLimitResetTimes)
+	return c
+}
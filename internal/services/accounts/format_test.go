@@ -0,0 +1,249 @@
+package accounts
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// loadTestFile writes content to an accounts file and opens a service on it.
+func loadTestFile(t *testing.T, content string) (*Service, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	svc, err := New(path)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = svc.Close() })
+	return svc, path
+}
+
+// readJSON decodes the accounts file into generic values.
+func readJSON(t *testing.T, path string) any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal() failed: %v\n%s", err, data)
+	}
+	return v
+}
+
+// topLevelKeys returns the keys of the file's root object in order.
+func topLevelKeys(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	o, err := parseObject(data)
+	if err != nil {
+		t.Fatalf("parseObject() failed: %v", err)
+	}
+	return o.keys
+}
+
+const jsDashboardFile = `{
+  "version": 3,
+  "accounts": [
+    {
+      "email": "a@example.com",
+      "refreshToken": "token-a",
+      "projectId": "proj-a",
+      "addedAt": 1767225600000,
+      "lastUsed": "2026-01-02T03:04:05.000Z",
+      "rateLimitResetTimes": {"claude": 1767229200000.5},
+      "fingerprint": {"device": "laptop"}
+    },
+    {
+      "email": "b@example.com",
+      "refreshToken": "token-b",
+      "projectId": "proj-b",
+      "addedAt": 1767225600000,
+      "enabled": false
+    }
+  ],
+  "activeIndex": 0,
+  "activeIndexByFamily": {"claude": 1, "gemini": 0}
+}`
+
+func TestSave_JSDashboardFormat(t *testing.T) {
+	svc, path := loadTestFile(t, jsDashboardFile)
+	want := readJSON(t, path).(map[string]any)
+
+	if err := svc.SetActiveAccount("b@example.com"); err != nil {
+		t.Fatalf("SetActiveAccount() failed: %v", err)
+	}
+	want["activeIndex"] = float64(1)
+	if got := readJSON(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("file after SetActiveAccount =\n%v\nwant\n%v", got, want)
+	}
+	if keys := topLevelKeys(t, path); !reflect.DeepEqual(keys, []string{"version", "accounts", "activeIndex", "activeIndexByFamily"}) {
+		t.Errorf("top-level keys = %v, want original order", keys)
+	}
+
+	acc := svc.GetAccountByEmail("a@example.com")
+	acc.LastUsed = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	acc.AddedAt = time.UnixMilli(1767225700000)
+	acc.ManagedProjectID = "managed-a"
+	acc.DisplayName = "Alice"
+	acc.PollInterval = "2m"
+	acc.PollPaused = true
+	if err := svc.UpdateAccount(acc); err != nil {
+		t.Fatalf("UpdateAccount() failed: %v", err)
+	}
+	first := want["accounts"].([]any)[0].(map[string]any)
+	first["lastUsed"] = "2026-02-01T00:00:00.000Z"
+	first["addedAt"] = float64(1767225700000)
+	first["managedProjectId"] = "managed-a"
+	first["displayName"] = "Alice"
+	first["pollInterval"] = "2m"
+	first["pollPaused"] = true
+	if got := readJSON(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("file after UpdateAccount =\n%v\nwant\n%v", got, want)
+	}
+
+	if err := svc.DeleteAccount("a@example.com"); err != nil {
+		t.Fatalf("DeleteAccount() failed: %v", err)
+	}
+	want["accounts"] = want["accounts"].([]any)[1:]
+	want["activeIndex"] = float64(0)
+	if got := readJSON(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("file after DeleteAccount =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestSave_JSDashboardAddAccount(t *testing.T) {
+	svc, path := loadTestFile(t, jsDashboardFile)
+
+	added := time.UnixMilli(1767312000000)
+	if err := svc.AddAccount(&models.Account{Email: "c@example.com", RefreshToken: "token-c", ProjectID: "proj-c", AddedAt: added}); err != nil {
+		t.Fatalf("AddAccount() failed: %v", err)
+	}
+
+	accounts := readJSON(t, path).(map[string]any)["accounts"].([]any)
+	if len(accounts) != 3 {
+		t.Fatalf("got %d accounts, want 3", len(accounts))
+	}
+	want := map[string]any{
+		"email":        "c@example.com",
+		"refreshToken": "token-c",
+		"projectId":    "proj-c",
+		"addedAt":      float64(1767312000000),
+	}
+	if got := accounts[2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("added account = %v, want %v", got, want)
+	}
+}
+
+func TestSave_StandardFormat(t *testing.T) {
+	svc, path := loadTestFile(t, `{
+  "accounts": [
+    {"id": "acc-1", "email": "a@example.com", "refreshToken": "token-a", "addedAt": "2026-01-01T00:00:00Z", "lastUsed": "0001-01-01T00:00:00Z", "expiresAt": "0001-01-01T00:00:00Z", "label": "work"},
+    {"id": "acc-2", "email": "b@example.com", "refreshToken": "token-b", "addedAt": "2026-01-01T00:00:00Z", "lastUsed": "0001-01-01T00:00:00Z", "expiresAt": "0001-01-01T00:00:00Z"}
+  ],
+  "activeAccount": "acc-1",
+  "version": 2,
+  "theme": "dark"
+}`)
+	want := readJSON(t, path).(map[string]any)
+
+	if err := svc.SetActiveAccount("b@example.com"); err != nil {
+		t.Fatalf("SetActiveAccount() failed: %v", err)
+	}
+	want["activeAccount"] = "acc-2"
+	if got := readJSON(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("file after SetActiveAccount =\n%v\nwant\n%v", got, want)
+	}
+
+	acc := svc.GetAccountByEmail("a@example.com")
+	acc.DisplayName = "Alice"
+	if err := svc.UpdateAccount(acc); err != nil {
+		t.Fatalf("UpdateAccount() failed: %v", err)
+	}
+	want["accounts"].([]any)[0].(map[string]any)["displayName"] = "Alice"
+	if got := readJSON(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("file after UpdateAccount =\n%v\nwant\n%v", got, want)
+	}
+	if keys := topLevelKeys(t, path); !reflect.DeepEqual(keys, []string{"accounts", "activeAccount", "version", "theme"}) {
+		t.Errorf("top-level keys = %v, want original order", keys)
+	}
+}
+
+func TestSave_LegacyFormat(t *testing.T) {
+	svc, path := loadTestFile(t, `[
+  {"email": "a@example.com", "refreshToken": "token-a", "note": "first"},
+  {"email": "b@example.com", "refreshToken": "token-b"}
+]`)
+	want := readJSON(t, path).([]any)
+
+	acc := svc.GetAccountByEmail("b@example.com")
+	acc.RefreshToken = "token-b2"
+	if err := svc.UpdateAccount(acc); err != nil {
+		t.Fatalf("UpdateAccount() failed: %v", err)
+	}
+	want[1].(map[string]any)["refreshToken"] = "token-b2"
+	if got := readJSON(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("file after UpdateAccount =\n%v\nwant\n%v", got, want)
+	}
+
+	// A bare array cannot record another active account, so the file is
+	// upgraded to the standard format, keeping the per-account fields.
+	if err := svc.SetActiveAccount("b@example.com"); err != nil {
+		t.Fatalf("SetActiveAccount() failed: %v", err)
+	}
+	got, ok := readJSON(t, path).(map[string]any)
+	if !ok {
+		t.Fatal("file should be upgraded to the standard format")
+	}
+	if got["activeAccount"] != "b@example.com" || !reflect.DeepEqual(got["accounts"], []any(want)) {
+		t.Errorf("upgraded file = %v", got)
+	}
+}
+
+func TestSave_NewFileIsIndented(t *testing.T) {
+	svc, path := newTestService(t)
+
+	if err := svc.AddAccount(&models.Account{ID: "acc-1", Email: "a@example.com"}); err != nil {
+		t.Fatalf("AddAccount() failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	if !strings.Contains(string(data), "\n  \"accounts\": [") {
+		t.Errorf("file is not indented:\n%s", data)
+	}
+}
+
+func TestParseObject(t *testing.T) {
+	o, err := parseObject([]byte(`{"b": 1, "a": {"x": [1, 2]}, "c": null}`))
+	if err != nil {
+		t.Fatalf("parseObject() failed: %v", err)
+	}
+	o.set("d", json.RawMessage(`true`))
+	o.del("a")
+	data, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() failed: %v", err)
+	}
+	if string(data) != `{"b":1,"c":null,"d":true}` {
+		t.Errorf("MarshalJSON() = %s", data)
+	}
+
+	if _, err := parseObject([]byte(`[1]`)); err == nil {
+		t.Error("parseObject() should reject an array")
+	}
+}
@@ -0,0 +1,139 @@
+package accounts
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+)
+
+// ErrLocked is returned when another process holds the accounts file lock
+// for longer than lockTimeout.
+var ErrLocked = errors.New("accounts file is locked by another process")
+
+// Lock timings. A lock older than lockStale belongs to a writer that died
+// without releasing it; 10s matches the default of Node's proper-lockfile.
+var (
+	lockTimeout = 5 * time.Second
+	lockStale   = 10 * time.Second
+	lockRetry   = 50 * time.Millisecond
+)
+
+// racyWindow is how long after a write an unchanged mtime is not trusted:
+// two writes within the filesystem's timestamp resolution look the same.
+const racyWindow = 2 * time.Second
+
+// lockFile takes the advisory lock on path: a "<path>.lock" directory, the
+// convention of Node's proper-lockfile, so cooperating writers such as
+// opencode serialize with us. The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := os.Mkdir(lockPath, 0o700)
+		if err == nil {
+			return func() {
+				if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+					logger.Error("failed to release accounts file lock", "path", lockPath, "error", err)
+				}
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock accounts file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			logger.Warn("Removing stale accounts file lock", "path", lockPath, "age", time.Since(info.ModTime()).Round(time.Second))
+			if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove stale lock: %w", err)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// diskState identifies the accounts file contents last read or written.
+type diskState struct {
+	modTime time.Time
+	seenAt  time.Time
+	size    int64
+	hash    [sha256.Size]byte
+	exists  bool
+}
+
+// newDiskState describes data as stored in a file with the given info.
+func newDiskState(data []byte, info os.FileInfo) diskState {
+	return diskState{
+		modTime: info.ModTime(),
+		seenAt:  time.Now(),
+		size:    info.Size(),
+		hash:    sha256.Sum256(data),
+		exists:  true,
+	}
+}
+
+// unchanged reports whether info shows the file as last seen without reading
+// it. A file seen shortly after it was modified is never trusted this way.
+func (d *diskState) unchanged(info os.FileInfo) bool {
+	return d.exists &&
+		d.size == info.Size() &&
+		d.modTime.Equal(info.ModTime()) &&
+		d.seenAt.Sub(d.modTime) > racyWindow
+}
+
+// readAccountsFile reads the file at path and describes what was read.
+func readAccountsFile(path string) ([]byte, diskState, error) {
+	f, err := os.Open(path) //nolint:gosec // Path comes from configuration
+	if err != nil {
+		return nil, diskState{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, diskState{}, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, diskState{}, err
+	}
+	return data, newDiskState(data, info), nil
+}
+
+// changedOnDisk reports whether another process changed the file since it
+// was last read or written and, if so, returns the new contents. A missing
+// file is not a change: there is nothing to merge with.
+func (s *Service) changedOnDisk() ([]byte, bool, error) {
+	info, err := os.Stat(s.filePath)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat accounts file: %w", err)
+	}
+	if s.disk.unchanged(info) {
+		return nil, false, nil
+	}
+
+	data, state, err := readAccountsFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read accounts file: %w", err)
+	}
+	if s.disk.exists && state.hash == s.disk.hash {
+		s.disk = state
+		return nil, false, nil
+	}
+	return data, true, nil
+}
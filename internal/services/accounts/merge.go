@@ -0,0 +1,102 @@
+package accounts
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// mergeLocked replays the changes made since the file was last read or
+// written onto data, the file as another process left it. Accounts are
+// matched by email and only the fields we changed are taken from ours, so
+// the other writer's additions, token refreshes and unknown fields survive.
+// Where both sides changed the same field, ours wins. It returns the merged
+// accounts and active account with a document describing data.
+func (s *Service) mergeLocked(data []byte) ([]models.Account, string, document, error) {
+	theirs, theirActive, format, err := s.parseAccountsFormat(data)
+	if err != nil {
+		return nil, "", document{}, fmt.Errorf("failed to merge accounts file: %w", err)
+	}
+	doc := newDocument(data, format, theirs, theirActive)
+	base := &s.doc
+
+	index := make(map[string]int, len(theirs))
+	for i := range theirs {
+		index[theirs[i].Email] = i
+	}
+	removed := make([]bool, len(theirs))
+	for _, email := range base.deleted {
+		if j, ok := index[email]; ok {
+			removed[j] = true
+		}
+	}
+
+	merged := slices.Clone(theirs)
+	var added []models.Account
+	for i := range s.accounts {
+		ours := &s.accounts[i]
+		var prev entry
+		key := ours.Email
+		if i < len(base.entries) {
+			prev.base = base.entries[i].base
+			key = prev.base.Email
+		}
+
+		j, ok := index[key]
+		switch {
+		case !ok && i < len(base.entries):
+			logger.Warn("Account was removed from the accounts file by another process", "email", key)
+			continue
+		case !ok:
+			added = append(added, *ours)
+			continue
+		}
+
+		// Accounts we added that the other writer added too are overlaid
+		// field by field, as if ours changed everything it sets.
+		prev.raw = doc.entries[j].raw
+		raw, err := format.encodeAccount(ours, &prev)
+		if err != nil {
+			return nil, "", document{}, fmt.Errorf("failed to merge account %s: %w", ours.Email, err)
+		}
+		acc, err := format.decodeAccount(raw)
+		if err != nil {
+			return nil, "", document{}, fmt.Errorf("failed to merge account %s: %w", ours.Email, err)
+		}
+		merged[j] = acc
+		doc.entries[j] = entry{raw: raw, base: cloneAccount(&acc)}
+	}
+
+	var accounts []models.Account
+	var entries []entry
+	for j := range merged {
+		if !removed[j] {
+			accounts = append(accounts, merged[j])
+			entries = append(entries, doc.entries[j])
+		}
+	}
+	doc.entries = entries
+	accounts = append(accounts, added...)
+	if accounts == nil {
+		accounts = make([]models.Account, 0)
+	}
+
+	active := theirActive
+	if s.activeAccount != base.active {
+		if i := accountIndex(s.accounts, s.activeAccount); i >= 0 {
+			active = s.accounts[i].Email
+		}
+	}
+	if i := accountIndex(accounts, active); i >= 0 {
+		active = accounts[i].ID
+		if active == "" {
+			active = accounts[i].Email
+		}
+	} else {
+		active = s.getDefaultActiveAccount(accounts)
+	}
+
+	return accounts, active, doc, nil
+}
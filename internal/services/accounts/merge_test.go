@@ -0,0 +1,228 @@
+package accounts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+const standardFile = `{
+  "accounts": [
+    {"id": "acc-1", "email": "a@example.com", "refreshToken": "token-a", "label": "work"},
+    {"id": "acc-2", "email": "b@example.com", "refreshToken": "token-b"}
+  ],
+  "activeAccount": "acc-1",
+  "version": 1
+}`
+
+// writeExternal changes the accounts file behind the service's back, the way
+// opencode would. The service saves before its watcher reloads the file.
+func writeExternal(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+}
+
+func accountByEmail(t *testing.T, file any, email string) map[string]any {
+	t.Helper()
+	for _, a := range file.(map[string]any)["accounts"].([]any) {
+		if acc := a.(map[string]any); acc["email"] == email {
+			return acc
+		}
+	}
+	return nil
+}
+
+func TestSave_MergesConcurrentChanges(t *testing.T) {
+	svc, path := loadTestFile(t, standardFile)
+
+	// opencode refreshes a's token, adds c and a field of its own.
+	writeExternal(t, path, `{
+  "accounts": [
+    {"id": "acc-1", "email": "a@example.com", "refreshToken": "token-a2", "label": "work"},
+    {"id": "acc-2", "email": "b@example.com", "refreshToken": "token-b"},
+    {"id": "acc-3", "email": "c@example.com", "refreshToken": "token-c"}
+  ],
+  "activeAccount": "acc-1",
+  "version": 1,
+  "plugin": {"v": 2}
+}`)
+
+	if err := svc.SetActiveAccount("b@example.com"); err != nil {
+		t.Fatalf("SetActiveAccount() failed: %v", err)
+	}
+
+	file := readJSON(t, path)
+	if got := file.(map[string]any)["activeAccount"]; got != "acc-2" {
+		t.Errorf("activeAccount = %v, want acc-2", got)
+	}
+	if a := accountByEmail(t, file, "a@example.com"); a["refreshToken"] != "token-a2" || a["label"] != "work" {
+		t.Errorf("account a = %v, want the refreshed token kept", a)
+	}
+	if accountByEmail(t, file, "c@example.com") == nil {
+		t.Error("account c added by another process was dropped")
+	}
+	if file.(map[string]any)["plugin"] == nil {
+		t.Error("unknown top-level field was dropped")
+	}
+	if n := svc.Count(); n != 3 {
+		t.Errorf("Count() = %d, want 3 after the merge", n)
+	}
+}
+
+func TestSave_MergesFieldsOfTheSameAccount(t *testing.T) {
+	svc, path := loadTestFile(t, standardFile)
+
+	writeExternal(t, path, `{
+  "accounts": [
+    {"id": "acc-1", "email": "a@example.com", "refreshToken": "token-a2", "label": "home", "projectId": "theirs"},
+    {"id": "acc-2", "email": "b@example.com", "refreshToken": "token-b"}
+  ],
+  "activeAccount": "acc-1",
+  "version": 1
+}`)
+
+	acc := svc.GetAccountByEmail("a@example.com")
+	acc.DisplayName = "Alice"
+	acc.ProjectID = "ours"
+	if err := svc.UpdateAccount(acc); err != nil {
+		t.Fatalf("UpdateAccount() failed: %v", err)
+	}
+
+	got := accountByEmail(t, readJSON(t, path), "a@example.com")
+	want := map[string]any{
+		"id":           "acc-1",
+		"email":        "a@example.com",
+		"refreshToken": "token-a2",
+		"label":        "home",
+		"projectId":    "ours",
+		"displayName":  "Alice",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestSave_MergesDeletionsAndAdditions(t *testing.T) {
+	svc, path := loadTestFile(t, jsDashboardFile)
+
+	if err := svc.DeleteAccount("a@example.com"); err != nil {
+		t.Fatalf("DeleteAccount() failed: %v", err)
+	}
+
+	// opencode adds c and disables b before our next save.
+	writeExternal(t, path, `{
+  "version": 3,
+  "accounts": [
+    {"email": "b@example.com", "refreshToken": "token-b", "projectId": "proj-b", "addedAt": 1767225600000, "enabled": true},
+    {"email": "c@example.com", "refreshToken": "token-c", "projectId": "proj-c"}
+  ],
+  "activeIndex": 1
+}`)
+
+	if err := svc.AddAccount(&models.Account{Email: "d@example.com", ProjectID: "proj-d", AddedAt: time.UnixMilli(1767312000000)}); err != nil {
+		t.Fatalf("AddAccount() failed: %v", err)
+	}
+
+	file := readJSON(t, path).(map[string]any)
+	var emails []string
+	for _, a := range file["accounts"].([]any) {
+		emails = append(emails, a.(map[string]any)["email"].(string))
+	}
+	if want := []string{"b@example.com", "c@example.com", "d@example.com"}; !reflect.DeepEqual(emails, want) {
+		t.Errorf("accounts = %v, want %v", emails, want)
+	}
+	if b := accountByEmail(t, file, "b@example.com"); b["enabled"] != true {
+		t.Errorf("account b = %v, want their change kept", b)
+	}
+	if file["activeIndex"] != float64(1) {
+		t.Errorf("activeIndex = %v, want their active account kept", file["activeIndex"])
+	}
+}
+
+func TestSave_RefusesToOverwriteInvalidFile(t *testing.T) {
+	svc, path := loadTestFile(t, standardFile)
+
+	writeExternal(t, path, `{"accounts": [`)
+	if err := svc.SetActiveAccount("b@example.com"); err == nil {
+		t.Fatal("SetActiveAccount() should fail while the file is unreadable")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"accounts": [` {
+		t.Errorf("file was overwritten: %s", data)
+	}
+}
+
+func TestSave_WaitsForLock(t *testing.T) {
+	svc, path := loadTestFile(t, standardFile)
+	oldTimeout := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = oldTimeout })
+
+	lockPath := path + ".lock"
+	if err := os.Mkdir(lockPath, 0o700); err != nil {
+		t.Fatalf("Mkdir() failed: %v", err)
+	}
+	if err := svc.SetActiveAccount("b@example.com"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("SetActiveAccount() error = %v, want ErrLocked", err)
+	}
+
+	// A lock left behind by a crashed writer is taken over.
+	old := time.Now().Add(-2 * lockStale)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("Chtimes() failed: %v", err)
+	}
+	if err := svc.SetActiveAccount("b@example.com"); err != nil {
+		t.Fatalf("SetActiveAccount() failed: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock not released: %v", err)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	svc, err := New(path, ReadOnly(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("read-only service created the accounts file: %v", err)
+	}
+	_ = svc.Close()
+
+	writeExternal(t, path, standardFile)
+	svc, err = New(path, ReadOnly(true))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	if !svc.ReadOnly() || svc.Count() != 2 {
+		t.Fatalf("ReadOnly() = %v, Count() = %d", svc.ReadOnly(), svc.Count())
+	}
+	checks := map[string]error{
+		"SetActiveAccount": svc.SetActiveAccount("b@example.com"),
+		"AddAccount":       svc.AddAccount(&models.Account{Email: "c@example.com"}),
+		"UpdateAccount":    svc.UpdateAccount(&models.Account{ID: "acc-1", Email: "a@example.com"}),
+		"DeleteAccount":    svc.DeleteAccount("a@example.com"),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s() error = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != standardFile {
+		t.Errorf("read-only service wrote the file:\n%s", data)
+	}
+	if svc.GetActiveAccountID() != "acc-1" {
+		t.Errorf("active account changed to %s", svc.GetActiveAccountID())
+	}
+}
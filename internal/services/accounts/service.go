@@ -3,6 +3,7 @@ package accounts
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"os"
@@ -16,6 +17,12 @@ import (
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
 )
 
+// ErrAccountNotFound is returned when no account matches an ID or email.
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrReadOnly is returned by every change to a read-only service.
+var ErrReadOnly = errors.New("accounts file is read-only")
+
 // File represents the JSON file structure for accounts storage.
 type File struct {
 	ActiveAccount string           `json:"activeAccount,omitempty"`
@@ -60,8 +67,22 @@ type Service struct {
 	activeAccount string
 	filePath      string
 	accounts      []models.Account
+	doc           document
+	disk          diskState
 	mu            sync.RWMutex
 	closeOnce     sync.Once
+	readOnly      bool
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// ReadOnly makes the service never write the accounts file when readOnly is
+// set: every change fails with ErrReadOnly and a missing file is not created.
+func ReadOnly(readOnly bool) Option {
+	return func(s *Service) {
+		s.readOnly = readOnly
+	}
 }
 
 // defaultAccountsPath returns the default accounts file path.
@@ -74,7 +95,7 @@ func defaultAccountsPath() string {
 }
 
 // New creates a new accounts service and starts file watching.
-func New(filePath string) (*Service, error) {
+func New(filePath string, opts ...Option) (*Service, error) {
 	if filePath == "" {
 		filePath = defaultAccountsPath()
 	}
@@ -85,6 +106,9 @@ func New(filePath string) (*Service, error) {
 		eventChan: make(chan Event, 100),
 		stopChan:  make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
 
 	// Ensure directory exists
 	dir := filepath.Dir(filePath)
@@ -95,11 +119,11 @@ func New(filePath string) (*Service, error) {
 	// Load accounts from file
 	if err := s.loadAccounts(); err != nil {
 		// If file doesn't exist, create empty accounts file
-		if os.IsNotExist(err) {
+		if os.IsNotExist(err) && !s.readOnly {
 			if createErr := s.saveAccounts(); createErr != nil {
 				return nil, fmt.Errorf("failed to create accounts file: %w", createErr)
 			}
-		} else {
+		} else if !os.IsNotExist(err) {
 			return nil, fmt.Errorf("failed to load accounts: %w", err)
 		}
 	}
@@ -158,6 +182,11 @@ func (s *Service) GetActiveAccount() *models.Account {
 	return nil
 }
 
+// ReadOnly reports whether the service never writes the accounts file.
+func (s *Service) ReadOnly() bool {
+	return s.readOnly
+}
+
 // GetActiveAccountID returns the ID of the active account.
 func (s *Service) GetActiveAccountID() string {
 	s.mu.RLock()
@@ -167,6 +196,10 @@ func (s *Service) GetActiveAccountID() string {
 
 // SetActiveAccount sets the active account by ID or email.
 func (s *Service) SetActiveAccount(idOrEmail string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -185,7 +218,7 @@ func (s *Service) SetActiveAccount(idOrEmail string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("account not found: %s", idOrEmail)
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, idOrEmail)
 	}
 
 	if err := s.saveAccountsLocked(); err != nil {
@@ -198,6 +231,10 @@ func (s *Service) SetActiveAccount(idOrEmail string) error {
 
 // AddAccount adds a new account.
 func (s *Service) AddAccount(account *models.Account) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -236,13 +273,18 @@ func (s *Service) AddAccount(account *models.Account) error {
 
 // UpdateAccount updates an existing account.
 func (s *Service) UpdateAccount(account *models.Account) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	found := false
 	for i := range s.accounts {
 		acc := &s.accounts[i]
-		if acc.ID != account.ID && acc.Email != account.Email {
+		// Legacy files have no IDs, so an empty ID never matches.
+		if (account.ID == "" || acc.ID != account.ID) && acc.Email != account.Email {
 			continue
 		}
 		// Preserve ID if updating by email
@@ -259,7 +301,7 @@ func (s *Service) UpdateAccount(account *models.Account) error {
 	}
 
 	if !found {
-		return fmt.Errorf("account not found: %s", account.Email)
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, account.Email)
 	}
 
 	if err := s.saveAccountsLocked(); err != nil {
@@ -272,6 +314,10 @@ func (s *Service) UpdateAccount(account *models.Account) error {
 
 // DeleteAccount removes an account by ID or email.
 func (s *Service) DeleteAccount(idOrEmail string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -287,10 +333,11 @@ func (s *Service) DeleteAccount(idOrEmail string) error {
 	}
 
 	if idx == -1 {
-		return fmt.Errorf("account not found: %s", idOrEmail)
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, idOrEmail)
 	}
 
 	s.accounts = append(s.accounts[:idx], s.accounts[idx+1:]...)
+	s.doc.remove(idx)
 
 	// Update active account if deleted
 	if s.activeAccount == deleted.ID || s.activeAccount == deleted.Email {
@@ -337,7 +384,7 @@ func (s *Service) Count() int {
 func (s *Service) UpdateAccountEmail(oldEmail, newEmail string) error {
 	acc := s.GetAccountByEmail(oldEmail)
 	if acc == nil {
-		return fmt.Errorf("account not found: %s", oldEmail)
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, oldEmail)
 	}
 
 	newAcc := *acc
@@ -348,19 +395,25 @@ func (s *Service) UpdateAccountEmail(oldEmail, newEmail string) error {
 
 // parseAccounts parses account data handling multiple formats.
 func (s *Service) parseAccounts(data []byte) ([]models.Account, string, error) {
+	accounts, activeAccount, _, err := s.parseAccountsFormat(data)
+	return accounts, activeAccount, err
+}
+
+// parseAccountsFormat parses account data and reports which format it is in.
+func (s *Service) parseAccountsFormat(data []byte) ([]models.Account, string, fileFormat, error) {
 	if accs, active, err := s.parseJSDashboardFormat(data); err == nil {
-		return accs, active, nil
+		return accs, active, formatJSDashboard, nil
 	}
 
 	if accs, active, err := s.parseStandardFormat(data); err == nil {
-		return accs, active, nil
+		return accs, active, formatStandard, nil
 	}
 
 	if accs, active, err := s.parseLegacyFormat(data); err == nil {
-		return accs, active, nil
+		return accs, active, formatLegacy, nil
 	}
 
-	return nil, "", fmt.Errorf("failed to parse accounts file: invalid format")
+	return nil, "", formatStandard, fmt.Errorf("failed to parse accounts file: invalid format")
 }
 
 func (s *Service) parseLegacyFormat(data []byte) ([]models.Account, string, error) {
@@ -458,20 +511,22 @@ func (s *Service) parseStandardFormat(data []byte) ([]models.Account, string, er
 	return accountsFile.Accounts, activeAccount, nil
 }
 
-// loadAccounts loads accounts from the JSON file.
+// loadAccounts loads accounts from the JSON file (must hold lock).
 func (s *Service) loadAccounts() error {
-	data, err := os.ReadFile(s.filePath)
+	data, state, err := readAccountsFile(s.filePath)
 	if err != nil {
 		return err
 	}
 
-	accounts, activeAccount, err := s.parseAccounts(data)
+	accounts, activeAccount, format, err := s.parseAccountsFormat(data)
 	if err != nil {
 		return err
 	}
 
 	s.accounts = accounts
 	s.activeAccount = activeAccount
+	s.doc = newDocument(data, format, accounts, activeAccount)
+	s.disk = state
 	return nil
 }
 
@@ -483,16 +538,38 @@ func (s *Service) saveAccounts() error {
 }
 
 // saveAccountsLocked saves accounts to the JSON file (must hold lock).
+// The file keeps the format it was loaded in, along with any fields this
+// dashboard does not know about. The write happens under the advisory file
+// lock; if another process changed the file since it was last read, our
+// changes are merged onto its contents instead of overwriting them.
 func (s *Service) saveAccountsLocked() error {
-	accountsFile := File{
-		Accounts:      s.accounts,
-		ActiveAccount: s.activeAccount,
-		Version:       1,
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	unlock, err := lockFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	accounts, activeAccount, doc := s.accounts, s.activeAccount, &s.doc
+	current, changed, err := s.changedOnDisk()
+	if err != nil {
+		return err
+	}
+	if changed {
+		var merged document
+		accounts, activeAccount, merged, err = s.mergeLocked(current)
+		if err != nil {
+			return err
+		}
+		doc = &merged
 	}
 
-	data, err := json.MarshalIndent(accountsFile, "", "  ")
+	data, next, err := doc.encode(accounts, activeAccount)
 	if err != nil {
-		return fmt.Errorf("failed to marshal accounts: %w", err)
+		return err
 	}
 
 	// Write to temp file first, then rename
@@ -508,6 +585,17 @@ func (s *Service) saveAccountsLocked() error {
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
+	s.accounts, s.activeAccount, s.doc = accounts, activeAccount, next
+	if info, err := os.Stat(s.filePath); err == nil {
+		s.disk = newDiskState(data, info)
+	} else {
+		s.disk = diskState{}
+	}
+
+	if changed {
+		logger.Info("Merged concurrent changes to the accounts file", "path", s.filePath)
+		s.sendEvent(Event{Type: EventAccountsChanged})
+	}
 	return nil
 }
 
@@ -600,20 +688,7 @@ func (s *Service) handleFileChange() {
 func (s *Service) loadAccountsWithLock() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	data, err := os.ReadFile(s.filePath)
-	if err != nil {
-		return err
-	}
-
-	accounts, activeAccount, err := s.parseAccounts(data)
-	if err != nil {
-		return err
-	}
-
-	s.accounts = accounts
-	s.activeAccount = activeAccount
-	return nil
+	return s.loadAccounts()
 }
 
 // sendEvent sends an event to the event channel non-blocking.
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/daemon"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+)
+
+const (
+	// maxFollowInterval caps how often a follower re-reads the shared
+	// database, so daemon updates show up soon after they are written.
+	maxFollowInterval = 5 * time.Second
+	// followStaleFactor is how many poll intervals stored data may age
+	// before a follower marks it as cached.
+	followStaleFactor = 3
+)
+
+// daemonRunning reports whether the daemon this manager follows is running.
+func (m *Manager) daemonRunning() (int, bool) {
+	if m.daemonPIDPath == "" {
+		return 0, false
+	}
+	return daemon.Running(m.daemonPIDPath)
+}
+
+// DaemonPID returns the PID of the daemon whose data the manager shows, or 0
+// when the manager polls the API itself or the daemon has not written its
+// PID yet.
+func (m *Manager) DaemonPID() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.daemonPID
+}
+
+// FollowingDaemon reports whether quotas are read from a running daemon.
+func (m *Manager) FollowingDaemon() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.following
+}
+
+func (m *Manager) setDaemon(pid int, following bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.daemonPID = pid
+	m.following = following
+}
+
+// followDaemon keeps quotas and projections in sync with the shared database
+// while the daemon runs, and takes over polling once it stops.
+func (m *Manager) followDaemon() {
+	defer m.wg.Done()
+
+	m.syncFromDatabase()
+
+	interval := maxFollowInterval
+	if m.pollInterval > 0 {
+		interval = min(m.pollInterval, maxFollowInterval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pid, running := m.daemonRunning()
+			if !running {
+				logger.Info("daemon stopped, polling quotas directly")
+				m.setDaemon(0, false)
+				m.startCollecting()
+				m.broadcast(m.GetStats())
+				if m.onTakeover != nil {
+					m.onTakeover(m)
+				}
+				return
+			}
+			if pid != m.DaemonPID() {
+				m.setDaemon(pid, true)
+				m.broadcast(m.GetStats())
+			}
+			m.syncFromDatabase()
+
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// syncFromDatabase loads the quotas the daemon last stored and broadcasts
+// the accounts whose data changed. Sessions and projections are derived
+// read-only; the daemon records snapshots and session transitions.
+func (m *Manager) syncFromDatabase() {
+	stored := m.loadStoredQuotas()
+	staleAfter := time.Duration(followStaleFactor) * m.pollInterval
+
+	for email, qi := range stored {
+		qi.Cached = staleAfter > 0 && time.Since(qi.LastUpdated) > staleAfter
+
+		prev := m.quota.GetQuota(email)
+		if prev != nil && prev.LastUpdated.Equal(qi.LastUpdated) &&
+			prev.Cached == qi.Cached && prev.Error == qi.Error && prev.Degraded == qi.Degraded {
+			continue
+		}
+
+		m.quota.SetQuota(email, qi)
+		m.broadcast(QuotaUpdatedEvent{
+			AccountEmail: email,
+			QuotaInfo:    qi,
+		})
+
+		if m.projection == nil || len(qi.ModelQuotas) == 0 {
+			continue
+		}
+		m.projection.FollowSession(email)
+		proj, err := m.projection.ProjectStored(email, qi)
+		if err != nil {
+			logger.Error("failed to calculate projections", "email", email, "error", err)
+		}
+		if proj != nil {
+			m.broadcast(ProjectionUpdatedEvent{
+				Email:      email,
+				Projection: proj,
+			})
+		}
+	}
+}
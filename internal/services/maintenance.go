@@ -0,0 +1,57 @@
+package services
+
+import (
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+)
+
+// maintenanceStartDelay keeps the first maintenance run out of the way of
+// startup work.
+const maintenanceStartDelay = 10 * time.Second
+
+// runMaintenance periodically rolls up and prunes quota history until the
+// manager is closed.
+func (m *Manager) runMaintenance(interval time.Duration) {
+	defer m.wg.Done()
+
+	if interval <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(maintenanceStartDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			m.maintainDatabase()
+			timer.Reset(interval)
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// maintainDatabase runs one maintenance pass and logs what it did.
+func (m *Manager) maintainDatabase() {
+	if m.database == nil {
+		return
+	}
+
+	start := time.Now()
+	result, err := m.database.Maintain(start)
+	if err != nil {
+		logger.Error("database maintenance failed", "error", err)
+		return
+	}
+
+	logger.Debug("database maintenance complete",
+		"raw_deleted", result.RawDeleted,
+		"buckets_deleted", result.BucketsDeleted,
+		"model_buckets_deleted", result.ModelBucketsDeleted,
+		"hourly_deleted", result.HourlyDeleted,
+		"vacuumed", result.Vacuumed,
+		"duration", time.Since(start),
+	)
+}
@@ -2,17 +2,21 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/gen2brain/beeep"
 
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/alerts"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/hooks"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/notify"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/rotation"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/projection"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
@@ -43,12 +47,23 @@ type (
 		Service string
 	}
 
+	// RotationEvent is emitted when an automatic account rotation is
+	// scheduled, cancelled, applied, undone or fails.
+	RotationEvent struct {
+		Error    error
+		Type     rotation.EventType
+		Rotation rotation.Rotation
+	}
+
 	// StatsEvent is emitted when global statistics change.
 	StatsEvent struct {
+		Endpoints      []quota.EndpointHealth // health of the quota endpoints polled by this process
 		AccountCount   int
 		QuotaCached    int
 		TotalRemaining int64
 		TotalLimit     int64
+		DaemonPID      int  // daemon whose data is shown; 0 when polling directly or not yet known
+		Following      bool // quotas are read from a running daemon
 	}
 )
 
@@ -61,31 +76,85 @@ func (AccountsChangedEvent) isServiceEvent()   {}
 func (QuotaUpdatedEvent) isServiceEvent()      {}
 func (ProjectionUpdatedEvent) isServiceEvent() {}
 func (ErrorEvent) isServiceEvent()             {}
+func (RotationEvent) isServiceEvent()          {}
 func (StatsEvent) isServiceEvent()             {}
 
 // Manager orchestrates services and event routing.
 type Manager struct {
-	accounts       *accounts.Service
-	quota          *quota.Service
-	projection     *projection.Service
-	database       *db.DB
-	eventChan      chan ServiceEvent
-	stopChan       chan struct{}
-	previousQuotas map[string]*models.QuotaInfo
-	subscribers    []chan<- ServiceEvent
-	mu             sync.RWMutex
-}
-
-// NewManager creates a new service manager.
-func NewManager(cfg *config.Config) (*Manager, error) {
-	m := &Manager{
-		eventChan:      make(chan ServiceEvent, 100),
-		stopChan:       make(chan struct{}),
-		previousQuotas: make(map[string]*models.QuotaInfo),
+	accounts      *accounts.Service
+	quota         *quota.Service
+	projection    *projection.Service
+	database      *db.DB
+	eventChan     chan ServiceEvent
+	ctx           context.Context
+	cancel        context.CancelFunc
+	alerts        *alerts.Engine
+	notifier      *notify.Dispatcher
+	hooks         *hooks.Runner
+	rotator       *rotation.Rotator
+	lastErrors    map[string]string
+	subscribers   []chan<- ServiceEvent
+	daemonPIDPath string
+	pollInterval  time.Duration
+	maintenance   time.Duration
+	daemonPID     int
+	// following is set while quotas are read from a running daemon; its
+	// PID may still be unknown if the daemon has not written it yet.
+	following bool
+	// onTakeover is called once the manager polls after its daemon stopped.
+	onTakeover func(*Manager)
+	// wg tracks the goroutines that write to the database, so Close can
+	// wait for them before closing it.
+	wg sync.WaitGroup
+	mu sync.RWMutex
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// FollowDaemon makes the manager read quotas from the shared database
+// instead of polling while a daemon holds the lock at pidPath. If the daemon
+// goes away the manager takes over polling.
+func FollowDaemon(pidPath string) Option {
+	return func(m *Manager) {
+		m.daemonPIDPath = pidPath
 	}
+}
+
+// OnTakeover registers fn to be called, from a background goroutine, when a
+// manager following a daemon takes over polling because the daemon stopped.
+// It lets the caller start what the daemon provided, such as the HTTP API.
+func OnTakeover(fn func(*Manager)) Option {
+	return func(m *Manager) {
+		m.onTakeover = fn
+	}
+}
 
-	var err error
-	m.accounts, err = accounts.New(cfg.AccountsPath)
+// NewManager creates a new service manager. Cancelling ctx stops its
+// background work and aborts in-flight API requests; Close still has to be
+// called to release its resources.
+func NewManager(ctx context.Context, cfg *config.Config, opts ...Option) (_ *Manager, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	m := &Manager{
+		eventChan:    make(chan ServiceEvent, 100),
+		ctx:          ctx,
+		cancel:       cancel,
+		lastErrors:   make(map[string]string),
+		pollInterval: cfg.QuotaRefreshInterval,
+		maintenance:  cfg.MaintenanceInterval,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	// Close cancels the context and releases whatever was opened before
+	// the failure; it skips the services that are still nil.
+	defer func() {
+		if err != nil {
+			_ = m.Close()
+		}
+	}()
+
+	m.accounts, err = accounts.New(cfg.AccountsPath, accounts.ReadOnly(cfg.ReadOnlyAccounts))
 	if err != nil {
 		return nil, err
 	}
@@ -94,24 +163,116 @@ func NewManager(cfg *config.Config) (*Manager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
+	m.database.SetRetentionPolicy(db.RetentionPolicy{
+		RawDays:    cfg.RetentionRawDays,
+		BucketDays: cfg.RetentionBucketDays,
+		HourlyDays: cfg.RetentionHourlyDays,
+	})
 
 	m.projection = projection.New(m.database)
 
+	alertConfig, err := alerts.LoadConfig(cfg.AlertRulesPath)
+	if err != nil {
+		return nil, err
+	}
+	notifiers, err := notify.Build(alertConfig.Notifiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize notifiers: %w", err)
+	}
+	m.notifier = notify.NewDispatcher(m.ctx, notifiers, m.database)
+	m.alerts, err = alerts.NewEngine(alertConfig, m.database, m.sendAlert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize alerts: %w", err)
+	}
+
+	hookConfig, err := hooks.LoadConfig(cfg.HooksPath)
+	if err != nil {
+		return nil, err
+	}
+	m.hooks, err = hooks.NewRunner(m.ctx, hookConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hooks: %w", err)
+	}
+
+	switch {
+	case cfg.AutoRotate && cfg.ReadOnlyAccounts:
+		logger.Warn("auto-rotation disabled: the accounts file is read-only")
+	case cfg.AutoRotate:
+		if err := m.initRotation(cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	quotaConfig := quota.DefaultConfig()
 	quotaConfig.ClientID = cfg.GoogleClientID
 	quotaConfig.ClientSecret = cfg.GoogleClientSecret
 	quotaConfig.PollInterval = cfg.QuotaRefreshInterval
+	quotaConfig.MinPollInterval = cfg.QuotaRefreshMin
+	quotaConfig.MaxPollInterval = cfg.QuotaRefreshMax
+	quotaConfig.PollJitter = cfg.QuotaRefreshJitter
+	quotaConfig.CredentialsFunc = config.LoadCredentials
+	quotaConfig.Endpoints = quota.Endpoints{
+		Headers:     cfg.QuotaHeaders,
+		TokenURL:    cfg.GoogleTokenURL,
+		UserInfoURL: cfg.GoogleUserInfoURL,
+		Quota:       cfg.QuotaEndpoints,
+	}
 
-	m.quota = quota.New(m.accounts, quotaConfig)
-	m.quota.Start()
+	if !cfg.HasCredentials() {
+		logger.Warn("OAuth credentials not configured, starting in degraded mode")
+	}
 
+	m.quota = quota.New(m.ctx, m.accounts, quotaConfig)
+	m.seedFromDatabase()
+
+	m.wg.Add(1)
 	go m.routeEvents()
 
+	if pid, running := m.daemonRunning(); running {
+		logger.Info("daemon is running, reading quotas from the shared database", "pid", pid)
+		m.setDaemon(pid, true)
+		m.wg.Add(1)
+		go m.followDaemon()
+	} else {
+		m.startCollecting()
+	}
+
 	return m, nil
 }
 
+// startCollecting starts polling the API and maintaining the database.
+func (m *Manager) startCollecting() {
+	m.quota.Start()
+	m.notifier.Start()
+	m.hooks.Start()
+
+	m.wg.Add(1)
+	go m.runMaintenance(m.maintenance)
+}
+
+// initRotation sets up automatic rotation of the active account.
+func (m *Manager) initRotation(cfg *config.Config) error {
+	strategy, err := rotation.ParseStrategy(cfg.AutoRotateStrategy)
+	if err != nil {
+		return fmt.Errorf("invalid AUTO_ROTATE_STRATEGY: %w", err)
+	}
+	m.rotator, err = rotation.New(rotation.Config{
+		Strategy: strategy,
+		Model:    cfg.AutoRotateModel,
+		Delay:    cfg.AutoRotateDelay,
+	}, m.accounts, m.sendRotation)
+	if err != nil {
+		return fmt.Errorf("invalid AUTO_ROTATE_MODEL: %w", err)
+	}
+	logger.Info("auto-rotation enabled", "strategy", strategy.Name(), "model", cfg.AutoRotateModel,
+		"delay", cfg.AutoRotateDelay)
+	return nil
+}
+
 // routeEvents routes events from individual services to subscribers.
 func (m *Manager) routeEvents() {
+	defer m.wg.Done()
+
 	for {
 		select {
 		case event := <-m.accounts.Events():
@@ -120,7 +281,7 @@ func (m *Manager) routeEvents() {
 		case event := <-m.quota.Events():
 			m.handleQuotaEvent(event)
 
-		case <-m.stopChan:
+		case <-m.ctx.Done():
 			return
 		}
 	}
@@ -133,10 +294,20 @@ func (m *Manager) handleAccountEvent(event accounts.Event) {
 		accounts.EventAccountAdded, accounts.EventAccountUpdated,
 		accounts.EventAccountDeleted, accounts.EventActiveAccountChanged:
 
+		accs, active := m.accounts.GetAccounts(), m.accounts.GetActiveAccount()
 		m.broadcast(AccountsChangedEvent{
-			Accounts:      m.accounts.GetAccounts(),
-			ActiveAccount: m.accounts.GetActiveAccount(),
+			Accounts:      accs,
+			ActiveAccount: active,
 		})
+		if event.Type != accounts.EventAccountsLoaded && m.hooks != nil {
+			m.hooks.ObserveAccounts(accs, active)
+		}
+		if m.rotator != nil {
+			m.rotator.ObserveAccounts()
+		}
+		if m.quota != nil {
+			m.quota.Reschedule()
+		}
 
 	case accounts.EventError:
 		m.broadcast(ErrorEvent{
@@ -155,14 +326,60 @@ func (m *Manager) handleQuotaEvent(event quota.Event) {
 		})
 
 		if event.QuotaInfo != nil {
-			m.checkNotifications(event.AccountEmail, event.QuotaInfo)
+			m.persistQuota(event.QuotaInfo)
 		}
+		m.setLastError(event.AccountEmail, "")
 
+		if m.alerts != nil {
+			m.alerts.ObserveQuota(event.QuotaInfo)
+		}
+		if m.hooks != nil {
+			m.hooks.ObserveQuota(event.QuotaInfo)
+		}
+		if m.rotator != nil {
+			m.rotator.ObserveQuota(event.QuotaInfo)
+		}
+
+		// Projections are updated in event order, so sessions and resets
+		// are never tracked from an older refresh after a newer one.
 		if m.projection != nil && event.QuotaInfo != nil {
-			go m.updateProjection(event.AccountEmail, event.QuotaInfo)
+			m.updateProjection(event.AccountEmail, event.QuotaInfo)
+		}
+
+	case quota.EventTokenRevoked:
+		if m.alerts != nil {
+			m.alerts.ObserveTokenRevoked(event.AccountEmail, event.Error)
+		}
+		if m.database != nil {
+			fingerprint := m.quota.RevokedToken(event.AccountEmail)
+			if err := m.database.SetAccountTokenRevoked(event.AccountEmail, fingerprint); err != nil {
+				logger.Error("failed to persist revoked token", "email", event.AccountEmail, "error", err)
+			}
 		}
 
 	case quota.EventQuotaError, quota.EventTokenError:
+		if event.Type == quota.EventTokenError && m.alerts != nil {
+			m.alerts.ObserveTokenFailure(event.AccountEmail, event.Error)
+		}
+
+		if m.database != nil && event.AccountEmail != "" && event.Error != nil {
+			if err := m.database.UpdateAccountStatusError(event.AccountEmail, event.Error.Error()); err != nil {
+				logger.Error("failed to persist account error", "email", event.AccountEmail, "error", err)
+			}
+		}
+
+		// Let the UI pick up the degraded state of the account.
+		if event.QuotaInfo != nil {
+			m.broadcast(QuotaUpdatedEvent{
+				AccountEmail: event.AccountEmail,
+				QuotaInfo:    event.QuotaInfo,
+			})
+		}
+
+		// While offline every poll fails the same way; only report changes.
+		if event.Error != nil && !m.setLastError(event.AccountEmail, errorKey(event.Error)) {
+			return
+		}
 		m.broadcast(ErrorEvent{
 			Service: "quota",
 			Error:   event.Error,
@@ -170,83 +387,185 @@ func (m *Manager) handleQuotaEvent(event quota.Event) {
 	}
 }
 
-func (m *Manager) checkNotifications(email string, newQuota *models.QuotaInfo) {
-	oldQuota, exists := m.previousQuotas[email]
-	m.previousQuotas[email] = newQuota
+// errorKey groups errors that share a degraded reason so repeated failures
+// with slightly different messages are not reported again.
+func errorKey(err error) string {
+	if reason := quota.ClassifyError(err); reason != models.DegradedNone {
+		return string(reason)
+	}
+	return err.Error()
+}
+
+// setLastError records the last error key for an account and reports
+// whether it changed.
+func (m *Manager) setLastError(email, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.lastErrors == nil {
+		m.lastErrors = make(map[string]string)
+	}
+	if m.lastErrors[email] == key {
+		return false
+	}
+	if key == "" {
+		delete(m.lastErrors, email)
+	} else {
+		m.lastErrors[email] = key
+	}
+	return true
+}
+
+// persistQuota writes the account's current status and a raw snapshot.
+func (m *Manager) persistQuota(quotaInfo *models.QuotaInfo) {
+	if m.database == nil {
+		return
+	}
+
+	status := quotaInfo.ToAccountStatus()
+	if err := m.database.UpsertAccountStatus(&status); err != nil {
+		logger.Error("failed to persist account status", "email", status.Email, "error", err)
+	}
+
+	snapshot := quotaInfo.ToSnapshot()
+	if err := m.database.InsertQuotaSnapshot(&snapshot); err != nil {
+		logger.Error("failed to persist quota snapshot", "email", snapshot.Email, "error", err)
+	}
+}
 
-	if !exists {
+// seedFromDatabase restores last-known quotas from account_status so the
+// dashboard has something to show before the first refresh completes.
+func (m *Manager) seedFromDatabase() {
+	seeded := m.loadStoredQuotas()
+	if seeded == nil {
 		return
 	}
 
-	// Check for critical quota (< 5%)
-	// Only notify if we crossed the threshold downwards
-	if newQuota.TotalLimit > 0 {
-		newPercent := float64(newQuota.TotalRemaining) / float64(newQuota.TotalLimit) * 100
-		oldPercent := float64(oldQuota.TotalRemaining) / float64(oldQuota.TotalLimit) * 100
+	m.quota.SeedQuotas(seeded)
 
-		if newPercent < 5.0 && oldPercent >= 5.0 {
-			title := fmt.Sprintf("Critical Quota: %s", email)
-			body := fmt.Sprintf("Remaining quota is below 5%% (%.1f%%)", newPercent)
-			if err := beeep.Notify(title, body, ""); err != nil {
-				logger.Error("failed to send notification", "error", err)
-			}
+	revoked := make(map[string]string)
+	for email, qi := range seeded {
+		if acc := m.accounts.GetAccountByEmail(email); acc != nil && qi.Degraded == models.DegradedTokenRevoked {
+			revoked[email] = quota.TokenFingerprint(acc.RefreshToken)
 		}
 	}
+	m.quota.SeedRevoked(revoked)
 
-	// Check for reset (significant increase)
-	// If remaining increases by more than 20% of limit
-	if newQuota.TotalRemaining > oldQuota.TotalRemaining {
-		diff := newQuota.TotalRemaining - oldQuota.TotalRemaining
-		if newQuota.TotalLimit > 0 {
-			percentDiff := float64(diff) / float64(newQuota.TotalLimit) * 100
-			if percentDiff > 20.0 {
-				title := fmt.Sprintf("Quota Reset: %s", email)
-				body := "Your quota has been refreshed."
-				if err := beeep.Notify(title, body, ""); err != nil {
-					logger.Error("failed to send notification", "error", err)
-				}
-			}
-		}
+	if m.projection == nil {
+		return
+	}
+	for email, qi := range seeded {
+		m.restoreProjection(email, qi)
 	}
 }
 
-func (m *Manager) updateProjection(email string, quotaInfo *models.QuotaInfo) {
-	claudePercent := 0.0
-	geminiPercent := 0.0
-	var claudeReset, geminiReset time.Time
-	tier := quotaInfo.SubscriptionTier
+// loadStoredQuotas returns the last-known quota of every configured account
+// from account_status, or nil if it cannot be read. A revoked token is only
+// reported while the account still uses it.
+func (m *Manager) loadStoredQuotas() map[string]*models.QuotaInfo {
+	statuses, err := m.database.GetAllAccountStatuses()
+	if err != nil {
+		logger.Error("failed to load account statuses", "error", err)
+		return nil
+	}
 
-	for i := range quotaInfo.ModelQuotas {
-		mq := &quotaInfo.ModelQuotas[i]
-		percent := 0.0
-		if mq.Limit > 0 {
-			percent = float64(mq.Remaining) / float64(mq.Limit) * 100
+	tokens := make(map[string]string)
+	for _, acc := range m.accounts.GetAccounts() {
+		tokens[acc.Email] = quota.TokenFingerprint(acc.RefreshToken)
+	}
+
+	stored := make(map[string]*models.QuotaInfo, len(statuses))
+	for i := range statuses {
+		fingerprint, known := tokens[statuses[i].Email]
+		if !known {
+			continue
 		}
-		switch mq.ModelFamily {
-		case "claude":
-			if claudePercent == 0 || percent < claudePercent {
-				claudePercent = percent
-				claudeReset = mq.ResetTime
-			}
-		case "gemini":
-			if geminiPercent == 0 || percent < geminiPercent {
-				geminiPercent = percent
-				geminiReset = mq.ResetTime
-			}
+		if statuses[i].RevokedToken != fingerprint {
+			statuses[i].RevokedToken = ""
+		}
+		if qi := statuses[i].ToQuotaInfo(); qi != nil {
+			stored[statuses[i].Email] = qi
 		}
 	}
+	return stored
+}
 
-	sessionID := m.projection.GetOrCreateSessionID(email, claudeReset)
+// restoreProjection resumes the account's last session and computes a
+// projection from last-known data so it is available while offline.
+func (m *Manager) restoreProjection(email string, quotaInfo *models.QuotaInfo) {
+	if len(quotaInfo.ModelQuotas) == 0 {
+		return
+	}
 
-	if err := m.projection.AggregateSnapshot(email, claudePercent, geminiPercent, tier, sessionID); err != nil {
+	last, err := m.database.GetLastAggregatedSnapshot(email)
+	if err != nil {
+		logger.Error("failed to load last snapshot", "email", email, "error", err)
+	} else if last != nil {
+		m.projection.RestoreSession(email, last.SessionID)
+	}
+
+	if _, err := m.projection.ProjectStored(email, quotaInfo); err != nil {
+		logger.Error("failed to restore projection", "email", email, "error", err)
+	}
+}
+
+// sendAlert hands an alert to its notifiers.
+func (m *Manager) sendAlert(a alerts.Alert) {
+	m.notifier.Send(notify.Message{
+		At:       a.At,
+		RuleID:   a.RuleID,
+		Kind:     string(a.Kind),
+		Severity: a.Severity,
+		Email:    a.Email,
+		Model:    a.Model,
+		Title:    a.Title,
+		Body:     a.Body,
+	}, a.Notifiers)
+}
+
+// sendRotation passes a rotation change on to subscribers.
+func (m *Manager) sendRotation(ev rotation.Event) {
+	m.broadcast(RotationEvent{
+		Type:     ev.Type,
+		Rotation: ev.Rotation,
+		Error:    ev.Error,
+	})
+}
+
+func (m *Manager) updateProjection(email string, quotaInfo *models.QuotaInfo) {
+	// A family without models rolls up to -1: it is stored as 0% and gets
+	// no family projection.
+	claudePercent, claudeReset := quotaInfo.FamilyRollup("claude")
+	geminiPercent, geminiReset := quotaInfo.FamilyRollup("gemini")
+	tier := quotaInfo.SubscriptionTier
+
+	obs := projection.ObservationFromQuota(quotaInfo, time.Now())
+	obs.Email = email
+	sessionID := m.projection.TrackSession(obs)
+
+	if err := m.projection.AggregateSnapshot(
+		email, max(claudePercent, 0), max(geminiPercent, 0), tier, sessionID); err != nil {
 		logger.Error("failed to aggregate snapshot", "error", err)
 	}
 
+	if err := m.projection.AggregateModelSnapshots(email, quotaInfo.ModelQuotas, tier, sessionID); err != nil {
+		logger.Error("failed to aggregate model snapshots", "error", err)
+	}
+
 	proj, err := m.projection.CalculateProjections(email, claudePercent, geminiPercent, claudeReset, geminiReset)
 	if err != nil {
 		logger.Error("failed to calculate projections", "error", err)
 	}
 	if proj != nil {
+		if m.alerts != nil {
+			m.alerts.ObserveProjection(proj)
+		}
+		if m.hooks != nil {
+			m.hooks.ObserveProjection(proj)
+		}
+		if m.rotator != nil {
+			m.rotator.ObserveProjection(proj)
+		}
 		m.broadcast(ProjectionUpdatedEvent{
 			Email:      email,
 			Projection: proj,
@@ -331,25 +650,66 @@ func (m *Manager) GetAccountsWithQuota() []models.AccountWithQuota {
 	return result
 }
 
-// RefreshQuota forces a refresh of quota for all accounts.
+// RefreshQuota forces a refresh of quota for all accounts. While following a
+// daemon it re-reads the shared database instead.
 func (m *Manager) RefreshQuota() {
+	if m.FollowingDaemon() {
+		m.syncFromDatabase()
+		return
+	}
 	m.quota.RefreshAllQuotas()
 }
 
 // RefreshQuotaForAccount forces a refresh of quota for a specific account.
 func (m *Manager) RefreshQuotaForAccount(email string) (*models.QuotaInfo, error) {
+	if m.FollowingDaemon() {
+		m.syncFromDatabase()
+		return m.quota.GetQuota(email), nil
+	}
 	return m.quota.RefreshQuota(email)
 }
 
+// SetFocusAccount prioritises the account selected in the dashboard for
+// background quota refreshes.
+func (m *Manager) SetFocusAccount(email string) {
+	m.quota.SetFocus(email)
+}
+
+// SetActiveAccount switches the active account by ID or email.
+func (m *Manager) SetActiveAccount(idOrEmail string) error {
+	return m.accounts.SetActiveAccount(idOrEmail)
+}
+
+// PendingRotation returns the automatic rotation that is counting down, or
+// nil.
+func (m *Manager) PendingRotation() *rotation.Rotation {
+	if m.rotator == nil {
+		return nil
+	}
+	return m.rotator.Pending()
+}
+
+// UndoRotation cancels the pending automatic rotation or reverts the last
+// one. It returns rotation.ErrNothingToUndo when there is nothing to undo.
+func (m *Manager) UndoRotation() error {
+	if m.rotator == nil {
+		return rotation.ErrNothingToUndo
+	}
+	return m.rotator.Undo()
+}
+
 // GetStats returns aggregated statistics.
 func (m *Manager) GetStats() StatsEvent {
 	quotaStats := m.quota.GetStats()
 
 	return StatsEvent{
+		Endpoints:      m.quota.FetchHealth(),
 		AccountCount:   m.accounts.Count(),
 		QuotaCached:    quotaStats.CachedQuotas,
 		TotalRemaining: quotaStats.TotalRemaining,
 		TotalLimit:     quotaStats.TotalLimit,
+		DaemonPID:      m.DaemonPID(),
+		Following:      m.FollowingDaemon(),
 	}
 }
 
@@ -376,6 +736,22 @@ func (m *Manager) GetAllProjections() map[string]*models.AccountProjection {
 	return m.projection.GetAllProjections()
 }
 
+// TokenRefreshFailures returns the failed token refreshes per account.
+func (m *Manager) TokenRefreshFailures() map[string]uint64 {
+	if m.quota == nil {
+		return nil
+	}
+	return m.quota.TokenRefreshFailures()
+}
+
+// FetchMetrics returns the quota fetch metrics per endpoint.
+func (m *Manager) FetchMetrics() []quota.EndpointMetrics {
+	if m.quota == nil {
+		return nil
+	}
+	return m.quota.FetchMetrics()
+}
+
 // GetAccountHistory retrieves historical statistics for a specific account.
 func (m *Manager) GetAccountHistory(email string, timeRange models.TimeRange) (*models.AccountHistoryStats, error) {
 	if m.database == nil {
@@ -389,11 +765,14 @@ func (m *Manager) Database() *db.DB {
 	return m.database
 }
 
-// Close closes the manager and all its services.
+// Close closes the manager and all its services. It cancels in-flight API
+// requests and returns once every goroutine that writes to the database has
+// stopped, so the database is closed last.
 func (m *Manager) Close() error {
-	if m.stopChan != nil {
-		close(m.stopChan)
+	if m.cancel != nil {
+		m.cancel()
 	}
+	m.wg.Wait()
 
 	m.mu.Lock()
 	for _, sub := range m.subscribers {
@@ -404,6 +783,10 @@ func (m *Manager) Close() error {
 
 	var errs []error
 
+	if m.rotator != nil {
+		m.rotator.Close()
+	}
+
 	if m.accounts != nil {
 		if err := m.accounts.Close(); err != nil {
 			errs = append(errs, err)
@@ -416,6 +799,15 @@ func (m *Manager) Close() error {
 		}
 	}
 
+	if m.hooks != nil {
+		m.hooks.Close()
+	}
+
+	// Pending webhook retries are queued in the database before it closes.
+	if m.notifier != nil {
+		m.notifier.Close()
+	}
+
 	if m.database != nil {
 		if err := m.database.Close(); err != nil {
 			errs = append(errs, err)
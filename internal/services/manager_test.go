@@ -2,11 +2,19 @@ package services
 
 import (
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/alerts"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/daemon"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/rotation"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/projection"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
 )
 
 // MockAccountProvider for testing
@@ -25,7 +33,7 @@ func TestNewManager(t *testing.T) {
 		QuotaRefreshInterval: time.Minute,
 	}
 
-	mgr, err := NewManager(cfg)
+	mgr, err := NewManager(t.Context(), cfg)
 	if err != nil {
 		t.Fatalf("NewManager failed: %v", err)
 	}
@@ -51,7 +59,7 @@ func TestManager_Getters(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	if mgr.GetAccountsWithQuota() == nil {
@@ -77,7 +85,7 @@ func TestManager_Subscription(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	ch, cmd := mgr.Subscribe()
@@ -108,7 +116,7 @@ func TestManager_InitialState(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	accs, stats := mgr.InitialState()
@@ -126,7 +134,7 @@ func TestManager_Broadcast(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	ch, _ := mgr.Subscribe()
@@ -143,7 +151,7 @@ func TestManager_Broadcast(t *testing.T) {
 
 	select {
 	case e := <-ch:
-		if e != event {
+		if !reflect.DeepEqual(e, event) {
 			t.Errorf("Got event %v, want %v", e, event)
 		}
 	case <-time.After(time.Second):
@@ -151,36 +159,117 @@ func TestManager_Broadcast(t *testing.T) {
 	}
 }
 
-func TestManager_CheckNotifications(t *testing.T) {
-	// Private method test
+func TestManager_AlertsOnQuotaUpdates(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := &config.Config{
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, err := NewManager(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
 	defer mgr.Close()
 
+	var fired []alerts.Alert
+	mgr.alerts, err = alerts.NewEngine(alerts.DefaultConfig(), mgr.database, func(a alerts.Alert) {
+		fired = append(fired, a)
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
 	email := "test@example.com"
+	update := func(remaining int64) {
+		mgr.alerts.ObserveQuota(&models.QuotaInfo{
+			AccountEmail: email,
+			ModelQuotas: []models.ModelQuota{
+				{ModelID: "claude-sonnet-4-5", ModelFamily: "claude", Limit: 100, Remaining: remaining},
+			},
+		})
+	}
 
-	// First update (no previous)
-	quota1 := &models.QuotaInfo{
-		AccountEmail:   email,
-		TotalLimit:     100,
-		TotalRemaining: 50,
+	update(50)
+	update(4)
+	update(3)
+	if len(fired) != 1 || fired[0].RuleID != "quota-low" {
+		t.Fatalf("fired = %+v, want one quota-low alert", fired)
 	}
-	mgr.checkNotifications(email, quota1)
 
-	// Second update - drop below 5%
-	quota2 := &models.QuotaInfo{
-		AccountEmail:   email,
-		TotalLimit:     100,
-		TotalRemaining: 4,
+	update(90)
+	if len(fired) != 2 || fired[1].RuleID != "quota-reset" {
+		t.Fatalf("fired = %+v, want a quota-reset alert", fired)
+	}
+}
+
+func TestManager_AutoRotation(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(tmpDir+"/accounts.json", []byte(`{"activeAccount":"a@example.com","accounts":[
+		{"email":"a@example.com","refreshToken":"rt"},{"email":"b@example.com","refreshToken":"rt"}]}`), 0600)
+	os.WriteFile(tmpDir+"/alerts.json", []byte(`{"rules":[]}`), 0600)
+	cfg := &config.Config{
+		DatabasePath:       tmpDir + "/test.db",
+		AccountsPath:       tmpDir + "/accounts.json",
+		AlertRulesPath:     tmpDir + "/alerts.json",
+		AutoRotate:         true,
+		AutoRotateStrategy: "round_robin",
+	}
+	mgr, err := NewManager(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	defer mgr.Close()
+	ch, _ := mgr.Subscribe()
+
+	update := func(email string, rateLimited bool) {
+		mgr.handleQuotaEvent(quota.Event{
+			Type:         quota.EventQuotaUpdated,
+			AccountEmail: email,
+			QuotaInfo: &models.QuotaInfo{
+				AccountEmail: email,
+				ModelQuotas: []models.ModelQuota{
+					{ModelID: "claude-sonnet-4-5", ModelFamily: "claude", Limit: 100, Remaining: 50, IsRateLimited: rateLimited},
+				},
+			},
+		})
+	}
+	update("b@example.com", false)
+	update("a@example.com", true)
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-ch:
+			if re, ok := ev.(RotationEvent); ok && re.Type == rotation.EventSwitched {
+				if active := mgr.Accounts().GetActiveAccount(); active.Email != "b@example.com" {
+					t.Fatalf("active = %s, want b@example.com", active.Email)
+				}
+				if err := mgr.UndoRotation(); err != nil {
+					t.Fatalf("UndoRotation() error = %v", err)
+				}
+				if active := mgr.Accounts().GetActiveAccount(); active.Email != "a@example.com" {
+					t.Errorf("active after undo = %s, want a@example.com", active.Email)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("no rotation event")
+		}
+	}
+}
+
+func TestManager_AutoRotationInvalidStrategy(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		DatabasePath:       tmpDir + "/test.db",
+		AccountsPath:       tmpDir + "/accounts.json",
+		AutoRotate:         true,
+		AutoRotateStrategy: "random",
+	}
+	if mgr, err := NewManager(t.Context(), cfg); err == nil {
+		mgr.Close()
+		t.Fatal("NewManager() should reject an unknown strategy")
 	}
-	// This would trigger beeep.Notify, which might fail or panic in headless env if not mocked.
-	// beeep usually detects environment.
-	// We just hope it doesn't panic.
-	mgr.checkNotifications(email, quota2)
 }
 
 func TestWaitForEvent(t *testing.T) {
@@ -216,7 +305,7 @@ func TestManager_GetAccountHistory(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	// Should fail if no data or return empty stats
@@ -237,13 +326,66 @@ func TestManager_Close(t *testing.T) {
 	}
 }
 
+func TestManager_CloseWaitsForWriters(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		DatabasePath: tmpDir + "/test.db",
+		AccountsPath: tmpDir + "/accounts.json",
+	}
+	mgr, err := NewManager(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	email := "writer@example.com"
+	mgr.handleQuotaEvent(quota.Event{
+		Type:         quota.EventQuotaUpdated,
+		AccountEmail: email,
+		QuotaInfo: &models.QuotaInfo{
+			AccountEmail: email,
+			LastUpdated:  time.Now(),
+			ModelQuotas:  []models.ModelQuota{{ModelFamily: "claude", Limit: 100, Remaining: 50}},
+		},
+	})
+
+	// Hold Close up like a slow writer would.
+	mgr.wg.Add(1)
+	closed := make(chan error, 1)
+	go func() { closed <- mgr.Close() }()
+	select {
+	case <-closed:
+		t.Fatal("Close() returned while a writer was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := mgr.database.Ping(); err != nil {
+		t.Errorf("the database was closed under a running writer: %v", err)
+	}
+	mgr.wg.Done()
+	if err := <-closed; err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	database, err := db.New(cfg.DatabasePath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+	var written int
+	if err := database.QueryRow("SELECT COUNT(*) FROM quota_snapshots_agg WHERE email = ?", email).Scan(&written); err != nil {
+		t.Fatal(err)
+	}
+	if written != 1 {
+		t.Error("Close() returned before the projection update was written")
+	}
+}
+
 func TestManager_UpdateProjection(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := &config.Config{
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	email := "test@example.com"
@@ -259,6 +401,41 @@ func TestManager_UpdateProjection(t *testing.T) {
 	// Async, might not update immediately, but ensures coverage of function
 }
 
+func TestManager_QuotaEventsProjectInOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		DatabasePath: tmpDir + "/test.db",
+		AccountsPath: tmpDir + "/accounts.json",
+	}
+	mgr, err := NewManager(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	defer mgr.Close()
+
+	email := "test@example.com"
+	reset := time.Now().Add(time.Hour)
+	for _, remaining := range []int64{80, 60, 40} {
+		mgr.handleQuotaEvent(quota.Event{
+			Type:         quota.EventQuotaUpdated,
+			AccountEmail: email,
+			QuotaInfo: &models.QuotaInfo{
+				AccountEmail: email,
+				LastUpdated:  time.Now(),
+				ModelQuotas: []models.ModelQuota{
+					{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: remaining, ResetTime: reset},
+				},
+			},
+		})
+	}
+
+	// Each event is projected before the next is handled.
+	proj := mgr.GetAllProjections()[email]
+	if proj == nil || proj.Claude == nil || proj.Claude.CurrentPercent != 40 {
+		t.Fatalf("projection after the last event = %+v, want it from the last refresh", proj)
+	}
+}
+
 func TestManager_HandleEvents(t *testing.T) {
 	// Coverage for event handling logic
 	// We can't easily inject events into internal channels from outside
@@ -270,7 +447,7 @@ func TestManager_HandleEvents(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	// Create dummy events
@@ -290,7 +467,7 @@ func TestManager_RefreshQuota(t *testing.T) {
 		DatabasePath: tmpDir + "/test.db",
 		AccountsPath: tmpDir + "/accounts.json",
 	}
-	mgr, _ := NewManager(cfg)
+	mgr, _ := NewManager(t.Context(), cfg)
 	defer mgr.Close()
 
 	mgr.RefreshQuota()
@@ -302,3 +479,261 @@ func TestManager_RefreshQuota(t *testing.T) {
 		// Actually RefreshQuota might return "account not found" inside internal service logic
 	}
 }
+
+func TestManager_PersistAndSeed(t *testing.T) {
+	tmpDir := t.TempDir()
+	email := "seed@example.com"
+	os.WriteFile(tmpDir+"/accounts.json",
+		[]byte(`{"accounts":[{"id":"1","email":"seed@example.com","refreshToken":"rt"}]}`), 0600)
+
+	database, err := db.New(tmpDir + "/test.db")
+	if err != nil {
+		t.Fatalf("db.New failed: %v", err)
+	}
+	accSvc, err := accounts.New(tmpDir + "/accounts.json")
+	if err != nil {
+		t.Fatalf("accounts.New failed: %v", err)
+	}
+	mgr := &Manager{
+		accounts:   accSvc,
+		database:   database,
+		projection: projection.New(database),
+		quota:      quota.New(t.Context(), accSvc, quota.DefaultConfig()),
+	}
+	defer mgr.Close()
+
+	now := time.Now()
+	mgr.persistQuota(&models.QuotaInfo{
+		AccountEmail:     email,
+		LastUpdated:      now,
+		SubscriptionTier: "PRO",
+		TotalRemaining:   110,
+		TotalLimit:       200,
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 40, ResetTime: now.Add(time.Hour)},
+			{ModelID: "gemini-pro", ModelFamily: "gemini", Limit: 100, Remaining: 70},
+		},
+	})
+
+	status, err := database.GetAccountStatus(email)
+	if err != nil || status == nil {
+		t.Fatalf("expected persisted status, got %v (err %v)", status, err)
+	}
+	if status.ClaudeQuota != 40 || status.GeminiQuota != 70 || status.Tier != "PRO" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	var snapshots int
+	database.QueryRow("SELECT COUNT(*) FROM quota_snapshots WHERE email = ?", email).Scan(&snapshots)
+	if snapshots != 1 {
+		t.Errorf("expected 1 raw snapshot, got %d", snapshots)
+	}
+
+	mgr.seedFromDatabase()
+
+	qi := mgr.quota.GetQuota(email)
+	if qi == nil || !qi.Cached {
+		t.Fatalf("expected cached quota after seeding, got %+v", qi)
+	}
+	if pct, _ := qi.FamilyRollup("claude"); pct != 40 {
+		t.Errorf("expected seeded claude 40%%, got %v", pct)
+	}
+
+	if proj := mgr.GetAllProjections()[email]; proj == nil || proj.Claude.CurrentPercent != 40 {
+		t.Errorf("expected projection restored from last-known data, got %+v", proj)
+	}
+}
+
+func TestManager_SeedRevokedToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	email := "seed@example.com"
+	accountsPath := tmpDir + "/accounts.json"
+	os.WriteFile(accountsPath,
+		[]byte(`{"accounts":[{"id":"1","email":"seed@example.com","refreshToken":"dead"}]}`), 0600)
+
+	database, err := db.New(tmpDir + "/test.db")
+	if err != nil {
+		t.Fatalf("db.New failed: %v", err)
+	}
+	accSvc, err := accounts.New(accountsPath)
+	if err != nil {
+		t.Fatalf("accounts.New failed: %v", err)
+	}
+	mgr := &Manager{
+		accounts: accSvc,
+		database: database,
+		quota:    quota.New(t.Context(), accSvc, quota.DefaultConfig()),
+	}
+	defer mgr.Close()
+
+	if err := database.SetAccountTokenRevoked(email, quota.TokenFingerprint("dead")); err != nil {
+		t.Fatalf("SetAccountTokenRevoked failed: %v", err)
+	}
+
+	mgr.seedFromDatabase()
+	if qi := mgr.quota.GetQuota(email); qi == nil || qi.Degraded != models.DegradedTokenRevoked {
+		t.Fatalf("expected the revoked token to be restored, got %+v", qi)
+	}
+	if mgr.quota.RevokedToken(email) == "" {
+		t.Error("polling should stay paused for the revoked token")
+	}
+
+	// After logging in again the stored revocation no longer applies.
+	acc := accSvc.GetAccountByEmail(email)
+	acc.RefreshToken = "fresh"
+	if err := accSvc.UpdateAccount(acc); err != nil {
+		t.Fatalf("UpdateAccount failed: %v", err)
+	}
+	if qi := mgr.loadStoredQuotas()[email]; qi != nil && qi.Degraded == models.DegradedTokenRevoked {
+		t.Errorf("a replaced token should not be reported as revoked, got %+v", qi)
+	}
+}
+
+func TestManager_SetLastError(t *testing.T) {
+	mgr := &Manager{}
+
+	if !mgr.setLastError("a@example.com", "network unreachable") {
+		t.Error("first error should be reported")
+	}
+	if mgr.setLastError("a@example.com", "network unreachable") {
+		t.Error("repeated error should be suppressed")
+	}
+	if !mgr.setLastError("a@example.com", "") {
+		t.Error("recovery should be reported as a change")
+	}
+	if !mgr.setLastError("a@example.com", "network unreachable") {
+		t.Error("error after recovery should be reported again")
+	}
+}
+
+func TestManager_FollowDaemon(t *testing.T) {
+	tmpDir := t.TempDir()
+	email := "follow@example.com"
+	os.WriteFile(tmpDir+"/accounts.json",
+		[]byte(`{"accounts":[{"id":"1","email":"follow@example.com","refreshToken":"rt"}]}`), 0600)
+
+	pidFile, err := daemon.Acquire(tmpDir + "/adt-daemon.pid")
+	if err != nil {
+		t.Fatalf("daemon.Acquire failed: %v", err)
+	}
+	defer pidFile.Release()
+
+	cfg := &config.Config{
+		DatabasePath:         tmpDir + "/test.db",
+		AccountsPath:         tmpDir + "/accounts.json",
+		QuotaRefreshInterval: time.Minute,
+	}
+	mgr, err := NewManager(t.Context(), cfg, FollowDaemon(pidFile.Path()))
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	defer mgr.Close()
+
+	if !mgr.FollowingDaemon() || mgr.GetStats().DaemonPID != os.Getpid() {
+		t.Fatalf("expected manager to follow daemon pid %d, got %d", os.Getpid(), mgr.DaemonPID())
+	}
+
+	// The daemon persists a refresh; the follower picks it up from the database.
+	status := (&models.QuotaInfo{
+		AccountEmail: email,
+		LastUpdated:  time.Now(),
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 35},
+		},
+	}).ToAccountStatus()
+	if err := mgr.Database().UpsertAccountStatus(&status); err != nil {
+		t.Fatalf("UpsertAccountStatus failed: %v", err)
+	}
+
+	mgr.RefreshQuota()
+
+	qi := mgr.Quota().GetQuota(email)
+	if qi == nil || qi.Cached || len(qi.ModelQuotas) != 1 || qi.ModelQuotas[0].ModelID != "claude-sonnet" {
+		t.Fatalf("expected fresh per-model quota from the database, got %+v", qi)
+	}
+	if proj := mgr.GetAllProjections()[email]; proj == nil || len(proj.Models) != 1 {
+		t.Errorf("expected projection with per-model detail, got %+v", proj)
+	}
+}
+
+func TestManager_FollowDaemon_PIDNotWritten(t *testing.T) {
+	tmpDir := t.TempDir()
+	pidFile, err := daemon.Acquire(tmpDir + "/adt-daemon.pid")
+	if err != nil {
+		t.Fatalf("daemon.Acquire failed: %v", err)
+	}
+	defer pidFile.Release()
+	// The daemon holds the lock but has not written its PID yet.
+	if err := os.Truncate(pidFile.Path(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		DatabasePath:         tmpDir + "/test.db",
+		AccountsPath:         tmpDir + "/accounts.json",
+		QuotaRefreshInterval: 10 * time.Millisecond,
+	}
+	mgr, err := NewManager(t.Context(), cfg, FollowDaemon(pidFile.Path()))
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	defer mgr.Close()
+
+	if stats := mgr.GetStats(); !mgr.FollowingDaemon() || !stats.Following || stats.DaemonPID != 0 {
+		t.Fatalf("FollowingDaemon() = %v, stats = %+v; want following with an unknown pid", mgr.FollowingDaemon(), stats)
+	}
+
+	if err := os.WriteFile(pidFile.Path(), []byte("4242\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for mgr.DaemonPID() != 4242 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if mgr.DaemonPID() != 4242 || !mgr.FollowingDaemon() {
+		t.Errorf("DaemonPID() = %d, want the pid once the daemon writes it", mgr.DaemonPID())
+	}
+}
+
+func TestManager_FollowDaemon_TakesOver(t *testing.T) {
+	tmpDir := t.TempDir()
+	pidFile, err := daemon.Acquire(tmpDir + "/adt-daemon.pid")
+	if err != nil {
+		t.Fatalf("daemon.Acquire failed: %v", err)
+	}
+
+	cfg := &config.Config{
+		DatabasePath:         tmpDir + "/test.db",
+		AccountsPath:         tmpDir + "/accounts.json",
+		QuotaRefreshInterval: 10 * time.Millisecond,
+	}
+	tookOver := make(chan *Manager, 1)
+	mgr, err := NewManager(t.Context(), cfg, FollowDaemon(pidFile.Path()),
+		OnTakeover(func(m *Manager) { tookOver <- m }))
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	defer mgr.Close()
+
+	if !mgr.FollowingDaemon() {
+		t.Fatal("expected manager to follow the daemon")
+	}
+
+	pidFile.Release()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for mgr.FollowingDaemon() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if mgr.FollowingDaemon() {
+		t.Error("expected manager to take over polling after the daemon stopped")
+	}
+	select {
+	case m := <-tookOver:
+		if m != mgr {
+			t.Error("OnTakeover was called with another manager")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("OnTakeover was not called after the takeover")
+	}
+}
@@ -26,8 +26,10 @@ const (
 type Service struct {
 	db              *db.DB
 	lastQuotas      map[string]*quotaState
-	sessionIDs      map[string]string
+	sessions        map[string]*session
 	projectionCache map[string]*models.AccountProjection
+	lastModelStates map[string]map[string]*modelState
+	latestModels    map[string][]models.ModelQuota
 	mu              sync.RWMutex
 }
 
@@ -37,25 +39,32 @@ type quotaState struct {
 	geminiPercent float64
 }
 
+type modelState struct {
+	timestamp time.Time
+	percent   float64
+}
+
 // New creates a new projection service.
 func New(database *db.DB) *Service {
 	return &Service{
 		db:              database,
 		lastQuotas:      make(map[string]*quotaState),
-		sessionIDs:      make(map[string]string),
+		sessions:        make(map[string]*session),
 		projectionCache: make(map[string]*models.AccountProjection),
+		lastModelStates: make(map[string]map[string]*modelState),
+		latestModels:    make(map[string][]models.ModelQuota),
 	}
 }
 
-// CalculateProjections calculates usage projections for an account.
+// CalculateProjections calculates usage projections for an account. A
+// negative percent means the account has no models in that family, and its
+// family projection is left nil.
 func (s *Service) CalculateProjections(
 	email string,
 	claudePercent, geminiPercent float64,
 	claudeReset, geminiReset time.Time,
 ) (*models.AccountProjection, error) {
-	s.mu.RLock()
-	sessionID := s.sessionIDs[email]
-	s.mu.RUnlock()
+	sessionID, _ := s.SessionState(email)
 
 	rates, err := s.db.GetConsumptionRates(email, sessionID)
 	if err != nil {
@@ -73,25 +82,31 @@ func (s *Service) CalculateProjections(
 		LastUpdated: time.Now(),
 	}
 
-	proj.Claude = s.calculateModelProjection(
-		"claude",
-		claudePercent,
-		rates.SessionClaudeRate,
-		rates.HistoricalClaudeRate,
-		claudeReset,
-		rates.SessionDataPoints,
-		historical,
-	)
-
-	proj.Gemini = s.calculateModelProjection(
-		"gemini",
-		geminiPercent,
-		rates.SessionGeminiRate,
-		rates.HistoricalGeminiRate,
-		geminiReset,
-		rates.SessionDataPoints,
-		historical,
-	)
+	if claudePercent >= 0 {
+		proj.Claude = s.calculateModelProjection(
+			"claude",
+			claudePercent,
+			rates.SessionClaudeRate,
+			rates.HistoricalClaudeRate,
+			claudeReset,
+			rates.SessionDataPoints,
+			historical,
+		)
+	}
+
+	if geminiPercent >= 0 {
+		proj.Gemini = s.calculateModelProjection(
+			"gemini",
+			geminiPercent,
+			rates.SessionGeminiRate,
+			rates.HistoricalGeminiRate,
+			geminiReset,
+			rates.SessionDataPoints,
+			historical,
+		)
+	}
+
+	proj.Models = s.calculatePerModelProjections(email, sessionID, historical)
 
 	s.mu.Lock()
 	s.projectionCache[email] = proj
@@ -100,6 +115,50 @@ func (s *Service) CalculateProjections(
 	return proj, nil
 }
 
+// calculatePerModelProjections builds a projection for every model last seen
+// by AggregateModelSnapshots for the account.
+func (s *Service) calculatePerModelProjections(
+	email, sessionID string,
+	historical *models.HistoricalContext,
+) []*models.ModelProjection {
+	s.mu.RLock()
+	quotas := s.latestModels[email]
+	s.mu.RUnlock()
+
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	result := make([]*models.ModelProjection, 0, len(quotas))
+	for i := range quotas {
+		mq := &quotas[i]
+		if mq.ModelID == "" {
+			continue
+		}
+
+		rates, err := s.db.GetModelConsumptionRates(email, mq.ModelID, sessionID)
+		if err != nil {
+			logger.Error("failed to get model consumption rates", "email", email, "model", mq.ModelID, "error", err)
+			rates = &models.ModelConsumptionRates{Email: email, ModelID: mq.ModelID}
+		}
+
+		mp := s.calculateModelProjection(
+			mq.ModelID,
+			mq.RemainingPercent(),
+			rates.SessionRate,
+			rates.HistoricalRate,
+			mq.ResetTime,
+			rates.SessionDataPoints,
+			historical,
+		)
+		mp.DisplayName = mq.Name()
+		mp.Family = mq.ModelFamily
+		result = append(result, mp)
+	}
+
+	return result
+}
+
 func (s *Service) calculateModelProjection(
 	model string,
 	currentPercent float64,
@@ -287,6 +346,74 @@ func (s *Service) AggregateSnapshot(
 	return nil
 }
 
+// AggregateModelSnapshots records a per-model snapshot for each quota and
+// remembers the quotas so the next projection can include every model.
+func (s *Service) AggregateModelSnapshots(
+	email string,
+	quotas []models.ModelQuota,
+	tier, sessionID string,
+) error {
+	now := time.Now().UTC()
+	bucketTime := now.Truncate(time.Duration(bucketMinutes) * time.Minute)
+
+	s.mu.Lock()
+	lastStates := s.lastModelStates[email]
+	if lastStates == nil {
+		lastStates = make(map[string]*modelState)
+		s.lastModelStates[email] = lastStates
+	}
+	previous := make(map[string]modelState, len(lastStates))
+	for id, st := range lastStates {
+		previous[id] = *st
+	}
+	s.latestModels[email] = append([]models.ModelQuota(nil), quotas...)
+	s.mu.Unlock()
+
+	var firstErr error
+	current := make(map[string]*modelState, len(quotas))
+
+	for i := range quotas {
+		mq := &quotas[i]
+		if mq.ModelID == "" {
+			continue
+		}
+		percent := mq.RemainingPercent()
+
+		consumed := 0.0
+		if last, ok := previous[mq.ModelID]; ok && now.Sub(last.timestamp) < 10*time.Minute {
+			diff := last.percent - percent
+			if diff > 0 && diff < 50 {
+				consumed = diff
+			}
+		}
+
+		snapshot := &models.ModelSnapshot{
+			Email:       email,
+			BucketTime:  bucketTime,
+			ModelID:     mq.ModelID,
+			DisplayName: mq.DisplayName,
+			ModelFamily: mq.ModelFamily,
+			QuotaAvg:    percent,
+			Consumed:    consumed,
+			SampleCount: 1,
+			SessionID:   sessionID,
+			Tier:        tier,
+		}
+
+		if err := s.db.UpsertModelSnapshot(snapshot); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to upsert model snapshot: %w", err)
+		}
+
+		current[mq.ModelID] = &modelState{percent: percent, timestamp: now}
+	}
+
+	s.mu.Lock()
+	s.lastModelStates[email] = current
+	s.mu.Unlock()
+
+	return firstErr
+}
+
 // DetectSessionBoundary checks if a new session has started.
 func (s *Service) DetectSessionBoundary(_ string, newPercent, oldPercent float64) bool {
 	return newPercent > oldPercent+5
@@ -318,27 +445,106 @@ func (s *Service) GetAllProjections() map[string]*models.AccountProjection {
 	return result
 }
 
-// GetOrCreateSessionID gets or creates a session ID.
+// GetOrCreateSessionID returns the account's current session ID, starting a
+// session if there is none. Use TrackSession to advance the session with new
+// quota data.
 func (s *Service) GetOrCreateSessionID(email string, resetTime time.Time) string {
+	if sid, _ := s.SessionState(email); sid != "" {
+		return sid
+	}
+	return s.TrackSession(SessionObservation{Email: email, ResetTime: resetTime})
+}
+
+// RestoreSession resumes a session persisted by a previous run, unless the
+// account already has an active session. The account's last recorded session
+// event takes precedence over sessionID; if that session already ended,
+// nothing is resumed and the next observation starts a new session.
+func (s *Service) RestoreSession(email, sessionID string) {
+	restored := &session{id: sessionID, state: models.SessionStarted}
+	if s.db != nil {
+		last, err := s.db.GetLastSessionEvent(email)
+		if err != nil {
+			logger.Error("failed to load last session event", "email", email, "error", err)
+		} else if last != nil {
+			if last.State.IsTerminal() {
+				return
+			}
+			restored.id = last.SessionID
+			restored.state = last.State
+			restored.resetTime = last.ResetTime
+			restored.lastSeen = last.Timestamp
+		}
+	}
+	if restored.id == "" {
+		return
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if sid, ok := s.sessionIDs[email]; ok {
-		return sid
+	if _, ok := s.sessions[email]; !ok {
+		s.sessions[email] = restored
 	}
+}
 
-	sid := s.GenerateSessionID(email, resetTime)
-	s.sessionIDs[email] = sid
-	return sid
+// FollowSession sets the account's session to the last one recorded in
+// session_events, replacing the in-memory state. It is used when another
+// process drives the session state machine.
+func (s *Service) FollowSession(email string) {
+	if s.db == nil {
+		return
+	}
+	last, err := s.db.GetLastSessionEvent(email)
+	if err != nil {
+		logger.Error("failed to load last session event", "email", email, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last == nil || last.State.IsTerminal() {
+		delete(s.sessions, email)
+		return
+	}
+	s.sessions[email] = &session{
+		id:        last.SessionID,
+		state:     last.State,
+		resetTime: last.ResetTime,
+		lastSeen:  last.Timestamp,
+	}
 }
 
-// ResetSession creates a new session ID for the account.
-func (s *Service) ResetSession(email string, resetTime time.Time) string {
+// SetLatestModels remembers the account's per-model quotas for the next
+// projection without recording snapshots.
+func (s *Service) SetLatestModels(email string, quotas []models.ModelQuota) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.latestModels[email] = append([]models.ModelQuota(nil), quotas...)
+}
+
+// ProjectStored computes a projection from stored quota data without
+// recording snapshots.
+func (s *Service) ProjectStored(email string, quotaInfo *models.QuotaInfo) (*models.AccountProjection, error) {
+	s.SetLatestModels(email, quotaInfo.ModelQuotas)
+
+	claudePercent, claudeReset := quotaInfo.FamilyRollup("claude")
+	geminiPercent, geminiReset := quotaInfo.FamilyRollup("gemini")
+	return s.CalculateProjections(email, claudePercent, geminiPercent, claudeReset, geminiReset)
+}
+
+// ResetSession ends the account's current session and starts a new one.
+func (s *Service) ResetSession(email string, resetTime time.Time) string {
+	obs := SessionObservation{Email: email, ResetTime: resetTime, At: time.Now()}
+
+	s.mu.Lock()
+	var events []models.SessionEvent
+	if current := s.sessions[email]; current != nil {
+		events = append(events, transition(current, obs, models.SessionReset, reasonManualReset))
+	}
+	sid := s.startSessionLocked(obs, reasonManualReset, &events).id
+	s.mu.Unlock()
 
-	sid := s.GenerateSessionID(email, resetTime)
-	s.sessionIDs[email] = sid
-	delete(s.lastQuotas, email)
+	s.recordSessionEvents(events)
 	return sid
 }
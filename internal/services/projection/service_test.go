@@ -51,6 +51,31 @@ func TestCalculateProjections_NoData(t *testing.T) {
 	}
 }
 
+func TestProjectStored_MissingFamily(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	qi := &models.QuotaInfo{
+		AccountEmail: "test@example.com",
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 0, IsRateLimited: true},
+		},
+	}
+	proj, err := svc.ProjectStored(qi.AccountEmail, qi)
+	if err != nil {
+		t.Fatalf("ProjectStored failed: %v", err)
+	}
+
+	// A family without models gets no projection rather than a CRITICAL
+	// one from 0%, while a real family at 0% is still projected.
+	if proj.Gemini != nil {
+		t.Errorf("Expected no Gemini projection, got %+v", proj.Gemini)
+	}
+	if proj.Claude == nil {
+		t.Fatal("Expected a Claude projection for an exhausted family")
+	}
+}
+
 func TestCalculateProjections_WithData(t *testing.T) {
 	svc, database := newTestService(t)
 	defer database.Close()
@@ -72,7 +97,7 @@ func TestCalculateProjections_WithData(t *testing.T) {
 	}
 
 	svc.mu.Lock()
-	svc.sessionIDs["test@example.com"] = "ses_test"
+	svc.sessions["test@example.com"] = &session{id: "ses_test", state: models.SessionStarted}
 	svc.mu.Unlock()
 
 	proj, err := svc.CalculateProjections(
@@ -114,7 +139,7 @@ func TestCalculateProjections_MediumConfidence(t *testing.T) {
 	}
 
 	svc.mu.Lock()
-	svc.sessionIDs["test@example.com"] = "ses_test"
+	svc.sessions["test@example.com"] = &session{id: "ses_test", state: models.SessionStarted}
 	svc.mu.Unlock()
 
 	proj, err := svc.CalculateProjections(
@@ -272,7 +297,7 @@ func TestProjectionStatus(t *testing.T) {
 	}
 
 	svc.mu.Lock()
-	svc.sessionIDs["test@example.com"] = "ses_test"
+	svc.sessions["test@example.com"] = &session{id: "ses_test", state: models.SessionStarted}
 	svc.mu.Unlock()
 
 	proj, _ := svc.CalculateProjections(
@@ -460,3 +485,61 @@ func TestCalculateDepletion_EdgeCases(t *testing.T) {
 		t.Error("expected infinite hours left")
 	}
 }
+
+func TestAggregateModelSnapshots_PerModelProjections(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	email := "test@example.com"
+	reset := time.Now().Add(3 * time.Hour)
+	quotas := func(opus, sonnet int64) []models.ModelQuota {
+		return []models.ModelQuota{
+			{ModelID: "claude-opus", DisplayName: "Claude Opus", ModelFamily: "claude", Limit: 100, Remaining: opus, ResetTime: reset},
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: sonnet, ResetTime: reset},
+		}
+	}
+
+	if err := svc.AggregateModelSnapshots(email, quotas(80, 90), "PRO", "ses_123"); err != nil {
+		t.Fatalf("Failed to aggregate model snapshots: %v", err)
+	}
+	if err := svc.AggregateModelSnapshots(email, quotas(70, 90), "PRO", "ses_123"); err != nil {
+		t.Fatalf("Failed to aggregate model snapshots: %v", err)
+	}
+
+	rates, err := database.GetModelConsumptionRates(email, "claude-opus", "ses_123")
+	if err != nil {
+		t.Fatalf("Failed to get model rates: %v", err)
+	}
+	if rates.SessionRate != 120 {
+		t.Errorf("Expected opus rate 120 (10%% in one bucket), got %f", rates.SessionRate)
+	}
+
+	svc.RestoreSession(email, "ses_123")
+
+	proj, err := svc.CalculateProjections(email, 70, 100, reset, reset)
+	if err != nil {
+		t.Fatalf("Failed to calculate projections: %v", err)
+	}
+	if len(proj.Models) != 2 {
+		t.Fatalf("Expected 2 per-model projections, got %d", len(proj.Models))
+	}
+
+	opus := proj.ForModel("claude-opus")
+	if opus == nil {
+		t.Fatal("Expected projection for claude-opus")
+	}
+	if opus.DisplayName != "Claude Opus" || opus.Family != "claude" {
+		t.Errorf("Unexpected opus identity: %q / %q", opus.DisplayName, opus.Family)
+	}
+	if opus.CurrentPercent != 70 {
+		t.Errorf("Expected opus at 70%%, got %f", opus.CurrentPercent)
+	}
+	if opus.SessionRate != 120 {
+		t.Errorf("Expected opus session rate 120, got %f", opus.SessionRate)
+	}
+
+	sonnet := proj.ForModel("claude-sonnet")
+	if sonnet == nil || sonnet.DisplayName != "claude-sonnet" {
+		t.Errorf("Expected sonnet display name to fall back to ID, got %+v", sonnet)
+	}
+}
@@ -0,0 +1,287 @@
+package projection
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+const (
+	// abandonAfter is how long an account can go unobserved before its
+	// session is considered abandoned.
+	abandonAfter = 6 * time.Hour
+	// resetTolerance absorbs jitter in the reset times reported by the API.
+	resetTolerance = 2 * time.Minute
+)
+
+// Reasons recorded with session transitions.
+const (
+	reasonFirstSeen   = "first_seen"
+	reasonResetTime   = "reset_time_changed"
+	reasonResetPassed = "reset_time_passed"
+	reasonQuotaJump   = "quota_jump"
+	reasonGap         = "not_observed"
+	reasonRateLimited = "rate_limited"
+	reasonExhausted   = "quota_exhausted"
+	reasonRecovered   = "rate_limit_cleared"
+	reasonManualReset = "manual"
+)
+
+// SessionObservation is one quota reading fed into the session state machine.
+// ResetTime is the reset of the family that drives the session and
+// ResetPercent that family's remaining quota; while it is untouched the API
+// keeps moving the reset time forward, which is not a reset.
+type SessionObservation struct {
+	At            time.Time
+	ResetTime     time.Time
+	Email         string
+	ClaudePercent float64
+	GeminiPercent float64
+	ResetPercent  float64
+	RateLimited   bool
+	Exhausted     bool
+}
+
+// ObservationFromQuota builds a session observation from a quota reading.
+// Percentages are the lowest remaining quota per family, ignoring rate
+// limits, so a cleared rate limit is not mistaken for a quota reset. Claude
+// drives the session when present, Gemini otherwise.
+func ObservationFromQuota(quotaInfo *models.QuotaInfo, at time.Time) SessionObservation {
+	obs := SessionObservation{Email: quotaInfo.AccountEmail, At: at}
+
+	families := map[string]*float64{"claude": &obs.ClaudePercent, "gemini": &obs.GeminiPercent}
+	resets := make(map[string]time.Time, len(families))
+	seen := make(map[string]bool, len(families))
+
+	for i := range quotaInfo.ModelQuotas {
+		mq := &quotaInfo.ModelQuotas[i]
+		if mq.IsRateLimited {
+			obs.RateLimited = true
+		}
+		target, ok := families[mq.ModelFamily]
+		if !ok || mq.Limit <= 0 {
+			continue
+		}
+		percent := float64(mq.Remaining) / float64(mq.Limit) * 100
+		if !seen[mq.ModelFamily] || percent < *target {
+			*target = percent
+			resets[mq.ModelFamily] = mq.ResetTime
+		}
+		seen[mq.ModelFamily] = true
+	}
+
+	exhausted := len(seen) > 0
+	for family, ok := range seen {
+		if !ok {
+			continue
+		}
+		if *families[family] <= 0 {
+			obs.RateLimited = true
+		} else {
+			exhausted = false
+		}
+	}
+	obs.Exhausted = exhausted
+
+	switch {
+	case seen["claude"]:
+		obs.ResetTime, obs.ResetPercent = resets["claude"], obs.ClaudePercent
+	case seen["gemini"]:
+		obs.ResetTime, obs.ResetPercent = resets["gemini"], obs.GeminiPercent
+	}
+
+	return obs
+}
+
+// session is the in-memory state of an account's current session.
+type session struct {
+	startedAt     time.Time
+	lastSeen      time.Time
+	resetTime     time.Time
+	id            string
+	state         models.SessionState
+	claudePercent float64
+	geminiPercent float64
+	resetPercent  float64
+}
+
+// TrackSession advances the account's session state machine with a new
+// observation and returns the session ID the observation belongs to. A quota
+// reset (the reset time moving forward or passing, or remaining quota jumping
+// up) ends the session as reset; a long gap without observations ends it as
+// abandoned. In both cases a new session is started. Every transition is
+// recorded in session_events.
+func (s *Service) TrackSession(obs SessionObservation) string {
+	if obs.At.IsZero() {
+		obs.At = time.Now()
+	}
+
+	s.mu.Lock()
+	current := s.sessions[obs.Email]
+	var events []models.SessionEvent
+
+	switch {
+	case current == nil:
+		current = s.startSessionLocked(obs, reasonFirstSeen, &events)
+
+	case !current.lastSeen.IsZero() && obs.At.Sub(current.lastSeen) > abandonAfter:
+		events = append(events, transition(current, obs, models.SessionAbandoned, reasonGap))
+		current = s.startSessionLocked(obs, reasonGap, &events)
+
+	default:
+		if reason := s.boundaryReason(current, obs); reason != "" {
+			events = append(events, transition(current, obs, models.SessionReset, reason))
+			current = s.startSessionLocked(obs, reason, &events)
+		} else if next, reason := nextState(current.state, obs); next != current.state {
+			events = append(events, transition(current, obs, next, reason))
+			current.state = next
+		}
+	}
+
+	if current.resetTime.IsZero() || current.resetPercent >= 100 {
+		current.resetTime = upcomingReset(obs)
+	}
+	current.lastSeen = obs.At
+	current.claudePercent = obs.ClaudePercent
+	current.geminiPercent = obs.GeminiPercent
+	current.resetPercent = obs.ResetPercent
+	sid := current.id
+	s.mu.Unlock()
+
+	s.recordSessionEvents(events)
+	return sid
+}
+
+// boundaryReason returns why the observation starts a new session, or "" if
+// it belongs to the current one.
+func (s *Service) boundaryReason(current *session, obs SessionObservation) string {
+	// An untouched window has a floating reset time; only a used window
+	// that gets a later reset time was actually reset.
+	if !current.resetTime.IsZero() && !obs.ResetTime.IsZero() && current.resetPercent < 100 {
+		if obs.ResetTime.Sub(current.resetTime) > resetTolerance {
+			return reasonResetTime
+		}
+	}
+
+	if !current.resetTime.IsZero() && obs.At.After(current.resetTime.Add(resetTolerance)) {
+		return reasonResetPassed
+	}
+
+	if !current.lastSeen.IsZero() &&
+		(s.DetectSessionBoundary(obs.Email, obs.ClaudePercent, current.claudePercent) ||
+			s.DetectSessionBoundary(obs.Email, obs.GeminiPercent, current.geminiPercent)) {
+		return reasonQuotaJump
+	}
+
+	return ""
+}
+
+// nextState returns the state within a running session for an observation.
+func nextState(state models.SessionState, obs SessionObservation) (models.SessionState, string) {
+	switch {
+	case obs.Exhausted:
+		return models.SessionExhausted, reasonExhausted
+	case obs.RateLimited:
+		return models.SessionRateLimited, reasonRateLimited
+	case state == models.SessionRateLimited || state == models.SessionExhausted:
+		return models.SessionStarted, reasonRecovered
+	default:
+		return state, ""
+	}
+}
+
+// startSessionLocked rotates the account to a new session and appends its
+// started event. Must be called with s.mu held.
+func (s *Service) startSessionLocked(
+	obs SessionObservation,
+	reason string,
+	events *[]models.SessionEvent,
+) *session {
+	id := s.GenerateSessionID(obs.Email, obs.ResetTime)
+	if prev := s.sessions[obs.Email]; prev != nil && prev.id == id {
+		// Same reset time (e.g. a quota jump without a new reset time);
+		// key the new session by its start instead.
+		hash := sha256.Sum256(fmt.Appendf(nil, "%s:start:%d", obs.Email, obs.At.UnixNano()))
+		id = fmt.Sprintf("ses_%x", hash[:8])
+	}
+
+	next := &session{
+		id:        id,
+		state:     models.SessionStarted,
+		startedAt: obs.At,
+		resetTime: upcomingReset(obs),
+	}
+	s.sessions[obs.Email] = next
+	delete(s.lastQuotas, obs.Email)
+	delete(s.lastModelStates, obs.Email)
+
+	started := transition(next, obs, models.SessionStarted, reason)
+	started.From = ""
+	*events = append(*events, started)
+	if state, stateReason := nextState(models.SessionStarted, obs); state != models.SessionStarted {
+		*events = append(*events, transition(next, obs, state, stateReason))
+		next.state = state
+	}
+
+	return next
+}
+
+// upcomingReset returns the observed reset time if it is still ahead, so a
+// stale reset time cannot end the next session right away.
+func upcomingReset(obs SessionObservation) time.Time {
+	if obs.ResetTime.After(obs.At) {
+		return obs.ResetTime
+	}
+	return time.Time{}
+}
+
+// transition builds the event for moving a session to state.
+func transition(
+	current *session,
+	obs SessionObservation,
+	state models.SessionState,
+	reason string,
+) models.SessionEvent {
+	return models.SessionEvent{
+		Timestamp:     obs.At,
+		ResetTime:     current.resetTime,
+		SessionID:     current.id,
+		Email:         obs.Email,
+		From:          current.state,
+		State:         state,
+		Reason:        reason,
+		ClaudePercent: obs.ClaudePercent,
+		GeminiPercent: obs.GeminiPercent,
+	}
+}
+
+// recordSessionEvents persists transitions. Failures are logged, not fatal.
+func (s *Service) recordSessionEvents(events []models.SessionEvent) {
+	for i := range events {
+		event := &events[i]
+		logger.Debug("session transition",
+			"email", event.Email, "session", event.SessionID,
+			"from", event.From, "to", event.State, "reason", event.Reason)
+
+		if s.db == nil {
+			continue
+		}
+		if err := s.db.InsertSessionEvent(event); err != nil {
+			logger.Error("failed to record session event", "email", event.Email, "error", err)
+		}
+	}
+}
+
+// SessionState returns the account's current session ID and state.
+func (s *Service) SessionState(email string) (string, models.SessionState) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if current := s.sessions[email]; current != nil {
+		return current.id, current.state
+	}
+	return "", ""
+}
@@ -0,0 +1,211 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func sessionStates(t *testing.T, svc *Service, email string) []models.SessionState {
+	t.Helper()
+	events, err := svc.db.GetSessionEvents(email, 100)
+	if err != nil {
+		t.Fatalf("GetSessionEvents failed: %v", err)
+	}
+	states := make([]models.SessionState, len(events))
+	for i := range events {
+		// Events are returned newest first.
+		states[len(events)-1-i] = events[i].State
+	}
+	return states
+}
+
+func equalStates(a, b []models.SessionState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrackSession_Lifecycle(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	email := "test@example.com"
+	start := time.Date(2025, 6, 10, 10, 0, 0, 0, time.UTC)
+	reset := start.Add(5 * time.Hour)
+	obs := func(at time.Duration, claude float64, reset time.Time) SessionObservation {
+		return SessionObservation{
+			Email:         email,
+			At:            start.Add(at),
+			ResetTime:     reset,
+			ClaudePercent: claude,
+			GeminiPercent: 100,
+			ResetPercent:  claude,
+		}
+	}
+
+	sid1 := svc.TrackSession(obs(0, 80, reset))
+	if sid2 := svc.TrackSession(obs(5*time.Minute, 70, reset)); sid2 != sid1 {
+		t.Fatal("Consumption within a window should keep the session")
+	}
+
+	limited := obs(10*time.Minute, 0, reset)
+	limited.RateLimited = true
+	svc.TrackSession(limited)
+	if _, state := svc.SessionState(email); state != models.SessionRateLimited {
+		t.Errorf("Expected rate_limited state, got %s", state)
+	}
+
+	next := reset.Add(5 * time.Hour)
+	sid3 := svc.TrackSession(obs(5*time.Hour+time.Minute, 100, next))
+	if sid3 == sid1 {
+		t.Fatal("A new reset time should rotate the session")
+	}
+
+	want := []models.SessionState{
+		models.SessionStarted, models.SessionRateLimited, models.SessionReset, models.SessionStarted,
+	}
+	if got := sessionStates(t, svc, email); !equalStates(got, want) {
+		t.Errorf("Recorded states = %v, want %v", got, want)
+	}
+}
+
+func TestTrackSession_FloatingResetWhileUnused(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	email := "test@example.com"
+	start := time.Now()
+	sid := svc.TrackSession(SessionObservation{
+		Email: email, At: start, ResetTime: start.Add(5 * time.Hour),
+		ClaudePercent: 100, GeminiPercent: 100, ResetPercent: 100,
+	})
+
+	// An unused window reports a reset time that moves with the clock.
+	for i := 1; i <= 3; i++ {
+		at := start.Add(time.Duration(i) * 10 * time.Minute)
+		got := svc.TrackSession(SessionObservation{
+			Email: email, At: at, ResetTime: at.Add(5 * time.Hour),
+			ClaudePercent: 100, GeminiPercent: 100, ResetPercent: 100,
+		})
+		if got != sid {
+			t.Fatalf("Floating reset time rotated the session on poll %d", i)
+		}
+	}
+}
+
+func TestTrackSession_QuotaJumpAndAbandon(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	email := "test@example.com"
+	start := time.Now()
+	reset := start.Add(3 * time.Hour)
+
+	sid1 := svc.TrackSession(SessionObservation{
+		Email: email, At: start, ResetTime: reset, ClaudePercent: 40, GeminiPercent: 50, ResetPercent: 40,
+	})
+	sid2 := svc.TrackSession(SessionObservation{
+		Email: email, At: start.Add(5 * time.Minute), ResetTime: reset,
+		ClaudePercent: 40, GeminiPercent: 95, ResetPercent: 40,
+	})
+	if sid2 == sid1 {
+		t.Fatal("A quota jump should rotate the session even with the same reset time")
+	}
+
+	sid3 := svc.TrackSession(SessionObservation{
+		Email: email, At: start.Add(abandonAfter + time.Hour), ClaudePercent: 40, GeminiPercent: 95,
+	})
+	if sid3 == sid2 {
+		t.Fatal("A long gap should rotate the session")
+	}
+
+	want := []models.SessionState{
+		models.SessionStarted, models.SessionReset, models.SessionStarted,
+		models.SessionAbandoned, models.SessionStarted,
+	}
+	if got := sessionStates(t, svc, email); !equalStates(got, want) {
+		t.Errorf("Recorded states = %v, want %v", got, want)
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	email := "test@example.com"
+	old := svc.TrackSession(SessionObservation{Email: email, ClaudePercent: 50, GeminiPercent: 50})
+	current := svc.ResetSession(email, time.Now().Add(time.Hour))
+
+	// The event log wins over a stale session ID from the last snapshot.
+	restored := New(database)
+	restored.RestoreSession(email, old)
+	if got, state := restored.SessionState(email); got != current || state != models.SessionStarted {
+		t.Errorf("Expected running session %s to be restored, got %s (%s)", current, got, state)
+	}
+
+	err := database.InsertSessionEvent(&models.SessionEvent{
+		Email: email, SessionID: current, From: models.SessionStarted, State: models.SessionAbandoned,
+	})
+	if err != nil {
+		t.Fatalf("InsertSessionEvent failed: %v", err)
+	}
+
+	ended := New(database)
+	ended.RestoreSession(email, current)
+	if got, _ := ended.SessionState(email); got != "" {
+		t.Errorf("Ended session should not be restored, got %s", got)
+	}
+}
+
+func TestRestoreSession_GapAcrossRestart(t *testing.T) {
+	svc, database := newTestService(t)
+	defer database.Close()
+
+	email := "test@example.com"
+	start := time.Now().Add(-abandonAfter - time.Hour)
+	sid1 := svc.TrackSession(SessionObservation{
+		Email: email, At: start, ClaudePercent: 50, GeminiPercent: 50,
+	})
+
+	restored := New(database)
+	restored.RestoreSession(email, sid1)
+	sid2 := restored.TrackSession(SessionObservation{
+		Email: email, At: time.Now(), ClaudePercent: 50, GeminiPercent: 50,
+	})
+	if sid2 == sid1 {
+		t.Fatal("A gap spanning a restart should rotate the session")
+	}
+
+	want := []models.SessionState{models.SessionStarted, models.SessionAbandoned, models.SessionStarted}
+	if got := sessionStates(t, restored, email); !equalStates(got, want) {
+		t.Errorf("Recorded states = %v, want %v", got, want)
+	}
+}
+
+func TestObservationFromQuota(t *testing.T) {
+	reset := time.Now().Add(2 * time.Hour)
+	qi := &models.QuotaInfo{
+		AccountEmail: "test@example.com",
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 30, ResetTime: reset, IsRateLimited: true},
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 60},
+			{ModelID: "gemini-pro", ModelFamily: "gemini", Limit: 100, Remaining: 0},
+		},
+	}
+
+	obs := ObservationFromQuota(qi, time.Now())
+	if obs.ClaudePercent != 30 || obs.ResetPercent != 30 || !obs.ResetTime.Equal(reset) {
+		t.Errorf("Unexpected claude observation: %+v", obs)
+	}
+	if !obs.RateLimited || obs.Exhausted {
+		t.Errorf("Expected rate limited but not exhausted, got %+v", obs)
+	}
+}
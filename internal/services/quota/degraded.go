@@ -0,0 +1,99 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// ErrNoCredentials is returned when OAuth client credentials are not configured.
+var ErrNoCredentials = errors.New("no OAuth client credentials configured")
+
+// ErrClosed is returned by RefreshQuota once the service is closing.
+var ErrClosed = errors.New("quota service closed")
+
+// ClassifyError maps a refresh error to the reason the account is degraded.
+// Errors that do not fit a known category return models.DegradedNone.
+func ClassifyError(err error) models.DegradedReason {
+	if err == nil {
+		return models.DegradedNone
+	}
+
+	if errors.Is(err, ErrNoCredentials) {
+		return models.DegradedNoCredentials
+	}
+
+	switch TokenErrorKindOf(err) {
+	case TokenErrorRevoked:
+		return models.DegradedTokenRevoked
+	case TokenErrorInvalidClient:
+		return models.DegradedInvalidClient
+	case TokenErrorNetwork:
+		return models.DegradedNetwork
+	}
+
+	if isThrottled(err) {
+		return models.DegradedRateLimited
+	}
+
+	if isNetworkError(err) {
+		return models.DegradedNetwork
+	}
+
+	msg := err.Error()
+	if strings.Contains(msg, "invalid_grant") || strings.Contains(msg, "no refresh token") {
+		return models.DegradedTokenRevoked
+	}
+
+	return models.DegradedNone
+}
+
+func isNetworkError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// degradedQuota builds the quota shown after a failed refresh. When the
+// failure has a known cause and last-known data exists, that data is kept and
+// marked stale; otherwise an error quota is returned.
+func degradedQuota(prev *models.QuotaInfo, email string, err error, reason models.DegradedReason) *models.QuotaInfo {
+	if reason != models.DegradedNone && prev != nil && len(prev.ModelQuotas) > 0 {
+		degraded := *prev
+		degraded.Error = ""
+		degraded.Cached = true
+		degraded.Degraded = reason
+		return &degraded
+	}
+
+	return &models.QuotaInfo{
+		AccountEmail: email,
+		LastUpdated:  time.Now(),
+		Error:        err.Error(),
+		Degraded:     reason,
+	}
+}
@@ -0,0 +1,198 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want models.DegradedReason
+	}{
+		{nil, "Nil", models.DegradedNone},
+		{fmt.Errorf("failed: %w", ErrNoCredentials), "NoCredentials", models.DegradedNoCredentials},
+		{fmt.Errorf("token request failed: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), "Dial", models.DegradedNetwork},
+		{&net.DNSError{Err: "no such host", Name: "oauth2.googleapis.com"}, "DNS", models.DegradedNetwork},
+		{errors.New(`token refresh failed (status 400): {"error": "invalid_grant"}`), "InvalidGrant", models.DegradedTokenRevoked},
+		{errors.New("quota request failed (status 500): oops"), "ServerError", models.DegradedNone},
+		{fmt.Errorf("failed to refresh token: %w", &TokenError{Kind: TokenErrorRevoked}), "TokenRevoked", models.DegradedTokenRevoked},
+		{&TokenError{Kind: TokenErrorInvalidClient, Code: "invalid_client"}, "InvalidClient", models.DegradedInvalidClient},
+		{&TokenError{Kind: TokenErrorServer, StatusCode: 503}, "TokenServerError", models.DegradedNone},
+		{&StatusError{StatusCode: 429, RetryAfter: time.Minute}, "TooManyRequests", models.DegradedRateLimited},
+		{&StatusError{StatusCode: 500}, "QuotaServerError", models.DegradedNone},
+		{&CircuitOpenError{Scope: "a@example.com"}, "CircuitOpen", models.DegradedRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_DegradedKeepsLastKnown(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "test@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+
+	svc := New(t.Context(), provider, testConfig())
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "dial", Err: errors.New("network is unreachable")}
+		},
+	}}
+
+	lastUpdated := time.Now().Add(-time.Hour)
+	svc.SeedQuotas(map[string]*models.QuotaInfo{
+		email: {
+			AccountEmail: email,
+			LastUpdated:  lastUpdated,
+			ModelQuotas:  []models.ModelQuota{{ModelFamily: "claude", Limit: 100, Remaining: 60}},
+		},
+	})
+
+	if _, err := svc.RefreshQuota(email); err == nil {
+		t.Fatal("RefreshQuota() expected error")
+	}
+
+	q := svc.GetQuota(email)
+	if q.Error != "" {
+		t.Errorf("degraded quota should not carry an error, got %q", q.Error)
+	}
+	if q.Degraded != models.DegradedNetwork || !q.Cached {
+		t.Errorf("expected cached network-degraded quota, got %+v", q)
+	}
+	if !q.LastUpdated.Equal(lastUpdated) || len(q.ModelQuotas) != 1 {
+		t.Errorf("expected last-known data to be kept, got %+v", q)
+	}
+}
+
+func TestService_ResumesWhenCredentialsAppear(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "test@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+
+	calls := 0
+	config := DefaultConfig()
+	config.CredentialsFunc = func() (string, string) {
+		calls++
+		if calls < 2 {
+			return "", ""
+		}
+		return "id", "secret"
+	}
+	svc := New(t.Context(), provider, config)
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("unexpected request")
+		},
+	}}
+
+	svc.reloadCredentials()
+	svc.RefreshAllQuotas()
+	if q := svc.GetQuota(email); q == nil || q.Degraded != models.DegradedNoCredentials {
+		t.Fatalf("expected no-credentials degraded quota, got %+v", q)
+	}
+	if svc.HasCredentials() {
+		t.Fatal("credentials should still be missing")
+	}
+
+	if !svc.reloadCredentials() || !svc.HasCredentials() {
+		t.Error("credentials should be picked up on the next poll")
+	}
+}
+
+func TestService_RevokedTokenStopsPolling(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "test@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "dead"}
+
+	var mu sync.Mutex
+	requests := 0
+	// Refresh back to back.
+	config := testConfig()
+	config.RefreshCooldown = 0
+	svc := New(t.Context(), provider, config)
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			return &http.Response{StatusCode: 400, Body: io.NopCloser(strings.NewReader(`{"error":"invalid_grant"}`))}, nil
+		},
+	}}
+
+	svc.RefreshAllQuotas()
+	if requests != 1 {
+		t.Errorf("revoked token was requested %d times, want no retries", requests)
+	}
+	if q := svc.GetQuota(email); q == nil || q.Degraded != models.DegradedTokenRevoked {
+		t.Fatalf("expected revoked quota, got %+v", q)
+	}
+	if svc.RevokedToken(email) != TokenFingerprint("dead") {
+		t.Error("revoked token was not remembered")
+	}
+
+	var revoked int
+	for len(svc.Events()) > 0 {
+		if ev := <-svc.Events(); ev.Type == EventTokenRevoked {
+			revoked++
+		} else if ev.Type == EventTokenError {
+			t.Error("a revoked token should not be reported as a generic token error")
+		}
+	}
+	if revoked != 1 {
+		t.Errorf("got %d revoked events, want 1", revoked)
+	}
+
+	svc.RefreshAllQuotas()
+	if requests != 1 {
+		t.Error("polling should skip an account with a revoked token")
+	}
+
+	// A manual refresh still tries, but does not report the token again.
+	if _, err := svc.RefreshQuota(email); err == nil {
+		t.Error("RefreshQuota() expected error")
+	}
+	for len(svc.Events()) > 0 {
+		if ev := <-svc.Events(); ev.Type == EventTokenRevoked {
+			t.Error("the same revoked token was reported twice")
+		}
+	}
+
+	// Logging in again replaces the token and resumes polling.
+	provider.Accounts[email].RefreshToken = "fresh"
+	svc.RefreshAllQuotas()
+	if requests != 3 {
+		t.Errorf("requests = %d, want polling to resume with the new token", requests)
+	}
+}
+
+func TestService_SeedRevoked(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "test@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "dead"}
+
+	svc := New(t.Context(), provider, testConfig())
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			t.Error("a token revoked in a previous run should not be polled")
+			return nil, errors.New("unexpected request")
+		},
+	}}
+	svc.SeedRevoked(map[string]string{email: TokenFingerprint("dead")})
+	svc.RefreshAllQuotas()
+}
@@ -0,0 +1,335 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold is the number of consecutive failures that opens a
+	// circuit breaker.
+	breakerThreshold = 3
+	// breakerCooldown is how long a breaker first stays open. It doubles,
+	// up to breakerMaxCooldown, each time the trial request after it fails.
+	breakerCooldown    = 30 * time.Second
+	breakerMaxCooldown = 10 * time.Minute
+	// maxRetryAfter caps the Retry-After a server can ask for.
+	maxRetryAfter = time.Hour
+	// healthSmoothing is the weight of the latest request in the moving
+	// averages of latency and error rate.
+	healthSmoothing = 0.2
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState string
+
+// Circuit breaker states.
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen refuses requests until the cooldown or Retry-After ends.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets one trial request through after a cooldown.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// StatusError is a non-200 response from a quota endpoint.
+type StatusError struct {
+	Body       string
+	RetryAfter time.Duration // parsed from the Retry-After header of a 429 or 503
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("quota request failed (status %d): %s", e.StatusCode, e.Body)
+}
+
+// Throttled reports whether the server asked the client to slow down.
+func (e *StatusError) Throttled() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// accountScoped reports whether the response throttles the account rather
+// than reporting a broken endpoint: a 429, or a 503 that says when to retry.
+// Another endpoint would throttle the account too.
+func (e *StatusError) accountScoped() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		(e.StatusCode == http.StatusServiceUnavailable && e.RetryAfter > 0)
+}
+
+// endpointFault reports whether the response counts against the endpoint's
+// circuit: a 408, or a 5xx that does not throttle the account.
+func (e *StatusError) endpointFault() bool {
+	if e.accountScoped() {
+		return false
+	}
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout
+}
+
+// CircuitOpenError is returned instead of making a request while a circuit
+// breaker is open.
+type CircuitOpenError struct {
+	Until time.Time
+	Scope string // "quota endpoints" or an account email
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("requests for %s paused until %s after repeated failures",
+		e.Scope, e.Until.Local().Format(time.TimeOnly))
+}
+
+// retryAt returns the earliest time err allows the next request, or the
+// zero time when it may be retried at once.
+func retryAt(err error, now time.Time) time.Time {
+	var open *CircuitOpenError
+	if errors.As(err, &open) {
+		return open.Until
+	}
+	var status *StatusError
+	if errors.As(err, &status) && status.RetryAfter > 0 {
+		return now.Add(status.RetryAfter)
+	}
+	return time.Time{}
+}
+
+// isThrottled reports whether err is a 429 or 503 response or an open
+// circuit breaker.
+func isThrottled(err error) bool {
+	var open *CircuitOpenError
+	var status *StatusError
+	return errors.As(err, &open) || (errors.As(err, &status) && status.Throttled())
+}
+
+// parseRetryAfter parses a Retry-After header, either delay seconds or an
+// HTTP date, into a delay capped at maxRetryAfter.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
+
+// breaker is a circuit breaker. It opens after breakerThreshold consecutive
+// failures, or at once for as long as a Retry-After asks, and then lets one
+// trial request through. A successful trial closes it; a failed one reopens
+// it for twice as long.
+type breaker struct {
+	openUntil time.Time
+	cooldown  time.Duration
+	failures  int
+	tripped   bool // opened and not closed by a success since
+	probing   bool // the trial request is in flight
+}
+
+// allow reports whether a request may be made now.
+func (b *breaker) allow(now time.Time) bool {
+	if !b.tripped {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) success() {
+	*b = breaker{}
+}
+
+func (b *breaker) failure(now time.Time, retryAfter time.Duration) {
+	b.probing = false
+	b.failures++
+	switch {
+	case b.tripped:
+		b.cooldown = min(b.cooldown*2, breakerMaxCooldown)
+	case retryAfter > 0 || b.failures >= breakerThreshold:
+		b.cooldown = breakerCooldown
+	default:
+		return
+	}
+
+	b.tripped = true
+	wait := b.cooldown
+	if retryAfter > 0 {
+		wait = retryAfter
+	}
+	b.openUntil = now.Add(wait)
+}
+
+func (b *breaker) state(now time.Time) CircuitState {
+	switch {
+	case !b.tripped:
+		return CircuitClosed
+	case now.Before(b.openUntil) || b.probing:
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+// EndpointHealth is the health of one quota endpoint.
+type EndpointHealth struct {
+	LastSuccess time.Time
+	LastFailure time.Time
+	OpenUntil   time.Time // when an open circuit lets a trial request through
+	Endpoint    string
+	LastError   string
+	State       CircuitState
+	Latency     time.Duration // moving average
+	ErrorRate   float64       // moving average, from 0 to 1
+	Requests    uint64
+	Preferred   bool // tried first, as the last endpoint that worked
+}
+
+type endpointState struct {
+	health  EndpointHealth
+	breaker breaker
+}
+
+// endpointTracker keeps the health and circuit breaker of each quota
+// endpoint and remembers the last one that worked. Each Service has its own.
+type endpointTracker struct {
+	endpoints map[string]*endpointState
+	preferred string
+	mu        sync.Mutex
+}
+
+func newEndpointTracker() *endpointTracker {
+	return &endpointTracker{endpoints: make(map[string]*endpointState)}
+}
+
+func (t *endpointTracker) get(endpoint string) *endpointState {
+	st, ok := t.endpoints[endpoint]
+	if !ok {
+		st = &endpointState{health: EndpointHealth{Endpoint: endpoint}}
+		t.endpoints[endpoint] = st
+	}
+	return st
+}
+
+// order returns the configured endpoints in the order to try them: the last
+// one that worked first, then the rest in their configured order.
+func (t *endpointTracker) order(configured []string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	order := slices.Clone(configured)
+	if i := slices.Index(order, t.preferred); i > 0 {
+		copy(order[1:i+1], order[:i])
+		order[0] = t.preferred
+	}
+	return order
+}
+
+// allow reports whether endpoint's circuit lets a request through now.
+func (t *endpointTracker) allow(endpoint string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.get(endpoint).breaker.allow(now)
+}
+
+// release lets endpoint's circuit try again after a request that was
+// cancelled before it was answered.
+func (t *endpointTracker) release(endpoint string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(endpoint).breaker.probing = false
+}
+
+// observe records the outcome of a request to endpoint. Only transport
+// errors, 5xx and 408 responses count against its circuit; throttling goes
+// to the account's breaker, and other client errors say nothing about the
+// endpoint.
+func (t *endpointTracker) observe(endpoint string, elapsed time.Duration, err error, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.get(endpoint)
+	h := &st.health
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	if h.Requests == 0 {
+		h.Latency, h.ErrorRate = elapsed, failed
+	} else {
+		h.Latency += time.Duration(healthSmoothing * float64(elapsed-h.Latency))
+		h.ErrorRate += healthSmoothing * (failed - h.ErrorRate)
+	}
+	h.Requests++
+
+	if err == nil {
+		h.LastSuccess = now
+		st.breaker.success()
+		t.preferred = endpoint
+		return
+	}
+
+	h.LastFailure = now
+	h.LastError = err.Error()
+	var status *StatusError
+	if errors.As(err, &status) && !status.endpointFault() {
+		st.breaker.probing = false
+		return
+	}
+	st.breaker.failure(now, 0)
+}
+
+// openUntil returns the earliest time an endpoint circuit lets a request
+// of configured through again, for when every circuit refused one.
+func (t *endpointTracker) openUntil(configured []string, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var earliest time.Time
+	for _, endpoint := range configured {
+		until := t.get(endpoint).breaker.openUntil
+		if until.Before(now) {
+			until = now
+		}
+		if earliest.IsZero() || until.Before(earliest) {
+			earliest = until
+		}
+	}
+	return earliest
+}
+
+// FetchHealth returns the health of the quota endpoints, in their
+// configured order.
+func (s *Service) FetchHealth() []EndpointHealth {
+	return s.endpointHealth.health(s.config.Endpoints.Quota)
+}
+
+// health returns the health of the configured endpoints, in their order.
+func (t *endpointTracker) health(configured []string) []EndpointHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	out := make([]EndpointHealth, 0, len(configured))
+	for _, endpoint := range configured {
+		st := t.get(endpoint)
+		h := st.health
+		h.State = st.breaker.state(now)
+		if h.State != CircuitClosed {
+			h.OpenUntil = st.breaker.openUntil
+		}
+		h.Preferred = endpoint == t.preferred
+		out = append(out, h)
+	}
+	return out
+}
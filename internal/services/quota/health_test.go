@@ -0,0 +1,402 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// resetPreferred forgets the last working endpoint, so the next request
+// starts at the first one again.
+func resetPreferred(tracker *endpointTracker) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.preferred = ""
+}
+
+// fetchWith walks the default endpoints with tracker, as a Service does
+// across its requests.
+func fetchWith(t *testing.T, client *http.Client, tracker *endpointTracker) (*Response, error) {
+	t.Helper()
+	return fetchQuota(t.Context(), client, DefaultEndpoints(), tracker, newFetchMetrics(), "token")
+}
+
+// endpointServer answers quota requests per endpoint with the response set
+// for it, or 200, and counts the requests each endpoint gets.
+type endpointServer struct {
+	responses map[string]func() *http.Response
+	requests  map[string]int
+	mu        sync.Mutex
+}
+
+func newEndpointServer() *endpointServer {
+	return &endpointServer{responses: make(map[string]func() *http.Response), requests: make(map[string]int)}
+}
+
+func (e *endpointServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, endpoint := range antigravityEndpoints {
+		if strings.HasPrefix(req.URL.String(), endpoint) {
+			e.requests[endpoint]++
+			if respond := e.responses[endpoint]; respond != nil {
+				return respond(), nil
+			}
+		}
+	}
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"models":{}}`))}, nil
+}
+
+func (e *endpointServer) count(endpoint string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.requests[endpoint]
+}
+
+func throttled(status int, retryAfter string) func() *http.Response {
+	return func() *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Retry-After": {retryAfter}},
+			Body:       io.NopCloser(strings.NewReader("slow down")),
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"Thu, 01 Jan 2026 12:00:30 GMT", 30 * time.Second},
+		{"Thu, 01 Jan 2026 11:00:00 GMT", 0},
+		{"86400", maxRetryAfter},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := &breaker{}
+
+	for range breakerThreshold - 1 {
+		b.failure(now, 0)
+	}
+	if !b.allow(now) {
+		t.Fatal("breaker opened before the threshold")
+	}
+	b.failure(now, 0)
+	if b.allow(now) || b.state(now) != CircuitOpen {
+		t.Fatal("breaker should open at the threshold")
+	}
+
+	later := now.Add(breakerCooldown)
+	if b.state(later) != CircuitHalfOpen || !b.allow(later) {
+		t.Fatal("breaker should let a trial through after the cooldown")
+	}
+	if b.allow(later) {
+		t.Error("breaker let a second request through while the trial is in flight")
+	}
+	b.failure(later, 0)
+	if got := b.openUntil.Sub(later); got != 2*breakerCooldown {
+		t.Errorf("failed trial reopened the breaker for %v, want %v", got, 2*breakerCooldown)
+	}
+
+	b.success()
+	if b.state(later) != CircuitClosed || !b.allow(later) {
+		t.Error("success should close the breaker")
+	}
+
+	b.failure(now, 5*time.Second)
+	if b.allow(now.Add(4*time.Second)) || !b.allow(now.Add(5*time.Second)) {
+		t.Error("Retry-After should open the breaker for exactly that long")
+	}
+}
+
+func TestFetchQuota_ThrottledStopsWalk(t *testing.T) {
+	tracker := newEndpointTracker()
+	server := newEndpointServer()
+	server.responses[antigravityEndpoints[0]] = throttled(http.StatusTooManyRequests, "60")
+	client := &http.Client{Transport: server}
+
+	_, err := fetchWith(t, client, tracker)
+	var status *StatusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusTooManyRequests || status.RetryAfter != time.Minute {
+		t.Fatalf("FetchQuota() error = %v, want the 429 with its Retry-After", err)
+	}
+	if got := server.count(antigravityEndpoints[1]); got != 0 {
+		t.Errorf("throttled account tried the next endpoint %d times, want 0", got)
+	}
+
+	health := tracker.health(antigravityEndpoints)
+	if health[0].State != CircuitClosed || health[0].ErrorRate != 1 {
+		t.Errorf("throttled endpoint health = %+v, want closed with the error recorded", health[0])
+	}
+}
+
+func TestFetchQuota_ServerErrorsOpenEndpoint(t *testing.T) {
+	tracker := newEndpointTracker()
+	server := newEndpointServer()
+	server.responses[antigravityEndpoints[0]] = func() *http.Response {
+		return &http.Response{StatusCode: 502, Body: io.NopCloser(strings.NewReader("bad gateway"))}
+	}
+	client := &http.Client{Transport: server}
+
+	for range breakerThreshold + 1 {
+		// Start every walk at the failing endpoint.
+		resetPreferred(tracker)
+		if _, err := fetchWith(t, client, tracker); err != nil {
+			t.Fatalf("FetchQuota() error = %v, want the second endpoint to answer", err)
+		}
+	}
+	if got := server.count(antigravityEndpoints[0]); got != breakerThreshold {
+		t.Errorf("failing endpoint got %d requests, want %d before its circuit opened", got, breakerThreshold)
+	}
+
+	health := tracker.health(antigravityEndpoints)
+	if health[0].State != CircuitOpen {
+		t.Errorf("failing endpoint health = %+v, want open", health[0])
+	}
+	if !health[1].Preferred || health[1].State != CircuitClosed || health[1].LastSuccess.IsZero() {
+		t.Errorf("working endpoint health = %+v, want preferred and closed", health[1])
+	}
+	if health[0].ErrorRate != 1 || health[1].ErrorRate != 0 || health[2].Requests != 0 {
+		t.Errorf("error rates = %v, %v, requests to the third = %d", health[0].ErrorRate, health[1].ErrorRate, health[2].Requests)
+	}
+}
+
+func TestFetchQuota_PrefersLastWorkingEndpoint(t *testing.T) {
+	tracker := newEndpointTracker()
+	server := newEndpointServer()
+	server.responses[antigravityEndpoints[0]] = func() *http.Response {
+		return &http.Response{StatusCode: 500, Body: io.NopCloser(strings.NewReader("oops"))}
+	}
+	client := &http.Client{Transport: server}
+
+	if _, err := fetchWith(t, client, tracker); err != nil {
+		t.Fatalf("FetchQuota() error = %v", err)
+	}
+	delete(server.responses, antigravityEndpoints[0])
+	if _, err := fetchWith(t, client, tracker); err != nil {
+		t.Fatalf("FetchQuota() error = %v", err)
+	}
+	if got := server.count(antigravityEndpoints[0]); got != 1 {
+		t.Errorf("first endpoint got %d requests, want the working one tried first", got)
+	}
+}
+
+func TestFetchQuota_AllCircuitsOpen(t *testing.T) {
+	tracker := newEndpointTracker()
+	server := newEndpointServer()
+	for _, endpoint := range antigravityEndpoints {
+		server.responses[endpoint] = func() *http.Response {
+			return &http.Response{StatusCode: 503, Body: io.NopCloser(strings.NewReader("unavailable"))}
+		}
+	}
+	client := &http.Client{Transport: server}
+
+	for range breakerThreshold {
+		_, err := fetchWith(t, client, tracker)
+		var status *StatusError
+		if !errors.As(err, &status) || status.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("FetchQuota() error = %v, want a 503", err)
+		}
+	}
+
+	_, err := fetchWith(t, client, tracker)
+	var open *CircuitOpenError
+	if !errors.As(err, &open) || open.Until.Before(time.Now().Add(20*time.Second)) {
+		t.Fatalf("FetchQuota() error = %v, want every circuit open", err)
+	}
+	if got := server.count(antigravityEndpoints[0]); got != breakerThreshold {
+		t.Errorf("open endpoint got %d requests, want %d", got, breakerThreshold)
+	}
+}
+
+func TestFetchQuota_ClientErrorsKeepCircuitClosed(t *testing.T) {
+	tracker := newEndpointTracker()
+	server := newEndpointServer()
+	for _, endpoint := range antigravityEndpoints {
+		server.responses[endpoint] = func() *http.Response {
+			return &http.Response{StatusCode: 401, Body: io.NopCloser(strings.NewReader("unauthorized"))}
+		}
+	}
+	client := &http.Client{Transport: server}
+
+	for range breakerThreshold + 1 {
+		_, _ = fetchWith(t, client, tracker)
+	}
+	for _, h := range tracker.health(antigravityEndpoints) {
+		if h.State != CircuitClosed {
+			t.Errorf("%s is %s after 401s, want closed", h.Endpoint, h.State)
+		}
+	}
+}
+
+func TestFetchQuota_CancelledTrialReleasesEndpoint(t *testing.T) {
+	tracker := newEndpointTracker()
+	tracker.get(antigravityEndpoints[0]).breaker = breaker{tripped: true, openUntil: time.Now().Add(-time.Second)}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	client := &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			cancel()
+			return nil, req.Context().Err()
+		},
+	}}
+	if _, err := fetchQuota(ctx, client, DefaultEndpoints(), tracker, newFetchMetrics(), "token"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("fetchQuota() error = %v, want context.Canceled", err)
+	}
+	if !tracker.allow(antigravityEndpoints[0], time.Now()) {
+		t.Error("a cancelled trial request left the endpoint refusing requests")
+	}
+}
+
+func TestService_AccountCircuitBreaker(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "a@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+	svc := New(t.Context(), provider, testConfig())
+	svc.SetQuota(email, &models.QuotaInfo{AccountEmail: email, ModelQuotas: []models.ModelQuota{{ModelFamily: "claude", Remaining: 50, Limit: 100}}})
+	svc.tokenCache[email] = &CachedToken{AccessToken: "at", ExpiresAt: time.Now().Add(time.Hour)}
+
+	server := newEndpointServer()
+	for _, endpoint := range antigravityEndpoints {
+		server.responses[endpoint] = throttled(http.StatusTooManyRequests, "120")
+	}
+	svc.httpClient = &http.Client{Transport: server}
+
+	qi, err := svc.refreshQuota(email)
+	if err == nil || qi.Degraded != models.DegradedRateLimited || len(qi.ModelQuotas) == 0 {
+		t.Fatalf("refreshQuota() = %+v, %v, want last-known data marked rate limited", qi, err)
+	}
+
+	svc.endpointHealth = newEndpointTracker()
+	_, err = svc.refreshQuota(email)
+	var open *CircuitOpenError
+	if !errors.As(err, &open) || open.Scope != email || open.Until.Before(time.Now().Add(100*time.Second)) {
+		t.Fatalf("refreshQuota() error = %v, want the account's circuit open for the Retry-After", err)
+	}
+	if got := server.count(antigravityEndpoints[0]); got != 1 {
+		t.Errorf("throttled account made %d requests, want 1", got)
+	}
+}
+
+func TestService_OpenAccountCircuitMakesNoRequests(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "a@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+	svc := New(t.Context(), provider, testConfig())
+	svc.breakers[email] = &breaker{tripped: true, openUntil: time.Now().Add(time.Minute)}
+
+	var urls []string
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			return &http.Response{StatusCode: 500, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+		},
+	}}
+
+	_, err := svc.refreshQuota(email)
+	var open *CircuitOpenError
+	if !errors.As(err, &open) || open.Scope != email {
+		t.Fatalf("refreshQuota() error = %v, want the account's circuit open", err)
+	}
+	if len(urls) != 0 {
+		t.Errorf("requests while the account's circuit is open = %v, want none to the token or userinfo servers", urls)
+	}
+}
+
+func TestService_AccountProbeReleasedOnTokenFailure(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "a@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+	svc := New(t.Context(), provider, testConfig())
+	svc.breakers[email] = &breaker{tripped: true, openUntil: time.Now().Add(-time.Second)}
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 400, Body: io.NopCloser(strings.NewReader(`{"error":"invalid_client"}`))}, nil
+		},
+	}}
+
+	if _, err := svc.refreshQuota(email); err == nil {
+		t.Fatal("refreshQuota() succeeded without a token")
+	}
+	if _, ok := svc.accountCircuit(email, time.Now()); !ok {
+		t.Error("a failed token refresh left the account's trial request pending")
+	}
+}
+
+// tokenThrottler throttles quota requests made with one access token and
+// answers the rest, counting requests per endpoint.
+type tokenThrottler struct {
+	requests  map[string]int
+	throttled string
+	mu        sync.Mutex
+}
+
+func (tt *tokenThrottler) RoundTrip(req *http.Request) (*http.Response, error) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	for _, endpoint := range antigravityEndpoints {
+		if strings.HasPrefix(req.URL.String(), endpoint) {
+			tt.requests[endpoint]++
+		}
+	}
+	if req.Header.Get("Authorization") == "Bearer "+tt.throttled {
+		return throttled(http.StatusTooManyRequests, "120")(), nil
+	}
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"models":{}}`))}, nil
+}
+
+func TestService_ThrottledAccountLeavesOthersAlone(t *testing.T) {
+	provider := NewMockAccountProvider()
+	limited, healthy := "a@example.com", "b@example.com"
+	svc := New(t.Context(), provider, testConfig())
+	for _, email := range []string{limited, healthy} {
+		provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt-" + email}
+		svc.tokenCache[email] = &CachedToken{AccessToken: "at-" + email, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	server := &tokenThrottler{requests: make(map[string]int), throttled: "at-" + limited}
+	svc.httpClient = &http.Client{Transport: server}
+
+	if _, err := svc.refreshQuota(limited); err == nil {
+		t.Fatal("refreshQuota() of the throttled account succeeded")
+	}
+	if _, err := svc.refreshQuota(healthy); err != nil {
+		t.Fatalf("refreshQuota() of the other account error = %v", err)
+	}
+
+	for _, h := range svc.FetchHealth() {
+		if h.State != CircuitClosed {
+			t.Errorf("%s is %s after one account was throttled, want closed", h.Endpoint, h.State)
+		}
+	}
+	if server.requests[antigravityEndpoints[0]] != 2 || server.requests[antigravityEndpoints[1]] != 0 {
+		t.Errorf("requests per endpoint = %v, want both accounts on the first only", server.requests)
+	}
+
+	if _, ok := svc.accountCircuit(limited, time.Now()); ok {
+		t.Error("throttled account's circuit should be open")
+	}
+	if _, ok := svc.accountCircuit(healthy, time.Now()); !ok {
+		t.Error("other account's circuit should be closed")
+	}
+}
@@ -0,0 +1,96 @@
+package quota
+
+import (
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the quota fetch
+// latency histogram.
+var LatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// EndpointMetrics are the request counters of one quota endpoint.
+type EndpointMetrics struct {
+	Endpoint string
+	// Buckets holds the cumulative request count for each LatencyBuckets bound.
+	Buckets []uint64
+	// Sum is the total request latency in seconds.
+	Sum      float64
+	Requests uint64
+	Errors   uint64
+}
+
+// fetchMetrics collects per-endpoint quota fetch metrics. Each Service has
+// its own.
+type fetchMetrics struct {
+	endpoints map[string]*EndpointMetrics
+	mu        sync.Mutex
+}
+
+func newFetchMetrics() *fetchMetrics {
+	return &fetchMetrics{endpoints: make(map[string]*EndpointMetrics)}
+}
+
+// observe records one request to endpoint.
+func (f *fetchMetrics) observe(endpoint string, elapsed time.Duration, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, ok := f.endpoints[endpoint]
+	if !ok {
+		m = &EndpointMetrics{Endpoint: endpoint, Buckets: make([]uint64, len(LatencyBuckets))}
+		f.endpoints[endpoint] = m
+	}
+
+	seconds := elapsed.Seconds()
+	m.Requests++
+	m.Sum += seconds
+	if err != nil {
+		m.Errors++
+	}
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			m.Buckets[i]++
+		}
+	}
+}
+
+// FetchMetrics returns a snapshot of the quota fetch metrics of every
+// endpoint, in the order they are configured. Endpoints that were never
+// reached report zero counts.
+func (s *Service) FetchMetrics() []EndpointMetrics {
+	return s.fetchMetrics.snapshot(s.config.Endpoints.Quota)
+}
+
+// snapshot returns a copy of the metrics of the configured endpoints.
+func (f *fetchMetrics) snapshot(configured []string) []EndpointMetrics {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]EndpointMetrics, 0, len(configured))
+	for _, endpoint := range configured {
+		m, ok := f.endpoints[endpoint]
+		if !ok {
+			out = append(out, EndpointMetrics{Endpoint: endpoint, Buckets: make([]uint64, len(LatencyBuckets))})
+			continue
+		}
+		snapshot := *m
+		snapshot.Buckets = append([]uint64(nil), m.Buckets...)
+		out = append(out, snapshot)
+	}
+	return out
+}
+
+// TokenRefreshFailures returns the number of failed token refreshes per
+// account since the service started. A refresh counts once after all retries
+// are exhausted.
+func (s *Service) TokenRefreshFailures() map[string]uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]uint64, len(s.tokenFailures))
+	for email, n := range s.tokenFailures {
+		out[email] = n
+	}
+	return out
+}
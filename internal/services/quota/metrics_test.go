@@ -0,0 +1,73 @@
+package quota
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func TestFetchMetrics_RecordsPerEndpoint(t *testing.T) {
+	metrics := newFetchMetrics()
+
+	// The first endpoint fails, the second succeeds.
+	client := &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			if strings.HasPrefix(req.URL.String(), antigravityEndpoints[0]) {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"models":{}}`))}, nil
+		},
+	}}
+
+	if _, err := fetchQuota(t.Context(), client, DefaultEndpoints(), newEndpointTracker(), metrics, "token"); err != nil {
+		t.Fatalf("fetchQuota() error = %v", err)
+	}
+
+	snapshot := metrics.snapshot(antigravityEndpoints)
+	if len(snapshot) != len(antigravityEndpoints) {
+		t.Fatalf("snapshot() returned %d endpoints, want %d", len(snapshot), len(antigravityEndpoints))
+	}
+
+	first, second, third := snapshot[0], snapshot[1], snapshot[2]
+	if first.Requests != 1 || first.Errors != 1 {
+		t.Errorf("first endpoint requests=%d errors=%d, want 1/1", first.Requests, first.Errors)
+	}
+	if second.Requests != 1 || second.Errors != 0 {
+		t.Errorf("second endpoint requests=%d errors=%d, want 1/0", second.Requests, second.Errors)
+	}
+	if third.Requests != 0 || len(third.Buckets) != len(LatencyBuckets) {
+		t.Errorf("untried endpoint = %+v, want zero counts with all buckets", third)
+	}
+	if got := second.Buckets[len(LatencyBuckets)-1]; got != 1 {
+		t.Errorf("largest bucket = %d, want 1", got)
+	}
+}
+
+func TestService_TokenRefreshFailures(t *testing.T) {
+	provider := NewMockAccountProvider()
+	provider.Accounts["a@example.com"] = &models.Account{Email: "a@example.com", RefreshToken: "rt"}
+	s := New(t.Context(), provider, testConfig())
+	s.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 400, Body: io.NopCloser(strings.NewReader(`{"error":"invalid_grant"}`))}, nil
+		},
+	}}
+
+	if _, err := s.GetAccessToken("a@example.com"); err == nil {
+		t.Fatal("GetAccessToken() succeeded, want error")
+	}
+
+	failures := s.TokenRefreshFailures()
+	if got := failures["a@example.com"]; got != 1 {
+		t.Errorf("TokenRefreshFailures() = %d, want 1 after retries", got)
+	}
+
+	failures["a@example.com"] = 99
+	if got := s.TokenRefreshFailures()["a@example.com"]; got != 1 {
+		t.Errorf("TokenRefreshFailures() = %d, want a copy", got)
+	}
+}
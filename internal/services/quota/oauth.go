@@ -4,10 +4,13 @@ package quota
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -37,6 +40,51 @@ const (
 	userInfoEndpoint = "https://www.googleapis.com/oauth2/v2/userinfo"
 )
 
+// Endpoints are the URLs and headers the package sends its requests to,
+// e.g. to point it at a staging server or at adt mock-server.
+type Endpoints struct {
+	// Headers are sent with every quota request. They are merged over the
+	// default headers; an empty value removes a default header.
+	Headers     map[string]string
+	TokenURL    string
+	UserInfoURL string
+	// Quota are the base URLs of the Cloud Code API, tried in this order.
+	Quota []string
+}
+
+// DefaultEndpoints returns the Google endpoints.
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		Headers:     maps.Clone(antigravityHeaders),
+		TokenURL:    googleOAuthURL,
+		UserInfoURL: userInfoEndpoint,
+		Quota:       slices.Clone(antigravityEndpoints),
+	}
+}
+
+// WithDefaults returns e with its empty fields set to the Google defaults
+// and its headers merged over the default headers.
+func (e Endpoints) WithDefaults() Endpoints {
+	resolved := DefaultEndpoints()
+	if e.TokenURL != "" {
+		resolved.TokenURL = e.TokenURL
+	}
+	if e.UserInfoURL != "" {
+		resolved.UserInfoURL = e.UserInfoURL
+	}
+	if len(e.Quota) > 0 {
+		resolved.Quota = slices.Clone(e.Quota)
+	}
+	for k, v := range e.Headers {
+		if v == "" {
+			delete(resolved.Headers, k)
+			continue
+		}
+		resolved.Headers[k] = v
+	}
+	return resolved
+}
+
 // TokenResponse represents the OAuth token response from Google.
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -62,8 +110,118 @@ func (t *CachedToken) IsValid() bool {
 	return time.Now().Add(5 * time.Minute).Before(t.ExpiresAt)
 }
 
-// RefreshAccessToken exchanges a refresh token for a new access token.
-func RefreshAccessToken(client *http.Client, refreshToken, clientID, clientSecret string) (*TokenResponse, error) {
+// TokenErrorKind classifies why an access token could not be refreshed.
+type TokenErrorKind int
+
+const (
+	// TokenErrorUnknown is a failure that fits none of the other kinds.
+	TokenErrorUnknown TokenErrorKind = iota
+	// TokenErrorRevoked means Google rejected the refresh token as revoked or
+	// expired (invalid_grant). Only logging in again fixes it.
+	TokenErrorRevoked
+	// TokenErrorInvalidClient means the OAuth client ID or secret was
+	// rejected (invalid_client, unauthorized_client).
+	TokenErrorInvalidClient
+	// TokenErrorNetwork means the token endpoint could not be reached.
+	TokenErrorNetwork
+	// TokenErrorServer means the token endpoint answered with a 5xx status or
+	// asked the client to slow down (429).
+	TokenErrorServer
+)
+
+// String returns a short name for the kind.
+func (k TokenErrorKind) String() string {
+	switch k {
+	case TokenErrorRevoked:
+		return "revoked"
+	case TokenErrorInvalidClient:
+		return "invalid client"
+	case TokenErrorNetwork:
+		return "network"
+	case TokenErrorServer:
+		return "server"
+	default:
+		return "unknown"
+	}
+}
+
+// Retryable reports whether retrying the refresh may succeed.
+func (k TokenErrorKind) Retryable() bool {
+	return k == TokenErrorNetwork || k == TokenErrorServer
+}
+
+// TokenError is returned by RefreshAccessToken when the token endpoint could
+// not be reached or refused the refresh.
+type TokenError struct {
+	// Err is the transport error, if the request did not complete.
+	Err error
+	// Code and Description are the OAuth error fields of the response, e.g.
+	// "invalid_grant" and "Token has been expired or revoked."
+	Code        string
+	Description string
+	Body        string
+	StatusCode  int
+	Kind        TokenErrorKind
+}
+
+// Error implements error.
+func (e *TokenError) Error() string {
+	if e.Err != nil {
+		return "token request failed: " + e.Err.Error()
+	}
+	detail := e.Body
+	if e.Code != "" {
+		detail = e.Code
+		if e.Description != "" {
+			detail += ": " + e.Description
+		}
+	}
+	return fmt.Sprintf("token refresh failed (status %d): %s", e.StatusCode, detail)
+}
+
+// Unwrap returns the transport error.
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// TokenErrorKindOf returns the kind of a token refresh error, or
+// TokenErrorUnknown if err is not a *TokenError.
+func TokenErrorKindOf(err error) TokenErrorKind {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.Kind
+	}
+	return TokenErrorUnknown
+}
+
+// newStatusTokenError classifies an error response of the token endpoint.
+func newStatusTokenError(status int, body []byte) *TokenError {
+	tokenErr := &TokenError{StatusCode: status, Body: strings.TrimSpace(string(body))}
+
+	var oauthErr struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &oauthErr) == nil {
+		tokenErr.Code = oauthErr.Error
+		tokenErr.Description = oauthErr.ErrorDescription
+	}
+
+	switch {
+	case tokenErr.Code == "invalid_grant":
+		tokenErr.Kind = TokenErrorRevoked
+	case tokenErr.Code == "invalid_client", tokenErr.Code == "unauthorized_client":
+		tokenErr.Kind = TokenErrorInvalidClient
+	case status >= 500, status == http.StatusTooManyRequests:
+		tokenErr.Kind = TokenErrorServer
+	}
+	return tokenErr
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token at
+// endpoints.TokenURL. Failures of the request itself are returned as
+// *TokenError.
+func RefreshAccessToken(ctx context.Context, client *http.Client, endpoints Endpoints, refreshToken, clientID, clientSecret string) (*TokenResponse, error) {
 	if refreshToken == "" {
 		return nil, fmt.Errorf("refresh token is empty")
 	}
@@ -74,7 +232,7 @@ func RefreshAccessToken(client *http.Client, refreshToken, clientID, clientSecre
 	data.Set("refresh_token", refreshToken)
 	data.Set("grant_type", "refresh_token")
 
-	req, err := http.NewRequestWithContext(context.Background(), "POST", googleOAuthURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoints.TokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create token request: %w", err)
 	}
@@ -85,7 +243,7 @@ func RefreshAccessToken(client *http.Client, refreshToken, clientID, clientSecre
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("token request failed: %w", err)
+		return nil, &TokenError{Err: err, Kind: TokenErrorNetwork}
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -99,7 +257,7 @@ func RefreshAccessToken(client *http.Client, refreshToken, clientID, clientSecre
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token refresh failed (status %d): %s", resp.StatusCode, string(body))
+		return nil, newStatusTokenError(resp.StatusCode, body)
 	}
 
 	var tokenResp TokenResponse
@@ -137,24 +295,45 @@ func normalizeModelFamily(name string) string {
 	return name
 }
 
-// FetchQuota retrieves quota information from the Google Cloud Code API.
-func FetchQuota(client *http.Client, accessToken string) (*Response, error) {
+// FetchQuota retrieves quota information from the Cloud Code API at
+// endpoints.Quota, trying them in order. It keeps no endpoint health
+// between calls; a Service remembers it for its own requests.
+func FetchQuota(ctx context.Context, client *http.Client, endpoints Endpoints, accessToken string) (*Response, error) {
+	return fetchQuota(ctx, client, endpoints, newEndpointTracker(), newFetchMetrics(), accessToken)
+}
+
+// fetchQuota walks the quota endpoints, recording each attempt in tracker
+// and metrics. A request cancelled through ctx is not held against the
+// endpoint, and a throttled account is reported at once instead of trying
+// the next endpoint.
+func fetchQuota(ctx context.Context, client *http.Client, endpoints Endpoints, tracker *endpointTracker, metrics *fetchMetrics, accessToken string) (*Response, error) {
 	if accessToken == "" {
 		return nil, fmt.Errorf("access token is empty")
 	}
 
 	var lastErr error
 
-	// Try each endpoint
-	for _, endpoint := range antigravityEndpoints {
-		body, err := makeQuotaRequest(client, endpoint, accessToken)
-		if err != nil {
-			lastErr = err
+	// Try each endpoint whose circuit is closed, the last one that worked
+	// first.
+	for _, endpoint := range tracker.order(endpoints.Quota) {
+		if !tracker.allow(endpoint, time.Now()) {
 			continue
 		}
 
-		modelQuotas, err := parseQuotaResponse(body)
+		start := time.Now()
+		modelQuotas, err := fetchFromEndpoint(ctx, client, endpoint, endpoints.Headers, accessToken)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			tracker.release(endpoint)
+			return nil, ctxErr
+		}
+		elapsed := time.Since(start)
+		metrics.observe(endpoint, elapsed, err)
+		tracker.observe(endpoint, elapsed, err, time.Now())
 		if err != nil {
+			var status *StatusError
+			if errors.As(err, &status) && status.accountScoped() {
+				return nil, err
+			}
 			lastErr = err
 			continue
 		}
@@ -165,19 +344,27 @@ func FetchQuota(client *http.Client, accessToken string) (*Response, error) {
 	if lastErr != nil {
 		return nil, lastErr
 	}
-	return nil, fmt.Errorf("failed to fetch quota from any endpoint")
+	return nil, &CircuitOpenError{Scope: "quota endpoints", Until: tracker.openUntil(endpoints.Quota, time.Now())}
 }
 
-func makeQuotaRequest(client *http.Client, endpoint, accessToken string) ([]byte, error) {
+func fetchFromEndpoint(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, accessToken string) ([]models.ModelQuota, error) {
+	body, err := makeQuotaRequest(ctx, client, endpoint, headers, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return parseQuotaResponse(body)
+}
+
+func makeQuotaRequest(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, accessToken string) ([]byte, error) {
 	requestURL := endpoint + "/v1internal:fetchAvailableModels"
-	req, err := http.NewRequestWithContext(context.Background(), "POST", requestURL, strings.NewReader("{}"))
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, strings.NewReader("{}"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create quota request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
-	for k, v := range antigravityHeaders {
+	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
@@ -200,11 +387,15 @@ func makeQuotaRequest(client *http.Client, endpoint, accessToken string) ([]byte
 	}
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("unauthorized: access token may be expired")
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: "unauthorized: access token may be expired"}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("quota request failed (status %d): %s", resp.StatusCode, string(body))
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+		if statusErr.Throttled() {
+			statusErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return nil, statusErr
 	}
 
 	return body, nil
@@ -241,7 +432,14 @@ func parseQuotaResponse(body []byte) ([]models.ModelQuota, error) {
 			usagePercentage = float64(used)
 		}
 
+		displayName := data.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+
 		mq := models.ModelQuota{
+			ModelID:          name,
+			DisplayName:      displayName,
 			ModelFamily:      normalizeModelFamily(name),
 			Tier:             detectSubscriptionTier(resetTime),
 			Used:             used,
@@ -256,6 +454,11 @@ func parseQuotaResponse(body []byte) ([]models.ModelQuota, error) {
 		modelQuotas = append(modelQuotas, mq)
 	}
 
+	// Map iteration order is random; keep output stable for display and storage.
+	slices.SortFunc(modelQuotas, func(a, b models.ModelQuota) int {
+		return strings.Compare(a.ModelID, b.ModelID)
+	})
+
 	return modelQuotas, nil
 }
 
@@ -272,12 +475,12 @@ type UserInfo struct {
 }
 
 // FetchUserInfo retrieves user information from Google.
-func FetchUserInfo(client *http.Client, accessToken string) (*UserInfo, error) {
+func FetchUserInfo(ctx context.Context, client *http.Client, endpoints Endpoints, accessToken string) (*UserInfo, error) {
 	if accessToken == "" {
 		return nil, fmt.Errorf("access token is empty")
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), "GET", userInfoEndpoint, http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoints.UserInfoURL, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create userinfo request: %w", err)
 	}
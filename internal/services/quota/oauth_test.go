@@ -72,7 +72,7 @@ func TestRefreshAccessToken(t *testing.T) {
 			if tt.transport == nil {
 				client = nil
 			}
-			_, err := RefreshAccessToken(client, tt.refreshToken, "cid", "csec")
+			_, err := RefreshAccessToken(t.Context(), client, DefaultEndpoints(), tt.refreshToken, "cid", "csec")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("RefreshAccessToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -80,6 +80,49 @@ func TestRefreshAccessToken(t *testing.T) {
 	}
 }
 
+func TestRefreshAccessToken_Classify(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   TokenErrorKind
+	}{
+		{"Revoked", `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`, 400, TokenErrorRevoked},
+		{"InvalidClient", `{"error":"invalid_client"}`, 401, TokenErrorInvalidClient},
+		{"UnauthorizedClient", `{"error":"unauthorized_client"}`, 400, TokenErrorInvalidClient},
+		{"ServerError", "<html>oops</html>", 503, TokenErrorServer},
+		{"RateLimited", `{"error":"rate_limit_exceeded"}`, 429, TokenErrorServer},
+		{"BadRequest", "bad request", 400, TokenErrorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: &MockRoundTripper{
+				RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}, nil
+				},
+			}}
+			_, err := RefreshAccessToken(t.Context(), client, DefaultEndpoints(), "rt", "cid", "csec")
+			if got := TokenErrorKindOf(err); got != tt.want {
+				t.Errorf("kind = %v, want %v (error %v)", got, tt.want, err)
+			}
+		})
+	}
+
+	client := &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection reset")
+		},
+	}}
+	_, err := RefreshAccessToken(t.Context(), client, DefaultEndpoints(), "rt", "cid", "csec")
+	if kind := TokenErrorKindOf(err); kind != TokenErrorNetwork || !kind.Retryable() {
+		t.Errorf("transport failure kind = %v, want a retryable network error", kind)
+	}
+	if TokenErrorRevoked.Retryable() || TokenErrorInvalidClient.Retryable() {
+		t.Error("revoked tokens and rejected clients should not be retried")
+	}
+}
+
 func TestFetchQuota(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -180,7 +223,7 @@ func TestFetchQuota(t *testing.T) {
 			if tt.transport == nil {
 				client = nil
 			}
-			_, err := FetchQuota(client, tt.accessToken)
+			_, err := FetchQuota(t.Context(), client, DefaultEndpoints(), tt.accessToken)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FetchQuota() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -249,7 +292,7 @@ func TestFetchUserInfo(t *testing.T) {
 			if tt.transport == nil {
 				client = nil
 			}
-			_, err := FetchUserInfo(client, tt.accessToken)
+			_, err := FetchUserInfo(t.Context(), client, DefaultEndpoints(), tt.accessToken)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FetchUserInfo() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -257,6 +300,50 @@ func TestFetchUserInfo(t *testing.T) {
 	}
 }
 
+func TestEndpoints_WithDefaults(t *testing.T) {
+	endpoints := Endpoints{
+		TokenURL:    "http://mock/token",
+		UserInfoURL: "http://mock/userinfo",
+		Quota:       []string{"http://mock/a", "http://mock/b"},
+		Headers:     map[string]string{"User-Agent": "adt-test", "X-Goog-Api-Client": ""},
+	}.WithDefaults()
+
+	var urls []string
+	var quotaHeader http.Header
+	client := &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			if strings.HasSuffix(req.URL.Path, ":fetchAvailableModels") {
+				quotaHeader = req.Header
+			}
+			body := `{"access_token":"at","email":"a@example.com","models":{}}`
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}}
+
+	if _, err := RefreshAccessToken(t.Context(), client, endpoints, "rt", "cid", "csec"); err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	if _, err := FetchUserInfo(t.Context(), client, endpoints, "at"); err != nil {
+		t.Fatalf("FetchUserInfo() error = %v", err)
+	}
+	if _, err := FetchQuota(t.Context(), client, endpoints, "at"); err != nil {
+		t.Fatalf("FetchQuota() error = %v", err)
+	}
+
+	want := []string{"http://mock/token", "http://mock/userinfo", "http://mock/a/v1internal:fetchAvailableModels"}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("requests went to %v, want %v", urls, want)
+	}
+	if quotaHeader.Get("User-Agent") != "adt-test" || quotaHeader.Get("X-Goog-Api-Client") != "" || quotaHeader.Get("Client-Metadata") == "" {
+		t.Errorf("quota request headers = %v, want the overrides merged over the defaults", quotaHeader)
+	}
+
+	if got := (Endpoints{}).WithDefaults(); got.TokenURL != googleOAuthURL || len(got.Quota) != len(antigravityEndpoints) {
+		t.Errorf("Endpoints{}.WithDefaults() = %+v, want the defaults", got)
+	}
+}
+
 func TestCachedToken_IsValid(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -278,3 +365,36 @@ func TestCachedToken_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestParseQuotaResponse_KeepsModelIdentity(t *testing.T) {
+	body := []byte(`{"models":{
+		"gemini-3-pro-high":{"displayName":"Gemini 3 Pro (High)","quotaInfo":{"remainingFraction":0.5}},
+		"claude-sonnet-4-5":{"displayName":"Claude Sonnet 4.5","quotaInfo":{"remainingFraction":0.9}},
+		"claude-opus-4-5-thinking":{"quotaInfo":{"remainingFraction":0.2}}
+	}}`)
+
+	quotas, err := parseQuotaResponse(body)
+	if err != nil {
+		t.Fatalf("parseQuotaResponse failed: %v", err)
+	}
+	if len(quotas) != 3 {
+		t.Fatalf("expected 3 quotas, got %d", len(quotas))
+	}
+
+	wantIDs := []string{"claude-opus-4-5-thinking", "claude-sonnet-4-5", "gemini-3-pro-high"}
+	for i, want := range wantIDs {
+		if quotas[i].ModelID != want {
+			t.Errorf("quotas[%d].ModelID = %q, want %q", i, quotas[i].ModelID, want)
+		}
+	}
+
+	if quotas[0].DisplayName != "claude-opus-4-5-thinking" {
+		t.Errorf("expected display name to fall back to model ID, got %q", quotas[0].DisplayName)
+	}
+	if quotas[1].DisplayName != "Claude Sonnet 4.5" || quotas[1].ModelFamily != "claude" {
+		t.Errorf("unexpected sonnet quota: %+v", quotas[1])
+	}
+	if quotas[2].ModelFamily != "gemini" || quotas[2].Remaining != 50 {
+		t.Errorf("unexpected gemini quota: %+v", quotas[2])
+	}
+}
@@ -0,0 +1,294 @@
+package quota
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+const (
+	// defaultMinPollInterval is the fastest an account is polled while it
+	// consumes quota.
+	defaultMinPollInterval = 10 * time.Second
+	// defaultMaxPollInterval is the slowest an account is polled while it is
+	// idle, exhausted or failing.
+	defaultMaxPollInterval = 5 * time.Minute
+	// defaultPollJitter spreads refreshes by up to 10% of their interval.
+	defaultPollJitter = 0.1
+	// resetGrace is how long after a known reset time the account is
+	// refreshed, so the API already reports the new quota.
+	resetGrace = 5 * time.Second
+)
+
+// accountSchedule is the polling state of one account.
+type accountSchedule struct {
+	next     time.Time
+	last     *models.QuotaInfo
+	interval time.Duration
+	inFlight bool
+}
+
+// scheduler decides when each account is refreshed next. Each account has
+// its own interval: it shrinks while the account consumes quota, grows while
+// it is idle, exhausted or failing, and is cut short by known reset times.
+type scheduler struct {
+	accounts map[string]*accountSchedule
+	wake     chan struct{}
+	random   func() float64
+	focus    string
+	base     time.Duration
+	min      time.Duration
+	max      time.Duration
+	jitter   float64
+	mu       sync.Mutex
+}
+
+func newScheduler(config Config) *scheduler {
+	base := config.PollInterval
+	minInterval := config.MinPollInterval
+	if minInterval <= 0 {
+		minInterval = defaultMinPollInterval
+	}
+	maxInterval := config.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+
+	return &scheduler{
+		accounts: make(map[string]*accountSchedule),
+		wake:     make(chan struct{}, 1),
+		random:   rand.Float64,
+		base:     base,
+		min:      min(minInterval, base),
+		max:      max(maxInterval, base),
+		jitter:   config.PollJitter,
+	}
+}
+
+// due returns the accounts to refresh now, the focused account first, and
+// marks them in flight. wait is how long until the next account is due,
+// capped at the base interval so new accounts are picked up. Accounts not
+// in pollable are forgotten; they are polled right away if they come back.
+func (sc *scheduler) due(now time.Time, pollable []models.Account) (due []string, wait time.Duration) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	keep := make(map[string]bool, len(pollable))
+	wait = sc.base
+	for i := range pollable {
+		email := pollable[i].Email
+		keep[email] = true
+
+		st := sc.accounts[email]
+		if st == nil {
+			st = &accountSchedule{next: now, interval: sc.base}
+			sc.accounts[email] = st
+		}
+		if st.inFlight {
+			continue
+		}
+		if !st.next.After(now) {
+			st.inFlight = true
+			due = append(due, email)
+			continue
+		}
+		wait = min(wait, st.next.Sub(now))
+	}
+
+	for email := range sc.accounts {
+		if !keep[email] {
+			delete(sc.accounts, email)
+		}
+	}
+
+	if i := slices.Index(due, sc.focus); i > 0 {
+		due[0], due[i] = due[i], due[0]
+	}
+	return due, wait
+}
+
+// observe records the result of a refresh of email and schedules the next
+// one, never before a Retry-After or open circuit breaker allows. override
+// is the account's fixed poll interval, or 0.
+func (sc *scheduler) observe(email string, override time.Duration, qi *models.QuotaInfo, err error, now time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	st := sc.accounts[email]
+	if st == nil {
+		st = &accountSchedule{interval: sc.base}
+		sc.accounts[email] = st
+	}
+	st.inFlight = false
+
+	live := err == nil && qi != nil && !qi.IsStale()
+	switch {
+	case override > 0:
+		st.interval = override
+	case !live:
+		st.interval = min(st.interval*2, sc.max)
+	case isExhausted(qi):
+		st.interval = sc.max
+	case st.last == nil:
+		// Nothing to compare with yet.
+	default:
+		consumed, reset := compareQuotas(st.last, qi)
+		switch {
+		case reset:
+			st.interval = sc.base
+		case consumed:
+			st.interval = max(st.interval/2, sc.min)
+		default:
+			st.interval = min(st.interval*3/2, sc.max)
+		}
+	}
+	if email == sc.focus && override <= 0 {
+		st.interval = min(st.interval, sc.base)
+	}
+	if live {
+		st.last = qi
+	}
+
+	st.next = now.Add(sc.jittered(st.interval))
+	if reset := nextReset(st.last, now); !reset.IsZero() && reset.Add(resetGrace).Before(st.next) {
+		st.next = reset.Add(resetGrace)
+	}
+	if until := retryAt(err, now); until.After(st.next) {
+		st.next = until
+	}
+}
+
+// release ends a refresh of email that was not observed, e.g. because it
+// shared another refresh's result, and makes sure the next one is not due
+// before notBefore.
+func (sc *scheduler) release(email string, notBefore time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	st := sc.accounts[email]
+	if st == nil {
+		return
+	}
+	st.inFlight = false
+	if st.next.Before(notBefore) {
+		st.next = notBefore
+	}
+}
+
+// setFocus prioritises email: it goes first when several accounts are due,
+// is never polled less often than the base interval, and is refreshed within
+// the minimum interval of being focused.
+func (sc *scheduler) setFocus(email string, now time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.focus = email
+	if st := sc.accounts[email]; st != nil && st.next.After(now.Add(sc.min)) {
+		st.next = now.Add(sc.min)
+		st.interval = min(st.interval, sc.base)
+	}
+}
+
+// reset makes every account due now, e.g. once credentials appear.
+func (sc *scheduler) reset(now time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for _, st := range sc.accounts {
+		st.next = now
+		st.interval = sc.base
+	}
+}
+
+// next returns when email is due next, or the zero time if it is not
+// scheduled.
+func (sc *scheduler) next(email string) time.Time {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if st := sc.accounts[email]; st != nil {
+		return st.next
+	}
+	return time.Time{}
+}
+
+// notify wakes the scheduling loop without blocking.
+func (sc *scheduler) notify() {
+	select {
+	case sc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// jittered spreads d by up to ±jitter of its length.
+func (sc *scheduler) jittered(d time.Duration) time.Duration {
+	if sc.jitter <= 0 {
+		return d
+	}
+	return d + time.Duration((sc.random()*2-1)*sc.jitter*float64(d))
+}
+
+// isExhausted reports whether every model with a limit has no quota left.
+func isExhausted(qi *models.QuotaInfo) bool {
+	exhausted := false
+	for i := range qi.ModelQuotas {
+		mq := &qi.ModelQuotas[i]
+		if mq.Limit <= 0 && !mq.IsRateLimited {
+			continue
+		}
+		if mq.RemainingPercent() > 0 {
+			return false
+		}
+		exhausted = true
+	}
+	return exhausted
+}
+
+// compareQuotas reports whether any model's remaining quota went down
+// (consumed) or up (reset) between two refreshes.
+func compareQuotas(prev, cur *models.QuotaInfo) (consumed, reset bool) {
+	before := make(map[string]int64, len(prev.ModelQuotas))
+	for i := range prev.ModelQuotas {
+		before[modelKey(&prev.ModelQuotas[i])] = prev.ModelQuotas[i].Remaining
+	}
+	for i := range cur.ModelQuotas {
+		remaining, ok := before[modelKey(&cur.ModelQuotas[i])]
+		switch {
+		case !ok:
+		case cur.ModelQuotas[i].Remaining < remaining:
+			consumed = true
+		case cur.ModelQuotas[i].Remaining > remaining:
+			reset = true
+		}
+	}
+	return consumed, reset
+}
+
+func modelKey(mq *models.ModelQuota) string {
+	if mq.ModelID != "" {
+		return mq.ModelID
+	}
+	return mq.ModelFamily
+}
+
+// nextReset returns the earliest reset time after now of a model that has
+// used some quota, or the zero time.
+func nextReset(qi *models.QuotaInfo, now time.Time) time.Time {
+	var earliest time.Time
+	if qi == nil {
+		return earliest
+	}
+	for i := range qi.ModelQuotas {
+		mq := &qi.ModelQuotas[i]
+		if mq.Remaining >= mq.Limit && !mq.IsRateLimited {
+			continue
+		}
+		if mq.ResetTime.After(now) && (earliest.IsZero() || mq.ResetTime.Before(earliest)) {
+			earliest = mq.ResetTime
+		}
+	}
+	return earliest
+}
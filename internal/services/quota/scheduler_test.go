@@ -0,0 +1,216 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// testScheduler returns a scheduler without jitter polling at 30s, between
+// 10s and 5m.
+func testScheduler() *scheduler {
+	config := DefaultConfig()
+	config.PollJitter = 0
+	return newScheduler(config)
+}
+
+func quotaWith(remaining int64, reset time.Time) *models.QuotaInfo {
+	return &models.QuotaInfo{
+		LastUpdated: time.Now(),
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Remaining: remaining, Limit: 100, ResetTime: reset},
+		},
+	}
+}
+
+func accountsNamed(emails ...string) []models.Account {
+	accs := make([]models.Account, len(emails))
+	for i, email := range emails {
+		accs[i] = models.Account{Email: email}
+	}
+	return accs
+}
+
+func TestScheduler_Intervals(t *testing.T) {
+	now := time.Now()
+	farReset := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		err      error
+		first    *models.QuotaInfo
+		second   *models.QuotaInfo
+		name     string
+		want     time.Duration
+		override time.Duration
+	}{
+		{name: "consuming", first: quotaWith(80, farReset), second: quotaWith(70, farReset), want: 15 * time.Second},
+		{name: "idle", first: quotaWith(80, farReset), second: quotaWith(80, farReset), want: 45 * time.Second},
+		{name: "exhausted", first: quotaWith(10, farReset), second: quotaWith(0, farReset), want: 5 * time.Minute},
+		{name: "reset", first: quotaWith(10, farReset), second: quotaWith(100, time.Time{}), want: 30 * time.Second},
+		{name: "failing", first: quotaWith(80, farReset), err: errors.New("boom"), want: 60 * time.Second},
+		{name: "override", first: quotaWith(80, farReset), second: quotaWith(70, farReset), override: 2 * time.Minute, want: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := testScheduler()
+			sc.observe("a", 0, tt.first, nil, now)
+			sc.observe("a", tt.override, tt.second, tt.err, now)
+			if got := sc.next("a").Sub(now); got != tt.want {
+				t.Errorf("next refresh in %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Bounds(t *testing.T) {
+	now := time.Now()
+	farReset := now.Add(24 * time.Hour)
+	sc := testScheduler()
+
+	for i := range 10 {
+		sc.observe("a", 0, quotaWith(int64(90-i), farReset), nil, now)
+	}
+	if got := sc.next("a").Sub(now); got != 10*time.Second {
+		t.Errorf("consuming account polled every %v, want the 10s minimum", got)
+	}
+
+	for range 20 {
+		sc.observe("a", 0, quotaWith(50, farReset), nil, now)
+	}
+	if got := sc.next("a").Sub(now); got != 5*time.Minute {
+		t.Errorf("idle account polled every %v, want the 5m maximum", got)
+	}
+}
+
+func TestScheduler_RefreshesAfterReset(t *testing.T) {
+	now := time.Now()
+	reset := now.Add(time.Minute)
+	sc := testScheduler()
+
+	sc.observe("a", 0, quotaWith(0, reset), nil, now)
+	if got := sc.next("a"); !got.Equal(reset.Add(resetGrace)) {
+		t.Errorf("exhausted account refreshed at %v, want just after the reset at %v", got, reset)
+	}
+
+	sc.observe("b", 0, quotaWith(100, reset), nil, now)
+	if got := sc.next("b").Sub(now); got != 30*time.Second {
+		t.Errorf("unused account refreshed in %v, want the reset ignored", got)
+	}
+}
+
+func TestScheduler_HonoursRetryAfter(t *testing.T) {
+	now := time.Now()
+	sc := testScheduler()
+
+	sc.observe("a", 0, nil, &StatusError{StatusCode: 429, RetryAfter: 3 * time.Minute}, now)
+	if got := sc.next("a").Sub(now); got != 3*time.Minute {
+		t.Errorf("throttled account refreshed in %v, want after the Retry-After", got)
+	}
+
+	until := now.Add(10 * time.Minute)
+	sc.observe("b", 0, nil, &CircuitOpenError{Scope: "b", Until: until}, now)
+	if got := sc.next("b"); !got.Equal(until) {
+		t.Errorf("account refreshed at %v, want when its circuit closes at %v", got, until)
+	}
+}
+
+func TestScheduler_Due(t *testing.T) {
+	now := time.Now()
+	sc := testScheduler()
+
+	due, wait := sc.due(now, accountsNamed("a", "b", "c"))
+	if len(due) != 3 || wait != 30*time.Second {
+		t.Fatalf("due() = %v, %v, want all new accounts now", due, wait)
+	}
+	if again, _ := sc.due(now, accountsNamed("a", "b", "c")); len(again) != 0 {
+		t.Errorf("due() = %v, want in-flight accounts skipped", again)
+	}
+
+	sc.observe("a", 0, quotaWith(50, time.Time{}), nil, now)
+	sc.observe("b", 0, quotaWith(50, time.Time{}), nil, now.Add(-40*time.Second))
+	due, wait = sc.due(now, accountsNamed("a", "b"))
+	if len(due) != 1 || due[0] != "b" || wait != 30*time.Second {
+		t.Errorf("due() = %v, %v, want b now and a in 30s", due, wait)
+	}
+	if !sc.next("c").IsZero() {
+		t.Error("an account that is no longer pollable should be forgotten")
+	}
+}
+
+func TestScheduler_Focus(t *testing.T) {
+	now := time.Now()
+	farReset := now.Add(24 * time.Hour)
+	sc := testScheduler()
+
+	due, _ := sc.due(now, accountsNamed("a", "b", "c"))
+	sc.setFocus("c", now)
+	for _, email := range due {
+		sc.observe(email, 0, quotaWith(50, farReset), nil, now)
+	}
+	for range 10 {
+		sc.observe("a", 0, quotaWith(50, farReset), nil, now)
+		sc.observe("c", 0, quotaWith(50, farReset), nil, now)
+	}
+	if got := sc.next("c").Sub(now); got != 30*time.Second {
+		t.Errorf("idle focused account polled every %v, want at most 30s", got)
+	}
+	if got := sc.next("a").Sub(now); got <= 30*time.Second {
+		t.Errorf("idle account polled every %v, want it to back off", got)
+	}
+
+	sc.setFocus("a", now)
+	if got := sc.next("a").Sub(now); got != 10*time.Second {
+		t.Errorf("newly focused account refreshed in %v, want 10s", got)
+	}
+
+	later := now.Add(time.Hour)
+	due, _ = sc.due(later, accountsNamed("b", "c", "a"))
+	if len(due) != 3 || due[0] != "a" {
+		t.Errorf("due() = %v, want the focused account first", due)
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	sc := testScheduler()
+	sc.jitter = 0.1
+
+	sc.random = func() float64 { return 0 }
+	if got := sc.jittered(time.Minute); got != 54*time.Second {
+		t.Errorf("jittered() = %v, want 54s", got)
+	}
+	sc.random = func() float64 { return 1 }
+	if got := sc.jittered(time.Minute); got != 66*time.Second {
+		t.Errorf("jittered() = %v, want 66s", got)
+	}
+}
+
+func TestScheduler_Release(t *testing.T) {
+	sc := testScheduler()
+	now := time.Now()
+
+	due, _ := sc.due(now, accountsNamed("a"))
+	if len(due) != 1 {
+		t.Fatalf("due() = %v, want a new account due", due)
+	}
+	sc.release("a", now.Add(5*time.Second))
+	if due, wait := sc.due(now, accountsNamed("a")); len(due) != 0 || wait != 5*time.Second {
+		t.Errorf("due() = %v, %v, want nothing due until the cooldown ends", due, wait)
+	}
+	if due, _ := sc.due(now.Add(5*time.Second), accountsNamed("a")); len(due) != 1 {
+		t.Error("a released account should be due again after the cooldown")
+	}
+}
+
+func TestService_PausedAccountsAreNotPolled(t *testing.T) {
+	provider := NewMockAccountProvider()
+	provider.Accounts["a@example.com"] = &models.Account{Email: "a@example.com", RefreshToken: "rt-a"}
+	provider.Accounts["b@example.com"] = &models.Account{Email: "b@example.com", RefreshToken: "rt-b", PollPaused: true}
+
+	svc := New(t.Context(), provider, testConfig())
+	pollable := svc.pollableAccounts()
+	if len(pollable) != 1 || pollable[0].Email != "a@example.com" {
+		t.Errorf("pollableAccounts() = %v, want only the unpaused account", pollable)
+	}
+}
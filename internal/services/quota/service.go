@@ -1,16 +1,26 @@
 package quota
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"maps"
 	"net/http"
 	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
 )
 
+// defaultRefreshCooldown is the minimum time between two refreshes of an
+// account.
+const defaultRefreshCooldown = 5 * time.Second
+
 // AccountProvider is an interface for getting account information.
 type AccountProvider interface {
 	GetAccounts() []models.Account
@@ -40,22 +50,48 @@ const (
 	EventTokenRefreshed
 	// EventTokenError indicates that an error occurred during token refresh.
 	EventTokenError
+	// EventTokenRevoked indicates that an account's refresh token was
+	// rejected as revoked or expired. It is sent once per token; the account
+	// is no longer polled until its refresh token changes.
+	EventTokenRevoked
 )
 
 // Config holds configuration for the quota service.
 type Config struct {
+	// CredentialsFunc, if set, is called on each poll while credentials are
+	// missing so polling can start as soon as they become available.
+	CredentialsFunc func() (clientID, clientSecret string)
 	ClientID        string
 	ClientSecret    string
+	// PollInterval is the interval a new account is polled at. Each
+	// account's interval then adapts between MinPollInterval, while it
+	// consumes quota, and MaxPollInterval, while it is idle or exhausted.
 	PollInterval    time.Duration
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
 	RefreshInterval time.Duration
+	// RefreshCooldown is the minimum time between two refreshes of an
+	// account. Refreshes requested sooner get the previous result;
+	// scheduled ones are postponed. Zero only coalesces concurrent
+	// refreshes.
+	RefreshCooldown time.Duration
 	MaxConcurrent   int
+	// PollJitter spreads refreshes by up to this fraction of the interval.
+	PollJitter float64
+	// Endpoints are the OAuth, userinfo and Cloud Code endpoints; empty
+	// fields use Google's.
+	Endpoints Endpoints
 }
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() Config {
 	return Config{
 		PollInterval:    30 * time.Second,
+		MinPollInterval: defaultMinPollInterval,
+		MaxPollInterval: defaultMaxPollInterval,
+		PollJitter:      defaultPollJitter,
 		RefreshInterval: 5 * time.Minute,
+		RefreshCooldown: defaultRefreshCooldown,
 		MaxConcurrent:   5,
 	}
 }
@@ -65,30 +101,58 @@ type Service struct {
 	accountProvider AccountProvider
 	quotaCache      map[string]*models.QuotaInfo
 	tokenCache      map[string]*CachedToken
+	tokenFailures   map[string]uint64
+	revokedTokens   map[string]string
+	breakers        map[string]*breaker
+	lastRefresh     map[string]refreshResult
 	eventChan       chan Event
-	stopChan        chan struct{}
-	pollTicker      *time.Ticker
+	ctx             context.Context
+	cancel          context.CancelFunc
+	scheduler       *scheduler
 	refreshSem      chan struct{}
 	httpClient      *http.Client
 	config          Config
-	mu              sync.RWMutex
+	// endpointHealth and fetchMetrics follow the quota endpoints across
+	// this service's requests.
+	endpointHealth *endpointTracker
+	fetchMetrics   *fetchMetrics
+	// refreshes coalesces concurrent quota refreshes per account and
+	// tokenRefreshes concurrent token refreshes per refresh token.
+	refreshes      singleflight.Group
+	tokenRefreshes singleflight.Group
+	// workers counts the goroutines that fetch quota or tokens, so Close
+	// can wait for them; closed stops new ones from starting.
+	workers sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
 }
 
-// New creates a new quota service.
-func New(provider AccountProvider, config Config) *Service {
+// New creates a new quota service. Cancelling ctx, or calling Close, aborts
+// its in-flight requests.
+func New(ctx context.Context, provider AccountProvider, config Config) *Service {
 	if config.PollInterval == 0 {
 		config = DefaultConfig()
 	}
+	config.Endpoints = config.Endpoints.WithDefaults()
 
+	ctx, cancel := context.WithCancel(ctx)
 	s := &Service{
 		accountProvider: provider,
 		quotaCache:      make(map[string]*models.QuotaInfo),
 		tokenCache:      make(map[string]*CachedToken),
+		tokenFailures:   make(map[string]uint64),
+		revokedTokens:   make(map[string]string),
+		breakers:        make(map[string]*breaker),
+		lastRefresh:     make(map[string]refreshResult),
 		eventChan:       make(chan Event, 100),
-		stopChan:        make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
+		scheduler:       newScheduler(config),
 		config:          config,
 		refreshSem:      make(chan struct{}, config.MaxConcurrent),
 		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		endpointHealth:  newEndpointTracker(),
+		fetchMetrics:    newFetchMetrics(),
 	}
 
 	return s
@@ -96,21 +160,33 @@ func New(provider AccountProvider, config Config) *Service {
 
 // Start starts the background polling.
 func (s *Service) Start() {
+	if !s.begin() {
+		return
+	}
 	go s.pollQuota()
 }
 
+// begin registers a worker goroutine or call. It reports false once the
+// service is closing; otherwise the caller must call s.workers.Done.
+func (s *Service) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.workers.Add(1)
+	return true
+}
+
 // Events returns the event channel.
 func (s *Service) Events() <-chan Event {
 	return s.eventChan
 }
 
 // GetAccessToken returns a valid access token for the account, refreshing if needed.
+// Concurrent refreshes of the same refresh token share one request.
 func (s *Service) GetAccessToken(email string) (string, error) {
-	s.mu.RLock()
-	cached, ok := s.tokenCache[email]
-	s.mu.RUnlock()
-
-	if ok && cached.IsValid() {
+	if cached := s.cachedToken(email); cached != nil {
 		return cached.AccessToken, nil
 	}
 
@@ -128,38 +204,91 @@ func (s *Service) GetAccessToken(email string) (string, error) {
 		return "", fmt.Errorf("no refresh token for account: %s", email)
 	}
 
+	clientID, clientSecret := s.credentials()
+	if clientID == "" || clientSecret == "" {
+		return "", ErrNoCredentials
+	}
+
+	v, err, _ := s.tokenRefreshes.Do(TokenFingerprint(refreshToken), func() (any, error) {
+		// A refresh that finished while this one was starting is as good.
+		if cached := s.cachedToken(email); cached != nil {
+			return cached, nil
+		}
+		return s.refreshAccessToken(email, refreshToken, clientID, clientSecret)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Accounts sharing the refresh token share the access token too.
+	token := v.(*CachedToken)
+	s.mu.Lock()
+	s.tokenCache[email] = token
+	s.mu.Unlock()
+	return token.AccessToken, nil
+}
+
+// cachedToken returns the account's cached access token if it is still
+// valid, or nil.
+func (s *Service) cachedToken(email string) *CachedToken {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cached, ok := s.tokenCache[email]; ok && cached.IsValid() {
+		return cached
+	}
+	return nil
+}
+
+// refreshAccessToken exchanges the refresh token for an access token.
+// Failures are counted and reported for email.
+func (s *Service) refreshAccessToken(email, refreshToken, clientID, clientSecret string) (*CachedToken, error) {
 	var tokenResp *TokenResponse
 	var err error
 
-	// Retry with exponential backoff
+	// Retry transient failures with exponential backoff; a revoked token or
+	// a rejected client fails the same way every time.
 	backoff := 500 * time.Millisecond
 	for i := range 3 {
-		tokenResp, err = RefreshAccessToken(s.httpClient, refreshToken, s.config.ClientID, s.config.ClientSecret)
-		if err == nil {
+		tokenResp, err = RefreshAccessToken(s.ctx, s.httpClient, s.config.Endpoints, refreshToken, clientID, clientSecret)
+		if err == nil || !TokenErrorKindOf(err).Retryable() || s.ctx.Err() != nil {
 			break
 		}
 
 		if i < 2 {
-			time.Sleep(backoff)
+			select {
+			case <-time.After(backoff):
+			case <-s.ctx.Done():
+			}
 			backoff *= 2
 		}
 	}
 
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
-		s.sendEvent(Event{
-			Type:         EventTokenError,
-			AccountEmail: email,
-			Error:        err,
-		})
-		return "", fmt.Errorf("failed to refresh token: %w", err)
+		s.mu.Lock()
+		s.tokenFailures[email]++
+		s.mu.Unlock()
+		if TokenErrorKindOf(err) == TokenErrorRevoked {
+			s.markRevoked(email, refreshToken, err)
+		} else {
+			s.sendEvent(Event{
+				Type:         EventTokenError,
+				AccountEmail: email,
+				Error:        err,
+			})
+		}
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	// Cache the token
-	s.mu.Lock()
-	s.tokenCache[email] = &CachedToken{
+	token := &CachedToken{
 		AccessToken: tokenResp.AccessToken,
 		ExpiresAt:   time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
 	}
+	s.mu.Lock()
+	delete(s.revokedTokens, email)
 	s.mu.Unlock()
 
 	s.sendEvent(Event{
@@ -167,7 +296,61 @@ func (s *Service) GetAccessToken(email string) (string, error) {
 		AccountEmail: email,
 	})
 
-	return tokenResp.AccessToken, nil
+	return token, nil
+}
+
+// TokenFingerprint identifies a refresh token without revealing it, so a
+// revoked token can be remembered and told apart from its replacement.
+func TokenFingerprint(refreshToken string) string {
+	if refreshToken == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// markRevoked remembers that the account's refresh token is dead and sends
+// EventTokenRevoked the first time it is seen.
+func (s *Service) markRevoked(email, refreshToken string, err error) {
+	fingerprint := TokenFingerprint(refreshToken)
+
+	s.mu.Lock()
+	known := s.revokedTokens[email] == fingerprint
+	s.revokedTokens[email] = fingerprint
+	s.mu.Unlock()
+
+	if known {
+		return
+	}
+	logger.Warn("refresh token revoked, pausing polling until the account logs in again", "email", email)
+	s.sendEvent(Event{
+		Type:         EventTokenRevoked,
+		AccountEmail: email,
+		Error:        err,
+	})
+}
+
+// SeedRevoked restores the fingerprints of refresh tokens found revoked in a
+// previous run, keyed by account email.
+func (s *Service) SeedRevoked(fingerprints map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	maps.Copy(s.revokedTokens, fingerprints)
+}
+
+// RevokedToken returns the fingerprint of the account's revoked refresh
+// token, or an empty string if its token is not known to be revoked.
+func (s *Service) RevokedToken(email string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revokedTokens[email]
+}
+
+// isRevoked reports whether the account's current refresh token is known to
+// be revoked. A token replaced by logging in again is not.
+func (s *Service) isRevoked(acc *models.Account) bool {
+	fingerprint := s.RevokedToken(acc.Email)
+	return fingerprint != "" && fingerprint == TokenFingerprint(acc.RefreshToken)
 }
 
 // GetQuota returns cached quota for an account.
@@ -177,15 +360,133 @@ func (s *Service) GetQuota(email string) *models.QuotaInfo {
 	return s.quotaCache[email]
 }
 
-// RefreshQuota fetches fresh quota for an account.
+// SeedQuotas pre-populates the cache with last-known quotas. Entries that
+// already have fresher data are left untouched.
+func (s *Service) SeedQuotas(quotas map[string]*models.QuotaInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for email, qi := range quotas {
+		if _, ok := s.quotaCache[email]; !ok {
+			s.quotaCache[email] = qi
+		}
+	}
+}
+
+// SetQuota replaces the cached quota for an account without fetching. It is
+// used when another process does the polling.
+func (s *Service) SetQuota(email string, qi *models.QuotaInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotaCache[email] = qi
+}
+
+// RefreshQuota fetches fresh quota for an account and reschedules its next
+// background refresh. Concurrent calls for the account share one refresh,
+// and calls within RefreshCooldown of the last one get its result. It fails
+// with ErrClosed once the service is closing.
 func (s *Service) RefreshQuota(email string) (*models.QuotaInfo, error) {
+	if !s.begin() {
+		return nil, ErrClosed
+	}
+	defer s.workers.Done()
+	return s.refreshShared(email)
+}
+
+// refreshResult is the outcome of an account's last completed refresh.
+type refreshResult struct {
+	at        time.Time
+	quotaInfo *models.QuotaInfo
+	err       error
+}
+
+// refreshShared refreshes the account, or joins its refresh in flight, or
+// returns the result of the last one if it finished within the cooldown.
+func (s *Service) refreshShared(email string) (*models.QuotaInfo, error) {
+	if last, ok := s.recentRefresh(email, time.Now()); ok {
+		return last.quotaInfo, last.err
+	}
+
+	v, err, _ := s.refreshes.Do(email, func() (any, error) {
+		// A refresh that finished while this one was starting counts too.
+		if last, ok := s.recentRefresh(email, time.Now()); ok {
+			return last.quotaInfo, last.err
+		}
+		quotaInfo, err := s.refreshAndObserve(email)
+		if s.ctx.Err() == nil {
+			s.mu.Lock()
+			s.lastRefresh[email] = refreshResult{at: time.Now(), quotaInfo: quotaInfo, err: err}
+			s.mu.Unlock()
+		}
+		return quotaInfo, err
+	})
+	quotaInfo, _ := v.(*models.QuotaInfo)
+	return quotaInfo, err
+}
+
+// recentRefresh returns the account's last refresh if it finished less than
+// RefreshCooldown before now.
+func (s *Service) recentRefresh(email string, now time.Time) (refreshResult, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	last, ok := s.lastRefresh[email]
+	return last, ok && now.Before(last.at.Add(s.config.RefreshCooldown))
+}
+
+// cooldownEnd returns when the account may be refreshed again, or the zero
+// time if it has not been refreshed.
+func (s *Service) cooldownEnd(email string) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	last, ok := s.lastRefresh[email]
+	if !ok {
+		return time.Time{}
+	}
+	return last.at.Add(s.config.RefreshCooldown)
+}
+
+func (s *Service) refreshAndObserve(email string) (*models.QuotaInfo, error) {
+	quotaInfo, err := s.refreshQuota(email)
+	if s.ctx.Err() != nil {
+		// Shutting down: the request was cut short, not answered.
+		return quotaInfo, err
+	}
+	if quotaInfo != nil && quotaInfo.AccountEmail != "" {
+		email = quotaInfo.AccountEmail
+	}
+
+	var override time.Duration
+	if s.accountProvider != nil {
+		if acc := s.accountProvider.GetAccountByEmail(email); acc != nil {
+			override = acc.PollOverride()
+		}
+	}
+	s.scheduler.observe(email, override, quotaInfo, err, time.Now())
+	s.scheduler.notify()
+
+	return quotaInfo, err
+}
+
+func (s *Service) refreshQuota(email string) (*models.QuotaInfo, error) {
 	s.sendEvent(Event{
 		Type:         EventQuotaRefreshing,
 		AccountEmail: email,
 	})
 
+	// A throttled account makes no requests at all, not even for a token.
+	if until, ok := s.accountCircuit(email, time.Now()); !ok {
+		return s.handleQuotaError(email, &CircuitOpenError{Scope: email, Until: until})
+	}
+
 	accessToken, err := s.GetAccessToken(email)
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		s.releaseAccount(email)
+		return s.GetQuota(email), ctxErr
+	}
 	if err != nil {
+		s.releaseAccount(email)
 		return s.handleQuotaError(email, err)
 	}
 
@@ -193,7 +494,12 @@ func (s *Service) RefreshQuota(email string) (*models.QuotaInfo, error) {
 		email = newEmail
 	}
 
-	quotaResp, err := FetchQuota(s.httpClient, accessToken)
+	quotaResp, err := fetchQuota(s.ctx, s.httpClient, s.config.Endpoints, s.endpointHealth, s.fetchMetrics, accessToken)
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		s.releaseAccount(email)
+		return s.GetQuota(email), ctxErr
+	}
+	s.observeAccount(email, err, time.Now())
 	if err != nil {
 		return s.handleQuotaError(email, err)
 	}
@@ -201,13 +507,64 @@ func (s *Service) RefreshQuota(email string) (*models.QuotaInfo, error) {
 	return s.processQuotaResponse(email, quotaResp)
 }
 
-func (s *Service) handleQuotaError(email string, err error) (*models.QuotaInfo, error) {
-	quotaInfo := &models.QuotaInfo{
-		AccountEmail: email,
-		LastUpdated:  time.Now(),
-		Error:        err.Error(),
+// accountCircuit reports whether the account's circuit breaker lets a quota
+// request through and, if not, until when it is open.
+func (s *Service) accountCircuit(email string, now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakers[email]
+	if b == nil || b.allow(now) {
+		return time.Time{}, true
+	}
+	return b.openUntil, false
+}
+
+// releaseAccount lets the account's circuit breaker try again after a
+// refresh that ended before its quota request was answered.
+func (s *Service) releaseAccount(email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b := s.breakers[email]; b != nil {
+		b.probing = false
 	}
+}
+
+// observeAccount feeds the result of a quota fetch to the account's circuit
+// breaker. Open endpoint circuits are not the account's fault and are
+// ignored.
+func (s *Service) observeAccount(email string, err error, now time.Time) {
 	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var open *CircuitOpenError
+	b := s.breakers[email]
+	switch {
+	case err == nil:
+		delete(s.breakers, email)
+	case errors.As(err, &open):
+		if b != nil {
+			b.probing = false
+		}
+	default:
+		if b == nil {
+			b = &breaker{}
+			s.breakers[email] = b
+		}
+		var retryAfter time.Duration
+		if until := retryAt(err, now); !until.IsZero() {
+			retryAfter = until.Sub(now)
+		}
+		b.failure(now, retryAfter)
+	}
+}
+
+func (s *Service) handleQuotaError(email string, err error) (*models.QuotaInfo, error) {
+	reason := ClassifyError(err)
+
+	s.mu.Lock()
+	quotaInfo := degradedQuota(s.quotaCache[email], email, err, reason)
 	s.quotaCache[email] = quotaInfo
 	s.mu.Unlock()
 	s.sendEvent(Event{
@@ -220,9 +577,10 @@ func (s *Service) handleQuotaError(email string, err error) (*models.QuotaInfo,
 }
 
 func (s *Service) checkEmailUpdate(email, accessToken string) string {
-	if userInfo, err := FetchUserInfo(s.httpClient, accessToken); err == nil && userInfo.Email != "" {
+	if userInfo, err := FetchUserInfo(s.ctx, s.httpClient, s.config.Endpoints, accessToken); err == nil && userInfo.Email != "" {
 		if userInfo.Email != email {
 			if err := s.accountProvider.UpdateAccountEmail(email, userInfo.Email); err == nil {
+				s.renameBreaker(email, userInfo.Email)
 				return userInfo.Email
 			}
 		}
@@ -230,6 +588,17 @@ func (s *Service) checkEmailUpdate(email, accessToken string) string {
 	return ""
 }
 
+// renameBreaker moves the account's circuit breaker to its new email.
+func (s *Service) renameBreaker(oldEmail, newEmail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.breakers[oldEmail]; ok {
+		delete(s.breakers, oldEmail)
+		s.breakers[newEmail] = b
+	}
+}
+
 func (s *Service) processQuotaResponse(email string, quotaResp *Response) (*models.QuotaInfo, error) {
 	quotaInfo := &models.QuotaInfo{
 		AccountEmail:     email,
@@ -272,13 +641,17 @@ func (s *Service) GetAllQuotas() map[string]*models.QuotaInfo {
 	return result
 }
 
-// RefreshAllQuotas refreshes quota for all accounts.
+// RefreshAllQuotas refreshes quota for all accounts. Paused accounts and
+// accounts whose refresh token is known to be revoked are skipped;
+// RefreshQuota still refreshes them. It returns early once the service is
+// closing.
 func (s *Service) RefreshAllQuotas() {
-	if s.accountProvider == nil {
+	if !s.begin() {
 		return
 	}
+	defer s.workers.Done()
 
-	accounts := s.accountProvider.GetAccounts()
+	accounts := s.pollableAccounts()
 	var wg sync.WaitGroup
 
 	for i := range accounts {
@@ -286,35 +659,136 @@ func (s *Service) RefreshAllQuotas() {
 		wg.Add(1)
 		go func(email string) {
 			defer wg.Done()
-
-			// Acquire semaphore
-			s.refreshSem <- struct{}{}
-			defer func() { <-s.refreshSem }()
-
-			if _, err := s.RefreshQuota(email); err != nil {
-				logger.Error("failed to refresh quota", "email", email, "error", err)
-			}
+			s.refreshLimited(email)
 		}(acc.Email)
 	}
 
 	wg.Wait()
 }
 
-// pollQuota runs the background polling goroutine.
-func (s *Service) pollQuota() {
-	// Initial refresh
-	s.RefreshAllQuotas()
+// refreshLimited refreshes an account once a slot of the MaxConcurrent
+// semaphore is free, unless the service closes first.
+func (s *Service) refreshLimited(email string) {
+	select {
+	case s.refreshSem <- struct{}{}:
+	case <-s.ctx.Done():
+		return
+	}
+	defer func() { <-s.refreshSem }()
+
+	if _, err := s.refreshShared(email); err != nil && s.ctx.Err() == nil {
+		logger.Error("failed to refresh quota", "email", email, "error", err)
+	}
+}
+
+// credentials returns the OAuth client credentials currently in use.
+func (s *Service) credentials() (clientID, clientSecret string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.ClientID, s.config.ClientSecret
+}
+
+// HasCredentials reports whether OAuth client credentials are configured.
+func (s *Service) HasCredentials() bool {
+	clientID, clientSecret := s.credentials()
+	return clientID != "" && clientSecret != ""
+}
 
-	s.pollTicker = time.NewTicker(s.config.PollInterval)
-	defer s.pollTicker.Stop()
+// reloadCredentials asks CredentialsFunc for credentials when none are set
+// and reports whether it found them.
+func (s *Service) reloadCredentials() bool {
+	if s.HasCredentials() || s.config.CredentialsFunc == nil {
+		return false
+	}
+
+	clientID, clientSecret := s.config.CredentialsFunc()
+	if clientID == "" || clientSecret == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	s.config.ClientID = clientID
+	s.config.ClientSecret = clientSecret
+	s.mu.Unlock()
+
+	logger.Info("OAuth credentials found, resuming live polling")
+	return true
+}
+
+// pollableAccounts returns the accounts polled in the background: all but
+// paused accounts and accounts whose refresh token is known to be revoked.
+func (s *Service) pollableAccounts() []models.Account {
+	if s.accountProvider == nil {
+		return nil
+	}
+
+	var pollable []models.Account
+	for _, acc := range s.accountProvider.GetAccounts() {
+		if !acc.PollPaused && !s.isRevoked(&acc) {
+			pollable = append(pollable, acc)
+		}
+	}
+	return pollable
+}
+
+// SetFocus prioritises the account shown in the dashboard: it is refreshed
+// first and never polled less often than PollInterval.
+func (s *Service) SetFocus(email string) {
+	s.scheduler.setFocus(email, time.Now())
+	s.scheduler.notify()
+}
+
+// Reschedule re-reads the accounts, so added, resumed or re-authenticated
+// accounts are polled right away instead of on the next scheduler pass.
+func (s *Service) Reschedule() {
+	s.scheduler.notify()
+}
+
+// NextRefresh returns when the account is due for its next background
+// refresh, or the zero time if it is not scheduled.
+func (s *Service) NextRefresh(email string) time.Time {
+	return s.scheduler.next(email)
+}
+
+// pollQuota runs the per-account scheduler: each account is refreshed in
+// its own goroutine when it is due, so a slow account does not hold up the
+// others.
+func (s *Service) pollQuota() {
+	defer s.workers.Done()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-s.pollTicker.C:
-			s.RefreshAllQuotas()
-		case <-s.stopChan:
+		case <-timer.C:
+		case <-s.scheduler.wake:
+		case <-s.ctx.Done():
 			return
 		}
+
+		if s.reloadCredentials() {
+			s.scheduler.reset(time.Now())
+		}
+		due, wait := s.scheduler.due(time.Now(), s.pollableAccounts())
+		for _, email := range due {
+			if !s.begin() {
+				return
+			}
+			go s.refreshScheduled(email)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// refreshScheduled refreshes an account the scheduler found due. If it got
+// a result shared with another refresh, its schedule is released here, no
+// earlier than the end of the cooldown.
+func (s *Service) refreshScheduled(email string) {
+	defer s.workers.Done()
+	s.refreshLimited(email)
+	if s.ctx.Err() == nil {
+		s.scheduler.release(email, s.cooldownEnd(email))
+		s.scheduler.notify()
 	}
 }
 
@@ -335,9 +809,16 @@ func (s *Service) sendEvent(event Event) {
 	}
 }
 
-// Close stops the service and cleans up resources.
+// Close stops the service: it cancels in-flight requests and returns once
+// every polling and refresh goroutine, and every RefreshQuota and
+// RefreshAllQuotas call, has returned.
 func (s *Service) Close() error {
-	close(s.stopChan)
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.cancel()
+	s.workers.Wait()
 	return nil
 }
 
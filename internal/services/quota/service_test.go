@@ -2,6 +2,7 @@ package quota
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -23,6 +24,14 @@ func (m *MockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return m.RoundTripFunc(req)
 }
 
+// testConfig returns the default configuration with dummy OAuth credentials.
+func testConfig() Config {
+	config := DefaultConfig()
+	config.ClientID = "test-client-id"
+	config.ClientSecret = "test-client-secret"
+	return config
+}
+
 // MockAccountProvider implements AccountProvider for testing
 type MockAccountProvider struct {
 	Accounts map[string]*models.Account
@@ -88,7 +97,7 @@ func TestService_GetAccessToken(t *testing.T) {
 		},
 	}
 
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	svc.httpClient = &http.Client{Transport: mockTransport}
 
 	// Test 1: Fetch new token
@@ -179,7 +188,7 @@ func TestService_RefreshQuota(t *testing.T) {
 		},
 	}
 
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	svc.httpClient = &http.Client{Transport: mockTransport}
 
 	quota, err := svc.RefreshQuota(email)
@@ -221,7 +230,7 @@ func TestService_RefreshAllQuotas(t *testing.T) {
 		},
 	}
 
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	svc.httpClient = &http.Client{Transport: mockTransport}
 
 	// This is async inside but we wait
@@ -235,7 +244,7 @@ func TestService_RefreshAllQuotas(t *testing.T) {
 
 func TestService_Events(t *testing.T) {
 	provider := NewMockAccountProvider()
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 
 	ch := svc.Events()
 	if ch == nil {
@@ -248,7 +257,7 @@ func TestService_Start(t *testing.T) {
 	// Use small interval to trigger poll
 	config := DefaultConfig()
 	config.PollInterval = 10 * time.Millisecond
-	svc := New(provider, config)
+	svc := New(t.Context(), provider, config)
 
 	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
 		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
@@ -266,7 +275,7 @@ func TestService_Start(t *testing.T) {
 
 func TestService_Getters(t *testing.T) {
 	provider := NewMockAccountProvider()
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	email := "test@example.com"
 
 	// Pre-populate cache
@@ -297,7 +306,7 @@ func TestService_HandleQuotaError(t *testing.T) {
 	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
 
 	// Mock transport that fails everything
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
 		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
 			return nil, errors.New("network error")
@@ -325,7 +334,7 @@ func TestService_CheckEmailUpdate(t *testing.T) {
 	newEmail := "new@example.com"
 	provider.Accounts[oldEmail] = &models.Account{Email: oldEmail, RefreshToken: "rt"}
 
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
 		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
 			if strings.Contains(req.URL.String(), "userinfo") {
@@ -363,7 +372,7 @@ func TestService_CheckEmailUpdate(t *testing.T) {
 
 func TestService_SendEvent_Full(t *testing.T) {
 	provider := NewMockAccountProvider()
-	svc := New(provider, DefaultConfig())
+	svc := New(t.Context(), provider, testConfig())
 	// eventChan size is 100 in New. We can't change it easily without exposing it or using reflection.
 	// But we can fill it.
 
@@ -381,8 +390,183 @@ func TestService_SendEvent_Full(t *testing.T) {
 }
 
 func TestService_Close(t *testing.T) {
-	svc := New(NewMockAccountProvider(), DefaultConfig())
+	svc := New(t.Context(), NewMockAccountProvider(), DefaultConfig())
 	if err := svc.Close(); err != nil {
 		t.Errorf("Close failed: %v", err)
 	}
 }
+
+func TestService_CloseCancelsInFlight(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "slow@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+
+	started := make(chan struct{})
+	svc := New(t.Context(), provider, testConfig())
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			close(started)
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		},
+	}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.RefreshQuota(email)
+		done <- err
+	}()
+	<-started
+
+	closed := make(chan struct{})
+	go func() {
+		_ = svc.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not cancel the in-flight token request")
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("RefreshQuota() error = %v, want context.Canceled", err)
+		}
+	default:
+		t.Fatal("Close() returned before RefreshQuota()")
+	}
+	if qi := svc.GetQuota(email); qi != nil {
+		t.Errorf("a cancelled refresh should not degrade the account, got %+v", qi)
+	}
+	if svc.GetStats().CachedTokens != 0 || svc.TokenRefreshFailures()[email] != 0 {
+		t.Error("a cancelled token refresh should not count as a failure")
+	}
+	if _, err := svc.RefreshQuota(email); !errors.Is(err, ErrClosed) {
+		t.Errorf("RefreshQuota() after Close() error = %v, want ErrClosed", err)
+	}
+}
+
+func TestService_RefreshQuota_Coalesces(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "busy@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	release := make(chan struct{})
+	svc := New(t.Context(), provider, testConfig())
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			requests[req.URL.Path]++
+			mu.Unlock()
+			<-release
+
+			var body []byte
+			switch {
+			case req.URL.String() == googleOAuthURL:
+				body, _ = json.Marshal(TokenResponse{AccessToken: "at", ExpiresIn: 3600})
+			case strings.Contains(req.URL.String(), "userinfo"):
+				body, _ = json.Marshal(UserInfo{Email: email})
+			default:
+				body = []byte(`{"models":{"claude-sonnet":{"quotaInfo":{"remainingFraction":0.5}}}}`)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}}
+
+	const callers = 5
+	results := make(chan *models.QuotaInfo, callers)
+	for range callers {
+		go func() {
+			qi, err := svc.RefreshQuota(email)
+			if err != nil {
+				t.Errorf("RefreshQuota() error = %v", err)
+			}
+			results <- qi
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	first := <-results
+	for range callers - 1 {
+		if qi := <-results; qi != first {
+			t.Errorf("RefreshQuota() = %p, want the shared result %p", qi, first)
+		}
+	}
+	for path, n := range requests {
+		if n != 1 {
+			t.Errorf("%d requests to %s, want 1", n, path)
+		}
+	}
+
+	var updates int
+	for len(svc.Events()) > 0 {
+		if ev := <-svc.Events(); ev.Type == EventQuotaUpdated {
+			updates++
+		}
+	}
+	if updates != 1 {
+		t.Errorf("got %d quota updates, want 1", updates)
+	}
+
+	// Within the cooldown the last result is reused; after it, the account
+	// is fetched again.
+	if qi, _ := svc.RefreshQuota(email); qi != first || requests["/v1internal:fetchAvailableModels"] != 1 {
+		t.Error("a refresh within the cooldown should reuse the last result")
+	}
+	svc.mu.Lock()
+	last := svc.lastRefresh[email]
+	last.at = last.at.Add(-defaultRefreshCooldown)
+	svc.lastRefresh[email] = last
+	svc.mu.Unlock()
+	if qi, _ := svc.RefreshQuota(email); qi == first {
+		t.Error("a refresh after the cooldown should fetch again")
+	}
+}
+
+func TestService_UsesConfiguredEndpoints(t *testing.T) {
+	provider := NewMockAccountProvider()
+	email := "mock@example.com"
+	provider.Accounts[email] = &models.Account{Email: email, RefreshToken: "rt"}
+
+	config := testConfig()
+	config.Endpoints = Endpoints{
+		TokenURL:    "http://mock/token",
+		UserInfoURL: "http://mock/userinfo",
+		Quota:       []string{"http://mock/quota"},
+	}
+	svc := New(t.Context(), provider, config)
+	var (
+		urls []string
+		mu   sync.Mutex
+	)
+	svc.httpClient = &http.Client{Transport: &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			urls = append(urls, req.URL.String())
+			mu.Unlock()
+			body := `{"access_token":"at","expires_in":3600,"models":{}}`
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}}
+
+	if _, err := svc.RefreshQuota(email); err != nil {
+		t.Fatalf("RefreshQuota() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"http://mock/token", "http://mock/userinfo", "http://mock/quota/v1internal:fetchAvailableModels"}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("requests went to %v, want %v", urls, want)
+	}
+	if got := svc.config.Endpoints; got.Headers["User-Agent"] != antigravityHeaders["User-Agent"] {
+		t.Errorf("config.Endpoints.Headers = %v, want the default headers", got.Headers)
+	}
+	if health := svc.FetchHealth(); len(health) != 1 || health[0].Endpoint != "http://mock/quota" || health[0].Requests != 1 {
+		t.Errorf("FetchHealth() = %+v, want the configured endpoint", health)
+	}
+}
@@ -0,0 +1,117 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+// WriteTable writes the report as a human-readable table.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACCOUNT\tTIER\tMODEL\tREMAINING\tRESETS IN\tSTATE")
+
+	for _, acc := range r.Accounts {
+		email := acc.Email
+		if acc.Active {
+			email += " *"
+		}
+		state := accountState(&acc)
+
+		if len(acc.Models) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t%s\n", email, dash(acc.Tier), state)
+			continue
+		}
+		tier := dash(acc.Tier)
+		for i, m := range acc.Models {
+			if i > 0 {
+				email, tier, state = "", "", ""
+			}
+			modelState := state
+			if m.RateLimited {
+				modelState = strings.TrimPrefix(modelState+", rate limited", ", ")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%5.1f%%\t%s\t%s\n",
+				email, tier, m.Name, m.RemainingPercent, resetsIn(m.ResetTime), modelState)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write status table: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	return nil
+}
+
+// WriteTemplate executes a Go text/template with the report as its data.
+// Besides the built-ins, templates can use "json", "percent", "resetsIn",
+// "join", "upper" and "lower".
+func (r *Report) WriteTemplate(w io.Writer, text string) error {
+	tmpl, err := template.New("status").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid format template: %w", err)
+	}
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to execute format template: %w", err)
+	}
+	return nil
+}
+
+// TemplateFuncs returns the functions available to format templates.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"percent":  func(p float64) string { return fmt.Sprintf("%.0f%%", p) },
+		"resetsIn": resetsIn,
+		"join":     strings.Join,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+	}
+}
+
+// accountState summarises why an account's data may not be live.
+func accountState(acc *Account) string {
+	switch {
+	case acc.Degraded != "":
+		return acc.Degraded
+	case acc.Error != "":
+		return "error"
+	case len(acc.Models) == 0:
+		return "no data"
+	case acc.Cached:
+		return "cached"
+	}
+	return ""
+}
+
+func resetsIn(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return quota.FormatResetTime(*t)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
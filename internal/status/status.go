@@ -0,0 +1,303 @@
+// Package status builds one-shot quota reports for command-line output.
+package status
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/daemon"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
+)
+
+// Report sources.
+const (
+	SourceLive   = "live"
+	SourceCached = "cached"
+	SourceDaemon = "daemon"
+)
+
+// daemonStaleFactor is how many poll intervals a running daemon's data may
+// age before it is reported as cached, as on a dashboard following it.
+const daemonStaleFactor = 3
+
+// Report is the quota of every account at one point in time.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Source      string    `json:"source"`
+	Accounts    []Account `json:"accounts"`
+}
+
+// Account is the quota of one account.
+type Account struct {
+	LastUpdated      *time.Time `json:"lastUpdated,omitempty"`
+	Email            string     `json:"email"`
+	Tier             string     `json:"tier,omitempty"`
+	Degraded         string     `json:"degraded,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	Models           []Model    `json:"models"`
+	RemainingPercent float64    `json:"remainingPercent"`
+	Active           bool       `json:"active"`
+	Cached           bool       `json:"cached"`
+}
+
+// Model is the quota of one model.
+type Model struct {
+	ResetTime        *time.Time `json:"resetTime,omitempty"`
+	ID               string     `json:"id,omitempty"`
+	Name             string     `json:"name"`
+	Family           string     `json:"family"`
+	RemainingPercent float64    `json:"remainingPercent"`
+	RateLimited      bool       `json:"rateLimited"`
+}
+
+// Live refreshes every account once and returns the result. Accounts that
+// cannot be refreshed, or are not refreshed before ctx is done, report their
+// last-known quota from the database. While a daemon holds the PID lock it
+// already polls every account, so its database is read instead of polling
+// alongside it.
+func Live(ctx context.Context, cfg *config.Config) (*Report, error) {
+	if _, running := daemon.Running(cfg.DaemonPIDPath); running {
+		return fromDaemon(cfg)
+	}
+
+	accs, database, err := open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = accs.Close()
+		_ = database.Close()
+	}()
+
+	quotaConfig := quota.DefaultConfig()
+	quotaConfig.ClientID = cfg.GoogleClientID
+	quotaConfig.ClientSecret = cfg.GoogleClientSecret
+	quotaConfig.CredentialsFunc = config.LoadCredentials
+	quotaConfig.Endpoints = quota.Endpoints{
+		Headers:     cfg.QuotaHeaders,
+		TokenURL:    cfg.GoogleTokenURL,
+		UserInfoURL: cfg.GoogleUserInfoURL,
+		Quota:       cfg.QuotaEndpoints,
+	}
+
+	svc := quota.New(ctx, accs, quotaConfig)
+	defer func() { _ = svc.Close() }()
+
+	stored, err := storedQuotas(database)
+	if err != nil {
+		return nil, err
+	}
+	svc.SeedQuotas(stored)
+	svc.RefreshAllQuotas()
+
+	return NewReport(accs.GetAccounts(), activeEmail(accs), svc.GetAllQuotas(), SourceLive, time.Now()), nil
+}
+
+// Cached returns the last-known quota of every account from the database
+// without contacting the API.
+func Cached(cfg *config.Config) (*Report, error) {
+	accs, database, err := open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = accs.Close()
+		_ = database.Close()
+	}()
+
+	stored, err := storedQuotas(database)
+	if err != nil {
+		return nil, err
+	}
+	return NewReport(accs.GetAccounts(), activeEmail(accs), stored, SourceCached, time.Now()), nil
+}
+
+// fromDaemon reports what a running daemon last stored. Accounts it has not
+// updated within a few poll intervals are marked as cached.
+func fromDaemon(cfg *config.Config) (*Report, error) {
+	accs, database, err := open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = accs.Close()
+		_ = database.Close()
+	}()
+
+	stored, err := storedQuotas(database)
+	if err != nil {
+		return nil, err
+	}
+	staleAfter := time.Duration(daemonStaleFactor) * cfg.QuotaRefreshInterval
+	for _, qi := range stored {
+		qi.Cached = staleAfter > 0 && time.Since(qi.LastUpdated) > staleAfter
+	}
+	return NewReport(accs.GetAccounts(), activeEmail(accs), stored, SourceDaemon, time.Now()), nil
+}
+
+func open(cfg *config.Config) (*accounts.Service, *db.DB, error) {
+	accs, err := accounts.New(cfg.AccountsPath, accounts.ReadOnly(cfg.ReadOnlyAccounts))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	database, err := db.New(cfg.DatabasePath)
+	if err != nil {
+		_ = accs.Close()
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return accs, database, nil
+}
+
+func storedQuotas(database *db.DB) (map[string]*models.QuotaInfo, error) {
+	statuses, err := database.GetAllAccountStatuses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account statuses: %w", err)
+	}
+	stored := make(map[string]*models.QuotaInfo, len(statuses))
+	for i := range statuses {
+		if qi := statuses[i].ToQuotaInfo(); qi != nil {
+			stored[statuses[i].Email] = qi
+		}
+	}
+	return stored, nil
+}
+
+func activeEmail(accs *accounts.Service) string {
+	if active := accs.GetActiveAccount(); active != nil {
+		return active.Email
+	}
+	return ""
+}
+
+// NewReport builds a report for accs from their quotas. Accounts without
+// quota data are included with no models.
+func NewReport(accs []models.Account, active string, quotas map[string]*models.QuotaInfo, source string, now time.Time) *Report {
+	r := &Report{
+		GeneratedAt: now,
+		Source:      source,
+		Accounts:    make([]Account, 0, len(accs)),
+	}
+
+	for i := range accs {
+		out := Account{
+			Email:  accs[i].Email,
+			Active: accs[i].Email == active,
+			Models: []Model{},
+		}
+		if qi := quotas[accs[i].Email]; qi != nil {
+			out.Tier = qi.SubscriptionTier
+			out.Degraded = string(qi.Degraded)
+			out.Error = qi.Error
+			out.Cached = qi.IsStale() || source == SourceCached
+			out.RemainingPercent = qi.RemainingPercent()
+			out.LastUpdated = models.OptionalTime(qi.LastUpdated)
+			for j := range qi.ModelQuotas {
+				mq := &qi.ModelQuotas[j]
+				out.Models = append(out.Models, Model{
+					ID:               mq.ModelID,
+					Name:             mq.Name(),
+					Family:           mq.ModelFamily,
+					RemainingPercent: mq.RemainingPercent(),
+					ResetTime:        models.OptionalTime(mq.ResetTime),
+					RateLimited:      mq.IsRateLimited,
+				})
+			}
+			slices.SortFunc(out.Models, func(a, b Model) int {
+				if c := strings.Compare(a.Family, b.Family); c != 0 {
+					return c
+				}
+				return strings.Compare(a.Name, b.Name)
+			})
+		}
+		r.Accounts = append(r.Accounts, out)
+	}
+
+	slices.SortFunc(r.Accounts, func(a, b Account) int {
+		return strings.Compare(a.Email, b.Email)
+	})
+	return r
+}
+
+// Filter keeps the accounts whose email contains one of accountTerms and the
+// models whose ID, name or family contains one of modelTerms. Accounts left
+// without a matching model are dropped, unless they had no quota data to
+// begin with. Matching is case-insensitive; empty term lists keep everything.
+func (r *Report) Filter(accountTerms, modelTerms []string) {
+	kept := r.Accounts[:0]
+	for _, acc := range r.Accounts {
+		if !matches(accountTerms, acc.Email) {
+			continue
+		}
+		models := acc.Models[:0]
+		for _, m := range acc.Models {
+			if matches(modelTerms, m.ID, m.Name, m.Family) {
+				models = append(models, m)
+			}
+		}
+		if len(models) == 0 && len(acc.Models) > 0 {
+			continue
+		}
+		acc.Models = models
+		kept = append(kept, acc)
+	}
+	r.Accounts = kept
+}
+
+func matches(terms []string, values ...string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		for _, v := range values {
+			if strings.Contains(strings.ToLower(v), term) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Violation is an account or model below the required remaining quota.
+type Violation struct {
+	Email            string
+	Model            string
+	RemainingPercent float64
+}
+
+func (v Violation) String() string {
+	if v.Model == "" {
+		return fmt.Sprintf("%s: no quota data", v.Email)
+	}
+	return fmt.Sprintf("%s: %s at %.1f%%", v.Email, v.Model, v.RemainingPercent)
+}
+
+// Check returns every selected model with less than minRemaining percent
+// left. Accounts without any quota data fail the check, since their quota
+// is unknown.
+func (r *Report) Check(minRemaining float64) []Violation {
+	var violations []Violation
+	for _, acc := range r.Accounts {
+		if len(acc.Models) == 0 {
+			violations = append(violations, Violation{Email: acc.Email})
+			continue
+		}
+		for _, m := range acc.Models {
+			if m.RemainingPercent < minRemaining || m.RateLimited {
+				violations = append(violations, Violation{
+					Email:            acc.Email,
+					Model:            m.Name,
+					RemainingPercent: m.RemainingPercent,
+				})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,201 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/daemon"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func testReport() *Report {
+	now := time.Now()
+	accs := []models.Account{
+		{Email: "b@example.com"},
+		{Email: "a@example.com"},
+		{Email: "c@example.com"},
+	}
+	quotas := map[string]*models.QuotaInfo{
+		"a@example.com": {
+			AccountEmail:     "a@example.com",
+			SubscriptionTier: "PRO",
+			LastUpdated:      now,
+			TotalRemaining:   85,
+			TotalLimit:       200,
+			ModelQuotas: []models.ModelQuota{
+				{ModelID: "gemini-3-pro", DisplayName: "Gemini 3 Pro", ModelFamily: "gemini", Limit: 100, Remaining: 80, ResetTime: now.Add(2 * time.Hour)},
+				{ModelID: "claude-sonnet", DisplayName: "Claude Sonnet", ModelFamily: "claude", Limit: 100, Remaining: 5},
+			},
+		},
+		"b@example.com": {
+			AccountEmail: "b@example.com",
+			Degraded:     models.DegradedNetwork,
+			ModelQuotas: []models.ModelQuota{
+				{ModelID: "claude-sonnet", DisplayName: "Claude Sonnet", ModelFamily: "claude", Limit: 100, Remaining: 50, IsRateLimited: true},
+			},
+		},
+	}
+	return NewReport(accs, "a@example.com", quotas, SourceLive, now)
+}
+
+func TestNewReport(t *testing.T) {
+	r := testReport()
+
+	if len(r.Accounts) != 3 {
+		t.Fatalf("got %d accounts, want 3", len(r.Accounts))
+	}
+	a, b, c := r.Accounts[0], r.Accounts[1], r.Accounts[2]
+	if a.Email != "a@example.com" || !a.Active || a.Tier != "PRO" || a.Cached {
+		t.Errorf("account a = %+v", a)
+	}
+	if len(a.Models) != 2 || a.Models[0].Family != "claude" || a.Models[1].ResetTime == nil {
+		t.Errorf("models of a not sorted by family or missing reset: %+v", a.Models)
+	}
+	if !b.Cached || b.Degraded != string(models.DegradedNetwork) {
+		t.Errorf("degraded account b = %+v", b)
+	}
+	if len(c.Models) != 0 || c.Models == nil {
+		t.Errorf("account without data should have an empty model list, got %#v", c.Models)
+	}
+}
+
+func TestReport_Filter(t *testing.T) {
+	r := testReport()
+	r.Filter(nil, []string{"GEMINI"})
+
+	// b has no gemini model and is dropped; c has no data and stays.
+	if len(r.Accounts) != 2 || r.Accounts[0].Email != "a@example.com" || r.Accounts[1].Email != "c@example.com" {
+		t.Fatalf("Filter() kept %+v", r.Accounts)
+	}
+	if len(r.Accounts[0].Models) != 1 || r.Accounts[0].Models[0].ID != "gemini-3-pro" {
+		t.Errorf("Filter() models = %+v", r.Accounts[0].Models)
+	}
+
+	r = testReport()
+	r.Filter([]string{"b@"}, nil)
+	if len(r.Accounts) != 1 || r.Accounts[0].Email != "b@example.com" {
+		t.Errorf("account filter kept %+v", r.Accounts)
+	}
+}
+
+func TestReport_Check(t *testing.T) {
+	violations := testReport().Check(10)
+
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.String())
+	}
+	want := []string{
+		"a@example.com: Claude Sonnet at 5.0%",
+		"b@example.com: Claude Sonnet at 0.0%",
+		"c@example.com: no quota data",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+
+	r := testReport()
+	r.Filter([]string{"a@"}, []string{"gemini"})
+	if v := r.Check(50); len(v) != 0 {
+		t.Errorf("Check(50) on gemini = %v, want none", v)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	r := testReport()
+
+	var table bytes.Buffer
+	if err := r.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ACCOUNT", "a@example.com *", "Claude Sonnet", "5.0%", "network unreachable, rate limited", "no data"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, table.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := r.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Accounts) != 3 {
+		t.Errorf("WriteJSON() round trip = %+v, %v", decoded, err)
+	}
+
+	out.Reset()
+	tmpl := `{{range .Accounts}}{{if .Active}}{{.Email}} {{percent .RemainingPercent}}{{end}}{{end}}`
+	if err := r.WriteTemplate(&out, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "a@example.com 42%" {
+		t.Errorf("WriteTemplate() = %q", out.String())
+	}
+
+	if err := r.WriteTemplate(&out, "{{.Missing"); err == nil {
+		t.Error("WriteTemplate() accepted an invalid template")
+	}
+}
+
+func TestLive_ReadsRunningDaemon(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		DatabasePath:         filepath.Join(tmpDir, "test.db"),
+		AccountsPath:         filepath.Join(tmpDir, "accounts.json"),
+		DaemonPIDPath:        filepath.Join(tmpDir, "adt-daemon.pid"),
+		QuotaRefreshInterval: time.Minute,
+	}
+	accs := `{"accounts":[{"email":"a@example.com","refreshToken":"rt"},{"email":"b@example.com","refreshToken":"rt"}],"activeAccount":"a@example.com"}`
+	if err := os.WriteFile(cfg.AccountsPath, []byte(accs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := db.New(cfg.DatabasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for email, updated := range map[string]time.Time{
+		"a@example.com": time.Now(),
+		"b@example.com": time.Now().Add(-time.Hour),
+	} {
+		status := (&models.QuotaInfo{
+			AccountEmail: email,
+			LastUpdated:  updated,
+			ModelQuotas:  []models.ModelQuota{{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 60}},
+		}).ToAccountStatus()
+		if err := database.UpsertAccountStatus(&status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = database.Close()
+
+	pid, err := daemon.Acquire(cfg.DaemonPIDPath)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer func() { _ = pid.Release() }()
+
+	// No credentials are configured, so a report with data can only come
+	// from the daemon's database.
+	r, err := Live(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("Live failed: %v", err)
+	}
+	if r.Source != SourceDaemon || len(r.Accounts) != 2 {
+		t.Fatalf("report = %+v, want both accounts from the daemon", r)
+	}
+	a, b := r.Accounts[0], r.Accounts[1]
+	if a.Cached || a.Degraded != "" || len(a.Models) != 1 || a.Models[0].RemainingPercent != 60 {
+		t.Errorf("fresh account a = %+v", a)
+	}
+	if !b.Cached {
+		t.Errorf("account b not updated for an hour should be cached: %+v", b)
+	}
+}
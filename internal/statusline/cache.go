@@ -0,0 +1,93 @@
+package statusline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/logger"
+)
+
+// cacheEntry is the content of the status line cache file.
+type cacheEntry struct {
+	CachedAt time.Time `json:"cachedAt"`
+	Data     *Data     `json:"data"`
+	Account  string    `json:"account"`
+}
+
+// LoadCached returns the data cached at cachePath while it is younger than
+// maxAge and the database has not been written since. Otherwise it calls
+// Load and refreshes the cache. Reset countdowns are derived from reset
+// times when rendering, so cached data does not go stale between writes.
+func LoadCached(cfg *config.Config, account, cachePath string, maxAge time.Duration) (*Data, error) {
+	now := time.Now()
+	if entry := readCache(cachePath); entry != nil &&
+		entry.Account == account &&
+		now.Sub(entry.CachedAt) < maxAge &&
+		!databaseModifiedSince(cfg.DatabasePath, entry.CachedAt) {
+		return entry.Data, nil
+	}
+
+	data, err := Load(cfg, account)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCache(cachePath, &cacheEntry{CachedAt: now, Account: account, Data: data}); err != nil {
+		logger.Debug("failed to write statusline cache", "path", cachePath, "error", err)
+	}
+	return data, nil
+}
+
+func readCache(path string) *cacheEntry {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Data == nil {
+		return nil
+	}
+	return &entry
+}
+
+// writeCache replaces the cache file atomically so concurrent callers never
+// read a partial file.
+func writeCache(path string, entry *cacheEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode statusline cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create statusline cache: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write statusline cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write statusline cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace statusline cache: %w", err)
+	}
+	return nil
+}
+
+// databaseModifiedSince reports whether the database or its WAL was written
+// after t.
+func databaseModifiedSince(path string, t time.Time) bool {
+	for _, p := range []string{path, path + "-wal"} {
+		if info, err := os.Stat(p); err == nil && info.ModTime().After(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package statusline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Outputs supported by Render.
+const (
+	OutputPlain    = "plain"
+	OutputANSI     = "ansi"
+	OutputTmux     = "tmux"
+	OutputI3blocks = "i3blocks"
+	OutputWaybar   = "waybar"
+)
+
+// Outputs lists the supported output names.
+var Outputs = []string{OutputPlain, OutputANSI, OutputTmux, OutputI3blocks, OutputWaybar}
+
+// DefaultTemplate shows the lowest Claude quota, the time to its reset and a
+// warning glyph when a projection is CRITICAL.
+const DefaultTemplate = `{{if .Critical}}⚠ {{end}}C {{percent .Claude.Percent}} ↻{{resetsIn .Claude.ResetTime}}`
+
+// palette holds the xterm-256 colour of each level, matching the TUI styles,
+// and its hex value for bars that take RGB colours.
+var palette = map[string]struct {
+	xterm string
+	hex   string
+}{
+	LevelOK:       {"42", "#00d787"},
+	LevelWarning:  {"220", "#ffd700"},
+	LevelCritical: {"196", "#ff0000"},
+	LevelUnknown:  {"240", "#585858"},
+}
+
+// Render writes the status line for d in the given output format. The text
+// comes from executing tmplText, or DefaultTemplate when it is empty.
+func Render(w io.Writer, d *Data, output, tmplText string, now time.Time) error {
+	if tmplText == "" {
+		tmplText = DefaultTemplate
+	}
+	tmpl, err := template.New("statusline").Funcs(templateFuncs(now)).Parse(tmplText)
+	if err != nil {
+		return fmt.Errorf("invalid statusline template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if d.NoData {
+		buf.WriteString("adt: no data")
+	} else if err := tmpl.Execute(&buf, d); err != nil {
+		return fmt.Errorf("failed to execute statusline template: %w", err)
+	}
+	text := strings.TrimSpace(buf.String())
+	colour := palette[d.Level()]
+
+	switch output {
+	case OutputPlain, "":
+		_, err = fmt.Fprintln(w, text)
+	case OutputANSI:
+		_, err = fmt.Fprintf(w, "\x1b[38;5;%sm%s\x1b[0m\n", colour.xterm, text)
+	case OutputTmux:
+		_, err = fmt.Fprintf(w, "#[fg=colour%s]%s#[default]\n", colour.xterm, strings.ReplaceAll(text, "#", "##"))
+	case OutputI3blocks:
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"full_text":  text,
+			"short_text": shortText(d),
+			"color":      colour.hex,
+		})
+	case OutputWaybar:
+		err = json.NewEncoder(w).Encode(map[string]any{
+			"text":       text,
+			"tooltip":    tooltip(d, now),
+			"class":      d.Level(),
+			"percentage": int(max(d.Claude.Percent, 0)),
+		})
+	default:
+		return fmt.Errorf("unknown output %q (want %s)", output, strings.Join(Outputs, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write statusline: %w", err)
+	}
+	return nil
+}
+
+// templateFuncs returns the functions available to statusline templates.
+func templateFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"percent":  formatPercent,
+		"resetsIn": func(t time.Time) string { return formatDuration(t.Sub(now), !t.IsZero()) },
+		"hours": func(h float64) string {
+			return formatDuration(time.Duration(h*float64(time.Hour)), h >= 0)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// formatPercent formats a remaining percentage; negative means no models.
+func formatPercent(p float64) string {
+	if p < 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%.0f%%", p)
+}
+
+// formatDuration formats d compactly as "45m", "2h13m" or "3d4h".
+func formatDuration(d time.Duration, known bool) string {
+	switch {
+	case !known:
+		return "--"
+	case d <= 0:
+		return "now"
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+func shortText(d *Data) string {
+	if d.NoData {
+		return "adt: --"
+	}
+	return "C " + formatPercent(d.Claude.Percent)
+}
+
+// tooltip describes every family for bars that show a tooltip on hover.
+func tooltip(d *Data, now time.Time) string {
+	if d.NoData {
+		if d.Email == "" {
+			return "No accounts configured"
+		}
+		return d.Email + ": no quota data yet"
+	}
+
+	var b strings.Builder
+	b.WriteString(d.Email)
+	if d.Tier != "" {
+		fmt.Fprintf(&b, " (%s)", d.Tier)
+	}
+	for _, f := range []struct {
+		name string
+		fam  Family
+	}{{"Claude", d.Claude}, {"Gemini", d.Gemini}} {
+		if f.fam.Percent < 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s %s · resets in %s · %s",
+			f.name, formatPercent(f.fam.Percent), formatDuration(f.fam.ResetTime.Sub(now), !f.fam.ResetTime.IsZero()), f.fam.Status)
+		if f.fam.HoursLeft >= 0 {
+			fmt.Fprintf(&b, " · %s left", formatDuration(time.Duration(f.fam.HoursLeft*float64(time.Hour)), true))
+		}
+	}
+	if d.RateLimited {
+		b.WriteString("\nRate limited")
+	}
+	if d.Stale() {
+		fmt.Fprintf(&b, "\nLast-known data (%s)", d.Degraded)
+	}
+	if !d.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "\nUpdated %s", d.UpdatedAt.Local().Format("15:04"))
+	}
+	return b.String()
+}
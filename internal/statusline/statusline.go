@@ -0,0 +1,200 @@
+// Package statusline renders a compact quota summary for tmux, shell
+// prompts and status bars from the state stored in the database.
+package statusline
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/accounts"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/projection"
+)
+
+// Levels summarise how urgent the line is; outputs colour by level.
+const (
+	LevelOK       = "ok"
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+	LevelUnknown  = "unknown"
+)
+
+// Data is the state shown on the status line for one account.
+type Data struct {
+	UpdatedAt   time.Time               `json:"updatedAt"`
+	Email       string                  `json:"email"`
+	Tier        string                  `json:"tier,omitempty"`
+	Degraded    string                  `json:"degraded,omitempty"`
+	Status      models.ProjectionStatus `json:"status"`
+	Claude      Family                  `json:"claude"`
+	Gemini      Family                  `json:"gemini"`
+	RateLimited bool                    `json:"rateLimited"`
+	NoData      bool                    `json:"noData"`
+}
+
+// Family summarises a model family.
+type Family struct {
+	ResetTime time.Time               `json:"resetTime"`
+	Status    models.ProjectionStatus `json:"status"`
+	// Percent is the lowest remaining percent across the family's models,
+	// or -1 when the account has none.
+	Percent float64 `json:"percent"`
+	// HoursLeft is the projected time to depletion, or -1 when no
+	// consumption has been observed.
+	HoursLeft float64 `json:"hoursLeft"`
+}
+
+// Critical reports whether any projection of the account is CRITICAL.
+func (d *Data) Critical() bool {
+	return d.Status == models.ProjectionCritical
+}
+
+// Warning reports whether the worst projection of the account is WARNING.
+func (d *Data) Warning() bool {
+	return d.Status == models.ProjectionWarning
+}
+
+// Stale reports whether the stored data is last-known rather than live.
+func (d *Data) Stale() bool {
+	return d.Degraded != ""
+}
+
+// Level returns the urgency used to colour the line.
+func (d *Data) Level() string {
+	switch {
+	case d.NoData:
+		return LevelUnknown
+	case d.Critical() || d.RateLimited:
+		return LevelCritical
+	case d.Warning() || d.Stale():
+		return LevelWarning
+	}
+	return LevelOK
+}
+
+// Load reads the account's latest status from the database and projects it.
+// account selects the first account whose email contains it; when empty the
+// active account is used.
+func Load(cfg *config.Config, account string) (*Data, error) {
+	accs, err := accounts.New(cfg.AccountsPath, accounts.ReadOnly(cfg.ReadOnlyAccounts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	email := selectAccount(accs.GetAccounts(), accs.GetActiveAccount(), account)
+	_ = accs.Close()
+	if email == "" {
+		if account != "" {
+			return nil, fmt.Errorf("%w: %s", accounts.ErrAccountNotFound, account)
+		}
+		return &Data{NoData: true, Status: models.ProjectionUnknown}, nil
+	}
+
+	database, err := db.New(cfg.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	status, err := database.GetAccountStatus(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account status: %w", err)
+	}
+	var qi *models.QuotaInfo
+	if status != nil {
+		qi = status.ToQuotaInfo()
+	}
+	if qi == nil || len(qi.ModelQuotas) == 0 {
+		return &Data{Email: email, NoData: true, Status: models.ProjectionUnknown}, nil
+	}
+
+	proj := projection.New(database)
+	proj.FollowSession(email)
+	ap, err := proj.ProjectStored(email, qi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to project quota: %w", err)
+	}
+
+	return newData(qi, ap), nil
+}
+
+// selectAccount returns the email of the account to show.
+func selectAccount(accs []models.Account, active *models.Account, term string) string {
+	if term != "" {
+		term = strings.ToLower(term)
+		for i := range accs {
+			if strings.Contains(strings.ToLower(accs[i].Email), term) {
+				return accs[i].Email
+			}
+		}
+		return ""
+	}
+	if active != nil {
+		return active.Email
+	}
+	if len(accs) > 0 {
+		return accs[0].Email
+	}
+	return ""
+}
+
+// newData builds the status line state from stored quota and its projection.
+func newData(qi *models.QuotaInfo, ap *models.AccountProjection) *Data {
+	d := &Data{
+		Email:     qi.AccountEmail,
+		Tier:      qi.SubscriptionTier,
+		Degraded:  string(qi.Degraded),
+		UpdatedAt: qi.LastUpdated,
+		Status:    models.ProjectionUnknown,
+		Claude:    newFamily(qi, "claude"),
+		Gemini:    newFamily(qi, "gemini"),
+	}
+	if d.Degraded == "" && qi.Error != "" {
+		d.Degraded = "error"
+	}
+	for i := range qi.ModelQuotas {
+		if qi.ModelQuotas[i].IsRateLimited {
+			d.RateLimited = true
+		}
+	}
+
+	if ap == nil {
+		return d
+	}
+	applyProjection(&d.Claude, ap.Claude)
+	applyProjection(&d.Gemini, ap.Gemini)
+
+	statuses := []models.ProjectionStatus{d.Claude.Status, d.Gemini.Status}
+	for _, mp := range ap.Models {
+		statuses = append(statuses, mp.Status)
+	}
+	for _, status := range statuses {
+		if status.Severity() > d.Status.Severity() {
+			d.Status = status
+		}
+	}
+	return d
+}
+
+func newFamily(qi *models.QuotaInfo, family string) Family {
+	percent, resetTime := qi.FamilyRollup(family)
+	return Family{
+		Percent:   percent,
+		ResetTime: resetTime,
+		Status:    models.ProjectionUnknown,
+		HoursLeft: -1,
+	}
+}
+
+func applyProjection(f *Family, mp *models.ModelProjection) {
+	if mp == nil {
+		return
+	}
+	f.Status = mp.Status
+	if !math.IsInf(mp.SessionHoursLeft, 0) && !math.IsNaN(mp.SessionHoursLeft) {
+		f.HoursLeft = mp.SessionHoursLeft
+	}
+}
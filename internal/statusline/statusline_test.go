@@ -0,0 +1,212 @@
+package statusline
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/db"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// newTestConfig writes an accounts file and stores a quota for the active
+// account.
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		DatabasePath: filepath.Join(tmpDir, "test.db"),
+		AccountsPath: filepath.Join(tmpDir, "accounts.json"),
+	}
+	accs := `{"accounts":[{"email":"a@example.com","refreshToken":"rt"},{"email":"b@example.com","refreshToken":"rt"}],"activeAccount":"b@example.com"}`
+	if err := os.WriteFile(cfg.AccountsPath, []byte(accs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := db.New(cfg.DatabasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	status := (&models.QuotaInfo{
+		AccountEmail:     "b@example.com",
+		SubscriptionTier: "PRO",
+		LastUpdated:      time.Now(),
+		ModelQuotas: []models.ModelQuota{
+			{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 35, ResetTime: time.Now().Add(2*time.Hour + 30*time.Second)},
+			{ModelID: "claude-sonnet", ModelFamily: "claude", Limit: 100, Remaining: 60},
+		},
+	}).ToAccountStatus()
+	if err := database.UpsertAccountStatus(&status); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLoad(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	d, err := Load(cfg, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if d.Email != "b@example.com" || d.NoData || d.Tier != "PRO" {
+		t.Fatalf("Load() = %+v, want the active account", d)
+	}
+	if d.Claude.Percent != 35 {
+		t.Errorf("Claude percent = %v, want the lowest model (35)", d.Claude.Percent)
+	}
+	if d.Gemini.Percent != -1 || d.Gemini.Status != models.ProjectionUnknown {
+		t.Errorf("Gemini = %+v, want no models", d.Gemini)
+	}
+
+	d, err = Load(cfg, "a@")
+	if err != nil || !d.NoData || d.Level() != LevelUnknown {
+		t.Errorf("Load(a@) = %+v, %v; want no data", d, err)
+	}
+
+	if _, err := Load(cfg, "nobody"); err == nil {
+		t.Error("Load(nobody) succeeded, want account not found")
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	cfg := newTestConfig(t)
+	cachePath := filepath.Join(filepath.Dir(cfg.DatabasePath), "statusline.json")
+
+	first, err := LoadCached(cfg, "", cachePath, time.Minute)
+	if err != nil {
+		t.Fatalf("LoadCached() error = %v", err)
+	}
+
+	// Without the accounts file Load finds no account; a hit never reads it.
+	if err := os.Remove(cfg.AccountsPath); err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadCached(cfg, "", cachePath, time.Minute)
+	if err != nil {
+		t.Fatalf("LoadCached() cache hit error = %v", err)
+	}
+	if second.Claude.Percent != first.Claude.Percent || second.Email != first.Email {
+		t.Errorf("cached data = %+v, want %+v", second, first)
+	}
+
+	// A different account selection misses the cache.
+	if _, err := LoadCached(cfg, "b@", cachePath, time.Minute); err == nil {
+		t.Error("LoadCached() with a new selection used the cache")
+	}
+
+	// Writes to the database invalidate the cache.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(cfg.DatabasePath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := LoadCached(cfg, "", cachePath, time.Minute); err != nil || !d.NoData {
+		t.Errorf("LoadCached() after a database write = %+v, %v; want a reload", d, err)
+	}
+}
+
+func TestData_Level(t *testing.T) {
+	tests := []struct {
+		name string
+		data Data
+		want string
+	}{
+		{"no data", Data{NoData: true}, LevelUnknown},
+		{"safe", Data{Status: models.ProjectionSafe}, LevelOK},
+		{"warning", Data{Status: models.ProjectionWarning}, LevelWarning},
+		{"stale", Data{Status: models.ProjectionSafe, Degraded: "network unreachable"}, LevelWarning},
+		{"critical", Data{Status: models.ProjectionCritical}, LevelCritical},
+		{"rate limited", Data{Status: models.ProjectionSafe, RateLimited: true}, LevelCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.Level(); got != tt.want {
+				t.Errorf("Level() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewData_IgnoresMissingFamily(t *testing.T) {
+	qi := &models.QuotaInfo{
+		AccountEmail: "a@example.com",
+		ModelQuotas:  []models.ModelQuota{{ModelID: "claude-opus", ModelFamily: "claude", Limit: 100, Remaining: 80}},
+	}
+	// No Gemini models, so there is no Gemini projection.
+	ap := &models.AccountProjection{
+		Claude: &models.ModelProjection{Status: models.ProjectionSafe, SessionHoursLeft: 3},
+	}
+
+	d := newData(qi, ap)
+	if d.Status != models.ProjectionSafe || d.Critical() {
+		t.Errorf("Status = %s, want SAFE", d.Status)
+	}
+	if d.Claude.HoursLeft != 3 || d.Gemini.HoursLeft != -1 {
+		t.Errorf("hours left = %v/%v, want 3/-1", d.Claude.HoursLeft, d.Gemini.HoursLeft)
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := &Data{
+		Email:  "a@example.com",
+		Status: models.ProjectionCritical,
+		Claude: Family{Percent: 12.4, ResetTime: now.Add(2*time.Hour + 13*time.Minute), Status: models.ProjectionCritical, HoursLeft: 0.5},
+		Gemini: Family{Percent: -1, HoursLeft: -1},
+	}
+
+	render := func(output, tmpl string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Render(&buf, d, output, tmpl, now); err != nil {
+			t.Fatalf("Render(%s) error = %v", output, err)
+		}
+		return buf.String()
+	}
+
+	if got := render(OutputPlain, ""); got != "⚠ C 12% ↻2h13m\n" {
+		t.Errorf("plain = %q", got)
+	}
+	if got := render(OutputANSI, ""); got != "\x1b[38;5;196m⚠ C 12% ↻2h13m\x1b[0m\n" {
+		t.Errorf("ansi = %q", got)
+	}
+	if got := render(OutputTmux, "#{{percent .Gemini.Percent}} {{hours .Claude.HoursLeft}}"); got != "#[fg=colour196]##-- 30m#[default]\n" {
+		t.Errorf("tmux = %q", got)
+	}
+
+	var i3 map[string]string
+	if err := json.Unmarshal([]byte(render(OutputI3blocks, "")), &i3); err != nil {
+		t.Fatal(err)
+	}
+	if i3["color"] != "#ff0000" || i3["short_text"] != "C 12%" {
+		t.Errorf("i3blocks = %v", i3)
+	}
+
+	var waybar struct {
+		Text       string `json:"text"`
+		Tooltip    string `json:"tooltip"`
+		Class      string `json:"class"`
+		Percentage int    `json:"percentage"`
+	}
+	if err := json.Unmarshal([]byte(render(OutputWaybar, "")), &waybar); err != nil {
+		t.Fatal(err)
+	}
+	if waybar.Class != LevelCritical || waybar.Percentage != 12 || !strings.Contains(waybar.Tooltip, "Claude 12% · resets in 2h13m · CRITICAL · 30m left") {
+		t.Errorf("waybar = %+v", waybar)
+	}
+	if strings.Contains(waybar.Tooltip, "Gemini") {
+		t.Errorf("tooltip lists a family without models: %q", waybar.Tooltip)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, d, "xml", "", now); err == nil {
+		t.Error("Render() accepted an unknown output")
+	}
+}
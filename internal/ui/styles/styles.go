@@ -235,6 +235,13 @@ var QuotaRateLimitedStyle = lipgloss.NewStyle().
 	Bold(true).
 	Italic(true)
 
+// RevokedBadgeStyle marks accounts whose refresh token was revoked.
+var RevokedBadgeStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("231")).
+	Background(Error).
+	Bold(true).
+	Padding(0, 1)
+
 // ErrorTextStyle for error messages.
 var ErrorTextStyle = lipgloss.NewStyle().
 	Foreground(Error)
@@ -0,0 +1,239 @@
+package accounts
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/app"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+func newTestModel(readOnly bool) *Model {
+	state := app.NewState()
+	state.SetAccounts([]models.AccountWithQuota{
+		{
+			Account:  models.Account{Email: "a@example.com", RefreshToken: "token-a", ProjectID: "proj-a"},
+			IsActive: true,
+			QuotaInfo: &models.QuotaInfo{
+				SubscriptionTier: "PRO",
+				ModelQuotas: []models.ModelQuota{
+					{ModelFamily: "claude", Remaining: 80, Limit: 100},
+					{ModelFamily: "gemini", Remaining: 30, Limit: 100},
+				},
+			},
+		},
+		{
+			Account:   models.Account{Email: "b@example.com", RefreshToken: "token-b", DisplayName: "Bob"},
+			QuotaInfo: &models.QuotaInfo{Degraded: models.DegradedTokenRevoked},
+		},
+		{
+			Account: models.Account{Email: "c@example.com", PollInterval: "2m", PollPaused: true},
+		},
+	})
+	m := New(state, readOnly)
+	m.SetSize(160, 30)
+	return m
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// runCmd executes cmd and returns its message, or nil.
+func runCmd(cmd tea.Cmd) tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	return cmd()
+}
+
+func TestModel_View(t *testing.T) {
+	m := newTestModel(false)
+	view := m.View()
+	for _, want := range []string{"a@example.com", "Bob", "PRO", "proj-a", "valid", "revoked", "missing", "C  80% G  30%", "auto", "paused"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() does not contain %q", want)
+		}
+	}
+
+	m.SetSize(60, 30)
+	if view := m.View(); strings.Contains(view, "proj-a") || !strings.Contains(view, "revoked") {
+		t.Error("narrow View() should drop the project column but keep the token status")
+	}
+}
+
+func TestModel_SwitchAndRefresh(t *testing.T) {
+	m := newTestModel(false)
+
+	if msg := runCmd(m.handleKeyMsg(keyMsg("enter"))); msg != nil {
+		t.Errorf("activating the active account = %#v, want nothing", msg)
+	}
+	m.Update(keyMsg("j"))
+	_, cmd := m.Update(keyMsg("enter"))
+	if msg, ok := runCmd(cmd).(app.SwitchAccountMsg); !ok || msg.Email != "b@example.com" {
+		t.Errorf("enter = %#v, want SwitchAccountMsg for b", runCmd(cmd))
+	}
+	_, cmd = m.Update(keyMsg("f"))
+	if msg, ok := runCmd(cmd).(app.RefreshQuotaForAccountMsg); !ok || msg.Email != "b@example.com" {
+		t.Errorf("f = %#v, want RefreshQuotaForAccountMsg for b", runCmd(cmd))
+	}
+}
+
+func TestModel_DeleteConfirmation(t *testing.T) {
+	m := newTestModel(false)
+	m.Update(keyMsg("G"))
+
+	m.Update(keyMsg("d"))
+	if !m.CapturingInput() || !strings.Contains(m.View(), "Delete c@example.com") {
+		t.Fatal("d should ask for confirmation")
+	}
+	if _, cmd := m.Update(keyMsg("n")); cmd != nil || m.CapturingInput() {
+		t.Fatal("n should cancel the deletion")
+	}
+
+	m.Update(keyMsg("d"))
+	_, cmd := m.Update(keyMsg("y"))
+	if msg, ok := runCmd(cmd).(app.DeleteAccountMsg); !ok || msg.Email != "c@example.com" {
+		t.Errorf("y = %#v, want DeleteAccountMsg for c", runCmd(cmd))
+	}
+	if m.CapturingInput() {
+		t.Error("confirming should return to browsing")
+	}
+}
+
+func TestModel_Rename(t *testing.T) {
+	m := newTestModel(false)
+	m.Update(keyMsg("j"))
+
+	m.Update(keyMsg("e"))
+	if !m.CapturingInput() || m.input.Value() != "Bob" {
+		t.Fatalf("e should edit the current name, got %q", m.input.Value())
+	}
+	for _, r := range "by" {
+		m.Update(keyMsg(string(r)))
+	}
+	_, cmd := m.Update(keyMsg("enter"))
+	msg, ok := runCmd(cmd).(app.RenameAccountMsg)
+	if !ok || msg.Email != "b@example.com" || msg.DisplayName != "Bobby" {
+		t.Errorf("enter = %#v, want RenameAccountMsg{b, Bobby}", runCmd(cmd))
+	}
+
+	m.Update(keyMsg("e"))
+	if _, cmd := m.Update(keyMsg("esc")); cmd != nil || m.CapturingInput() {
+		t.Error("esc should cancel renaming")
+	}
+}
+
+func TestModel_ReadOnly(t *testing.T) {
+	m := newTestModel(true)
+	m.Update(keyMsg("j"))
+
+	for _, k := range []string{"enter", "d", "e", "p"} {
+		_, cmd := m.Update(keyMsg(k))
+		if msg, ok := runCmd(cmd).(app.AddNotificationMsg); !ok || msg.Type != app.NotificationWarning {
+			t.Errorf("%s in read-only mode = %#v, want a warning", k, runCmd(cmd))
+		}
+		if m.CapturingInput() {
+			t.Errorf("%s in read-only mode should not change mode", k)
+		}
+	}
+	if _, cmd := m.Update(keyMsg("a")); runCmd(cmd) == nil {
+		t.Error("a in read-only mode should warn")
+	} else if _, ok := runCmd(cmd).(app.LoginMsg); ok {
+		t.Error("a in read-only mode should not log in")
+	}
+	if _, cmd := m.Update(keyMsg("f")); runCmd(cmd) == nil {
+		t.Error("refresh should work in read-only mode")
+	}
+}
+
+func TestModel_Mouse(t *testing.T) {
+	m := newTestModel(false)
+
+	m.Update(tea.MouseMsg{X: 10, Y: rowsTop + 2, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if m.selected != 2 {
+		t.Fatalf("clicking the third row selected %d", m.selected)
+	}
+	m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if m.selected != 1 {
+		t.Fatalf("wheel up selected %d, want 1", m.selected)
+	}
+
+	// The buttons are Set active, Rename, Delete, Refresh, Pause and Log in.
+	l := m.layout()
+	x := marginLeft
+	for _, b := range m.buttons()[:2] {
+		x += len(b.label) + 5
+	}
+	m.Update(tea.MouseMsg{X: x + 1, Y: l.buttonsY, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if m.mode != modeConfirmDelete || m.target != "b@example.com" {
+		t.Fatalf("clicking Delete: mode = %v, target = %q", m.mode, m.target)
+	}
+	_, cmd := m.Update(tea.MouseMsg{X: marginLeft + 1, Y: l.buttonsY, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if msg, ok := runCmd(cmd).(app.DeleteAccountMsg); !ok || msg.Email != "b@example.com" {
+		t.Errorf("clicking the confirm button = %#v, want DeleteAccountMsg", runCmd(cmd))
+	}
+}
+
+func TestModel_PausePolling(t *testing.T) {
+	m := newTestModel(false)
+
+	_, cmd := m.Update(keyMsg("p"))
+	if msg, ok := runCmd(cmd).(app.PausePollingMsg); !ok || msg.Email != "a@example.com" || !msg.Paused {
+		t.Errorf("p = %#v, want PausePollingMsg pausing a", runCmd(cmd))
+	}
+
+	m.Update(keyMsg("G"))
+	if !strings.Contains(m.View(), "Resume") {
+		t.Error("a paused account should offer to resume")
+	}
+	_, cmd = m.Update(keyMsg("p"))
+	if msg, ok := runCmd(cmd).(app.PausePollingMsg); !ok || msg.Email != "c@example.com" || msg.Paused {
+		t.Errorf("p = %#v, want PausePollingMsg resuming c", runCmd(cmd))
+	}
+}
+
+func TestPollCell(t *testing.T) {
+	acc := &models.AccountWithQuota{Account: models.Account{PollInterval: "90s"}}
+	if text, _ := pollCell(acc); text != "1m30s" {
+		t.Errorf("pollCell() = %q, want the override", text)
+	}
+}
+
+func TestModel_Login(t *testing.T) {
+	m := New(app.NewState(), false)
+	m.SetSize(100, 30)
+	if !strings.Contains(m.View(), "Press a to log in") {
+		t.Error("empty View() should point to the login")
+	}
+	_, cmd := m.Update(keyMsg("a"))
+	if _, ok := runCmd(cmd).(app.LoginMsg); !ok {
+		t.Errorf("a = %#v, want LoginMsg", runCmd(cmd))
+	}
+}
+
+func TestModel_SelectionFollowsAccounts(t *testing.T) {
+	m := newTestModel(false)
+	m.Update(keyMsg("G"))
+
+	m.state.SetAccounts(m.state.GetAccounts()[:1])
+	m.Update(app.AccountsLoadedMsg{})
+	if m.selected != 0 {
+		t.Errorf("selected = %d after accounts were removed, want 0", m.selected)
+	}
+}
+
+func TestModel_Help(t *testing.T) {
+	m := newTestModel(false)
+	if len(m.ShortHelp()) == 0 || len(m.FullHelp()) == 0 {
+		t.Error("help should not be empty")
+	}
+}
@@ -0,0 +1,7 @@
+package accounts
+
+import "testing"
+
+func TestPlaceholder(t *testing.T) {
+	// This test ensures that the package is instrumented for coverage.
+}
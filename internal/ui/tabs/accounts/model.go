@@ -0,0 +1,388 @@
+// Package accounts provides the accounts tab for the Antigravity Dashboard TUI.
+package accounts
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/app"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+)
+
+// mode is what the tab is doing with the keyboard.
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeConfirmDelete
+	modeRename
+)
+
+// action is an operation on the selected account, bound to a key and a
+// button.
+type action int
+
+const (
+	actionActivate action = iota
+	actionRename
+	actionDelete
+	actionRefresh
+	actionPause
+	actionLogin
+	actionConfirm
+	actionCancel
+)
+
+// keyMap defines the key bindings specific to the accounts tab.
+type keyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Home     key.Binding
+	End      key.Binding
+	Activate key.Binding
+	Rename   key.Binding
+	Delete   key.Binding
+	Refresh  key.Binding
+	Pause    key.Binding
+	Login    key.Binding
+	Confirm  key.Binding
+	Cancel   key.Binding
+	Save     key.Binding
+}
+
+// defaultKeyMap returns the default key bindings for the accounts tab.
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Home: key.NewBinding(
+			key.WithKeys("g", "home"),
+			key.WithHelp("g/home", "first account"),
+		),
+		End: key.NewBinding(
+			key.WithKeys("G", "end"),
+			key.WithHelp("G/end", "last account"),
+		),
+		Activate: key.NewBinding(
+			key.WithKeys("enter", "s"),
+			key.WithHelp("enter/s", "set active"),
+		),
+		Rename: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "rename"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d", "delete"),
+			key.WithHelp("d", "delete"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "refresh quota"),
+		),
+		Pause: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pause/resume polling"),
+		),
+		Login: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "log in (add/re-auth)"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("n", "esc"),
+			key.WithHelp("n/esc", "cancel"),
+		),
+		Save: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "save"),
+		),
+	}
+}
+
+// Model represents the accounts tab state.
+type Model struct {
+	state    *app.State
+	keys     keyMap
+	input    textinput.Model
+	target   string
+	width    int
+	height   int
+	selected int
+	offset   int
+	mode     mode
+	readOnly bool
+}
+
+// New creates a new accounts model. A read-only model shows the accounts but
+// refuses the actions that would write the accounts file.
+func New(state *app.State, readOnly bool) *Model {
+	input := textinput.New()
+	input.Placeholder = "display name"
+	input.CharLimit = 64
+	input.Prompt = "Name: "
+
+	return &Model{
+		state:    state,
+		keys:     defaultKeyMap(),
+		input:    input,
+		readOnly: readOnly,
+	}
+}
+
+// Init initializes the accounts tab.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// CapturingInput reports whether the tab is asking for confirmation or
+// editing a name, so global key bindings must not apply.
+func (m *Model) CapturingInput() bool {
+	return m.mode != modeBrowse
+}
+
+// Update handles messages for the accounts tab.
+func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m, m.handleKeyMsg(msg)
+	case tea.MouseMsg:
+		return m, m.handleMouseMsg(msg)
+	case app.AccountsLoadedMsg, app.DeleteAccountResultMsg:
+		m.clampSelection()
+	}
+
+	if m.mode == modeRename {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *Model) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	switch m.mode {
+	case modeConfirmDelete:
+		switch {
+		case key.Matches(msg, m.keys.Confirm):
+			return m.run(actionConfirm)
+		case key.Matches(msg, m.keys.Cancel):
+			return m.run(actionCancel)
+		}
+		return nil
+
+	case modeRename:
+		switch {
+		case key.Matches(msg, m.keys.Save):
+			return m.run(actionConfirm)
+		case msg.Type == tea.KeyEsc:
+			return m.run(actionCancel)
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.moveSelection(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.moveSelection(1)
+	case key.Matches(msg, m.keys.Home):
+		m.selected = 0
+		m.clampSelection()
+	case key.Matches(msg, m.keys.End):
+		m.selected = len(m.state.GetAccounts()) - 1
+		m.clampSelection()
+	case key.Matches(msg, m.keys.Activate):
+		return m.run(actionActivate)
+	case key.Matches(msg, m.keys.Rename):
+		return m.run(actionRename)
+	case key.Matches(msg, m.keys.Delete):
+		return m.run(actionDelete)
+	case key.Matches(msg, m.keys.Refresh):
+		return m.run(actionRefresh)
+	case key.Matches(msg, m.keys.Pause):
+		return m.run(actionPause)
+	case key.Matches(msg, m.keys.Login):
+		return m.run(actionLogin)
+	}
+	return nil
+}
+
+func (m *Model) handleMouseMsg(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if m.mode == modeBrowse {
+			m.moveSelection(-1)
+		}
+	case tea.MouseButtonWheelDown:
+		if m.mode == modeBrowse {
+			m.moveSelection(1)
+		}
+	case tea.MouseButtonLeft:
+		l := m.layout()
+		if i, ok := l.rowAt(msg.Y); ok && m.mode == modeBrowse {
+			m.selected = m.offset + i
+			m.clampSelection()
+			return nil
+		}
+		if a, ok := l.buttonAt(msg.X, msg.Y, m.buttons()); ok {
+			return m.run(a)
+		}
+	}
+	return nil
+}
+
+// run performs an action on the selected account.
+func (m *Model) run(a action) tea.Cmd {
+	switch a {
+	case actionConfirm:
+		return m.confirm()
+	case actionCancel:
+		m.reset()
+		return nil
+	case actionLogin:
+		if m.readOnly {
+			return readOnlyCmd()
+		}
+		return func() tea.Msg { return app.LoginMsg{} }
+	}
+
+	acc := m.selectedAccount()
+	if acc == nil {
+		return nil
+	}
+	if m.readOnly && a != actionRefresh {
+		return readOnlyCmd()
+	}
+
+	email := acc.Email
+	switch a {
+	case actionActivate:
+		if acc.IsActive {
+			return nil
+		}
+		return func() tea.Msg { return app.SwitchAccountMsg{Email: email} }
+	case actionRename:
+		m.mode = modeRename
+		m.target = email
+		m.input.SetValue(acc.DisplayName)
+		m.input.CursorEnd()
+		return m.input.Focus()
+	case actionDelete:
+		m.mode = modeConfirmDelete
+		m.target = email
+	case actionRefresh:
+		return func() tea.Msg { return app.RefreshQuotaForAccountMsg{Email: email} }
+	case actionPause:
+		paused := !acc.PollPaused
+		return func() tea.Msg { return app.PausePollingMsg{Email: email, Paused: paused} }
+	}
+	return nil
+}
+
+// confirm completes the pending delete or rename.
+func (m *Model) confirm() tea.Cmd {
+	email := m.target
+	pending := m.mode
+	name := m.input.Value()
+	m.reset()
+
+	switch pending {
+	case modeConfirmDelete:
+		return func() tea.Msg { return app.DeleteAccountMsg{Email: email} }
+	case modeRename:
+		return func() tea.Msg { return app.RenameAccountMsg{Email: email, DisplayName: name} }
+	}
+	return nil
+}
+
+// reset returns to browsing.
+func (m *Model) reset() {
+	m.mode = modeBrowse
+	m.target = ""
+	m.input.Blur()
+	m.input.Reset()
+}
+
+func readOnlyCmd() tea.Cmd {
+	return func() tea.Msg {
+		return app.AddNotificationMsg{
+			Type:     app.NotificationWarning,
+			Message:  "The accounts file is read-only",
+			Duration: app.DefaultNotificationDuration,
+		}
+	}
+}
+
+// selectedAccount returns the account under the cursor, or nil.
+func (m *Model) selectedAccount() *models.AccountWithQuota {
+	accounts := m.state.GetAccounts()
+	if m.selected < 0 || m.selected >= len(accounts) {
+		return nil
+	}
+	return &accounts[m.selected]
+}
+
+func (m *Model) moveSelection(delta int) {
+	m.selected += delta
+	m.clampSelection()
+}
+
+// clampSelection keeps the selection on an account and in view.
+func (m *Model) clampSelection() {
+	count := len(m.state.GetAccounts())
+	m.selected = max(min(m.selected, count-1), 0)
+
+	visible := m.layout().visibleRows
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+visible {
+		m.offset = m.selected - visible + 1
+	}
+	m.offset = max(min(m.offset, count-visible), 0)
+}
+
+// SetSize sets the available size for the accounts tab.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.input.Width = max(width-20, 10)
+	m.clampSelection()
+}
+
+// ShortHelp returns the key bindings for the short help view.
+func (m *Model) ShortHelp() []key.Binding {
+	switch m.mode {
+	case modeConfirmDelete:
+		return []key.Binding{m.keys.Confirm, m.keys.Cancel}
+	case modeRename:
+		return []key.Binding{m.keys.Save, m.keys.Cancel}
+	}
+	return []key.Binding{m.keys.Activate, m.keys.Rename, m.keys.Delete, m.keys.Refresh, m.keys.Pause, m.keys.Login}
+}
+
+// FullHelp returns the key bindings for the full help view.
+func (m *Model) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{m.keys.Up, m.keys.Down, m.keys.Home, m.keys.End},
+		{m.keys.Activate, m.keys.Rename, m.keys.Delete, m.keys.Refresh},
+		{m.keys.Pause, m.keys.Login},
+		{m.keys.Confirm, m.keys.Cancel},
+	}
+}
@@ -0,0 +1,343 @@
+package accounts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/styles"
+)
+
+// Layout of the tab content, in lines from the top of the tab. DocStyle adds
+// a one-line margin above the title; the title and the table header take two
+// lines each.
+const (
+	marginTop   = 1
+	rowsTop     = marginTop + 4
+	marginLeft  = 3 // DocStyle margin and padding
+	footerLines = 3 // blank line, buttons and status line
+	dateLayout  = "2006-01-02"
+	emptyCell   = "—"
+)
+
+// column is a table column. Columns with a drop rank are hidden, lowest rank
+// first, when the terminal is too narrow to show them all.
+type column struct {
+	value func(acc *models.AccountWithQuota) (string, lipgloss.Style)
+	title string
+	width int
+	drop  int
+}
+
+var columns = []column{
+	{title: "Name", width: 16, drop: 3, value: func(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+		return orEmpty(acc.DisplayName), lipgloss.NewStyle()
+	}},
+	{title: "Tier", width: 7, drop: 5, value: tierCell},
+	{title: "Token", width: 10, value: tokenStatus},
+	{title: "Project", width: 20, drop: 1, value: func(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+		return orEmpty(acc.ProjectID), styles.HelpStyle
+	}},
+	{title: "Added", width: 10, drop: 2, value: func(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+		return formatDate(acc.AddedAt), styles.HelpStyle
+	}},
+	{title: "Last Used", width: 10, drop: 4, value: func(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+		return formatDate(acc.LastUsed), styles.HelpStyle
+	}},
+	{title: "Poll", width: 7, drop: 6, value: pollCell},
+	{title: "Quota", width: 13, value: quotaCell},
+}
+
+// minEmailWidth is the narrowest the email column gets before other columns
+// are dropped; maxEmailWidth is the widest it grows.
+const (
+	minEmailWidth = 20
+	maxEmailWidth = 36
+)
+
+// button is a clickable action in the button bar.
+type button struct {
+	label  string
+	action action
+	active bool
+}
+
+// layout describes where rows and buttons are drawn so mouse events can be
+// mapped back to them.
+type layout struct {
+	visibleRows int
+	renderedRow int
+	buttonsY    int
+}
+
+// layout computes the current layout from the tab size and the accounts.
+func (m *Model) layout() layout {
+	visible := max(m.height-2*marginTop-4-footerLines, 1)
+	count := len(m.state.GetAccounts())
+	rendered := max(min(visible, count-m.offset), 1)
+	return layout{
+		visibleRows: visible,
+		renderedRow: rendered,
+		buttonsY:    rowsTop + rendered + 1,
+	}
+}
+
+// rowAt returns the visible row index at line y.
+func (l layout) rowAt(y int) (int, bool) {
+	i := y - rowsTop
+	return i, i >= 0 && i < l.renderedRow
+}
+
+// buttonAt returns the action of the button at x, y.
+func (l layout) buttonAt(x, y int, buttons []button) (action, bool) {
+	if y != l.buttonsY {
+		return 0, false
+	}
+	left := marginLeft
+	for _, b := range buttons {
+		w := lipgloss.Width(renderButton(b))
+		if x >= left && x < left+w-1 {
+			return b.action, true
+		}
+		left += w
+	}
+	return 0, false
+}
+
+// buttons returns the buttons for the current mode.
+func (m *Model) buttons() []button {
+	switch m.mode {
+	case modeConfirmDelete:
+		return []button{{label: "Delete", action: actionConfirm, active: true}, {label: "Cancel", action: actionCancel}}
+	case modeRename:
+		return []button{{label: "Save", action: actionConfirm, active: true}, {label: "Cancel", action: actionCancel}}
+	}
+	return []button{
+		{label: "Set active", action: actionActivate},
+		{label: "Rename", action: actionRename},
+		{label: "Delete", action: actionDelete},
+		{label: "Refresh", action: actionRefresh},
+		{label: m.pauseLabel(), action: actionPause},
+		{label: "Log in", action: actionLogin},
+	}
+}
+
+// pauseLabel names the pause button after what it does to the selected
+// account.
+func (m *Model) pauseLabel() string {
+	if acc := m.selectedAccount(); acc != nil && acc.PollPaused {
+		return "Resume"
+	}
+	return "Pause"
+}
+
+func renderButton(b button) string {
+	if b.active {
+		return styles.ButtonActiveStyle.Render(b.label)
+	}
+	return styles.ButtonInactiveStyle.Render(b.label)
+}
+
+// View renders the accounts tab.
+func (m *Model) View() string {
+	l := m.layout()
+	accounts := m.state.GetAccounts()
+	cols, emailWidth := m.visibleColumns()
+
+	lines := []string{styles.TitleStyle.Render("Accounts"), m.renderHeader(cols, emailWidth)}
+
+	if len(accounts) == 0 {
+		lines = append(lines, styles.HelpStyle.Render("  No accounts configured. Press a to log in with Google"))
+	}
+	end := min(m.offset+l.visibleRows, len(accounts))
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.renderRow(&accounts[i], i == m.selected, cols, emailWidth))
+	}
+
+	buttons := make([]string, 0, 6)
+	for _, b := range m.buttons() {
+		buttons = append(buttons, renderButton(b))
+	}
+	lines = append(lines, "", lipgloss.JoinHorizontal(lipgloss.Top, buttons...), m.renderStatus())
+
+	return styles.DocStyle.
+		Width(m.width).
+		Height(m.height).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// visibleColumns returns the columns that fit the width and the width left
+// for the email column.
+func (m *Model) visibleColumns() ([]column, int) {
+	available := m.width - 2*marginLeft - 2
+	cols := columns
+	for {
+		used := 0
+		for _, c := range cols {
+			used += c.width + 1
+		}
+		emailWidth := available - used
+		if emailWidth >= minEmailWidth || !hasDroppable(cols) {
+			return cols, max(min(emailWidth, maxEmailWidth), minEmailWidth)
+		}
+		cols = dropOne(cols)
+	}
+}
+
+func hasDroppable(cols []column) bool {
+	for _, c := range cols {
+		if c.drop > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dropOne removes the column with the lowest drop rank.
+func dropOne(cols []column) []column {
+	drop := -1
+	for i, c := range cols {
+		if c.drop > 0 && (drop < 0 || c.drop < cols[drop].drop) {
+			drop = i
+		}
+	}
+	out := make([]column, 0, len(cols)-1)
+	out = append(out, cols[:drop]...)
+	return append(out, cols[drop+1:]...)
+}
+
+func (m *Model) renderHeader(cols []column, emailWidth int) string {
+	cells := []string{"  ", pad("Email", emailWidth)}
+	for _, c := range cols {
+		cells = append(cells, pad(c.title, c.width))
+	}
+	return styles.TableHeaderStyle.Render(strings.Join(cells, " "))
+}
+
+func (m *Model) renderRow(acc *models.AccountWithQuota, selected bool, cols []column, emailWidth int) string {
+	marker := "  "
+	if acc.IsActive {
+		marker = "● "
+	}
+
+	if selected {
+		cells := []string{marker, pad(acc.Email, emailWidth)}
+		for _, c := range cols {
+			text, _ := c.value(acc)
+			cells = append(cells, pad(text, c.width))
+		}
+		return styles.TableSelectedStyle.Render(strings.Join(cells, " "))
+	}
+
+	cells := []string{styles.SuccessTextStyle.Render(marker), pad(acc.Email, emailWidth)}
+	for _, c := range cols {
+		text, style := c.value(acc)
+		cells = append(cells, style.Render(pad(text, c.width)))
+	}
+	return strings.Join(cells, " ")
+}
+
+// renderStatus renders the line below the buttons: the delete prompt, the
+// name input or a hint.
+func (m *Model) renderStatus() string {
+	switch m.mode {
+	case modeConfirmDelete:
+		return styles.WarningTextStyle.Render(fmt.Sprintf("Delete %s from the accounts file? [y/n]", m.target))
+	case modeRename:
+		return m.input.View()
+	}
+	if m.readOnly {
+		return styles.WarningTextStyle.Render("Read-only: the accounts file will not be changed")
+	}
+	return styles.HelpStyle.Render("Click a row to select it, or a button to act on the selected account")
+}
+
+// tokenStatus describes whether the account's refresh token works.
+func tokenStatus(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+	q := acc.QuotaInfo
+	switch {
+	case acc.RefreshToken == "":
+		return "missing", styles.ErrorTextStyle
+	case q == nil:
+		return "pending", styles.HelpStyle
+	case q.Degraded == models.DegradedTokenRevoked:
+		return "revoked", styles.ErrorTextStyle
+	case q.Degraded == models.DegradedInvalidClient:
+		return "bad client", styles.ErrorTextStyle
+	case q.Degraded == models.DegradedNoCredentials:
+		return "unverified", styles.WarningTextStyle
+	case q.Degraded == models.DegradedNetwork:
+		return "offline", styles.WarningTextStyle
+	case q.Error != "":
+		return "error", styles.ErrorTextStyle
+	default:
+		return "valid", styles.SuccessTextStyle
+	}
+}
+
+// pollCell shows whether the account is polled in the background, and at a
+// fixed interval if it overrides the adaptive one.
+func pollCell(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+	switch {
+	case acc.PollPaused:
+		return "paused", styles.WarningTextStyle
+	case acc.PollOverride() > 0:
+		return acc.PollOverride().String(), lipgloss.NewStyle()
+	default:
+		return "auto", styles.HelpStyle
+	}
+}
+
+func tierCell(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+	if acc.QuotaInfo == nil || acc.QuotaInfo.SubscriptionTier == "" {
+		return emptyCell, styles.TierUnknownStyle
+	}
+	return acc.QuotaInfo.SubscriptionTier, styles.GetTierStyle(acc.QuotaInfo.SubscriptionTier)
+}
+
+// quotaCell shows the remaining Claude and Gemini quota, styled by the lower.
+func quotaCell(acc *models.AccountWithQuota) (string, lipgloss.Style) {
+	if acc.QuotaInfo == nil {
+		return emptyCell, styles.HelpStyle
+	}
+	claude, _ := acc.QuotaInfo.FamilyRollup("claude")
+	gemini, _ := acc.QuotaInfo.FamilyRollup("gemini")
+	if claude < 0 && gemini < 0 {
+		return emptyCell, styles.HelpStyle
+	}
+
+	lowest := 100.0
+	part := func(prefix string, percent float64) string {
+		if percent < 0 {
+			return prefix + "    " + emptyCell
+		}
+		lowest = min(lowest, percent)
+		return fmt.Sprintf("%s %3.0f%%", prefix, percent)
+	}
+	text := part("C", claude) + " " + part("G", gemini)
+	return text, styles.GetQuotaStyle(lowest, false)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return emptyCell
+	}
+	return t.Local().Format(dateLayout)
+}
+
+func orEmpty(s string) string {
+	if s == "" {
+		return emptyCell
+	}
+	return s
+}
+
+// pad truncates or pads s to exactly width cells.
+func pad(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
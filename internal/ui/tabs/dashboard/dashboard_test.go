@@ -4,8 +4,10 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/app"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
 )
@@ -80,6 +82,73 @@ func TestModel_View(t *testing.T) {
 	}
 }
 
+func TestModel_View_PerModelRows(t *testing.T) {
+	state := app.NewState()
+	state.SetLoading("initial", false)
+	m := New(state)
+
+	reset := time.Now().Add(2 * time.Hour)
+	state.SetAccounts([]models.AccountWithQuota{
+		{
+			Account: models.Account{Email: "test@example.com", ID: "1"},
+			QuotaInfo: &models.QuotaInfo{
+				ModelQuotas: []models.ModelQuota{
+					{ModelID: "claude-opus-4-5", DisplayName: "Claude Opus 4.5", ModelFamily: "claude", Remaining: 40, Limit: 100, ResetTime: reset},
+					{ModelID: "claude-sonnet-4-5", DisplayName: "Claude Sonnet 4.5", ModelFamily: "claude", Remaining: 90, Limit: 100, ResetTime: reset},
+					{ModelID: "gemini-3-pro", DisplayName: "Gemini 3 Pro", ModelFamily: "gemini", Remaining: 70, Limit: 100, ResetTime: reset},
+				},
+			},
+		},
+	})
+	state.SetProjection("test@example.com", &models.AccountProjection{
+		Email: "test@example.com",
+		Models: []*models.ModelProjection{
+			{Model: "claude-opus-4-5", Family: "claude", Status: models.ProjectionCritical},
+		},
+	})
+
+	m.SetSize(140, 80)
+	view := m.View()
+
+	for _, want := range []string{"Claude Opus 4.5", "Claude Sonnet 4.5", "CRITICAL"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View should contain %q", want)
+		}
+	}
+	// Single-model families are covered by the rollup bar alone.
+	if strings.Contains(view, "Gemini 3 Pro") {
+		t.Error("View should not render a per-model row for a single-model family")
+	}
+
+	m.syncAnimationTargets(time.Now())
+	if _, ok := m.animations[modelAnimKey("test@example.com", "claude-opus-4-5")]; !ok {
+		t.Error("Expected per-model animation state")
+	}
+}
+
+func TestModel_RenderModelRows_TruncatesByWidth(t *testing.T) {
+	m := New(app.NewState())
+	acc := &models.AccountWithQuota{
+		Account: models.Account{Email: "a@example.com"},
+		QuotaInfo: &models.QuotaInfo{ModelQuotas: []models.ModelQuota{
+			{ModelID: "gemini-a", DisplayName: "ジェミニ・プロ・エクスペリメンタル・モデル", ModelFamily: "gemini", Limit: 100, Remaining: 50},
+			{ModelID: "gemini-b", DisplayName: "Gemini Flash", ModelFamily: "gemini", Limit: 100, Remaining: 80},
+		}},
+	}
+
+	rows := m.renderModelRows(acc, "gemini", 100, nil)
+	if len(rows) != 2 {
+		t.Fatalf("renderModelRows() = %d rows, want 2", len(rows))
+	}
+	joined := strings.Join(rows, "\n")
+	if !utf8.ValidString(joined) || !strings.Contains(joined, "...") {
+		t.Errorf("long name not truncated on a character boundary: %q", joined)
+	}
+	if w := ansi.StringWidth(rows[0]); w != ansi.StringWidth(rows[1]) {
+		t.Errorf("row widths differ: %d and %d", w, ansi.StringWidth(rows[1]))
+	}
+}
+
 func TestModel_Animation(t *testing.T) {
 	state := app.NewState()
 	m := New(state)
@@ -124,3 +193,88 @@ func TestModel_KeyBindings(t *testing.T) {
 
 	// Since there are no accounts, selection logic might be limited, but coverage should increase
 }
+
+func TestRenderStaleness(t *testing.T) {
+	now := time.Now()
+
+	if got := renderStaleness(&models.QuotaInfo{LastUpdated: now}, now); got != "" {
+		t.Errorf("live data should render nothing, got %q", got)
+	}
+
+	cached := &models.QuotaInfo{
+		LastUpdated: now.Add(-2 * time.Hour),
+		Cached:      true,
+		Degraded:    models.DegradedNetwork,
+		ModelQuotas: []models.ModelQuota{{ModelFamily: "claude", Limit: 100, Remaining: 50}},
+	}
+	got := renderStaleness(cached, now)
+	if !strings.Contains(got, "2h ago") || !strings.Contains(got, "network unreachable") {
+		t.Errorf("unexpected staleness: %q", got)
+	}
+
+	noData := &models.QuotaInfo{Error: "x", Degraded: models.DegradedNoCredentials, LastUpdated: now}
+	if got := renderStaleness(noData, now); !strings.Contains(got, "no credentials") {
+		t.Errorf("expected reason for account without data, got %q", got)
+	}
+
+	revoked := *cached
+	revoked.Degraded = models.DegradedTokenRevoked
+	if got := renderStaleness(&revoked, now); !strings.Contains(got, "LOGIN REQUIRED") || !strings.Contains(got, "2h ago") {
+		t.Errorf("expected a login badge for a revoked token, got %q", got)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		want string
+		d    time.Duration
+	}{
+		{"just now", 10 * time.Second},
+		{"5m ago", 5 * time.Minute},
+		{"3h ago", 3 * time.Hour},
+		{"2d ago", 49 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// focusedEmail runs cmd and returns the account of the FocusAccountMsg it
+// produces, or "".
+func focusedEmail(cmd tea.Cmd) string {
+	if cmd == nil {
+		return ""
+	}
+	switch msg := cmd().(type) {
+	case app.FocusAccountMsg:
+		return msg.Email
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if email := focusedEmail(c); email != "" {
+				return email
+			}
+		}
+	}
+	return ""
+}
+
+func TestModel_FocusFollowsSelection(t *testing.T) {
+	state := app.NewState()
+	state.SetAccounts([]models.AccountWithQuota{
+		{Account: models.Account{Email: "a@example.com"}},
+		{Account: models.Account{Email: "b@example.com"}},
+	})
+	m := New(state)
+
+	if _, cmd := m.Update(app.AccountsLoadedMsg{}); focusedEmail(cmd) != "a@example.com" {
+		t.Error("loading accounts should focus the first account")
+	}
+	if _, cmd := m.Update(app.AccountsLoadedMsg{}); focusedEmail(cmd) != "" {
+		t.Error("an unchanged selection should not be reported again")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyDown}); focusedEmail(cmd) != "b@example.com" {
+		t.Error("moving down should focus the second account")
+	}
+}
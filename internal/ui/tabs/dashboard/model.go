@@ -75,6 +75,7 @@ type Model struct {
 	timeBar        components.TimeBar
 	claudeQuotaBar components.QuotaBar
 	geminiQuotaBar components.QuotaBar
+	focused        string
 	width          int
 	height         int
 	selectedIndex  int
@@ -115,13 +116,13 @@ func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
 
 	case app.QuotaUpdatedEventMsg, app.AccountsLoadedMsg, app.RefreshMsg:
 		m.syncAnimationTargets(time.Now())
-		cmds = append(cmds, animationTickCmd())
+		cmds = append(cmds, animationTickCmd(), m.focusCmd())
 
 	case app.ProjectionUpdatedMsg:
 		m.state.SetProjection(msg.Email, msg.Projection)
 
 	case tea.KeyMsg:
-		cmds = append(cmds, m.handleKeyMsg(msg))
+		cmds = append(cmds, m.handleKeyMsg(msg), m.focusCmd())
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -175,6 +176,21 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+// focusCmd reports the selected account when it changes, so its quota is
+// refreshed first.
+func (m *Model) focusCmd() tea.Cmd {
+	accounts := m.state.GetAccounts()
+	if m.selectedIndex < 0 || m.selectedIndex >= len(accounts) {
+		return nil
+	}
+	email := accounts[m.selectedIndex].Email
+	if email == m.focused {
+		return nil
+	}
+	m.focused = email
+	return func() tea.Msg { return app.FocusAccountMsg{Email: email} }
+}
+
 // SetSize sets the available size for the dashboard.
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -201,11 +217,26 @@ func (m *Model) syncAnimationTargets(now time.Time) (animating, hasPendingData b
 		if m.updateAnimationState(acc.Email+":gemini", geminiTarget, now) {
 			animating = true
 		}
+
+		for j := range acc.QuotaInfo.ModelQuotas {
+			mq := &acc.QuotaInfo.ModelQuotas[j]
+			if mq.ModelID == "" {
+				continue
+			}
+			if m.updateAnimationState(modelAnimKey(acc.Email, mq.ModelID), mq.RemainingPercent(), now) {
+				animating = true
+			}
+		}
 	}
 
 	return animating, hasPendingData
 }
 
+// modelAnimKey returns the animation key for a single model's bar.
+func modelAnimKey(email, modelID string) string {
+	return email + ":model:" + modelID
+}
+
 func (m *Model) calculateTargets(quotaInfo *models.QuotaInfo) (claudeTarget, geminiTarget float64) {
 	claudeTarget = -1.0
 	geminiTarget = -1.0
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/models"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/components"
@@ -141,12 +142,66 @@ func (m *Model) renderAccountHeader(acc *models.AccountWithQuota, selected bool)
 		}
 	}
 
-	return fmt.Sprintf("%s%s%s %s",
+	header := fmt.Sprintf("%s%s%s %s",
 		selectionPrefix,
 		activeIndicator,
 		lipgloss.NewStyle().Bold(true).Render(email),
 		tierStyle.Render(tierIcon+" "+tier),
 	)
+
+	if staleness := renderStaleness(acc.QuotaInfo, time.Now()); staleness != "" {
+		header += "  " + staleness
+	}
+
+	return header
+}
+
+// renderStaleness renders the data age and degraded reason for accounts
+// showing last-known data. Returns an empty string for live data. Accounts
+// with a revoked token get a badge asking to log in again.
+func renderStaleness(quotaInfo *models.QuotaInfo, now time.Time) string {
+	if quotaInfo == nil || !quotaInfo.IsStale() {
+		return ""
+	}
+
+	var text string
+	switch {
+	case quotaInfo.Degraded == models.DegradedTokenRevoked:
+		badge := styles.RevokedBadgeStyle.Render("⊘ LOGIN REQUIRED")
+		if len(quotaInfo.ModelQuotas) == 0 {
+			return badge
+		}
+		return badge + " " + styles.HelpStyle.Render("◷ "+formatAge(quotaInfo.DataAge(now)))
+	case len(quotaInfo.ModelQuotas) == 0:
+		text = "⚠ no data · " + string(quotaInfo.Degraded)
+	case quotaInfo.Degraded != models.DegradedNone:
+		text = "◷ " + formatAge(quotaInfo.DataAge(now)) + " · " + string(quotaInfo.Degraded)
+	default:
+		text = "◷ " + formatAge(quotaInfo.DataAge(now))
+	}
+
+	style := styles.HelpStyle
+	switch quotaInfo.Degraded {
+	case models.DegradedInvalidClient:
+		style = styles.ErrorTextStyle
+	case models.DegradedNetwork, models.DegradedNoCredentials, models.DegradedRateLimited:
+		style = styles.WarningTextStyle
+	}
+	return style.Render(text)
+}
+
+// formatAge formats a data age as a short relative time.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
 }
 
 func (m *Model) renderAccountQuotas(acc *models.AccountWithQuota, width int) []string {
@@ -165,6 +220,7 @@ func (m *Model) renderAccountQuotas(acc *models.AccountWithQuota, width int) []s
 	if claudePercent >= 0 {
 		lines = append(lines, m.renderModelQuota(
 			"Claude", "⬡", "#cc785c", acc.Email+":claude", claudePercent, width, claudeResetSec, tier, claudeProj)...)
+		lines = append(lines, m.renderModelRows(acc, "claude", width, proj)...)
 	}
 
 	if claudePercent >= 0 && geminiPercent >= 0 {
@@ -174,6 +230,7 @@ func (m *Model) renderAccountQuotas(acc *models.AccountWithQuota, width int) []s
 	if geminiPercent >= 0 {
 		lines = append(lines, m.renderModelQuota(
 			"Gemini", "◎", "#4285f4", acc.Email+":gemini", geminiPercent, width, geminiResetSec, tier, geminiProj)...)
+		lines = append(lines, m.renderModelRows(acc, "gemini", width, proj)...)
 	}
 
 	if acc.QuotaInfo.TotalLimit > 0 {
@@ -251,6 +308,78 @@ func (m *Model) renderModelQuota(
 	return lines
 }
 
+// renderModelRows renders one compact row per model below its family rollup.
+// Nothing is rendered when the family has a single model, since the rollup
+// bar already shows it.
+func (m *Model) renderModelRows(
+	acc *models.AccountWithQuota,
+	family string,
+	width int,
+	proj *models.AccountProjection,
+) []string {
+	familyModels := acc.QuotaInfo.FamilyModels(family)
+	if len(familyModels) < 2 {
+		return nil
+	}
+
+	const (
+		nameWidth    = 26
+		percentWidth = 6
+		resetWidth   = 8
+		badgeWidth   = 10
+	)
+	barWidth := max(width-len(indentSpace)-2-nameWidth-percentWidth-resetWidth-badgeWidth-4, 6)
+
+	var lines []string
+	for i := range familyModels {
+		mq := &familyModels[i]
+
+		percent := mq.RemainingPercent()
+		if anim, ok := m.animations[modelAnimKey(acc.Email, mq.ModelID)]; ok {
+			percent = anim.CurrentPercent
+		}
+
+		name := ansi.Truncate(mq.Name(), nameWidth-1, "...")
+		nameStr := styles.HelpStyle.Width(nameWidth).Render(name)
+
+		percentStr := styles.GetQuotaStyle(percent, false).
+			Width(percentWidth).
+			Align(lipgloss.Right).
+			Render(fmt.Sprintf("%.0f%%", percent))
+
+		resetText := "---"
+		if !mq.ResetTime.IsZero() {
+			resetText = formatDuration(time.Until(mq.ResetTime).Hours())
+		}
+		resetStr := lipgloss.NewStyle().
+			Foreground(styles.TextSecondary).
+			Width(resetWidth).
+			Align(lipgloss.Right).
+			Render(resetText)
+
+		var modelProj *models.ModelProjection
+		if proj != nil {
+			modelProj = proj.ForModel(mq.ModelID)
+		}
+		badgeStr := renderStatusBadge(modelProj, badgeWidth)
+
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+			indentSpace,
+			"  ",
+			nameStr,
+			components.RenderGradientBar(percent, barWidth),
+			" ",
+			percentStr,
+			" ",
+			resetStr,
+			" ",
+			badgeStr,
+		))
+	}
+
+	return lines
+}
+
 func (m *Model) renderAccountError(acc *models.AccountWithQuota, width int) []string {
 	var lines []string
 	tier := acc.QuotaInfo.SubscriptionTier
@@ -342,26 +471,7 @@ func (m *Model) renderQuotaBarFirstLine(
 		rateStr = lipgloss.NewStyle().Width(rateWidth).Render("")
 	}
 
-	badgeStr := ""
-	if proj != nil && proj.Status != models.ProjectionUnknown {
-		var badgeStyle lipgloss.Style
-		var badgeText string
-
-		switch proj.Status {
-		case models.ProjectionCritical:
-			badgeStyle = styles.ProjectionCriticalStyle
-			badgeText = "▲ CRITICAL"
-		case models.ProjectionWarning:
-			badgeStyle = styles.ProjectionWarningStyle
-			badgeText = "▲ WARNING"
-		case models.ProjectionSafe:
-			badgeStyle = styles.ProjectionSafeStyle
-			badgeText = "● SAFE"
-		}
-		badgeStr = badgeStyle.Width(badgeWidth).Align(lipgloss.Right).Render(badgeText)
-	} else {
-		badgeStr = lipgloss.NewStyle().Width(badgeWidth).Render("")
-	}
+	badgeStr := renderStatusBadge(proj, badgeWidth)
 
 	bar1 := components.RenderGradientBar(percent, barWidth)
 
@@ -377,6 +487,30 @@ func (m *Model) renderQuotaBarFirstLine(
 	)
 }
 
+// renderStatusBadge renders the projection status badge, or blank padding
+// when the status is unknown.
+func renderStatusBadge(proj *models.ModelProjection, badgeWidth int) string {
+	if proj == nil || proj.Status == models.ProjectionUnknown {
+		return lipgloss.NewStyle().Width(badgeWidth).Render("")
+	}
+
+	var badgeStyle lipgloss.Style
+	var badgeText string
+
+	switch proj.Status {
+	case models.ProjectionCritical:
+		badgeStyle = styles.ProjectionCriticalStyle
+		badgeText = "▲ CRITICAL"
+	case models.ProjectionWarning:
+		badgeStyle = styles.ProjectionWarningStyle
+		badgeText = "▲ WARNING"
+	case models.ProjectionSafe:
+		badgeStyle = styles.ProjectionSafeStyle
+		badgeText = "● SAFE"
+	}
+	return badgeStyle.Width(badgeWidth).Align(lipgloss.Right).Render(badgeText)
+}
+
 func (m *Model) renderQuotaBarSecondLine(
 	barWidth, timeWidth, rateWidth, badgeWidth int,
 	resetSec int64,
@@ -56,7 +56,7 @@ func TestModel_WithData(t *testing.T) {
 		DatabasePath: filepath.Join(tmpDir, "test.db"),
 		AccountsPath: filepath.Join(tmpDir, "accounts.json"),
 	}
-	mgr, err := services.NewManager(cfg)
+	mgr, err := services.NewManager(t.Context(), cfg)
 	if err != nil {
 		t.Fatalf("Failed to create manager: %v", err)
 	}
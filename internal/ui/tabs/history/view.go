@@ -101,6 +101,9 @@ func (m *Model) renderHeader() string {
 			m.historyData.LastDataPoint.Format("Jan 2, 2006"),
 			m.historyData.TotalDataDays,
 		)
+		if m.historyData.Resolution != "" {
+			dataRange += fmt.Sprintf(" · %s resolution", m.historyData.Resolution)
+		}
 		subtitle = styles.HelpStyle.Render(dataRange)
 	}
 
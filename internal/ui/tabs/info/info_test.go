@@ -1,10 +1,14 @@
 package info
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/app"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/config"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services"
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
 )
 
 func TestNew(t *testing.T) {
@@ -68,3 +72,32 @@ func TestModel_Help(t *testing.T) {
 		// might be empty
 	}
 }
+
+func TestModel_EndpointHealth(t *testing.T) {
+	now := time.Now()
+	state := app.NewState()
+	state.SetStats(services.StatsEvent{Endpoints: []quota.EndpointHealth{
+		{Endpoint: "https://cloudcode-pa.googleapis.com", State: quota.CircuitOpen, OpenUntil: now.Add(90 * time.Second), Requests: 4, ErrorRate: 1, Latency: 120 * time.Millisecond},
+		{Endpoint: "https://daily-cloudcode-pa.sandbox.googleapis.com", State: quota.CircuitClosed, Requests: 9, LastSuccess: now.Add(-5 * time.Second), Latency: 80 * time.Millisecond, Preferred: true},
+		{Endpoint: "https://autopush-cloudcode-pa.sandbox.googleapis.com"},
+	}})
+	m := New(state, &config.Config{})
+	m.SetSize(100, 60)
+
+	card := m.renderEndpointsCard(now)
+	for _, want := range []string{"cloudcode-pa ", "open 1m", "100%", "120ms", "● daily-cloudcode-pa", "5s ago", "unused"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("endpoint card does not contain %q:\n%s", want, card)
+		}
+	}
+
+	state.SetStats(services.StatsEvent{DaemonPID: 42, Following: true})
+	if card := m.renderEndpointsCard(now); !strings.Contains(card, "daemon (pid 42)") {
+		t.Errorf("endpoint card while following a daemon =\n%s", card)
+	}
+
+	state.SetStats(services.StatsEvent{Following: true})
+	if card := m.renderEndpointsCard(now); !strings.Contains(card, "Polled by the daemon") {
+		t.Errorf("endpoint card while following a daemon that has not written its pid =\n%s", card)
+	}
+}
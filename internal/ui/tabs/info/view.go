@@ -3,9 +3,12 @@ package info
 import (
 	"fmt"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/j-veylop/antigravity-dashboard-tui/internal/services/quota"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/ui/styles"
 	"github.com/j-veylop/antigravity-dashboard-tui/internal/version"
 )
@@ -18,6 +21,7 @@ func (m *Model) View() string {
 	sections = append(sections,
 		m.renderTitle(),
 		m.renderConfigCard(),
+		m.renderEndpointsCard(time.Now()),
 		m.renderAboutCard(),
 	)
 
@@ -52,8 +56,12 @@ func (m *Model) renderConfigCard() string {
 	if m.config != nil {
 		rows = append(rows,
 			m.renderConfigRow("Accounts File", m.config.AccountsPath),
+			m.renderConfigRow("Accounts Access", m.accountsAccess()),
 			m.renderConfigRow("Database", m.config.DatabasePath),
 			m.renderConfigRow("Quota Refresh", m.config.QuotaRefreshInterval.String()),
+			m.renderConfigRow("OAuth Client", m.credentialsStatus()),
+			m.renderConfigRow("Data Source", m.dataSource()),
+			m.renderConfigRow("Auto-Rotate", m.autoRotateStatus()),
 		)
 	} else {
 		rows = append(rows, styles.HelpStyle.Render("Configuration not loaded"))
@@ -69,6 +77,56 @@ func (m *Model) renderConfigCard() string {
 	)
 }
 
+// credentialsStatus describes whether OAuth client credentials are configured.
+func (m *Model) credentialsStatus() string {
+	if m.config.HasCredentials() {
+		return "configured"
+	}
+	return "missing (showing cached data)"
+}
+
+// accountsAccess describes whether the accounts file may be written.
+func (m *Model) accountsAccess() string {
+	if m.config.ReadOnlyAccounts {
+		return "read-only"
+	}
+	return "read-write"
+}
+
+// autoRotateStatus describes the automatic account rotation settings.
+func (m *Model) autoRotateStatus() string {
+	if !m.config.AutoRotate {
+		return "off"
+	}
+	if m.config.ReadOnlyAccounts {
+		return "off (accounts file is read-only)"
+	}
+	status := fmt.Sprintf("%s after %s", m.config.AutoRotateStrategy, m.config.AutoRotateDelay)
+	if m.config.AutoRotateModel != "" {
+		status += " for " + m.config.AutoRotateModel
+	}
+	return status
+}
+
+// dataSource describes whether quotas are polled here or read from a daemon.
+func (m *Model) dataSource() string {
+	if m.state != nil {
+		if stats := m.state.GetStats(); stats != nil && stats.Following {
+			return daemonLabel(stats.DaemonPID)
+		}
+	}
+	return "polling"
+}
+
+// daemonLabel names the daemon quotas are read from; its PID is unknown
+// until the daemon has written it.
+func daemonLabel(pid int) string {
+	if pid == 0 {
+		return "daemon"
+	}
+	return fmt.Sprintf("daemon (pid %d)", pid)
+}
+
 // renderConfigRow renders a configuration key-value row.
 func (m *Model) renderConfigRow(label, value string) string {
 	labelStyle := lipgloss.NewStyle().
@@ -81,6 +139,109 @@ func (m *Model) renderConfigRow(label, value string) string {
 	return labelStyle.Render(label+":") + " " + valueStyle.Render(value)
 }
 
+// Column widths of the endpoint health table.
+const (
+	endpointStateWidth   = 10
+	endpointLatencyWidth = 8
+	endpointErrorsWidth  = 6
+	endpointLastOKWidth  = 8
+)
+
+// renderEndpointsCard renders the health and circuit state of the quota
+// endpoints. The endpoint tried first is marked.
+func (m *Model) renderEndpointsCard(now time.Time) string {
+	cardWidth := min(max(m.width-6, 50), 80)
+	hostWidth := max(cardWidth-4-2-endpointStateWidth-endpointLatencyWidth-endpointErrorsWidth-endpointLastOKWidth-4, 8)
+
+	rows := []string{styles.CardTitleStyle.Render("Quota Endpoints"), ""}
+
+	stats := m.state.GetStats()
+	switch {
+	case stats != nil && stats.Following:
+		rows = append(rows, styles.HelpStyle.Render("Polled by the "+daemonLabel(stats.DaemonPID)))
+	case stats == nil || len(stats.Endpoints) == 0:
+		rows = append(rows, styles.HelpStyle.Render("No quota requests yet"))
+	default:
+		rows = append(rows, styles.TableHeaderStyle.Render(strings.Join([]string{
+			"  " + padCell("Endpoint", hostWidth),
+			padCell("State", endpointStateWidth),
+			padCell("Latency", endpointLatencyWidth),
+			padCell("Errors", endpointErrorsWidth),
+			padCell("Last OK", endpointLastOKWidth),
+		}, " ")))
+		for i := range stats.Endpoints {
+			rows = append(rows, renderEndpointRow(&stats.Endpoints[i], hostWidth, now))
+		}
+	}
+
+	return styles.CardStyle.Width(cardWidth).Render(
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+	)
+}
+
+func renderEndpointRow(h *quota.EndpointHealth, hostWidth int, now time.Time) string {
+	marker := "  "
+	if h.Preferred {
+		marker = styles.SuccessTextStyle.Render("● ")
+	}
+	host := padCell(endpointHost(h.Endpoint), hostWidth)
+
+	if h.Requests == 0 {
+		return marker + host + " " + styles.HelpStyle.Render("unused")
+	}
+
+	state, stateStyle := "ok", styles.SuccessTextStyle
+	switch h.State {
+	case quota.CircuitOpen:
+		state, stateStyle = "open "+formatWait(h.OpenUntil.Sub(now)), styles.ErrorTextStyle
+	case quota.CircuitHalfOpen:
+		state, stateStyle = "half-open", styles.WarningTextStyle
+	}
+
+	errStyle := styles.HelpStyle
+	if h.ErrorRate >= 0.5 {
+		errStyle = styles.ErrorTextStyle
+	} else if h.ErrorRate > 0 {
+		errStyle = styles.WarningTextStyle
+	}
+
+	lastOK := "never"
+	if !h.LastSuccess.IsZero() {
+		lastOK = formatWait(now.Sub(h.LastSuccess)) + " ago"
+	}
+
+	return marker + strings.Join([]string{
+		host,
+		stateStyle.Render(padCell(state, endpointStateWidth)),
+		padCell(h.Latency.Round(time.Millisecond).String(), endpointLatencyWidth),
+		errStyle.Render(padCell(fmt.Sprintf("%.0f%%", h.ErrorRate*100), endpointErrorsWidth)),
+		styles.HelpStyle.Render(padCell(lastOK, endpointLastOKWidth)),
+	}, " ")
+}
+
+// endpointHost shortens an endpoint URL to the distinctive part of its host.
+func endpointHost(endpoint string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+	return strings.TrimSuffix(host, ".googleapis.com")
+}
+
+// formatWait formats a duration as whole seconds, minutes or hours.
+func formatWait(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", max(int(d.Seconds()), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+}
+
+// padCell truncates or pads s to width cells.
+func padCell(s string, width int) string {
+	return lipgloss.NewStyle().Width(width).MaxWidth(width).Inline(true).Render(s)
+}
+
 // renderAboutCard renders the about/version information card.
 func (m *Model) renderAboutCard() string {
 	cardWidth := min(max(m.width-6, 50), 80)